@@ -14,6 +14,7 @@
 package ast
 
 import (
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -26,6 +27,8 @@ var (
 	_ DDLNode = &DropTableStmt{}
 	_ DDLNode = &AlterTableStmt{}
 	_ DDLNode = &TruncateTableStmt{}
+	_ DDLNode = &CreateViewStmt{}
+	_ DDLNode = &DropViewStmt{}
 	_ Node    = &IndexColName{}
 	_ Node    = &ReferenceDef{}
 	_ Node    = &ColumnOption{}
@@ -351,6 +354,64 @@ func (n *DropTableStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// CreateViewStmt is a statement to create a view.
+// See: https://dev.mysql.com/doc/refman/5.7/en/create-view.html
+type CreateViewStmt struct {
+	ddlNode
+
+	OrReplace bool
+	ViewName  *TableName
+	// Cols are the column names of the view, the field names of Select are used if it is empty.
+	Cols   []model.CIStr
+	Select *SelectStmt
+}
+
+// Accept implements Node Accept interface.
+func (n *CreateViewStmt) Accept(v Visitor) (Node, bool) {
+	newNod, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNod)
+	}
+	n = newNod.(*CreateViewStmt)
+	node, ok := n.ViewName.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.ViewName = node.(*TableName)
+	node, ok = n.Select.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Select = node.(*SelectStmt)
+	return v.Leave(n)
+}
+
+// DropViewStmt is a statement to drop one or more views.
+// See: https://dev.mysql.com/doc/refman/5.7/en/drop-view.html
+type DropViewStmt struct {
+	ddlNode
+
+	IfExists bool
+	Views    []*TableName
+}
+
+// Accept implements Node Accept interface.
+func (n *DropViewStmt) Accept(v Visitor) (Node, bool) {
+	newNod, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNod)
+	}
+	n = newNod.(*DropViewStmt)
+	for i, val := range n.Views {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Views[i] = node.(*TableName)
+	}
+	return v.Leave(n)
+}
+
 // CreateIndexStmt is a statement to create an index.
 // See: https://dev.mysql.com/doc/refman/5.7/en/create-index.html
 type CreateIndexStmt struct {
@@ -30,6 +30,8 @@ var (
 	_ Node    = &Limit{}
 	_ Node    = &WildCardField{}
 	_ Node    = &SelectField{}
+	_ Node    = &WithClause{}
+	_ Node    = &CommonTableExpression{}
 )
 
 // JoinType is join type, including cross/left/right/full.
@@ -120,6 +122,9 @@ type TableSource struct {
 
 	// AsName is the as name of the table source.
 	AsName model.CIStr
+
+	// IndexHints are the USE, IGNORE and FORCE INDEX hints of a table.
+	IndexHints []*IndexHint
 }
 
 // Accept implements Node Accept interface.
@@ -137,6 +142,23 @@ func (n *TableSource) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// IndexHintType is the type of an index hint.
+type IndexHintType int
+
+// Index hint types.
+const (
+	HintUse IndexHintType = iota + 1
+	HintIgnore
+	HintForce
+)
+
+// IndexHint represents an index hint of a table, e.g. USE INDEX (idx).
+// See: https://dev.mysql.com/doc/refman/5.7/en/index-hints.html
+type IndexHint struct {
+	IndexNames []model.CIStr
+	HintType   IndexHintType
+}
+
 // OnCondition represetns JOIN on condition.
 type OnCondition struct {
 	node
@@ -373,6 +395,8 @@ type SelectStmt struct {
 	dmlNode
 	resultSetNode
 
+	// With is the WITH clause of the query, it is nil if there is none.
+	With *WithClause
 	// Distinct represents if the select has distinct option.
 	Distinct bool
 	// From is the from clause of the query.
@@ -401,6 +425,14 @@ func (n *SelectStmt) Accept(v Visitor) (Node, bool) {
 	}
 	n = newNod.(*SelectStmt)
 
+	if n.With != nil {
+		node, ok := n.With.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.With = node.(*WithClause)
+	}
+
 	if n.From != nil {
 		node, ok := n.From.Accept(v)
 		if !ok {
@@ -459,6 +491,59 @@ func (n *SelectStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// WithClause is the WITH clause of a query, which defines common table expressions.
+// See: https://dev.mysql.com/doc/refman/8.0/en/with.html
+type WithClause struct {
+	node
+
+	// IsRecursive is true for WITH RECURSIVE, the common table expressions may refer to themselves.
+	IsRecursive bool
+	CTEs        []*CommonTableExpression
+}
+
+// Accept implements Node Accept interface.
+func (n *WithClause) Accept(v Visitor) (Node, bool) {
+	newNod, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNod)
+	}
+	n = newNod.(*WithClause)
+	for i, val := range n.CTEs {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.CTEs[i] = node.(*CommonTableExpression)
+	}
+	return v.Leave(n)
+}
+
+// CommonTableExpression is a named temporary result set defined in WITH clause,
+// it can be referred to as a table in the query.
+type CommonTableExpression struct {
+	node
+
+	Name model.CIStr
+	// ColNames are the column names of the result set, the field names of Query are used if it is empty.
+	ColNames []model.CIStr
+	Query    *SubqueryExpr
+}
+
+// Accept implements Node Accept interface.
+func (n *CommonTableExpression) Accept(v Visitor) (Node, bool) {
+	newNod, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNod)
+	}
+	n = newNod.(*CommonTableExpression)
+	node, ok := n.Query.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Query = node.(*SubqueryExpr)
+	return v.Leave(n)
+}
+
 // UnionClause represents a single "UNION SELECT ..." or "UNION (SELECT ...)" clause.
 type UnionClause struct {
 	node
@@ -488,6 +573,8 @@ type UnionStmt struct {
 	dmlNode
 	resultSetNode
 
+	// With is the WITH clause of the union, it is nil if there is none.
+	With     *WithClause
 	Distinct bool
 	Selects  []*SelectStmt
 	OrderBy  *OrderByClause
@@ -501,6 +588,13 @@ func (n *UnionStmt) Accept(v Visitor) (Node, bool) {
 		return v.Leave(newNod)
 	}
 	n = newNod.(*UnionStmt)
+	if n.With != nil {
+		node, ok := n.With.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.With = node.(*WithClause)
+	}
 	for i, val := range n.Selects {
 		node, ok := val.Accept(v)
 		if !ok {
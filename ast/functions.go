@@ -30,6 +30,10 @@ var (
 	_ FuncNode = &FuncTrimExpr{}
 	_ FuncNode = &FuncDateArithExpr{}
 	_ FuncNode = &AggregateFuncExpr{}
+	_ FuncNode = &WindowFuncExpr{}
+	_ Node     = &WindowSpec{}
+	_ Node     = &FrameClause{}
+	_ Node     = &FrameBound{}
 )
 
 // UnquoteString is not quoted when printed.
@@ -426,3 +430,162 @@ func (n *AggregateFuncExpr) Accept(v Visitor) (Node, bool) {
 	}
 	return v.Leave(n)
 }
+
+// WindowFuncExpr represents window function expression, it is a window function
+// or an aggregate function with OVER clause.
+// See: https://dev.mysql.com/doc/refman/8.0/en/window-functions.html
+type WindowFuncExpr struct {
+	funcNode
+	// F is the function name.
+	F string
+	// Args is the function args.
+	Args []ExprNode
+	// Distinct is the DISTINCT of the aggregate function, which is not supported in a window.
+	Distinct bool
+	// Spec is the window of the function.
+	Spec *WindowSpec
+}
+
+// Accept implements Node Accept interface.
+func (n *WindowFuncExpr) Accept(v Visitor) (Node, bool) {
+	newNod, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNod)
+	}
+	n = newNod.(*WindowFuncExpr)
+	for i, val := range n.Args {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Args[i] = node.(ExprNode)
+	}
+	node, ok := n.Spec.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Spec = node.(*WindowSpec)
+	return v.Leave(n)
+}
+
+// WindowSpec is the window specification in OVER clause, the rows are
+// partitioned by PartitionBy, and sorted by OrderBy in a partition.
+type WindowSpec struct {
+	node
+
+	PartitionBy []*ByItem
+	// OrderBy is nil if there is no ORDER BY.
+	OrderBy *OrderByClause
+	// Frame is nil if there is no frame clause.
+	Frame *FrameClause
+}
+
+// Accept implements Node Accept interface.
+func (n *WindowSpec) Accept(v Visitor) (Node, bool) {
+	newNod, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNod)
+	}
+	n = newNod.(*WindowSpec)
+	for i, val := range n.PartitionBy {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.PartitionBy[i] = node.(*ByItem)
+	}
+	if n.OrderBy != nil {
+		node, ok := n.OrderBy.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.OrderBy = node.(*OrderByClause)
+	}
+	if n.Frame != nil {
+		node, ok := n.Frame.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Frame = node.(*FrameClause)
+	}
+	return v.Leave(n)
+}
+
+// FrameType is the unit of a window frame.
+type FrameType int
+
+// Frame types.
+const (
+	// Rows is the frame of ROWS unit, the bounds are row offsets.
+	Rows FrameType = iota + 1
+	// Ranges is the frame of RANGE unit, the bounds are value offsets of ORDER BY.
+	Ranges
+)
+
+// FrameClause is the frame of a window, which is the rows in the partition
+// between Start and End.
+type FrameClause struct {
+	node
+
+	Type  FrameType
+	Start *FrameBound
+	End   *FrameBound
+}
+
+// Accept implements Node Accept interface.
+func (n *FrameClause) Accept(v Visitor) (Node, bool) {
+	newNod, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNod)
+	}
+	n = newNod.(*FrameClause)
+	node, ok := n.Start.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Start = node.(*FrameBound)
+	node, ok = n.End.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.End = node.(*FrameBound)
+	return v.Leave(n)
+}
+
+// BoundType is the type of a frame bound.
+type BoundType int
+
+// Bound types.
+const (
+	Preceding BoundType = iota + 1
+	Following
+	CurrentRow
+)
+
+// FrameBound is a bound of a window frame, it is CURRENT ROW, UNBOUNDED PRECEDING,
+// UNBOUNDED FOLLOWING, or Expr PRECEDING or FOLLOWING.
+type FrameBound struct {
+	node
+
+	Type      BoundType
+	UnBounded bool
+	// Expr is nil for UNBOUNDED and CURRENT ROW.
+	Expr ExprNode
+}
+
+// Accept implements Node Accept interface.
+func (n *FrameBound) Accept(v Visitor) (Node, bool) {
+	newNod, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNod)
+	}
+	n = newNod.(*FrameBound)
+	if n.Expr != nil {
+		node, ok := n.Expr.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Expr = node.(ExprNode)
+	}
+	return v.Leave(n)
+}
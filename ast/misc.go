@@ -31,6 +31,7 @@ var (
 	_ StmtNode = &CreateUserStmt{}
 	_ StmtNode = &DoStmt{}
 	_ StmtNode = &GrantStmt{}
+	_ StmtNode = &AnalyzeTableStmt{}
 
 	_ Node = &VariableAssignment{}
 )
@@ -58,6 +59,11 @@ type ExplainStmt struct {
 	stmtNode
 
 	Stmt StmtNode
+	// Analyze is true for EXPLAIN ANALYZE, which executes the statement and
+	// explains it with the runtime statistics.
+	Analyze bool
+	// Format is the output format of EXPLAIN FORMAT=..., e.g. "json".
+	Format string
 }
 
 // Accept implements Node Accept interface.
@@ -168,6 +174,8 @@ const (
 	ShowCreateTable
 	ShowGrants
 	ShowTriggers
+	ShowStats
+	ShowCreateView
 )
 
 // ShowStmt is a statement to provide information about databases, tables, columns and so on.
@@ -177,7 +185,7 @@ type ShowStmt struct {
 
 	Tp     ShowStmtType // Databases/Tables/Columns/....
 	DBName string
-	Table  *TableName  // Used for showing columns.
+	Table  *TableName  // Used for showing columns and statistics.
 	Column *ColumnName // Used for `desc table column`.
 	Flag   int         // Some flag parsed from sql, such as FULL.
 	Full   bool
@@ -428,6 +436,31 @@ func (n *DoStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// AnalyzeTableStmt is a statement to collect the statistics of tables.
+// See: https://dev.mysql.com/doc/refman/5.7/en/analyze-table.html
+type AnalyzeTableStmt struct {
+	stmtNode
+
+	TableNames []*TableName
+}
+
+// Accept implements Node Accept interface.
+func (n *AnalyzeTableStmt) Accept(v Visitor) (Node, bool) {
+	newNod, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNod)
+	}
+	n = newNod.(*AnalyzeTableStmt)
+	for i, val := range n.TableNames {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.TableNames[i] = node.(*TableName)
+	}
+	return v.Leave(n)
+}
+
 // PrivElem is the privilege type and optional column list.
 type PrivElem struct {
 	node
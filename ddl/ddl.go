@@ -53,6 +53,8 @@ type DDL interface {
 	DropSchema(ctx context.Context, schema model.CIStr) error
 	CreateTable(ctx context.Context, ident table.Ident, cols []*coldef.ColumnDef, constrs []*coldef.TableConstraint) error
 	DropTable(ctx context.Context, tableIdent table.Ident) (err error)
+	CreateView(ctx context.Context, ident table.Ident, cols []*model.ColumnInfo, view *model.ViewInfo, orReplace bool) error
+	DropView(ctx context.Context, ident table.Ident) error
 	CreateIndex(ctx context.Context, tableIdent table.Ident, unique bool, indexName model.CIStr, columnNames []*coldef.IndexColName) error
 	DropIndex(ctx context.Context, schema, tableName, indexName model.CIStr) error
 	GetInformationSchema() infoschema.InfoSchema
@@ -342,7 +344,8 @@ func checkConstraintNames(constraints []*coldef.TableConstraint) error {
 
 func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*column.Col, constraints []*coldef.TableConstraint) (tbInfo *model.TableInfo, err error) {
 	tbInfo = &model.TableInfo{
-		Name: tableName,
+		Name:    tableName,
+		Version: model.CurrLatestTableInfoVersion,
 	}
 	tbInfo.ID, err = d.genGlobalID()
 	if err != nil {
@@ -360,6 +363,9 @@ func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*column.Col, constrai
 			if col == nil {
 				return nil, errors.Errorf("No such column: %v", key)
 			}
+			if col.Tp == mysql.TypeJSON {
+				return nil, errors.Trace(mysql.NewErr(mysql.ErrJSONUsedAsKey, col.Name.O))
+			}
 			indexColumns = append(indexColumns, &model.IndexColumn{
 				Name:   model.NewCIStr(key.ColumnName),
 				Offset: col.Offset,
@@ -442,6 +448,9 @@ func (d *ddl) AlterTable(ctx context.Context, ident table.Ident, specs []*AlterS
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if tbl.Meta().IsView() {
+		return errors.Errorf("'%s.%s' is not BASE TABLE", ident.Schema, ident.Name)
+	}
 	for _, spec := range specs {
 		switch spec.Action {
 		case AlterAddColumn:
@@ -596,6 +605,89 @@ func (d *ddl) DropTable(ctx context.Context, ti table.Ident) (err error) {
 	return errors.Trace(err)
 }
 
+// CreateView creates a view with the columns, or replaces the existing view
+// if orReplace is true.
+func (d *ddl) CreateView(ctx context.Context, ident table.Ident, cols []*model.ColumnInfo, view *model.ViewInfo, orReplace bool) (err error) {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return terror.DatabaseNotExists.Gen("database %s not exists", ident.Schema)
+	}
+	tbInfo := &model.TableInfo{
+		Name:    ident.Name,
+		Columns: cols,
+		View:    view,
+	}
+	old, err := is.TableByName(ident.Schema, ident.Name)
+	if err == nil {
+		if !old.Meta().IsView() {
+			return errors.Errorf("'%s.%s' is not VIEW", ident.Schema, ident.Name)
+		}
+		if !orReplace {
+			return errors.Trace(ErrExists)
+		}
+		tbInfo.ID = old.Meta().ID
+	} else if tbInfo.ID, err = d.genGlobalID(); err != nil {
+		return errors.Trace(err)
+	}
+	for _, col := range cols {
+		if col.ID, err = d.genGlobalID(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	log.Infof("New view: %+v", tbInfo)
+
+	err = kv.RunInNewTxn(d.store, false, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		err := d.verifySchemaMetaVersion(t, is.SchemaMetaVersion())
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		if old != nil {
+			err = t.UpdateTable(schema.ID, tbInfo)
+		} else {
+			err = t.CreateTable(schema.ID, tbInfo)
+		}
+		return errors.Trace(err)
+	})
+	if d.onDDLChange != nil {
+		err = d.onDDLChange(err)
+	}
+	return errors.Trace(err)
+}
+
+// DropView drops a view, a view has no data to delete.
+func (d *ddl) DropView(ctx context.Context, ti table.Ident) (err error) {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return terror.DatabaseNotExists.Gen("database %s not exists", ti.Schema)
+	}
+
+	tb, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !tb.Meta().IsView() {
+		return errors.Errorf("'%s.%s' is not VIEW", ti.Schema, ti.Name)
+	}
+	err = kv.RunInNewTxn(d.store, false, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		err := d.verifySchemaMetaVersion(t, is.SchemaMetaVersion())
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		err = t.DropTable(schema.ID, tb.Meta().ID)
+		return errors.Trace(err)
+	})
+	if d.onDDLChange != nil {
+		err = d.onDDLChange(err)
+	}
+	return errors.Trace(err)
+}
+
 func (d *ddl) deleteTableData(ctx context.Context, t table.Table) error {
 	// Remove data.
 	err := t.Truncate(ctx)
@@ -629,6 +721,9 @@ func (d *ddl) CreateIndex(ctx context.Context, ti table.Ident, unique bool, inde
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if t.Meta().IsView() {
+		return errors.Errorf("'%s.%s' is not BASE TABLE", ti.Schema, ti.Name)
+	}
 	if _, ok := is.IndexByName(ti.Schema, ti.Name, indexName); ok {
 		return errors.Errorf("CREATE INDEX: index already exist %s", indexName)
 	}
@@ -645,6 +740,9 @@ func (d *ddl) CreateIndex(ctx context.Context, ti table.Ident, unique bool, inde
 		if col == nil {
 			return errors.Errorf("CREATE INDEX: column does not exist: %s", ic.ColumnName)
 		}
+		if col.Tp == mysql.TypeJSON {
+			return errors.Trace(mysql.NewErr(mysql.ErrJSONUsedAsKey, col.Name.O))
+		}
 		idxColumns = append(idxColumns, &model.IndexColumn{
 			Name:   col.Name,
 			Offset: col.Offset,
@@ -22,6 +22,7 @@ import (
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/statistics"
 )
 
 // Domain represents a storage space. Different domains can use the same database name.
@@ -30,6 +31,7 @@ type Domain struct {
 	store      kv.Storage
 	infoHandle *infoschema.Handle
 	ddl        ddl.DDL
+	statsCache *statistics.Cache
 	lease      int
 }
 
@@ -75,6 +77,11 @@ func (do *Domain) DDL() ddl.DDL {
 	return do.ddl
 }
 
+// StatsCache gets the cache of the table statistics from domain.
+func (do *Domain) StatsCache() *statistics.Cache {
+	return do.statsCache
+}
+
 // Store gets KV store from domain.
 func (do *Domain) Store() kv.Storage {
 	return do.store
@@ -115,8 +122,9 @@ func (do *Domain) loadSchemaInLoop() {
 // NewDomain creates a new domain.
 func NewDomain(store kv.Storage, lease int) (d *Domain, err error) {
 	d = &Domain{
-		store: store,
-		lease: lease,
+		store:      store,
+		statsCache: statistics.NewCache(),
+		lease:      lease,
 	}
 
 	d.infoHandle = infoschema.NewHandle(d.store)
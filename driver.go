@@ -433,6 +433,8 @@ func (r *driverRows) Next(dest []driver.Value) error {
 			dest[i] = v.ToString()
 		case mysql.Bit:
 			dest[i] = v.ToString()
+		case mysql.JSON:
+			dest[i] = v.String()
 		case mysql.Enum:
 			dest[i] = v.String()
 		case mysql.Set:
@@ -684,6 +684,8 @@ func (o *BinaryOperation) coerceArithmetic(a interface{}) (interface{}, error) {
 		return x.ToNumber(), nil
 	case mysql.Set:
 		return x.ToNumber(), nil
+	case mysql.JSON:
+		return o.coerceArithmetic(x.Value)
 	default:
 		return x, nil
 	}
@@ -55,8 +55,38 @@ var Funcs = map[string]Func{
 	"coalesce": {builtinCoalesce, 1, -1, true, false},
 
 	// math functions
-	"abs":  {builtinAbs, 1, 1, true, false},
-	"rand": {builtinRand, 0, 1, true, false},
+	"abs":      {builtinAbs, 1, 1, true, false},
+	"acos":     {builtinAcos, 1, 1, true, false},
+	"asin":     {builtinAsin, 1, 1, true, false},
+	"atan":     {builtinAtan, 1, 2, true, false},
+	"atan2":    {builtinAtan, 2, 2, true, false},
+	"ceil":     {builtinCeil, 1, 1, true, false},
+	"ceiling":  {builtinCeil, 1, 1, true, false},
+	"conv":     {builtinConv, 3, 3, true, false},
+	"cos":      {builtinCos, 1, 1, true, false},
+	"cot":      {builtinCot, 1, 1, true, false},
+	"crc32":    {builtinCRC32, 1, 1, true, false},
+	"degrees":  {builtinDegrees, 1, 1, true, false},
+	"exp":      {builtinExp, 1, 1, true, false},
+	"floor":    {builtinFloor, 1, 1, true, false},
+	"greatest": {builtinGreatest, 2, -1, true, false},
+	"least":    {builtinLeast, 2, -1, true, false},
+	"ln":       {builtinLn, 1, 1, true, false},
+	"log":      {builtinLog, 1, 2, true, false},
+	"log10":    {builtinLog10, 1, 1, true, false},
+	"log2":     {builtinLog2, 1, 1, true, false},
+	"mod":      {builtinMod, 2, 2, true, false},
+	"pi":       {builtinPI, 0, 0, true, false},
+	"pow":      {builtinPow, 2, 2, true, false},
+	"power":    {builtinPow, 2, 2, true, false},
+	"radians":  {builtinRadians, 1, 1, true, false},
+	"rand":     {builtinRand, 0, 1, true, false},
+	"round":    {builtinRound, 1, 2, true, false},
+	"sign":     {builtinSign, 1, 1, true, false},
+	"sin":      {builtinSin, 1, 1, true, false},
+	"sqrt":     {builtinSqrt, 1, 1, true, false},
+	"tan":      {builtinTan, 1, 1, true, false},
+	"truncate": {builtinTruncate, 2, 2, true, false},
 
 	// group by functions
 	"avg":          {builtinAvg, 1, 1, false, true},
@@ -67,21 +97,34 @@ var Funcs = map[string]Func{
 	"sum":          {builtinSum, 1, 1, false, true},
 
 	// time functions
+	"convert_tz":        {builtinConvertTZ, 3, 3, true, false},
 	"curdate":           {builtinCurrentDate, 0, 0, false, false},
 	"current_date":      {builtinCurrentDate, 0, 0, false, false},
 	"current_timestamp": {builtinNow, 0, 1, false, false},
 	"date":              {builtinDate, 8, 8, true, false},
+	"date_format":       {builtinDateFormat, 2, 2, true, false},
+	"datediff":          {builtinDateDiff, 2, 2, true, false},
 	"day":               {builtinDay, 1, 1, true, false},
+	"dayname":           {builtinDayName, 1, 1, true, false},
 	"dayofmonth":        {builtinDayOfMonth, 1, 1, true, false},
 	"dayofweek":         {builtinDayOfWeek, 1, 1, true, false},
 	"dayofyear":         {builtinDayOfYear, 1, 1, true, false},
+	"from_unixtime":     {builtinFromUnixTime, 1, 2, true, false},
 	"hour":              {builtinHour, 1, 1, true, false},
+	"last_day":          {builtinLastDay, 1, 1, true, false},
+	"makedate":          {builtinMakeDate, 2, 2, true, false},
 	"microsecond":       {builtinMicroSecond, 1, 1, true, false},
 	"minute":            {builtinMinute, 1, 1, true, false},
 	"month":             {builtinMonth, 1, 1, true, false},
+	"monthname":         {builtinMonthName, 1, 1, true, false},
 	"now":               {builtinNow, 0, 1, false, false},
 	"second":            {builtinSecond, 1, 1, true, false},
+	"str_to_date":       {builtinStrToDate, 2, 2, true, false},
 	"sysdate":           {builtinSysDate, 0, 1, false, false},
+	"time_format":       {builtinTimeFormat, 2, 2, true, false},
+	"timestampadd":      {builtinTimestampAdd, 3, 3, true, false},
+	"timestampdiff":     {builtinTimestampDiff, 3, 3, true, false},
+	"unix_timestamp":    {builtinUnixTimestamp, 0, 1, false, false},
 	"week":              {builtinWeek, 1, 2, true, false},
 	"weekday":           {builtinWeekDay, 1, 1, true, false},
 	"weekofyear":        {builtinWeekOfYear, 1, 1, true, false},
@@ -94,20 +137,50 @@ var Funcs = map[string]Func{
 	"nullif": {builtinNullIf, 2, 2, true, false},
 
 	// string functions
-	"concat":    {builtinConcat, 1, -1, true, false},
-	"concat_ws": {builtinConcatWS, 2, -1, true, false},
-	"left":      {builtinLeft, 2, 2, true, false},
-	"length":    {builtinLength, 1, 1, true, false},
-	"lower":     {builtinLower, 1, 1, true, false},
-	"repeat":    {builtinRepeat, 2, 2, true, false},
-	"replace":   {builtinReplace, 3, 3, true, false},
-	"upper":     {builtinUpper, 1, 1, true, false},
+	"ascii":            {builtinASCII, 1, 1, true, false},
+	"char":             {builtinChar, 1, -1, true, false},
+	"char_length":      {builtinCharLength, 1, 1, true, false},
+	"character_length": {builtinCharLength, 1, 1, true, false},
+	"concat":           {builtinConcat, 1, -1, true, false},
+	"concat_ws":        {builtinConcatWS, 2, -1, true, false},
+	"elt":              {builtinElt, 2, -1, true, false},
+	"field":            {builtinField, 2, -1, true, false},
+	"find_in_set":      {builtinFindInSet, 2, 2, true, false},
+	"format":           {builtinFormat, 2, 3, true, false},
+	"hex":              {builtinHex, 1, 1, true, false},
+	"instr":            {builtinInstr, 2, 2, true, false},
+	"left":             {builtinLeft, 2, 2, true, false},
+	"length":           {builtinLength, 1, 1, true, false},
+	"lower":            {builtinLower, 1, 1, true, false},
+	"lpad":             {builtinLpad, 3, 3, true, false},
+	"ltrim":            {builtinLTrim, 1, 1, true, false},
+	"repeat":           {builtinRepeat, 2, 2, true, false},
+	"replace":          {builtinReplace, 3, 3, true, false},
+	"reverse":          {builtinReverse, 1, 1, true, false},
+	"right":            {builtinRight, 2, 2, true, false},
+	"rpad":             {builtinRpad, 3, 3, true, false},
+	"rtrim":            {builtinRTrim, 1, 1, true, false},
+	"space":            {builtinSpace, 1, 1, true, false},
+	"strcmp":           {builtinStrcmp, 2, 2, true, false},
+	"substring_index":  {builtinSubstringIndex, 3, 3, true, false},
+	"unhex":            {builtinUnHex, 1, 1, true, false},
+	"upper":            {builtinUpper, 1, 1, true, false},
 
 	// information functions
 	"current_user": {builtinCurrentUser, 0, 0, false, false},
 	"database":     {builtinDatabase, 0, 0, false, false},
 	"found_rows":   {builtinFoundRows, 0, 0, false, false},
 	"user":         {builtinUser, 0, 0, false, false},
+
+	// json functions
+	"json_array":    {builtinJSONArray, 0, -1, true, false},
+	"json_contains": {builtinJSONContains, 2, 3, true, false},
+	"json_extract":  {builtinJSONExtract, 2, -1, true, false},
+	"json_object":   {builtinJSONObject, 0, -1, true, false},
+	"json_remove":   {builtinJSONRemove, 2, -1, true, false},
+	"json_set":      {builtinJSONSet, 3, -1, true, false},
+	"json_type":     {builtinJSONType, 1, 1, true, false},
+	"json_unquote":  {builtinJSONUnquote, 1, 1, true, false},
 }
 
 func invArg(arg interface{}, s string) error {
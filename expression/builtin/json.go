@@ -0,0 +1,226 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builtin
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// toJSON converts the JSON document argument of the function name,
+// a string is parsed as JSON text. ok is false if the argument is NULL.
+func toJSON(arg interface{}, name string) (j mysql.JSON, ok bool, err error) {
+	switch x := types.RawData(arg).(type) {
+	case nil:
+		return j, false, nil
+	case mysql.JSON:
+		return x, true, nil
+	case string:
+		j, err = mysql.ParseJSON(x)
+	case []byte:
+		j, err = mysql.ParseJSON(string(x))
+	default:
+		err = invArg(arg, name)
+	}
+	if err != nil {
+		return j, false, errors.Trace(err)
+	}
+	return j, true, nil
+}
+
+// toJSONPaths parses the path arguments, ok is false if any of them is NULL.
+// The paths must not contain wildcards if wildcard is false.
+func toJSONPaths(args []interface{}, wildcard bool) (paths []mysql.JSONPath, ok bool, err error) {
+	for _, arg := range args {
+		if types.IsNil(arg) {
+			return nil, false, nil
+		}
+		s, err := types.ToString(arg)
+		if err != nil {
+			return nil, false, errors.Trace(err)
+		}
+		path, err := mysql.ParseJSONPath(s)
+		if err != nil {
+			return nil, false, errors.Trace(err)
+		}
+		if !wildcard && path.ContainsWildcard() {
+			return nil, false, errors.Trace(mysql.NewErr(mysql.ErrInvalidJSONPathWildcard))
+		}
+		paths = append(paths, path)
+	}
+	return paths, true, nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/json-search-functions.html#function_json-extract
+func builtinJSONExtract(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	j, ok, err := toJSON(args[0], "json_extract")
+	if err != nil || !ok {
+		return nil, errors.Trace(err)
+	}
+	paths, ok, err := toJSONPaths(args[1:], true)
+	if err != nil || !ok {
+		return nil, errors.Trace(err)
+	}
+
+	v, found := j.Extract(paths)
+	if !found {
+		return nil, nil
+	}
+	return v, nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/json-modification-functions.html#function_json-set
+func builtinJSONSet(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	if len(args)%2 != 1 {
+		return nil, errors.Trace(mysql.NewErr(mysql.ErrWrongParamcountToNativeFct, "json_set"))
+	}
+	j, ok, err := toJSON(args[0], "json_set")
+	if err != nil || !ok {
+		return nil, errors.Trace(err)
+	}
+
+	for i := 1; i < len(args); i += 2 {
+		paths, ok, err := toJSONPaths(args[i:i+1], false)
+		if err != nil || !ok {
+			return nil, errors.Trace(err)
+		}
+		v, err := mysql.ConvertToJSON(types.RawData(args[i+1]))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		j = j.Set(paths[0], v)
+	}
+	return j, nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/json-modification-functions.html#function_json-remove
+func builtinJSONRemove(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	j, ok, err := toJSON(args[0], "json_remove")
+	if err != nil || !ok {
+		return nil, errors.Trace(err)
+	}
+	paths, ok, err := toJSONPaths(args[1:], false)
+	if err != nil || !ok {
+		return nil, errors.Trace(err)
+	}
+
+	for _, path := range paths {
+		if path.IsDocument() {
+			return nil, errors.Trace(mysql.NewErr(mysql.ErrJSONVacuousPath))
+		}
+		j = j.Remove(path)
+	}
+	return j, nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/json-search-functions.html#function_json-contains
+func builtinJSONContains(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	target, ok1, err := toJSON(args[0], "json_contains")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	candidate, ok2, err := toJSON(args[1], "json_contains")
+	if err != nil || !ok1 || !ok2 {
+		return nil, errors.Trace(err)
+	}
+
+	if len(args) == 3 {
+		paths, ok, err := toJSONPaths(args[2:], false)
+		if err != nil || !ok {
+			return nil, errors.Trace(err)
+		}
+		var found bool
+		if target, found = target.Extract(paths); !found {
+			return nil, nil
+		}
+	}
+
+	if target.Contains(candidate) {
+		return int64(1), nil
+	}
+	return int64(0), nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/json-creation-functions.html#function_json-array
+func builtinJSONArray(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	a := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		v, err := mysql.ConvertToJSON(types.RawData(arg))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		a = append(a, v.Value)
+	}
+	return mysql.JSON{Value: a}, nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/json-creation-functions.html#function_json-object
+func builtinJSONObject(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	if len(args)%2 != 0 {
+		return nil, errors.Trace(mysql.NewErr(mysql.ErrWrongParamcountToNativeFct, "json_object"))
+	}
+
+	m := make(map[string]interface{}, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		if types.IsNil(args[i]) {
+			return nil, errors.Trace(mysql.NewErr(mysql.ErrJSONDocumentNULLKey))
+		}
+		key, err := types.ToString(args[i])
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		v, err := mysql.ConvertToJSON(types.RawData(args[i+1]))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		m[key] = v.Value
+	}
+	return mysql.JSON{Value: m}, nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/json-attribute-functions.html#function_json-type
+func builtinJSONType(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	j, ok, err := toJSON(args[0], "json_type")
+	if err != nil || !ok {
+		return nil, errors.Trace(err)
+	}
+	return j.Type(), nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/json-modification-functions.html#function_json-unquote
+func builtinJSONUnquote(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	switch x := types.RawData(args[0]).(type) {
+	case nil:
+		return nil, nil
+	case mysql.JSON:
+		return x.Unquote(), nil
+	}
+
+	s, err := types.ToString(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Only the strings in double quotes are unquoted.
+	if len(s) < 2 || !strings.HasPrefix(s, `"`) || !strings.HasSuffix(s, `"`) {
+		return s, nil
+	}
+	j, err := mysql.ParseJSON(s)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return j.Unquote(), nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builtin
+
+import (
+	"fmt"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/mysql"
+)
+
+func (s *testBuiltinSuite) TestJSONFunctions(c *C) {
+	doc := `{"a": [1, {"b": "x"}], "c": 2}`
+	tbl := []struct {
+		F      func([]interface{}, map[interface{}]interface{}) (interface{}, error)
+		Args   []interface{}
+		Expect interface{}
+	}{
+		{builtinJSONExtract, []interface{}{doc, "$.a[1].b"}, `"x"`},
+		{builtinJSONExtract, []interface{}{doc, "$.a[0]", "$.c"}, `[1, 2]`},
+		{builtinJSONExtract, []interface{}{doc, "$.a[*]"}, `[1, {"b": "x"}]`},
+		{builtinJSONExtract, []interface{}{doc, "$.d"}, nil},
+		{builtinJSONExtract, []interface{}{nil, "$.c"}, nil},
+		{builtinJSONExtract, []interface{}{doc, nil}, nil},
+		{builtinJSONSet, []interface{}{doc, "$.c", int64(3), "$.d", "y"}, `{"a": [1, {"b": "x"}], "c": 3, "d": "y"}`},
+		{builtinJSONSet, []interface{}{doc, "$.a[0]", mysql.JSON{Value: []interface{}{true}}}, `{"a": [[true], {"b": "x"}], "c": 2}`},
+		{builtinJSONSet, []interface{}{doc, "$.c", nil}, `{"a": [1, {"b": "x"}], "c": null}`},
+		{builtinJSONSet, []interface{}{nil, "$.c", int64(1)}, nil},
+		{builtinJSONRemove, []interface{}{doc, "$.a[0]", "$.c"}, `{"a": [{"b": "x"}]}`},
+		{builtinJSONRemove, []interface{}{doc, "$.d"}, doc},
+		{builtinJSONContains, []interface{}{doc, `{"c": 2}`}, int64(1)},
+		{builtinJSONContains, []interface{}{doc, `1`, "$.a"}, int64(1)},
+		{builtinJSONContains, []interface{}{doc, `3`, "$.c"}, int64(0)},
+		{builtinJSONContains, []interface{}{doc, `1`, "$.d"}, nil},
+		{builtinJSONContains, []interface{}{doc, nil}, nil},
+		{builtinJSONArray, []interface{}{}, `[]`},
+		{builtinJSONArray, []interface{}{int64(1), "a", nil, 1.5, mysql.NewDecimalFromFloat(2.5)}, `[1, "a", null, 1.5, 2.5]`},
+		{builtinJSONObject, []interface{}{}, `{}`},
+		{builtinJSONObject, []interface{}{"a", int64(1), "b", `[1]`}, `{"a": 1, "b": "[1]"}`},
+		{builtinJSONType, []interface{}{`[1]`}, "ARRAY"},
+		{builtinJSONType, []interface{}{mysql.JSON{Value: int64(1)}}, "INTEGER"},
+		{builtinJSONType, []interface{}{nil}, nil},
+		{builtinJSONUnquote, []interface{}{mysql.JSON{Value: "a\nb"}}, "a\nb"},
+		{builtinJSONUnquote, []interface{}{mysql.JSON{Value: []interface{}{"a"}}}, `["a"]`},
+		{builtinJSONUnquote, []interface{}{`"a\tb"`}, "a\tb"},
+		{builtinJSONUnquote, []interface{}{`abc`}, "abc"},
+		{builtinJSONUnquote, []interface{}{nil}, nil},
+	}
+
+	for _, t := range tbl {
+		v, err := t.F(t.Args, nil)
+		c.Assert(err, IsNil, Commentf("%v", t.Args))
+		if t.Expect == nil {
+			c.Assert(v, IsNil, Commentf("%v", t.Args))
+			continue
+		}
+		c.Assert(fmt.Sprint(v), Equals, fmt.Sprint(t.Expect), Commentf("%v", t.Args))
+	}
+
+	errTbl := []struct {
+		F    func([]interface{}, map[interface{}]interface{}) (interface{}, error)
+		Args []interface{}
+	}{
+		{builtinJSONExtract, []interface{}{`{"a"`, "$.a"}},
+		{builtinJSONExtract, []interface{}{int64(1), "$.a"}},
+		{builtinJSONExtract, []interface{}{doc, "a"}},
+		{builtinJSONSet, []interface{}{doc, "$.a"}},
+		{builtinJSONSet, []interface{}{doc, "$.a[*]", int64(1)}},
+		{builtinJSONRemove, []interface{}{doc, "$"}},
+		{builtinJSONRemove, []interface{}{doc, "$**.b"}},
+		{builtinJSONContains, []interface{}{doc, `1`, "$.a[*]"}},
+		{builtinJSONObject, []interface{}{"a"}},
+		{builtinJSONObject, []interface{}{nil, int64(1)}},
+		{builtinJSONUnquote, []interface{}{`"a\"`}},
+	}
+
+	for _, t := range errTbl {
+		_, err := t.F(t.Args, nil)
+		c.Assert(err, NotNil, Commentf("%v", t.Args))
+	}
+}
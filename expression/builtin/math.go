@@ -18,10 +18,14 @@
 package builtin
 
 import (
+	"hash/crc32"
 	"math"
 	"math/rand"
+	"strconv"
+	"strings"
 
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -43,6 +47,8 @@ func builtinAbs(args []interface{}, ctx map[interface{}]interface{}) (v interfac
 
 		// TODO: handle overflow if x is MinInt64
 		return -v, nil
+	case mysql.Decimal:
+		return x.Abs(), nil
 	default:
 		// we will try to convert other types to float
 		// TODO: if time has no precision, it will be a integer
@@ -63,3 +69,530 @@ func builtinRand(args []interface{}, ctx map[interface{}]interface{}) (v interfa
 
 	return rand.Float64(), nil
 }
+
+// toNumber converts v to int64, uint64, float64 or mysql.Decimal for the math
+// functions, the other types are converted to float64 as the arithmetic
+// operators do.
+func toNumber(v interface{}) (interface{}, error) {
+	switch x := types.RawData(v).(type) {
+	case nil:
+		return nil, nil
+	case int:
+		return int64(x), nil
+	case float32:
+		return float64(x), nil
+	case int64, uint64, float64, mysql.Decimal:
+		return x, nil
+	case mysql.Time:
+		// If time has no precision, it is an integer.
+		d := x.ToNumber()
+		if x.Fsp == 0 {
+			return d.IntPart(), nil
+		}
+		return d, nil
+	case mysql.Duration:
+		d := x.ToNumber()
+		if x.Fsp == 0 {
+			return d.IntPart(), nil
+		}
+		return d, nil
+	default:
+		f, err := types.ToFloat64(x)
+		return f, errors.Trace(err)
+	}
+}
+
+// decimalPlaces returns the decimal places argument D of round and truncate.
+func decimalPlaces(args []interface{}) (int64, bool, error) {
+	if len(args) < 2 {
+		return 0, true, nil
+	}
+	if types.IsNil(args[1]) {
+		return 0, false, nil
+	}
+	d, err := types.ToInt64(args[1])
+	if err != nil {
+		return 0, false, errors.Trace(err)
+	}
+	if d > mysql.MaxFractionDigits {
+		d = mysql.MaxFractionDigits
+	}
+	return d, true, nil
+}
+
+// pow10 returns 10 ^ n as a decimal.
+func pow10(n int64) mysql.Decimal {
+	return mysql.NewDecimalFromInt(1, int32(n))
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_round
+func builtinRound(args []interface{}, ctx map[interface{}]interface{}) (v interface{}, err error) {
+	x, err := toNumber(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	d, ok, err := decimalPlaces(args)
+	if err != nil || !ok || x == nil {
+		return nil, errors.Trace(err)
+	}
+	switch x := x.(type) {
+	case int64:
+		if d >= 0 {
+			return x, nil
+		}
+		return mysql.NewDecimalFromInt(x, 0).Round(int32(d)).IntPart(), nil
+	case uint64:
+		if d >= 0 {
+			return x, nil
+		}
+		if d < -19 {
+			return uint64(0), nil
+		}
+		shift := uint64(math.Pow10(int(-d)))
+		r := x / shift * shift
+		if x%shift >= shift/2 {
+			r += shift
+		}
+		return r, nil
+	case mysql.Decimal:
+		// The exact value is rounded half away from zero, and the result has no
+		// more decimal places than the argument.
+		if d >= int64(x.FracDigits()) {
+			return x, nil
+		}
+		r := x.Round(int32(d))
+		if d < 0 {
+			r = r.Truncate(0)
+		}
+		return r, nil
+	default:
+		// The approximate value is rounded half to even as the C library does.
+		f := x.(float64)
+		if d < -308 {
+			return float64(0), nil
+		}
+		shift := math.Pow10(int(d))
+		r := math.RoundToEven(f*shift) / shift
+		if math.IsInf(r, 0) || math.IsNaN(r) {
+			return f, nil
+		}
+		return r, nil
+	}
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_truncate
+func builtinTruncate(args []interface{}, ctx map[interface{}]interface{}) (v interface{}, err error) {
+	x, err := toNumber(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	d, ok, err := decimalPlaces(args)
+	if err != nil || !ok || x == nil {
+		return nil, errors.Trace(err)
+	}
+	switch x := x.(type) {
+	case int64:
+		if d >= 0 {
+			return x, nil
+		}
+		if d < -18 {
+			return int64(0), nil
+		}
+		shift := int64(math.Pow10(int(-d)))
+		return x / shift * shift, nil
+	case uint64:
+		if d >= 0 {
+			return x, nil
+		}
+		if d < -19 {
+			return uint64(0), nil
+		}
+		shift := uint64(math.Pow10(int(-d)))
+		return x / shift * shift, nil
+	case mysql.Decimal:
+		if d >= int64(x.FracDigits()) {
+			return x, nil
+		}
+		if d >= 0 {
+			return x.Truncate(int32(d)), nil
+		}
+		// Truncate the integer part by moving the decimal point to the left first.
+		return x.Mul(pow10(d)).Truncate(0).Mul(pow10(-d)).Truncate(0), nil
+	default:
+		f := x.(float64)
+		if d < -308 {
+			return float64(0), nil
+		}
+		shift := math.Pow10(int(d))
+		r := math.Trunc(f*shift) / shift
+		if math.IsInf(r, 0) || math.IsNaN(r) {
+			return f, nil
+		}
+		return r, nil
+	}
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_floor
+func builtinFloor(args []interface{}, ctx map[interface{}]interface{}) (v interface{}, err error) {
+	x, err := toNumber(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	switch x := x.(type) {
+	case mysql.Decimal:
+		if x.Exponent() >= 0 {
+			return x.Truncate(0), nil
+		}
+		return x.Floor(), nil
+	case float64:
+		return math.Floor(x), nil
+	default:
+		return x, nil
+	}
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_ceiling
+func builtinCeil(args []interface{}, ctx map[interface{}]interface{}) (v interface{}, err error) {
+	x, err := toNumber(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	switch x := x.(type) {
+	case mysql.Decimal:
+		if x.Exponent() >= 0 {
+			return x.Truncate(0), nil
+		}
+		return x.Ceil(), nil
+	case float64:
+		return math.Ceil(x), nil
+	default:
+		return x, nil
+	}
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_mod
+func builtinMod(args []interface{}, ctx map[interface{}]interface{}) (v interface{}, err error) {
+	x, err := toNumber(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	y, err := toNumber(args[1])
+	if err != nil || x == nil || y == nil {
+		return nil, errors.Trace(err)
+	}
+	switch a := x.(type) {
+	case int64:
+		switch b := y.(type) {
+		case int64:
+			if b == 0 {
+				return nil, nil
+			}
+			if b == -1 {
+				// MinInt64 % -1 panics.
+				return int64(0), nil
+			}
+			return a % b, nil
+		case uint64:
+			if b == 0 {
+				return nil, nil
+			}
+			// The sign of the result is the sign of the dividend.
+			if a < 0 {
+				return -int64(uint64(-a) % b), nil
+			}
+			return int64(uint64(a) % b), nil
+		}
+	case uint64:
+		switch b := y.(type) {
+		case int64:
+			if b == 0 {
+				return nil, nil
+			}
+			if b < 0 {
+				return a % uint64(-b), nil
+			}
+			return a % uint64(b), nil
+		case uint64:
+			if b == 0 {
+				return nil, nil
+			}
+			return a % b, nil
+		}
+	}
+
+	_, isFloatX := x.(float64)
+	_, isFloatY := y.(float64)
+	if isFloatX || isFloatY {
+		a, _ := types.ToFloat64(x)
+		b, _ := types.ToFloat64(y)
+		if b == 0 {
+			return nil, nil
+		}
+		return math.Mod(a, b), nil
+	}
+
+	// The exact value: a - b * TRUNCATE(a / b, 0).
+	a, err := mysql.ConvertToDecimal(x)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	b, err := mysql.ConvertToDecimal(y)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if b.Equals(mysql.ZeroDecimal) {
+		return nil, nil
+	}
+	return a.Sub(b.Mul(a.Div(b).Truncate(0))), nil
+}
+
+// toFloats converts the arguments to float64, it returns false if any argument
+// is NULL.
+func toFloats(args []interface{}) ([]float64, bool, error) {
+	fs := make([]float64, len(args))
+	for i, arg := range args {
+		if types.IsNil(arg) {
+			return nil, false, nil
+		}
+		f, err := types.ToFloat64(types.RawData(arg))
+		if err != nil {
+			return nil, false, errors.Trace(err)
+		}
+		fs[i] = f
+	}
+	return fs, true, nil
+}
+
+// floatFunc returns a builtin function computing f on the float64 arguments,
+// NULL is returned if any argument is NULL or f returns NaN.
+func floatFunc(name string, f func([]float64) float64) func([]interface{}, map[interface{}]interface{}) (interface{}, error) {
+	return func(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+		fs, ok, err := toFloats(args)
+		if err != nil || !ok {
+			return nil, errors.Trace(err)
+		}
+		v := f(fs)
+		if math.IsNaN(v) {
+			return nil, nil
+		}
+		if math.IsInf(v, 0) {
+			return nil, errors.Errorf("DOUBLE value is out of range in '%s'", name)
+		}
+		return v, nil
+	}
+}
+
+// nanIf returns NaN if invalid is true, so that NULL is returned for the invalid
+// arguments.
+func nanIf(invalid bool, v func() float64) float64 {
+	if invalid {
+		return math.NaN()
+	}
+	return v()
+}
+
+var (
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_pow
+	builtinPow = floatFunc("pow", func(fs []float64) float64 {
+		return math.Pow(fs[0], fs[1])
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_sqrt
+	builtinSqrt = floatFunc("sqrt", func(fs []float64) float64 {
+		return nanIf(fs[0] < 0, func() float64 { return math.Sqrt(fs[0]) })
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_exp
+	builtinExp = floatFunc("exp", func(fs []float64) float64 {
+		return math.Exp(fs[0])
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_ln
+	builtinLn = floatFunc("ln", func(fs []float64) float64 {
+		return nanIf(fs[0] <= 0, func() float64 { return math.Log(fs[0]) })
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_log
+	builtinLog = floatFunc("log", func(fs []float64) float64 {
+		if len(fs) == 1 {
+			return nanIf(fs[0] <= 0, func() float64 { return math.Log(fs[0]) })
+		}
+		// LOG(B, X) is the logarithm of X to the base B.
+		return nanIf(fs[0] <= 0 || fs[0] == 1 || fs[1] <= 0, func() float64 { return math.Log(fs[1]) / math.Log(fs[0]) })
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_log2
+	builtinLog2 = floatFunc("log2", func(fs []float64) float64 {
+		return nanIf(fs[0] <= 0, func() float64 { return math.Log2(fs[0]) })
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_log10
+	builtinLog10 = floatFunc("log10", func(fs []float64) float64 {
+		return nanIf(fs[0] <= 0, func() float64 { return math.Log10(fs[0]) })
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_sin
+	builtinSin = floatFunc("sin", func(fs []float64) float64 {
+		return math.Sin(fs[0])
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_cos
+	builtinCos = floatFunc("cos", func(fs []float64) float64 {
+		return math.Cos(fs[0])
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_tan
+	builtinTan = floatFunc("tan", func(fs []float64) float64 {
+		return math.Tan(fs[0])
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_cot
+	builtinCot = floatFunc("cot", func(fs []float64) float64 {
+		return 1 / math.Tan(fs[0])
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_asin
+	builtinAsin = floatFunc("asin", func(fs []float64) float64 {
+		return math.Asin(fs[0])
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_acos
+	builtinAcos = floatFunc("acos", func(fs []float64) float64 {
+		return math.Acos(fs[0])
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_atan
+	builtinAtan = floatFunc("atan", func(fs []float64) float64 {
+		if len(fs) == 1 {
+			return math.Atan(fs[0])
+		}
+		return math.Atan2(fs[0], fs[1])
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_degrees
+	builtinDegrees = floatFunc("degrees", func(fs []float64) float64 {
+		return fs[0] * 180 / math.Pi
+	})
+	// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_radians
+	builtinRadians = floatFunc("radians", func(fs []float64) float64 {
+		return fs[0] * math.Pi / 180
+	})
+)
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_pi
+func builtinPI(args []interface{}, ctx map[interface{}]interface{}) (v interface{}, err error) {
+	return math.Pi, nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_sign
+func builtinSign(args []interface{}, ctx map[interface{}]interface{}) (v interface{}, err error) {
+	x, err := toNumber(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	switch x := x.(type) {
+	case nil:
+		return nil, nil
+	case int64:
+		return int64(types.CompareInt64(x, 0)), nil
+	case uint64:
+		return int64(types.CompareUint64(x, 0)), nil
+	case mysql.Decimal:
+		return int64(x.Cmp(mysql.ZeroDecimal)), nil
+	default:
+		return int64(types.CompareFloat64(x.(float64), 0)), nil
+	}
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_conv
+func builtinConv(args []interface{}, ctx map[interface{}]interface{}) (v interface{}, err error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	s, err := types.ToString(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	fromBase, err := types.ToInt64(args[1])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	toBase, err := types.ToInt64(args[2])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// A negative base means the number is signed.
+	signedFrom, signedTo := fromBase < 0, toBase < 0
+	if signedFrom {
+		fromBase = -fromBase
+	}
+	if signedTo {
+		toBase = -toBase
+	}
+	if fromBase < 2 || fromBase > 36 || toBase < 2 || toBase > 36 {
+		return nil, nil
+	}
+
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	// The number is read until the first invalid digit, and it is saturated on
+	// overflow.
+	var n uint64
+	for _, c := range strings.ToLower(s) {
+		d, err := strconv.ParseUint(string(c), int(fromBase), 8)
+		if err != nil {
+			break
+		}
+		if n > (math.MaxUint64-d)/uint64(fromBase) {
+			n = math.MaxUint64
+			break
+		}
+		n = n*uint64(fromBase) + d
+	}
+	if signedFrom {
+		if !neg && n > math.MaxInt64 {
+			n = math.MaxInt64
+		} else if neg && n > 1<<63 {
+			n = 1 << 63
+		}
+	}
+	if neg {
+		n = -n
+	}
+
+	if signedTo && int64(n) < 0 {
+		return "-" + strings.ToUpper(strconv.FormatUint(-n, int(toBase))), nil
+	}
+	return strings.ToUpper(strconv.FormatUint(n, int(toBase))), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/mathematical-functions.html#function_crc32
+func builtinCRC32(args []interface{}, ctx map[interface{}]interface{}) (v interface{}, err error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	s, err := types.ToString(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return int64(crc32.ChecksumIEEE([]byte(s))), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_greatest
+func builtinGreatest(args []interface{}, ctx map[interface{}]interface{}) (v interface{}, err error) {
+	return extremum(args, 1)
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_least
+func builtinLeast(args []interface{}, ctx map[interface{}]interface{}) (v interface{}, err error) {
+	return extremum(args, -1)
+}
+
+// extremum returns the greatest argument if sign is 1, the least argument if
+// sign is -1. NULL is returned if any argument is NULL.
+func extremum(args []interface{}, sign int) (interface{}, error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	ret := types.RawData(args[0])
+	for _, arg := range args[1:] {
+		arg = types.RawData(arg)
+		n, err := types.Compare(arg, ret)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if n*sign > 0 {
+			ret = arg
+		}
+	}
+	return ret, nil
+}
@@ -14,7 +14,10 @@
 package builtin
 
 import (
+	"math"
+
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/mysql"
 )
 
 func (s *testBuiltinSuite) TestAbs(c *C) {
@@ -28,6 +31,7 @@ func (s *testBuiltinSuite) TestAbs(c *C) {
 		{int64(-1), int64(1)},
 		{float64(3.14), float64(3.14)},
 		{float64(-3.14), float64(3.14)},
+		{mysql.NewDecimalFromInt(-314, -2), mysql.NewDecimalFromInt(314, -2)},
 	}
 
 	for _, t := range tbl {
@@ -43,3 +47,138 @@ func (s *testBuiltinSuite) TestRand(c *C) {
 	c.Assert(v, Less, float64(1))
 	c.Assert(v, GreaterEqual, float64(0))
 }
+
+func (s *testBuiltinSuite) TestRoundAndTruncate(c *C) {
+	dec := func(str string) mysql.Decimal {
+		d, err := mysql.ParseDecimal(str)
+		c.Assert(err, IsNil)
+		return d
+	}
+	tbl := []struct {
+		F      func([]interface{}, map[interface{}]interface{}) (interface{}, error)
+		Args   []interface{}
+		Expect interface{}
+	}{
+		{builtinRound, []interface{}{int64(-2)}, int64(-2)},
+		{builtinRound, []interface{}{int64(125), int64(-1)}, int64(130)},
+		{builtinRound, []interface{}{int64(-125), int64(-1)}, int64(-130)},
+		{builtinRound, []interface{}{uint64(125), int64(-2)}, uint64(100)},
+		{builtinRound, []interface{}{float64(2.5)}, float64(2)},
+		{builtinRound, []interface{}{float64(1.298), int64(1)}, float64(1.3)},
+		{builtinRound, []interface{}{float64(23.298), int64(-1)}, float64(20)},
+		{builtinRound, []interface{}{"1.5"}, float64(2)},
+		{builtinRound, []interface{}{dec("2.5")}, "3"},
+		{builtinRound, []interface{}{dec("-2.5")}, "-3"},
+		{builtinRound, []interface{}{dec("1.2345"), int64(2)}, "1.23"},
+		{builtinRound, []interface{}{dec("1.005"), int64(2)}, "1.01"},
+		{builtinRound, []interface{}{dec("1.25"), int64(5)}, "1.25"},
+		{builtinRound, []interface{}{dec("125.5"), int64(-1)}, "130"},
+		{builtinRound, []interface{}{nil, int64(1)}, nil},
+		{builtinRound, []interface{}{float64(1.5), nil}, nil},
+		{builtinTruncate, []interface{}{int64(129), int64(-1)}, int64(120)},
+		{builtinTruncate, []interface{}{int64(-129), int64(-2)}, int64(-100)},
+		{builtinTruncate, []interface{}{uint64(129), int64(-1)}, uint64(120)},
+		{builtinTruncate, []interface{}{int64(129), int64(2)}, int64(129)},
+		{builtinTruncate, []interface{}{float64(1.999), int64(1)}, float64(1.9)},
+		{builtinTruncate, []interface{}{float64(-1.999), int64(0)}, float64(-1)},
+		{builtinTruncate, []interface{}{dec("1.999"), int64(1)}, "1.9"},
+		{builtinTruncate, []interface{}{dec("-1.999"), int64(0)}, "-1"},
+		{builtinTruncate, []interface{}{dec("122.9"), int64(-2)}, "100"},
+		{builtinTruncate, []interface{}{dec("-129.5"), int64(-1)}, "-120"},
+	}
+	for _, t := range tbl {
+		v, err := t.F(t.Args, nil)
+		c.Assert(err, IsNil)
+		if d, ok := v.(mysql.Decimal); ok {
+			v = d.String()
+		}
+		c.Assert(v, Equals, t.Expect, Commentf("%v", t.Args))
+	}
+}
+
+func (s *testBuiltinSuite) TestMathFuncs(c *C) {
+	dec := func(str string) mysql.Decimal {
+		d, err := mysql.ParseDecimal(str)
+		c.Assert(err, IsNil)
+		return d
+	}
+	tbl := []struct {
+		F      func([]interface{}, map[interface{}]interface{}) (interface{}, error)
+		Args   []interface{}
+		Expect interface{}
+	}{
+		{builtinFloor, []interface{}{float64(1.23)}, float64(1)},
+		{builtinFloor, []interface{}{float64(-1.23)}, float64(-2)},
+		{builtinFloor, []interface{}{int64(-1)}, int64(-1)},
+		{builtinFloor, []interface{}{dec("-1.23")}, "-2"},
+		{builtinFloor, []interface{}{nil}, nil},
+		{builtinCeil, []interface{}{float64(1.23)}, float64(2)},
+		{builtinCeil, []interface{}{float64(-1.23)}, float64(-1)},
+		{builtinCeil, []interface{}{dec("1.23")}, "2"},
+		{builtinCeil, []interface{}{dec("-1.23")}, "-1"},
+		{builtinMod, []interface{}{int64(234), int64(10)}, int64(4)},
+		{builtinMod, []interface{}{int64(-34), int64(10)}, int64(-4)},
+		{builtinMod, []interface{}{uint64(29), int64(-9)}, uint64(2)},
+		{builtinMod, []interface{}{int64(29), int64(0)}, nil},
+		{builtinMod, []interface{}{float64(34.5), int64(3)}, float64(1.5)},
+		{builtinMod, []interface{}{dec("34.5"), int64(3)}, "1.5"},
+		{builtinMod, []interface{}{dec("-10.25"), dec("3.1")}, "-0.95"},
+		{builtinMod, []interface{}{dec("1"), dec("0.00")}, nil},
+		{builtinMod, []interface{}{nil, int64(3)}, nil},
+		{builtinPow, []interface{}{int64(2), int64(-2)}, float64(0.25)},
+		{builtinPow, []interface{}{int64(-8), float64(1) / 3}, nil},
+		{builtinSqrt, []interface{}{int64(4)}, float64(2)},
+		{builtinSqrt, []interface{}{int64(-16)}, nil},
+		{builtinExp, []interface{}{int64(0)}, float64(1)},
+		{builtinLn, []interface{}{int64(1)}, float64(0)},
+		{builtinLn, []interface{}{int64(-2)}, nil},
+		{builtinLog, []interface{}{int64(1)}, float64(0)},
+		{builtinLog, []interface{}{int64(2), int64(65536)}, float64(16)},
+		{builtinLog, []interface{}{int64(1), int64(100)}, nil},
+		{builtinLog2, []interface{}{int64(65536)}, float64(16)},
+		{builtinLog10, []interface{}{int64(100)}, float64(2)},
+		{builtinLog10, []interface{}{int64(0)}, nil},
+		{builtinSign, []interface{}{int64(-32)}, int64(-1)},
+		{builtinSign, []interface{}{uint64(0)}, int64(0)},
+		{builtinSign, []interface{}{float64(234)}, int64(1)},
+		{builtinSign, []interface{}{dec("-0.01")}, int64(-1)},
+		{builtinPI, nil, math.Pi},
+		{builtinSin, []interface{}{int64(0)}, float64(0)},
+		{builtinCos, []interface{}{int64(0)}, float64(1)},
+		{builtinTan, []interface{}{int64(0)}, float64(0)},
+		{builtinAsin, []interface{}{int64(2)}, nil},
+		{builtinAcos, []interface{}{int64(1)}, float64(0)},
+		{builtinAtan, []interface{}{int64(0)}, float64(0)},
+		{builtinAtan, []interface{}{int64(1), int64(0)}, math.Pi / 2},
+		{builtinDegrees, []interface{}{math.Pi}, float64(180)},
+		{builtinRadians, []interface{}{int64(180)}, math.Pi},
+		{builtinConv, []interface{}{"a", int64(16), int64(2)}, "1010"},
+		{builtinConv, []interface{}{"6E", int64(18), int64(8)}, "172"},
+		{builtinConv, []interface{}{int64(-17), int64(10), int64(-18)}, "-H"},
+		{builtinConv, []interface{}{int64(-17), int64(10), int64(16)}, "FFFFFFFFFFFFFFEF"},
+		{builtinConv, []interface{}{"12z", int64(10), int64(10)}, "12"},
+		{builtinConv, []interface{}{"z", int64(10), int64(10)}, "0"},
+		{builtinConv, []interface{}{"1", int64(1), int64(10)}, nil},
+		{builtinConv, []interface{}{nil, int64(10), int64(10)}, nil},
+		{builtinCRC32, []interface{}{"MySQL"}, int64(3259397556)},
+		{builtinCRC32, []interface{}{nil}, nil},
+		{builtinGreatest, []interface{}{int64(2), int64(0), int64(34)}, int64(34)},
+		{builtinGreatest, []interface{}{"B", "A", "C"}, "C"},
+		{builtinGreatest, []interface{}{int64(2), nil}, nil},
+		{builtinLeast, []interface{}{int64(2), int64(0)}, int64(0)},
+		{builtinLeast, []interface{}{float64(34.0), float64(3.0), float64(5.0)}, float64(3)},
+	}
+	for _, t := range tbl {
+		v, err := t.F(t.Args, nil)
+		c.Assert(err, IsNil, Commentf("%v", t.Args))
+		if d, ok := v.(mysql.Decimal); ok {
+			v = d.String()
+		}
+		c.Assert(v, Equals, t.Expect, Commentf("%v", t.Args))
+	}
+
+	_, err := builtinCot([]interface{}{int64(0)}, nil)
+	c.Assert(err, NotNil)
+	_, err = builtinExp([]interface{}{int64(1000)}, nil)
+	c.Assert(err, NotNil)
+}
@@ -18,10 +18,16 @@
 package builtin
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -170,3 +176,450 @@ func builtinReplace(args []interface{}, ctx map[interface{}]interface{}) (interf
 
 	return strings.Replace(str, oldStr, newStr, -1), nil
 }
+
+// maxStringLength is the max length of the string built by space, lpad and rpad,
+// it is the default max_allowed_packet of MySQL. NULL is returned for a longer one.
+const maxStringLength = 4194304
+
+// toChars converts the string value v to characters, each byte is a character of
+// a binary string, and each UTF-8 encoded rune is a character of the other strings.
+func toChars(v interface{}) ([]rune, bool, error) {
+	if b, ok := v.([]byte); ok {
+		chars := make([]rune, len(b))
+		for i, c := range b {
+			chars[i] = rune(c)
+		}
+		return chars, true, nil
+	}
+	s, err := types.ToString(v)
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	return []rune(s), false, nil
+}
+
+// fromChars converts the characters to string, it is the reverse of toChars.
+func fromChars(chars []rune, binary bool) string {
+	if !binary {
+		return string(chars)
+	}
+	b := make([]byte, len(chars))
+	for i, c := range chars {
+		b[i] = byte(c)
+	}
+	return string(b)
+}
+
+// hasNil returns true if any argument is NULL, most string functions return
+// NULL for a NULL argument.
+func hasNil(args []interface{}) bool {
+	for _, arg := range args {
+		if types.IsNil(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_right
+func builtinRight(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	chars, binary, err := toChars(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	length, err := types.ToInt64(args[1])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	l := int(length)
+	if l < 0 {
+		l = 0
+	} else if l > len(chars) {
+		l = len(chars)
+	}
+	return fromChars(chars[len(chars)-l:], binary), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_lpad
+func builtinLpad(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	return pad(args, true)
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_rpad
+func builtinRpad(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	return pad(args, false)
+}
+
+func pad(args []interface{}, left bool) (interface{}, error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	chars, binary, err := toChars(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	length, err := types.ToInt64(args[1])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	padChars, _, err := toChars(args[2])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	l := int(length)
+	if length < 0 || length > maxStringLength {
+		return nil, nil
+	}
+	if l <= len(chars) {
+		return fromChars(chars[:l], binary), nil
+	}
+	if len(padChars) == 0 {
+		return nil, nil
+	}
+	padding := make([]rune, 0, l-len(chars))
+	for len(padding) < l-len(chars) {
+		padding = append(padding, padChars[len(padding)%len(padChars)])
+	}
+	if left {
+		return fromChars(append(padding, chars...), binary), nil
+	}
+	return fromChars(append(chars, padding...), binary), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_ltrim
+func builtinLTrim(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	s, err := types.ToString(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return strings.TrimLeft(s, " "), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_rtrim
+func builtinRTrim(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	s, err := types.ToString(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return strings.TrimRight(s, " "), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_reverse
+func builtinReverse(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	chars, binary, err := toChars(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for i, j := 0, len(chars)-1; i < j; i, j = i+1, j-1 {
+		chars[i], chars[j] = chars[j], chars[i]
+	}
+	return fromChars(chars, binary), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_instr
+func builtinInstr(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	str, err := types.ToString(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	substr, err := types.ToString(args[1])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	i := strings.Index(str, substr)
+	if i < 0 {
+		return int64(0), nil
+	}
+	if _, ok := args[0].([]byte); ok {
+		return int64(i + 1), nil
+	}
+	// Convert the byte offset to the character position.
+	return int64(utf8.RuneCountInString(str[:i]) + 1), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_field
+func builtinField(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	if types.IsNil(args[0]) {
+		return int64(0), nil
+	}
+	for i, arg := range args[1:] {
+		if types.IsNil(arg) {
+			continue
+		}
+		n, err := types.Compare(args[0], arg)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if n == 0 {
+			return int64(i + 1), nil
+		}
+	}
+	return int64(0), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_find-in-set
+func builtinFindInSet(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	str, err := types.ToString(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	strlist, err := types.ToString(args[1])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if strlist == "" || strings.Contains(str, ",") {
+		return int64(0), nil
+	}
+	for i, s := range strings.Split(strlist, ",") {
+		if s == str {
+			return int64(i + 1), nil
+		}
+	}
+	return int64(0), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_substring-index
+func builtinSubstringIndex(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	str, err := types.ToString(args[0])
+	if err != nil {
+		return nil, errors.Errorf("Substring_Index invalid args, need string but get %T", args[0])
+	}
+	delim, err := types.ToString(args[1])
+	if err != nil {
+		return nil, errors.Errorf("Substring_Index invalid delim, need string but get %T", args[1])
+	}
+	c, err := types.ToInt64(args[2])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if delim == "" {
+		return "", nil
+	}
+	count := int(c)
+	strs := strings.Split(str, delim)
+	var (
+		start = 0
+		end   = len(strs)
+	)
+	if count > 0 {
+		// If count is positive, everything to the left of the final delimiter (counting from the left) is returned.
+		if count < end {
+			end = count
+		}
+	} else {
+		// If count is negative, everything to the right of the final delimiter (counting from the right) is returned.
+		count = -count
+		if count < end {
+			start = end - count
+		}
+	}
+	substrs := strs[start:end]
+	return strings.Join(substrs, delim), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_ascii
+func builtinASCII(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	s, err := types.ToString(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(s) == 0 {
+		return int64(0), nil
+	}
+	return int64(s[0]), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_char
+func builtinChar(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	var b []byte
+	for _, arg := range args {
+		// NULL values are skipped.
+		if types.IsNil(arg) {
+			continue
+		}
+		n, err := types.ToInt64(arg)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		// The integer is interpreted as the bytes in big endian without leading zeros.
+		var bs []byte
+		for u := uint32(n); u > 0; u >>= 8 {
+			bs = append([]byte{byte(u)}, bs...)
+		}
+		if len(bs) == 0 {
+			bs = []byte{0}
+		}
+		b = append(b, bs...)
+	}
+	return string(b), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_hex
+func builtinHex(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	switch x := types.RawData(args[0]).(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return strings.ToUpper(hex.EncodeToString([]byte(x))), nil
+	case []byte:
+		return strings.ToUpper(hex.EncodeToString(x)), nil
+	case uint64:
+		return strings.ToUpper(strconv.FormatUint(x, 16)), nil
+	default:
+		// The number is rounded to an integer, the negative one is in two's complement.
+		f, err := types.ToFloat64(x)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return strings.ToUpper(strconv.FormatUint(uint64(int64(math.Floor(f+0.5))), 16)), nil
+	}
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_unhex
+func builtinUnHex(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	s, err := types.ToString(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(s)%2 != 0 {
+		s = "0" + s
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		// NULL is returned for the invalid hexadecimal digits.
+		return nil, nil
+	}
+	return string(b), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_space
+func builtinSpace(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	n, err := types.ToInt64(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if n > maxStringLength {
+		return nil, nil
+	}
+	if n < 1 {
+		return "", nil
+	}
+	return strings.Repeat(" ", int(n)), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-comparison-functions.html#function_strcmp
+func builtinStrcmp(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	left, err := types.ToString(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	right, err := types.ToString(args[1])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return int64(strings.Compare(left, right)), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_char-length
+func builtinCharLength(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	if hasNil(args) {
+		return nil, nil
+	}
+	chars, _, err := toChars(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return int64(len(chars)), nil
+}
+
+// maxFormatDecimals is the max number of decimal places of format.
+const maxFormatDecimals = 30
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_format
+func builtinFormat(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	// The locale argument is ignored, the result is always in en_US format.
+	if hasNil(args[:2]) {
+		return nil, nil
+	}
+	x, err := mysql.ConvertToDecimal(types.RawData(args[0]))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	d, err := types.ToInt64(args[1])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if d < 0 {
+		d = 0
+	} else if d > maxFormatDecimals {
+		d = maxFormatDecimals
+	}
+	s := x.StringFixed(int32(d))
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i:]
+	}
+	var b bytes.Buffer
+	b.WriteString(sign)
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	b.WriteString(fracPart)
+	return b.String(), nil
+}
+
+// See: https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_elt
+func builtinElt(args []interface{}, _ map[interface{}]interface{}) (interface{}, error) {
+	if types.IsNil(args[0]) {
+		return nil, nil
+	}
+	n, err := types.ToInt64(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if n < 1 || n >= int64(len(args)) || types.IsNil(args[n]) {
+		return nil, nil
+	}
+	s, err := types.ToString(args[n])
+	return s, errors.Trace(err)
+}
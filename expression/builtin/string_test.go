@@ -186,3 +186,76 @@ func (s *testBuiltinSuite) TestReplace(c *C) {
 		c.Assert(v, Equals, t.Expect)
 	}
 }
+
+func (s *testBuiltinSuite) TestStringFuncs(c *C) {
+	tbl := []struct {
+		F      func([]interface{}, map[interface{}]interface{}) (interface{}, error)
+		Input  []interface{}
+		Expect interface{}
+	}{
+		{builtinRight, []interface{}{"foobarbar", 4}, "rbar"},
+		{builtinRight, []interface{}{"中文字符", 2}, "字符"},
+		{builtinRight, []interface{}{"abc", -1}, ""},
+		{builtinRight, []interface{}{nil, 1}, nil},
+		{builtinLpad, []interface{}{"hi", 4, "??"}, "??hi"},
+		{builtinLpad, []interface{}{"hi", 5, "ab"}, "abahi"},
+		{builtinLpad, []interface{}{"hi", 1, "??"}, "h"},
+		{builtinLpad, []interface{}{"hi", 5, ""}, nil},
+		{builtinLpad, []interface{}{"hi", -1, "?"}, nil},
+		{builtinRpad, []interface{}{"hi", 5, "?"}, "hi???"},
+		{builtinRpad, []interface{}{"中", 3, "文"}, "中文文"},
+		{builtinLTrim, []interface{}{"  bar  "}, "bar  "},
+		{builtinRTrim, []interface{}{"  bar  "}, "  bar"},
+		{builtinReverse, []interface{}{"abc中"}, "中cba"},
+		{builtinReverse, []interface{}{nil}, nil},
+		{builtinInstr, []interface{}{"foobarbar", "bar"}, int64(4)},
+		{builtinInstr, []interface{}{"中文bar", "bar"}, int64(3)},
+		{builtinInstr, []interface{}{"xbar", "foobar"}, int64(0)},
+		{builtinField, []interface{}{"ej", "Hej", "ej", "Heja", "hej", "foo"}, int64(2)},
+		{builtinField, []interface{}{"fo", "Hej", "ej"}, int64(0)},
+		{builtinField, []interface{}{2, 1, nil, 2}, int64(3)},
+		{builtinField, []interface{}{nil, nil}, int64(0)},
+		{builtinFindInSet, []interface{}{"b", "a,b,c,d"}, int64(2)},
+		{builtinFindInSet, []interface{}{"b,c", "a,b,c,d"}, int64(0)},
+		{builtinFindInSet, []interface{}{"b", ""}, int64(0)},
+		{builtinFindInSet, []interface{}{nil, "a"}, nil},
+		{builtinSubstringIndex, []interface{}{"www.mysql.com", ".", 2}, "www.mysql"},
+		{builtinSubstringIndex, []interface{}{"www.mysql.com", ".", -2}, "mysql.com"},
+		{builtinSubstringIndex, []interface{}{"www.mysql.com", "", 2}, ""},
+		{builtinSubstringIndex, []interface{}{"www.mysql.com", nil, 2}, nil},
+		{builtinASCII, []interface{}{"2"}, int64(50)},
+		{builtinASCII, []interface{}{"dx"}, int64(100)},
+		{builtinASCII, []interface{}{""}, int64(0)},
+		{builtinChar, []interface{}{77, 121, 83, 81, "76"}, "MySQL"},
+		{builtinChar, []interface{}{77, nil, 77.3}, "MM"},
+		{builtinChar, []interface{}{256}, "\x01\x00"},
+		{builtinHex, []interface{}{"abc"}, "616263"},
+		{builtinHex, []interface{}{255}, "FF"},
+		{builtinHex, []interface{}{-1}, "FFFFFFFFFFFFFFFF"},
+		{builtinHex, []interface{}{nil}, nil},
+		{builtinUnHex, []interface{}{"4D7953514C"}, "MySQL"},
+		{builtinUnHex, []interface{}{"GG"}, nil},
+		{builtinSpace, []interface{}{3}, "   "},
+		{builtinSpace, []interface{}{-1}, ""},
+		{builtinStrcmp, []interface{}{"text", "text2"}, int64(-1)},
+		{builtinStrcmp, []interface{}{"text2", "text"}, int64(1)},
+		{builtinStrcmp, []interface{}{"text", "text"}, int64(0)},
+		{builtinCharLength, []interface{}{"中文"}, int64(2)},
+		{builtinCharLength, []interface{}{[]byte("中文")}, int64(6)},
+		{builtinFormat, []interface{}{12332.123456, 4}, "12,332.1235"},
+		{builtinFormat, []interface{}{12332.1, 4}, "12,332.1000"},
+		{builtinFormat, []interface{}{12332.2, 0}, "12,332"},
+		{builtinFormat, []interface{}{-1234567.5, 0, "en_US"}, "-1,234,568"},
+		{builtinFormat, []interface{}{"123", -1}, "123"},
+		{builtinFormat, []interface{}{nil, 2}, nil},
+		{builtinElt, []interface{}{1, "Aa", "Bb"}, "Aa"},
+		{builtinElt, []interface{}{3, "Aa", "Bb"}, nil},
+		{builtinElt, []interface{}{0, "Aa"}, nil},
+	}
+
+	for _, t := range tbl {
+		v, err := t.F(t.Input, nil)
+		c.Assert(err, IsNil)
+		c.Assert(v, Equals, t.Expect, Commentf("%v", t.Input))
+	}
+}
@@ -18,13 +18,26 @@
 package builtin
 
 import (
+	"strings"
 	"time"
 
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
 )
 
+// getTimeZone returns the time zone of the session, it is time.Local if the
+// function is not evaluated in a session.
+func getTimeZone(data map[interface{}]interface{}) *time.Location {
+	if c, ok := data[ExprEvalArgCtx]; ok {
+		return variable.GetTimeZone(c.(context.Context))
+	}
+	return time.Local
+}
+
 func convertToTime(arg interface{}, tp byte) (interface{}, error) {
 	f := types.NewFieldType(tp)
 	f.Decimal = mysql.MaxFsp
@@ -153,7 +166,7 @@ func builtinNow(args []interface{}, ctx map[interface{}]interface{}) (interface{
 	}
 
 	t := mysql.Time{
-		Time: time.Now(),
+		Time: mysql.WallClock(time.Now(), getTimeZone(ctx)),
 		Type: mysql.TypeDatetime,
 		// set unspecified for later round
 		Fsp: mysql.UnspecifiedFsp,
@@ -303,9 +316,9 @@ func builtinSysDate(args []interface{}, ctx map[interface{}]interface{}) (interf
 
 // See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_curdate
 func builtinCurrentDate(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
-	year, month, day := time.Now().Date()
+	year, month, day := mysql.WallClock(time.Now(), getTimeZone(ctx)).Date()
 	return mysql.Time{
-		Time: time.Date(year, month, day, 0, 0, 0, 0, time.Local),
+		Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC),
 		Type: mysql.TypeDate, Fsp: 0}, nil
 }
 
@@ -321,3 +334,412 @@ func checkFsp(arg interface{}) (int, error) {
 	}
 	return int(fsp), nil
 }
+
+// nilIfInvalid returns NULL for mysql.ErrInvalidTimeFormat, as MySQL does with
+// a warning.
+func nilIfInvalid(v interface{}, err error) (interface{}, error) {
+	if terror.ErrorEqual(err, mysql.ErrInvalidTimeFormat) {
+		return nil, nil
+	}
+	return v, errors.Trace(err)
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_date-format
+func builtinDateFormat(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	v, err := convertToTime(args[0], mysql.TypeDatetime)
+	if err != nil || types.IsNil(v) || types.IsNil(args[1]) {
+		return nil, errors.Trace(err)
+	}
+	layout, err := types.ToString(args[1])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return nilIfInvalid(v.(mysql.Time).DateFormat(layout))
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_time-format
+func builtinTimeFormat(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	v, err := convertToDuration(args[0])
+	if err != nil || types.IsNil(v) || types.IsNil(args[1]) {
+		return nil, errors.Trace(err)
+	}
+	layout, err := types.ToString(args[1])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return nilIfInvalid(v.(mysql.Duration).TimeFormat(layout))
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_str-to-date
+func builtinStrToDate(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	if types.IsNil(args[0]) || types.IsNil(args[1]) {
+		return nil, nil
+	}
+	str, err := types.ToString(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	format, err := types.ToString(args[1])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return nilIfInvalid(mysql.StrToDate(str, format))
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_unix-timestamp
+func builtinUnixTimestamp(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	if len(args) == 0 {
+		return time.Now().Unix(), nil
+	}
+	v, err := convertToTime(args[0], mysql.TypeDatetime)
+	if err != nil || types.IsNil(v) {
+		return v, errors.Trace(err)
+	}
+
+	// No need to check type here, the argument is the time in the time zone of the session.
+	t := v.(mysql.Time).ConvertTimeZone(getTimeZone(ctx), time.UTC)
+	// 0 is returned for the time out of the TIMESTAMP range.
+	if t.IsZero() || t.Time.Before(mysql.MinTimestamp) || t.Time.After(mysql.MaxTimestamp) {
+		return int64(0), nil
+	}
+	fsp := usedFsp(t)
+	if fsp == 0 {
+		return t.Unix(), nil
+	}
+	// The result has the fractional seconds of the argument.
+	d := mysql.NewDecimalFromInt(t.UnixNano()/1000, -6)
+	return d.Truncate(int32(fsp)), nil
+}
+
+// usedFsp returns the number of fractional digits t really has, because
+// convertToTime always uses mysql.MaxFsp.
+func usedFsp(t mysql.Time) int {
+	fsp := t.Fsp
+	for usec := t.Nanosecond() / 1000; fsp > 0 && usec%10 == 0; usec /= 10 {
+		fsp--
+	}
+	return fsp
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_from-unixtime
+func builtinFromUnixTime(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	if types.IsNil(args[0]) {
+		return nil, nil
+	}
+	d, err := types.ToDecimal(types.RawData(args[0]))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if d.Cmp(mysql.ZeroDecimal) < 0 {
+		return nil, nil
+	}
+
+	fsp := int(d.FracDigits())
+	if fsp > mysql.MaxFsp {
+		fsp = mysql.MaxFsp
+	}
+	sec := d.IntPart()
+	nsec := d.Sub(mysql.NewDecimalFromInt(sec, 0)).Mul(mysql.NewDecimalFromInt(1, 9)).IntPart()
+	t, err := mysql.Time{
+		Time: time.Unix(sec, nsec).UTC(),
+		Type: mysql.TypeDatetime,
+		Fsp:  mysql.UnspecifiedFsp,
+	}.RoundFrac(fsp)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if t.Time.After(mysql.MaxTimestamp) {
+		return nil, nil
+	}
+	t.Time = mysql.WallClock(t.Time, getTimeZone(ctx))
+	if len(args) == 1 {
+		return t, nil
+	}
+
+	if types.IsNil(args[1]) {
+		return nil, nil
+	}
+	layout, err := types.ToString(args[1])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return nilIfInvalid(t.DateFormat(layout))
+}
+
+// toDate converts the date part of arg to time.Time in UTC, so that the days
+// between the dates are not affected by daylight saving time. It returns false
+// if arg is NULL or zero time.
+func toDate(arg interface{}) (time.Time, bool, error) {
+	v, err := convertToTime(arg, mysql.TypeDatetime)
+	if err != nil || types.IsNil(v) {
+		return time.Time{}, false, errors.Trace(err)
+	}
+	t := v.(mysql.Time)
+	if t.IsZero() {
+		return time.Time{}, false, nil
+	}
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	return time.Date(year, month, day, hour, minute, second, t.Nanosecond(), time.UTC), true, nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_convert-tz
+func builtinConvertTZ(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	v, err := convertToTime(args[0], mysql.TypeDatetime)
+	if err != nil || types.IsNil(v) || types.IsNil(args[1]) || types.IsNil(args[2]) {
+		return nil, errors.Trace(err)
+	}
+	var locs [2]*time.Location
+	for i, arg := range args[1:] {
+		name, err := types.ToString(arg)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		// NULL is returned for the invalid time zones.
+		if locs[i], err = mysql.ParseTimeZone(name); err != nil {
+			return nil, nil
+		}
+	}
+
+	t := v.(mysql.Time)
+	t.Fsp = usedFsp(t)
+	// No conversion occurs for the time out of the TIMESTAMP range.
+	u := t.ConvertTimeZone(locs[0], time.UTC)
+	if u.IsZero() || u.Time.Before(mysql.MinTimestamp) || u.Time.After(mysql.MaxTimestamp) {
+		return t, nil
+	}
+	return u.ConvertTimeZone(time.UTC, locs[1]), nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_datediff
+func builtinDateDiff(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	t1, ok1, err := toDate(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	t2, ok2, err := toDate(args[1])
+	if err != nil || !ok1 || !ok2 {
+		return nil, errors.Trace(err)
+	}
+
+	// Only the date parts are used.
+	t1, t2 = t1.Truncate(24*time.Hour), t2.Truncate(24*time.Hour)
+	return int64(t1.Sub(t2) / (24 * time.Hour)), nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_timestampdiff
+func builtinTimestampDiff(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	unit, err := types.ToString(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	t1, ok1, err := toDate(args[1])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	t2, ok2, err := toDate(args[2])
+	if err != nil || !ok1 || !ok2 {
+		return nil, errors.Trace(err)
+	}
+
+	var d time.Duration
+	switch strings.ToUpper(unit) {
+	case "MICROSECOND":
+		d = time.Microsecond
+	case "SECOND":
+		d = time.Second
+	case "MINUTE":
+		d = time.Minute
+	case "HOUR":
+		d = time.Hour
+	case "DAY":
+		d = 24 * time.Hour
+	case "WEEK":
+		d = 7 * 24 * time.Hour
+	case "MONTH":
+		return monthDiff(t1, t2), nil
+	case "QUARTER":
+		return monthDiff(t1, t2) / 3, nil
+	case "YEAR":
+		return monthDiff(t1, t2) / 12, nil
+	default:
+		return nil, errors.Errorf("invalid unit %s for timestampdiff", unit)
+	}
+	// The duration may overflow, so the difference of days is computed separately.
+	days := t2.Truncate(24*time.Hour).Sub(t1.Truncate(24*time.Hour)) / (24 * time.Hour)
+	rest := t2.Sub(t2.Truncate(24*time.Hour)) - t1.Sub(t1.Truncate(24*time.Hour))
+	if d >= 24*time.Hour {
+		return int64((days*24*time.Hour + rest) / d), nil
+	}
+	return int64(days)*int64(24*time.Hour/d) + int64(rest/d), nil
+}
+
+// monthDiff returns the whole months from t1 to t2.
+func monthDiff(t1, t2 time.Time) int64 {
+	months := int64(t2.Year()-t1.Year())*12 + int64(t2.Month()-t1.Month())
+	// The month is not complete if the rest of t2 is before the rest of t1.
+	r1 := t1.Sub(time.Date(t1.Year(), t1.Month(), 1, 0, 0, 0, 0, time.UTC))
+	r2 := t2.Sub(time.Date(t2.Year(), t2.Month(), 1, 0, 0, 0, 0, time.UTC))
+	if months > 0 && r2 < r1 {
+		months--
+	} else if months < 0 && r2 > r1 {
+		months++
+	}
+	return months
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_timestampadd
+func builtinTimestampAdd(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	unit, err := types.ToString(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if types.IsNil(args[1]) {
+		return nil, nil
+	}
+	interval, err := types.ToInt64(args[1])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	v, err := convertToTime(args[2], mysql.TypeDatetime)
+	if err != nil || types.IsNil(v) {
+		return nil, errors.Trace(err)
+	}
+	t := v.(mysql.Time)
+	if t.IsZero() {
+		return nil, nil
+	}
+
+	var d time.Duration
+	switch strings.ToUpper(unit) {
+	case "MICROSECOND":
+		d = time.Microsecond
+	case "SECOND":
+		d = time.Second
+	case "MINUTE":
+		d = time.Minute
+	case "HOUR":
+		d = time.Hour
+	case "DAY":
+		t.Time = t.AddDate(0, 0, int(interval))
+	case "WEEK":
+		t.Time = t.AddDate(0, 0, 7*int(interval))
+	case "MONTH":
+		t.Time = addMonths(t.Time, int(interval))
+	case "QUARTER":
+		t.Time = addMonths(t.Time, 3*int(interval))
+	case "YEAR":
+		t.Time = addMonths(t.Time, 12*int(interval))
+	default:
+		return nil, errors.Errorf("invalid unit %s for timestampadd", unit)
+	}
+	if d != 0 {
+		t.Time = t.Add(time.Duration(interval) * d)
+	}
+	if d != time.Microsecond {
+		t.Fsp = usedFsp(t)
+	}
+	if t.Year() < 0 || t.Year() > 9999 {
+		return nil, nil
+	}
+	return t, nil
+}
+
+// addMonths adds n months to t, the day is the last day of the month if it
+// is out of range, e.g. 2015-01-31 + 1 month is 2015-02-28.
+func addMonths(t time.Time, n int) time.Time {
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	first := time.Date(year, month+time.Month(n), 1, hour, minute, second, t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_last-day
+func builtinLastDay(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	v, err := convertToTime(args[0], mysql.TypeDatetime)
+	if err != nil || types.IsNil(v) {
+		return v, errors.Trace(err)
+	}
+
+	// No need to check type here.
+	t := v.(mysql.Time)
+	if t.IsZero() {
+		return nil, nil
+	}
+
+	year, month, _ := t.Date()
+	return mysql.Time{
+		Time: time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC),
+		Type: mysql.TypeDate,
+	}, nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_makedate
+func builtinMakeDate(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	if types.IsNil(args[0]) || types.IsNil(args[1]) {
+		return nil, nil
+	}
+	year, err := types.ToInt64(args[0])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	dayOfYear, err := types.ToInt64(args[1])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if dayOfYear <= 0 || year < 0 || year > 9999 {
+		return nil, nil
+	}
+	if year < 100 {
+		// Two-digit years are converted the same as for YEAR columns.
+		y, err := mysql.AdjustYear(int(year))
+		if err != nil {
+			return nil, nil
+		}
+		year = int64(y)
+	}
+
+	t := time.Date(int(year), 1, int(dayOfYear), 0, 0, 0, 0, time.UTC)
+	if t.Year() > 9999 {
+		return nil, nil
+	}
+	return mysql.Time{Time: t, Type: mysql.TypeDate}, nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_dayname
+func builtinDayName(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	v, err := convertToTime(args[0], mysql.TypeDate)
+	if err != nil || types.IsNil(v) {
+		return v, errors.Trace(err)
+	}
+
+	// No need to check type here.
+	t := v.(mysql.Time)
+	if t.IsZero() {
+		return nil, nil
+	}
+
+	return t.Weekday().String(), nil
+}
+
+// See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_monthname
+func builtinMonthName(args []interface{}, ctx map[interface{}]interface{}) (interface{}, error) {
+	v, err := convertToTime(args[0], mysql.TypeDate)
+	if err != nil || types.IsNil(v) {
+		return v, errors.Trace(err)
+	}
+
+	// No need to check type here.
+	t := v.(mysql.Time)
+	if t.IsZero() {
+		return nil, nil
+	}
+
+	return mysql.MonthNames[t.Month()-1], nil
+}
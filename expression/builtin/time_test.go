@@ -14,11 +14,14 @@
 package builtin
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/mock"
 )
 
 func (s *testBuiltinSuite) TestDate(c *C) {
@@ -275,3 +278,127 @@ func (s *testBuiltinSuite) TestCurrentDate(c *C) {
 	c.Assert(ok, IsTrue)
 	c.Assert(n.String(), GreaterEqual, last.Format(mysql.DateFormat))
 }
+
+func (s *testBuiltinSuite) TestDateFunctions(c *C) {
+	tbl := []struct {
+		Fn     func([]interface{}, map[interface{}]interface{}) (interface{}, error)
+		Args   []interface{}
+		Expect interface{}
+	}{
+		{builtinDateFormat, []interface{}{"2009-10-04 22:23:00", "%W %M %Y"}, "Sunday October 2009"},
+		{builtinDateFormat, []interface{}{"0000-00-00", "%W"}, nil},
+		{builtinDateFormat, []interface{}{nil, "%Y"}, nil},
+		{builtinTimeFormat, []interface{}{"100:00:00", "%H %k %h %I %l"}, "100 100 04 04 4"},
+		{builtinTimeFormat, []interface{}{"10:00:00", "%Y"}, nil},
+		{builtinStrToDate, []interface{}{"01,5,2013", "%d,%m,%Y"}, "2013-05-01"},
+		{builtinStrToDate, []interface{}{"2013-05-01 10:11:12", "%Y-%m-%d %T"}, "2013-05-01 10:11:12"},
+		{builtinStrToDate, []interface{}{"2013-02-30", "%Y-%m-%d"}, nil},
+		{builtinDateDiff, []interface{}{"2007-12-31 23:59:59", "2007-12-30"}, "1"},
+		{builtinDateDiff, []interface{}{"2010-11-30 23:59:59", "2010-12-31"}, "-31"},
+		{builtinDateDiff, []interface{}{"0000-00-00", "2010-12-31"}, nil},
+		{builtinTimestampDiff, []interface{}{"MONTH", "2003-02-01", "2003-05-01"}, "3"},
+		{builtinTimestampDiff, []interface{}{"YEAR", "2002-05-01", "2001-01-01"}, "-1"},
+		{builtinTimestampDiff, []interface{}{"MINUTE", "2003-02-01", "2003-05-01 12:05:55"}, "128885"},
+		{builtinTimestampDiff, []interface{}{"MONTH", "2003-01-31", "2003-02-28"}, "0"},
+		{builtinTimestampDiff, []interface{}{"QUARTER", "2003-01-01", "2003-12-31"}, "3"},
+		{builtinTimestampDiff, []interface{}{"WEEK", "2003-01-01", "2003-01-15 00:00:00"}, "2"},
+		{builtinTimestampAdd, []interface{}{"MINUTE", 1, "2003-01-02"}, "2003-01-02 00:01:00"},
+		{builtinTimestampAdd, []interface{}{"WEEK", 1, "2003-01-02"}, "2003-01-09 00:00:00"},
+		{builtinTimestampAdd, []interface{}{"MONTH", 1, "2003-01-31"}, "2003-02-28 00:00:00"},
+		{builtinTimestampAdd, []interface{}{"YEAR", -1, "2004-02-29 10:00:00"}, "2003-02-28 10:00:00"},
+		{builtinLastDay, []interface{}{"2003-02-05"}, "2003-02-28"},
+		{builtinLastDay, []interface{}{"2004-02-05"}, "2004-02-29"},
+		{builtinLastDay, []interface{}{"2004-01-01 01:01:01"}, "2004-01-31"},
+		{builtinLastDay, []interface{}{nil}, nil},
+		{builtinMakeDate, []interface{}{2011, 31}, "2011-01-31"},
+		{builtinMakeDate, []interface{}{2011, 32}, "2011-02-01"},
+		{builtinMakeDate, []interface{}{2011, 365}, "2011-12-31"},
+		{builtinMakeDate, []interface{}{2014, 365 * 2}, "2015-12-31"},
+		{builtinMakeDate, []interface{}{71, 1}, "1971-01-01"},
+		{builtinMakeDate, []interface{}{2011, 0}, nil},
+		{builtinDayName, []interface{}{"2007-02-03"}, "Saturday"},
+		{builtinDayName, []interface{}{"0000-00-00"}, nil},
+		{builtinMonthName, []interface{}{"2008-02-03"}, "February"},
+		{builtinMonthName, []interface{}{nil}, nil},
+		{builtinUnixTimestamp, []interface{}{"1969-01-01 00:00:00"}, "0"},
+		{builtinFromUnixTime, []interface{}{-1}, nil},
+		{builtinFromUnixTime, []interface{}{nil}, nil},
+	}
+
+	for _, t := range tbl {
+		v, err := t.Fn(t.Args, nil)
+		c.Assert(err, IsNil, Commentf("%v", t.Args))
+		if t.Expect == nil {
+			c.Assert(v, IsNil, Commentf("%v", t.Args))
+			continue
+		}
+		c.Assert(fmt.Sprint(v), Equals, t.Expect, Commentf("%v", t.Args))
+	}
+
+	// FROM_UNIXTIME and UNIX_TIMESTAMP use the local time zone, so only
+	// check that they are inverse of each other.
+	v, err := builtinUnixTimestamp([]interface{}{"2015-11-13 10:20:19.012"}, nil)
+	c.Assert(err, IsNil)
+	v, err = builtinFromUnixTime([]interface{}{v}, nil)
+	c.Assert(err, IsNil)
+	c.Assert(fmt.Sprint(v), Equals, "2015-11-13 10:20:19.012")
+
+	v, err = builtinUnixTimestamp([]interface{}{"2015-11-13 10:20:19"}, nil)
+	c.Assert(err, IsNil)
+	v, err = builtinFromUnixTime([]interface{}{v, "%Y %D %M %h:%i:%s %x"}, nil)
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "2015 13th November 10:20:19 2015")
+
+	v, err = builtinUnixTimestamp(nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(v.(int64), Greater, int64(0))
+}
+
+func (s *testBuiltinSuite) TestConvertTZ(c *C) {
+	tbl := []struct {
+		Args   []interface{}
+		Expect interface{}
+	}{
+		{[]interface{}{"2004-01-01 12:00:00", "+00:00", "+10:00"}, "2004-01-01 22:00:00"},
+		{[]interface{}{"2004-01-01 12:00:00.5", "+10:00", "-05:30"}, "2003-12-31 20:30:00.5"},
+		{[]interface{}{"2004-07-01 12:00:00", "Europe/Helsinki", "UTC"}, "2004-07-01 09:00:00"},
+		{[]interface{}{"2004-01-01 12:00:00", "Europe/Helsinki", "UTC"}, "2004-01-01 10:00:00"},
+		// No conversion for the time out of the TIMESTAMP range.
+		{[]interface{}{"1960-01-01 12:00:00", "+00:00", "+10:00"}, "1960-01-01 12:00:00"},
+		{[]interface{}{"2004-01-01 12:00:00", "+00:00", "foo"}, nil},
+		{[]interface{}{"2004-01-01 12:00:00", nil, "+10:00"}, nil},
+		{[]interface{}{nil, "+00:00", "+10:00"}, nil},
+	}
+
+	for _, t := range tbl {
+		v, err := builtinConvertTZ(t.Args, nil)
+		c.Assert(err, IsNil)
+		if t.Expect == nil {
+			c.Assert(v, IsNil, Commentf("%v", t.Args))
+			continue
+		}
+		c.Assert(fmt.Sprint(v), Equals, t.Expect, Commentf("%v", t.Args))
+	}
+}
+
+func (s *testBuiltinSuite) TestSessionTimeZone(c *C) {
+	ctx := mock.NewContext()
+	variable.BindSessionVars(ctx)
+	variable.GetSessionVars(ctx).Systems[variable.TimeZone] = "+10:00"
+	m := map[interface{}]interface{}{ExprEvalArgCtx: ctx}
+
+	v, err := builtinUnixTimestamp([]interface{}{"1970-01-01 10:00:01"}, m)
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, int64(1))
+
+	v, err = builtinFromUnixTime([]interface{}{int64(1447430881)}, m)
+	c.Assert(err, IsNil)
+	c.Assert(fmt.Sprint(v), Equals, "2015-11-14 02:08:01")
+
+	// NOW is the wall clock in the time zone of the session.
+	utc := mysql.WallClock(time.Now(), time.UTC)
+	v, err = builtinNow(nil, m)
+	c.Assert(err, IsNil)
+	d := v.(mysql.Time).Sub(utc)
+	c.Assert(d > 10*time.Hour-time.Minute && d < 10*time.Hour+time.Minute, IsTrue)
+}
@@ -169,6 +169,47 @@ func (v *mentionedAggregateFuncsVisitor) VisitCall(c *Call) (Expression, error)
 	return c, nil
 }
 
+// MentionedWindowFuncs returns a list of the WindowFunc expressions in e, the
+// nested window functions are not allowed.
+func MentionedWindowFuncs(e Expression) ([]*WindowFunc, error) {
+	mwfv := newMentionedWindowFuncsVisitor()
+	_, err := e.Accept(mwfv)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return mwfv.funcs, nil
+}
+
+// ContainWindowFunc checks whether expression e contains a window function.
+func ContainWindowFunc(e Expression) bool {
+	mwfv := newMentionedWindowFuncsVisitor()
+	e.Accept(mwfv)
+	return len(mwfv.funcs) > 0
+}
+
+type mentionedWindowFuncsVisitor struct {
+	BaseVisitor
+	funcs []*WindowFunc
+}
+
+func newMentionedWindowFuncsVisitor() *mentionedWindowFuncsVisitor {
+	v := &mentionedWindowFuncsVisitor{}
+	v.BaseVisitor.V = v
+	return v
+}
+
+func (v *mentionedWindowFuncsVisitor) VisitWindowFunc(w *WindowFunc) (Expression, error) {
+	v.funcs = append(v.funcs, w)
+	n := len(v.funcs)
+	if _, err := v.BaseVisitor.VisitWindowFunc(w); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(v.funcs) != n {
+		return nil, errors.Errorf("You cannot use the window function '%s' in this context.", v.funcs[n].F)
+	}
+	return w, nil
+}
+
 // IsAggregateFunc checks whether name is an aggregate function or not.
 func IsAggregateFunc(name string) bool {
 	// TODO: use switch defined aggregate name "sum", "count", etc... directly.
@@ -234,14 +275,16 @@ func IsCurrentTimeExpr(e Expression) bool {
 	return x.Equal(CurrentTimeExpr)
 }
 
+// getSystemTimestamp returns the current time in the time zone of the session.
 func getSystemTimestamp(ctx context.Context) (time.Time, error) {
 	value := time.Now()
 
 	if ctx == nil {
-		return value, nil
+		return mysql.WallClock(value, time.Local), nil
 	}
 
 	// check whether use timestamp varibale
+	loc := variable.GetTimeZone(ctx)
 	sessionVars := variable.GetSessionVars(ctx)
 	if v, ok := sessionVars.Systems["timestamp"]; ok {
 		if v != "" {
@@ -251,14 +294,14 @@ func getSystemTimestamp(ctx context.Context) (time.Time, error) {
 			}
 
 			if timestamp <= 0 {
-				return value, nil
+				return mysql.WallClock(value, loc), nil
 			}
 
-			return time.Unix(timestamp, 0), nil
+			return mysql.WallClock(time.Unix(timestamp, 0), loc), nil
 		}
 	}
 
-	return value, nil
+	return mysql.WallClock(value, loc), nil
 }
 
 // GetTimeValue gets the time value with type tp.
@@ -19,6 +19,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression/builtin"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -151,52 +152,15 @@ func (f *FunctionSubstringIndex) String() string {
 
 // Eval implements the Expression Eval interface.
 func (f *FunctionSubstringIndex) Eval(ctx context.Context, args map[interface{}]interface{}) (interface{}, error) {
-	fs, err := f.StrExpr.Eval(ctx, args)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-	str, err := types.ToString(fs)
-	if err != nil {
-		return nil, errors.Errorf("Substring_Index invalid args, need string but get %T", fs)
-	}
-
-	t, err := f.Delim.Eval(ctx, args)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-	delim, err := types.ToString(t)
-	if err != nil {
-		return nil, errors.Errorf("Substring_Index invalid delim, need string but get %T", t)
-	}
-
-	t, err = f.Count.Eval(ctx, args)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-	c, err := types.ToInt64(t)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-	count := int(c)
-	strs := strings.Split(str, delim)
-	var (
-		start = 0
-		end   = len(strs)
-	)
-	if count > 0 {
-		// If count is positive, everything to the left of the final delimiter (counting from the left) is returned.
-		if count < end {
-			end = count
-		}
-	} else {
-		// If count is negative, everything to the right of the final delimiter (counting from the right) is returned.
-		count = -count
-		if count < end {
-			start = end - count
+	a := make([]interface{}, 3)
+	for i, e := range []Expression{f.StrExpr, f.Delim, f.Count} {
+		v, err := e.Eval(ctx, args)
+		if err != nil {
+			return nil, errors.Trace(err)
 		}
+		a[i] = v
 	}
-	substrs := strs[start:end]
-	return strings.Join(substrs, delim), nil
+	return builtin.Funcs["substring_index"].F(a, args)
 }
 
 // Accept implements Expression Accept interface.
@@ -109,6 +109,9 @@ type Visitor interface {
 
 	// VisitDateArith visits DateArith expression.
 	VisitDateArith(da *DateArith) (Expression, error)
+
+	// VisitWindowFunc visits WindowFunc expression.
+	VisitWindowFunc(w *WindowFunc) (Expression, error)
 }
 
 // BaseVisitor is the base implementation of Visitor.
@@ -497,3 +500,27 @@ func (bv *BaseVisitor) VisitDateArith(da *DateArith) (Expression, error) {
 
 	return da, nil
 }
+
+// VisitWindowFunc implements Visitor interface.
+func (bv *BaseVisitor) VisitWindowFunc(w *WindowFunc) (Expression, error) {
+	var err error
+	for i := range w.Args {
+		w.Args[i], err = w.Args[i].Accept(bv.V)
+		if err != nil {
+			return w, errors.Trace(err)
+		}
+	}
+	for i := range w.PartitionBy {
+		w.PartitionBy[i], err = w.PartitionBy[i].Accept(bv.V)
+		if err != nil {
+			return w, errors.Trace(err)
+		}
+	}
+	for _, v := range w.OrderBy {
+		v.Expr, err = v.Expr.Accept(bv.V)
+		if err != nil {
+			return w, errors.Trace(err)
+		}
+	}
+	return w, nil
+}
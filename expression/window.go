@@ -0,0 +1,275 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var (
+	_ Expression = (*WindowFunc)(nil)
+)
+
+// windowFunc is the definition of a window function.
+type windowFunc struct {
+	minArgs int
+	maxArgs int
+	// framed is true if the function is computed on the frame of the current row
+	// instead of the whole partition.
+	framed bool
+}
+
+// windowFuncs holds all the window functions, the aggregate functions are
+// computed on the frame too.
+// See: https://dev.mysql.com/doc/refman/8.0/en/window-function-descriptions.html
+var windowFuncs = map[string]windowFunc{
+	"cume_dist":    {0, 0, false},
+	"dense_rank":   {0, 0, false},
+	"first_value":  {1, 1, true},
+	"lag":          {1, 3, false},
+	"last_value":   {1, 1, true},
+	"lead":         {1, 3, false},
+	"nth_value":    {2, 2, true},
+	"ntile":        {1, 1, false},
+	"percent_rank": {0, 0, false},
+	"rank":         {0, 0, false},
+	"row_number":   {0, 0, false},
+
+	"avg":   {1, 1, true},
+	"count": {1, 1, true},
+	"max":   {1, 1, true},
+	"min":   {1, 1, true},
+	"sum":   {1, 1, true},
+}
+
+// BoundType is the type of a window frame bound.
+type BoundType int
+
+// Bound types.
+const (
+	BoundPreceding BoundType = iota + 1
+	BoundFollowing
+	BoundCurrentRow
+)
+
+// FrameBound is a bound of a window frame.
+type FrameBound struct {
+	Type      BoundType
+	UnBounded bool
+	// N is the offset of N PRECEDING and N FOLLOWING, it is a row count for a
+	// ROWS frame and a value offset of the ORDER BY item for a RANGE frame.
+	N interface{}
+}
+
+// String implements fmt.Stringer interface.
+func (b FrameBound) String() string {
+	var s string
+	switch b.Type {
+	case BoundCurrentRow:
+		return "CURRENT ROW"
+	case BoundPreceding:
+		s = "PRECEDING"
+	default:
+		s = "FOLLOWING"
+	}
+	if b.UnBounded {
+		return "UNBOUNDED " + s
+	}
+	return fmt.Sprintf("%v %s", b.N, s)
+}
+
+// WindowFrame is the rows of the partition between Start and End, on which
+// the framed window functions are computed.
+type WindowFrame struct {
+	// Range is true for RANGE frame, false for ROWS frame.
+	Range bool
+	Start FrameBound
+	End   FrameBound
+}
+
+// String implements fmt.Stringer interface.
+func (f *WindowFrame) String() string {
+	unit := "ROWS"
+	if f.Range {
+		unit = "RANGE"
+	}
+	return fmt.Sprintf("%s BETWEEN %s AND %s", unit, f.Start, f.End)
+}
+
+// WindowOrderItem is an item of ORDER BY in a window specification.
+type WindowOrderItem struct {
+	Expr Expression
+	Asc  bool
+}
+
+// WindowFunc is a window function or an aggregate function with OVER clause.
+// It is computed on the rows related to the current row by WindowPlan, which
+// saves the result in the eval args with the WindowFunc itself as the key.
+type WindowFunc struct {
+	// F is the function name.
+	F string
+	// Args is the function args.
+	Args []Expression
+	// PartitionBy is the PARTITION BY expressions of the window.
+	PartitionBy []Expression
+	// OrderBy is the ORDER BY items in a partition.
+	OrderBy []*WindowOrderItem
+	// Frame is nil if there is no frame clause in the window.
+	Frame *WindowFrame
+}
+
+// NewWindowFunc creates a WindowFunc expression.
+func NewWindowFunc(f string, args []Expression, distinct bool, partitionBy []Expression, orderBy []*WindowOrderItem, frame *WindowFrame) (*WindowFunc, error) {
+	name := strings.ToLower(f)
+	x, ok := windowFuncs[name]
+	if !ok {
+		return nil, errors.Errorf("This version of TiDB doesn't yet support '%s as window function'", f)
+	}
+	if distinct {
+		return nil, errors.Errorf("This version of TiDB doesn't yet support '<window function>(DISTINCT ..)'")
+	}
+	if g := len(args); g < x.minArgs || g > x.maxArgs {
+		a := []interface{}{}
+		for _, v := range args {
+			a = append(a, v)
+		}
+		return nil, badNArgs(x.minArgs, f, a)
+	}
+	if frame != nil {
+		if err := checkWindowFrame(frame, orderBy); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return &WindowFunc{
+		F:           name,
+		Args:        args,
+		PartitionBy: partitionBy,
+		OrderBy:     orderBy,
+		Frame:       frame,
+	}, nil
+}
+
+func checkWindowFrame(frame *WindowFrame, orderBy []*WindowOrderItem) error {
+	if frame.Start.Type == BoundFollowing && frame.Start.UnBounded {
+		return errors.New("Window '<unnamed window>': frame start cannot be UNBOUNDED FOLLOWING.")
+	}
+	if frame.End.Type == BoundPreceding && frame.End.UnBounded {
+		return errors.New("Window '<unnamed window>': frame end cannot be UNBOUNDED PRECEDING.")
+	}
+	for _, b := range []FrameBound{frame.Start, frame.End} {
+		if b.Type == BoundCurrentRow || b.UnBounded {
+			continue
+		}
+		if frame.Range && len(orderBy) != 1 {
+			return errors.New("Window '<unnamed window>' with RANGE N PRECEDING/FOLLOWING frame requires exactly one ORDER BY expression, of numeric or temporal type")
+		}
+		var n float64
+		switch x := types.RawData(b.N).(type) {
+		case int64:
+			n = float64(x)
+		case uint64:
+			n = float64(x)
+		default:
+			if !frame.Range {
+				return errors.New("Window '<unnamed window>': frame start or end is negative, NULL or of non-integral type")
+			}
+			var err error
+			if n, err = types.ToFloat64(x); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		if n < 0 {
+			return errors.New("Window '<unnamed window>': frame start or end is negative, NULL or of non-integral type")
+		}
+	}
+	return nil
+}
+
+// Framed returns true if the function is computed on the frame of the current row.
+func (w *WindowFunc) Framed() bool {
+	return windowFuncs[w.F].framed
+}
+
+// Clone implements the Expression Clone interface.
+func (w *WindowFunc) Clone() Expression {
+	nw := &WindowFunc{
+		F:           w.F,
+		Args:        cloneExpressionList(w.Args),
+		PartitionBy: cloneExpressionList(w.PartitionBy),
+	}
+	for _, v := range w.OrderBy {
+		nw.OrderBy = append(nw.OrderBy, &WindowOrderItem{Expr: v.Expr.Clone(), Asc: v.Asc})
+	}
+	if w.Frame != nil {
+		frame := *w.Frame
+		nw.Frame = &frame
+	}
+	return nw
+}
+
+// IsStatic implements the Expression IsStatic interface, always returns false.
+func (w *WindowFunc) IsStatic() bool {
+	return false
+}
+
+// String implements the Expression String interface.
+func (w *WindowFunc) String() string {
+	a := []string{}
+	for _, v := range w.Args {
+		a = append(a, v.String())
+	}
+	var spec []string
+	if len(w.PartitionBy) > 0 {
+		p := []string{}
+		for _, v := range w.PartitionBy {
+			p = append(p, v.String())
+		}
+		spec = append(spec, "PARTITION BY "+strings.Join(p, ", "))
+	}
+	if len(w.OrderBy) > 0 {
+		o := []string{}
+		for _, v := range w.OrderBy {
+			if v.Asc {
+				o = append(o, v.Expr.String())
+			} else {
+				o = append(o, v.Expr.String()+" DESC")
+			}
+		}
+		spec = append(spec, "ORDER BY "+strings.Join(o, ", "))
+	}
+	if w.Frame != nil {
+		spec = append(spec, w.Frame.String())
+	}
+	return fmt.Sprintf("%s(%s) OVER (%s)", w.F, strings.Join(a, ", "), strings.Join(spec, " "))
+}
+
+// Eval implements the Expression Eval interface, it returns the result computed
+// by WindowPlan for the current row.
+func (w *WindowFunc) Eval(ctx context.Context, args map[interface{}]interface{}) (v interface{}, err error) {
+	v, ok := args[w]
+	if !ok {
+		return nil, errors.Errorf("You cannot use the window function '%s' in this context.", w.F)
+	}
+	return v, nil
+}
+
+// Accept implements Expression Accept interface.
+func (w *WindowFunc) Accept(v Visitor) (Expression, error) {
+	return v.VisitWindowFunc(w)
+}
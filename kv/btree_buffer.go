@@ -16,6 +16,8 @@
 package kv
 
 import (
+	"bytes"
+
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/kv/memkv"
@@ -58,6 +60,9 @@ type btreeIter struct {
 	k  string
 	v  []byte
 	ok bool
+	// reverse is true if the iterator iterates the keys less than limit in descending order.
+	reverse bool
+	limit   []byte
 }
 
 // NewIterator creates a new Iterator based on the provided param
@@ -83,6 +88,40 @@ func (b *btreeBuffer) NewIterator(param interface{}) Iterator {
 	return iter
 }
 
+// NewReverseIterator creates a new Iterator which iterates in descending order.
+func (b *btreeBuffer) NewReverseIterator(param interface{}) Iterator {
+	var e *memkv.Enumerator
+	var limit []byte
+	var err error
+	if param == nil {
+		e, err = b.tree.SeekLast()
+		if err != nil {
+			return &btreeIter{ok: false}
+		}
+	} else {
+		limit = param.([]byte)
+		last, err := b.tree.SeekLast()
+		if err != nil {
+			return &btreeIter{ok: false}
+		}
+		lastKey, _, _ := last.Prev()
+		if bytes.Compare(fromIfaces(lastKey), limit) < 0 {
+			// Seek would position the enumerator after the last key, from where
+			// it can't move backwards.
+			e, _ = b.tree.SeekLast()
+		} else {
+			e, _ = b.tree.Seek(toIfaces(limit))
+		}
+	}
+	iter := &btreeIter{e: e, reverse: true, limit: limit}
+	err = iter.Next()
+	if err != nil {
+		log.Error(err)
+		return &btreeIter{ok: false}
+	}
+	return iter
+}
+
 // Close implements Iterator Close.
 func (i *btreeIter) Close() {
 	//noop
@@ -100,6 +139,9 @@ func (i *btreeIter) Value() []byte {
 
 // Next implements Iterator Next.
 func (i *btreeIter) Next() error {
+	if i.reverse {
+		return i.prev()
+	}
 	k, v, err := i.e.Next()
 	if err != nil {
 		i.ok = false
@@ -109,6 +151,23 @@ func (i *btreeIter) Next() error {
 	return nil
 }
 
+func (i *btreeIter) prev() error {
+	for {
+		k, v, err := i.e.Prev()
+		if err != nil {
+			i.ok = false
+			return errors.Trace(err)
+		}
+		key := fromIfaces(k)
+		// The enumerator starts at the first key not less than the limit.
+		if i.limit != nil && bytes.Compare(key, i.limit) >= 0 {
+			continue
+		}
+		i.k, i.v, i.ok = string(key), fromIfaces(v), true
+		return nil
+	}
+}
+
 // Valid implements Iterator Valid.
 func (i *btreeIter) Valid() bool {
 	return i.ok
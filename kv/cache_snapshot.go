@@ -106,6 +106,11 @@ func (c *cacheSnapshot) NewIterator(param interface{}) Iterator {
 	return newUnionIter(c.cache.NewIterator(param), c.snapshot.NewIterator(param))
 }
 
+// NewReverseIterator creates a reverse iterator of snapshot.
+func (c *cacheSnapshot) NewReverseIterator(param interface{}) Iterator {
+	return newReverseUnionIter(c.cache.NewReverseIterator(param), c.snapshot.NewReverseIterator(param))
+}
+
 // Release reset membuffer and release snapshot.
 func (c *cacheSnapshot) Release() {
 	if c.cache != nil {
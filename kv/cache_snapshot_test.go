@@ -144,6 +144,10 @@ func (s *mockSnapshot) NewIterator(param interface{}) Iterator {
 	return s.store.NewIterator(param)
 }
 
+func (s *mockSnapshot) NewReverseIterator(param interface{}) Iterator {
+	return s.store.NewReverseIterator(param)
+}
+
 func (s *mockSnapshot) Release() {
 	s.store.Release()
 }
@@ -74,8 +74,8 @@ func (c *indexIter) Next() (k []interface{}, h int64, err error) {
 	if err != nil {
 		return nil, 0, errors.Trace(err)
 	}
-	// if index is *not* unique, the handle is in keybuf
-	if !c.idx.unique {
+	// if index is *not* unique, or the values have null, the handle is in keybuf
+	if !c.idx.unique || hasNullValue(vv) {
 		h = vv[len(vv)-1].(int64)
 		k = vv[0 : len(vv)-1]
 	} else {
@@ -94,6 +94,17 @@ func (c *indexIter) Next() (k []interface{}, h int64, err error) {
 	return
 }
 
+// hasNullValue returns whether there is a null value in the decoded index key,
+// a unique index entry of null values is not distinct, see GenIndexKey.
+func hasNullValue(vv []interface{}) bool {
+	for _, v := range vv {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
+
 // kvIndex is the data structure for index data in the KV store.
 type kvIndex struct {
 	indexName string
@@ -236,6 +247,26 @@ func (c *kvIndex) SeekFirst(txn Transaction) (iter IndexIterator, err error) {
 	return &indexIter{it: it, idx: c, prefix: c.prefix}, nil
 }
 
+// SeekReverse returns an iterator which iterates the entries in descending order
+// from the last entry whose leading values equal to or less than indexedValues.
+// If indexedValues is empty, it iterates from the last entry of the KV index.
+func (c *kvIndex) SeekReverse(txn Transaction, indexedValues []interface{}) (iter IndexIterator, err error) {
+	key := []byte(c.prefix)
+	if len(indexedValues) > 0 {
+		// All the entries with the leading values have this key prefix.
+		prefix, err := codec.EncodeKeyPrefix(indexedValues...)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		key = append(key, prefix...)
+	}
+	it, err := txn.SeekReverse(Key(key).PrefixNext())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &indexIter{it: it, idx: c, prefix: c.prefix}, nil
+}
+
 func (c *kvIndex) Exist(txn Transaction, indexedValues []interface{}, h int64) (bool, int64, error) {
 	key, distinct, err := c.GenIndexKey(indexedValues, h)
 	if err != nil {
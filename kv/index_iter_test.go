@@ -140,6 +140,64 @@ func (s *testIndexSuite) TestIndex(c *C) {
 	c.Assert(h, Equals, int64(1))
 	c.Assert(exist, IsTrue)
 
+	// Null values are not unique, the handle is in the key.
+	nullValues := []interface{}{nil, 2}
+	err = index.Create(txn, nullValues, 3)
+	c.Assert(err, IsNil)
+	err = index.Create(txn, nullValues, 4)
+	c.Assert(err, IsNil)
+
+	it, err = index.SeekFirst(txn)
+	c.Assert(err, IsNil)
+	for _, want := range []int64{3, 4} {
+		getValues, h, err = it.Next()
+		c.Assert(err, IsNil)
+		c.Assert(getValues, HasLen, 2)
+		c.Assert(getValues[0], IsNil)
+		c.Assert(h, Equals, want)
+	}
+	it.Close()
+
 	err = txn.Commit()
 	c.Assert(err, IsNil)
 }
+
+func (s *testIndexSuite) TestSeekReverse(c *C) {
+	index := kv.NewKVIndex("r", "test", false)
+	txn, err := s.s.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+
+	rows := [][]interface{}{{1, 1}, {1, 2}, {2, 1}, {2, 2}, {3, 1}}
+	for i, values := range rows {
+		err = index.Create(txn, values, int64(i))
+		c.Assert(err, IsNil)
+	}
+	// An entry of another index.
+	err = kv.NewKVIndex("r", "test1", false).Create(txn, []interface{}{0, 0}, 0)
+	c.Assert(err, IsNil)
+
+	checkHandles := func(it kv.IndexIterator, handles ...int64) {
+		defer it.Close()
+		for _, want := range handles {
+			_, h, err := it.Next()
+			c.Assert(err, IsNil)
+			c.Assert(h, Equals, want)
+		}
+		_, _, err := it.Next()
+		c.Assert(terror.ErrorEqual(err, io.EOF), IsTrue)
+	}
+
+	it, err := index.SeekReverse(txn, nil)
+	c.Assert(err, IsNil)
+	checkHandles(it, 4, 3, 2, 1, 0)
+
+	// The entries with the leading values are included.
+	it, err = index.SeekReverse(txn, []interface{}{2})
+	c.Assert(err, IsNil)
+	checkHandles(it, 3, 2, 1, 0)
+
+	it, err = index.SeekReverse(txn, []interface{}{2, 1})
+	c.Assert(err, IsNil)
+	checkHandles(it, 2, 1, 0)
+}
@@ -14,6 +14,8 @@
 package kv
 
 import (
+	"bytes"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/util/codec"
 )
@@ -52,6 +54,15 @@ func EncodeKey(k []byte) []byte {
 	return append(keyPrefix, k...)
 }
 
+// EncodeSeekReverseKey returns the encoded key to seek reversely from k,
+// which is greater than all the encoded keys if k is nil.
+func EncodeSeekReverseKey(k []byte) []byte {
+	if k == nil {
+		return Key(keyPrefix).PrefixNext()
+	}
+	return EncodeKey(k)
+}
+
 // DecodeKey removes the prefixed keyPrefix.
 func DecodeKey(k []byte) []byte {
 	return k[len(keyPrefix):]
@@ -104,7 +115,8 @@ func (iter *decodeKeyIter) Key() string {
 }
 
 func (iter *decodeKeyIter) Valid() bool {
-	return iter.iter.Valid()
+	// A reverse iterator may go to the keys without keyPrefix.
+	return iter.iter.Valid() && bytes.HasPrefix([]byte(iter.iter.Key()), keyPrefix)
 }
 
 func (iter *decodeKeyIter) Close() {
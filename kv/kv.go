@@ -31,6 +31,20 @@ func (k Key) Next() Key {
 	return buf
 }
 
+// PrefixNext returns the next prefix key in byte-order, which is greater
+// than all the keys with prefix k. It returns nil if there is no such key.
+func (k Key) PrefixNext() Key {
+	buf := make([]byte, len([]byte(k)))
+	copy(buf, []byte(k))
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i]++
+		if buf[i] != 0 {
+			return buf[:i+1]
+		}
+	}
+	return nil
+}
+
 // Cmp returns the comparison result of two key.
 // The result will be 0 if a==b, -1 if a < b, and +1 if a > b.
 func (k Key) Cmp(another Key) int {
@@ -110,6 +124,9 @@ type Transaction interface {
 	Set(k Key, v []byte) error
 	// Seek searches for the entry with key k in KV store.
 	Seek(k Key) (Iterator, error)
+	// SeekReverse creates an iterator to iterate the entries with keys less
+	// than k in KV store in descending order, from the last entry if k is nil.
+	SeekReverse(k Key) (Iterator, error)
 	// Inc increases the value for key k in KV store by step.
 	Inc(k Key, step int64) (int64, error)
 	// GetInt64 get int64 which created by Inc method.
@@ -152,6 +169,9 @@ type Snapshot interface {
 	RangeGet(start, end Key, limit int) (map[string][]byte, error)
 	// NewIterator gets a new iterator on the snapshot.
 	NewIterator(param interface{}) Iterator
+	// NewReverseIterator gets a new iterator on the snapshot which iterates
+	// the keys less than param in descending order, all the keys if param is nil.
+	NewReverseIterator(param interface{}) Iterator
 	// Release releases the snapshot to store.
 	Release()
 }
@@ -164,6 +184,9 @@ type MemBuffer interface {
 	Set([]byte, []byte) error
 	// NewIterator gets a new iterator on the buffer.
 	NewIterator(param interface{}) Iterator
+	// NewReverseIterator gets a new iterator on the buffer which iterates
+	// the keys less than param in descending order, all the keys if param is nil.
+	NewReverseIterator(param interface{}) Iterator
 	// Release releases the buffer.
 	Release()
 }
@@ -218,4 +241,5 @@ type Index interface {
 	GenIndexKey(indexedValues []interface{}, h int64) (key []byte, distinct bool, err error)     // supports index check
 	Seek(txn Transaction, indexedValues []interface{}) (iter IndexIterator, hit bool, err error) // supports where clause
 	SeekFirst(txn Transaction) (iter IndexIterator, err error)                                   // supports aggregate min / ascending order by
+	SeekReverse(txn Transaction, indexedValues []interface{}) (iter IndexIterator, err error)    // supports aggregate max / descending order by
 }
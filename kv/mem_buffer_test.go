@@ -192,6 +192,52 @@ func (s *testKVSuite) TestNewIteratorMin(c *C) {
 	}
 }
 
+func (s *testKVSuite) TestNewReverseIterator(c *C) {
+	for _, buffer := range s.bs {
+		iter := buffer.NewReverseIterator(nil)
+		c.Assert(iter.Valid(), IsFalse)
+
+		for i := 0; i < 3; i++ {
+			val := encodeInt(i * indexStep)
+			c.Assert(buffer.Set(val, val), IsNil)
+		}
+
+		iter = buffer.NewReverseIterator(nil)
+		for i := 2; i >= 0; i-- {
+			c.Assert(iter.Valid(), IsTrue)
+			c.Assert(iter.Key(), Equals, string(encodeInt(i*indexStep)))
+			iter.Next()
+		}
+		c.Assert(iter.Valid(), IsFalse)
+		iter.Close()
+
+		// The keys less than the seek key are returned.
+		iter = buffer.NewReverseIterator(encodeInt(2 * indexStep))
+		c.Assert(iter.Key(), Equals, string(encodeInt(indexStep)))
+		iter.Close()
+
+		iter = buffer.NewReverseIterator(encodeInt(2*indexStep - 1))
+		c.Assert(iter.Key(), Equals, string(encodeInt(indexStep)))
+		iter.Close()
+
+		iter = buffer.NewReverseIterator(encodeInt(0))
+		c.Assert(iter.Valid(), IsFalse)
+		iter.Close()
+
+		// All the keys are less than the seek key.
+		iter = buffer.NewReverseIterator(encodeInt(3 * indexStep))
+		for i := 2; i >= 0; i-- {
+			c.Assert(iter.Valid(), IsTrue)
+			c.Assert(iter.Key(), Equals, string(encodeInt(i*indexStep)))
+			iter.Next()
+		}
+		c.Assert(iter.Valid(), IsFalse)
+		iter.Close()
+
+		buffer.Release()
+	}
+}
+
 var opCnt = 100000
 
 func BenchmarkBTreeBufferSequential(b *testing.B) {
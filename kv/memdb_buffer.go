@@ -29,7 +29,8 @@ type memDbBuffer struct {
 }
 
 type memDbIter struct {
-	iter iterator.Iterator
+	iter    iterator.Iterator
+	reverse bool
 }
 
 // NewMemDbBuffer creates a new memDbBuffer.
@@ -49,6 +50,18 @@ func (m *memDbBuffer) NewIterator(param interface{}) Iterator {
 	return i
 }
 
+// NewReverseIterator creates an Iterator which iterates in descending order.
+func (m *memDbBuffer) NewReverseIterator(param interface{}) Iterator {
+	var i *memDbIter
+	if param == nil {
+		i = &memDbIter{iter: m.db.NewIterator(&util.Range{}), reverse: true}
+	} else {
+		i = &memDbIter{iter: m.db.NewIterator(&util.Range{Limit: param.([]byte)}), reverse: true}
+	}
+	i.iter.Last()
+	return i
+}
+
 // Get returns the value associated with key.
 func (m *memDbBuffer) Get(k Key) ([]byte, error) {
 	v, err := m.db.Get(k)
@@ -70,7 +83,11 @@ func (m *memDbBuffer) Release() {
 
 // Next implements the Iterator Next.
 func (i *memDbIter) Next() error {
-	i.iter.Next()
+	if i.reverse {
+		i.iter.Prev()
+	} else {
+		i.iter.Next()
+	}
 	return nil
 }
 
@@ -29,6 +29,8 @@ type UnionIter struct {
 
 	curIsDirty bool
 	isValid    bool
+	// reverse is true if both iterators iterate in descending order.
+	reverse bool
 }
 
 func newUnionIter(dirtyIt Iterator, snapshotIt Iterator) *UnionIter {
@@ -42,6 +44,18 @@ func newUnionIter(dirtyIt Iterator, snapshotIt Iterator) *UnionIter {
 	return it
 }
 
+func newReverseUnionIter(dirtyIt Iterator, snapshotIt Iterator) *UnionIter {
+	it := &UnionIter{
+		dirtyIt:       dirtyIt,
+		snapshotIt:    snapshotIt,
+		dirtyValid:    dirtyIt.Valid(),
+		snapshotValid: snapshotIt.Valid(),
+		reverse:       true,
+	}
+	it.updateCur()
+	return it
+}
+
 // Go next and update valid status.
 func (iter *UnionIter) dirtyNext() {
 	iter.dirtyIt.Next()
@@ -82,6 +96,9 @@ func (iter *UnionIter) updateCur() {
 			snapshotKey := []byte(iter.snapshotIt.Key())
 			dirtyKey := []byte(iter.dirtyIt.Key())
 			cmp := bytes.Compare(dirtyKey, snapshotKey)
+			if iter.reverse {
+				cmp = -cmp
+			}
 			// if equal, means both have value
 			if cmp == 0 {
 				if len(iter.dirtyIt.Value()) == 0 {
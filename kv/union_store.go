@@ -81,6 +81,14 @@ func (us *UnionStore) Seek(key []byte, txn Transaction) (Iterator, error) {
 	return newUnionIter(bufferIt, cacheIt), nil
 }
 
+// SeekReverse creates a reverse iterator of the keys less than key, all the
+// keys if key is nil.
+func (us *UnionStore) SeekReverse(key []byte, txn Transaction) (Iterator, error) {
+	bufferIt := us.WBuffer.NewReverseIterator(key)
+	cacheIt := us.Snapshot.NewReverseIterator(key)
+	return newReverseUnionIter(bufferIt, cacheIt), nil
+}
+
 // Delete implements the Store Delete interface.
 func (us *UnionStore) Delete(k []byte) error {
 	// Mark as deleted
@@ -44,12 +44,22 @@ var (
 //		mTID:1 -> int64
 //		mTID:2 -> int64
 //	}
+//	mStats -> {
+//		mTable:1 -> table statistics []byte
+//		mTable:2 -> table statistics []byte
+//	}
+//	mStatsVersion -> {
+//		mTable:1 -> int64
+//		mTable:2 -> int64
+//	}
 //
 
 var (
 	mNextGlobalIDKey  = []byte("mNextGlobalID")
 	mSchemaVersionKey = []byte("mSchemaVersionKey")
 	mDBs              = []byte("mDBs")
+	mStats            = []byte("mStats")
+	mStatsVersion     = []byte("mStatsVersion")
 	mDBPrefix         = "mDB"
 	mTablePrefix      = "mTable"
 	mTableIDPrefix    = "mTID"
@@ -263,6 +273,23 @@ func (m *Meta) DropDatabase(dbID int64) error {
 	// first check db exists or not.
 	dbKey := m.dbKey(dbID)
 
+	// drop the statistics of all tables in the database.
+	fields, err := m.txn.HKeys(dbKey)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, field := range fields {
+		if !strings.HasPrefix(string(field), mTablePrefix) {
+			continue
+		}
+		if err = m.txn.HDel(mStats, field); err != nil {
+			return errors.Trace(err)
+		}
+		if err = m.txn.HDel(mStatsVersion, field); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
 	if err := m.txn.HClear(dbKey); err != nil {
 		return errors.Trace(err)
 	}
@@ -297,6 +324,14 @@ func (m *Meta) DropTable(dbID int64, tableID int64) error {
 		return errors.Trace(err)
 	}
 
+	if err := m.txn.HDel(mStats, tableKey); err != nil {
+		return errors.Trace(err)
+	}
+
+	if err := m.txn.HDel(mStatsVersion, tableKey); err != nil {
+		return errors.Trace(err)
+	}
+
 	return nil
 }
 
@@ -409,6 +444,31 @@ func (m *Meta) GetTable(dbID int64, tableID int64) (*model.TableInfo, error) {
 	return tableInfo, errors.Trace(err)
 }
 
+// SetTableStats sets the encoded statistics of the table and increases the
+// statistics version of the table.
+func (m *Meta) SetTableStats(tableID int64, data []byte) error {
+	tableKey := m.tableKey(tableID)
+	if err := m.txn.HSet(mStats, tableKey, data); err != nil {
+		return errors.Trace(err)
+	}
+	_, err := m.txn.HInc(mStatsVersion, tableKey, 1)
+	return errors.Trace(err)
+}
+
+// GetTableStatsVersion gets the statistics version of the table,
+// returns 0 if the statistics are not set.
+func (m *Meta) GetTableStatsVersion(tableID int64) (int64, error) {
+	version, err := m.txn.HGetInt64(mStatsVersion, m.tableKey(tableID))
+	return version, errors.Trace(err)
+}
+
+// GetTableStats gets the encoded statistics of the table,
+// returns nil if the table has not been analyzed.
+func (m *Meta) GetTableStats(tableID int64) ([]byte, error) {
+	value, err := m.txn.HGet(mStats, m.tableKey(tableID))
+	return value, errors.Trace(err)
+}
+
 // DDL structure
 //	mDDLOnwer: []byte
 //	mDDLJobList: list jobs
@@ -132,9 +132,38 @@ func (s *testSuite) TestMeta(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(tables, DeepEquals, []*model.TableInfo{tbInfo, tbInfo2})
 
+	stats, err := t.GetTableStats(2)
+	c.Assert(err, IsNil)
+	c.Assert(stats, IsNil)
+
+	err = t.SetTableStats(1, []byte("stats1"))
+	c.Assert(err, IsNil)
+	err = t.SetTableStats(2, []byte("stats2"))
+	c.Assert(err, IsNil)
+
+	stats, err = t.GetTableStats(2)
+	c.Assert(err, IsNil)
+	c.Assert(stats, BytesEquals, []byte("stats2"))
+
+	version, err := t.GetTableStatsVersion(2)
+	c.Assert(err, IsNil)
+	c.Assert(version, Equals, int64(1))
+	err = t.SetTableStats(2, []byte("stats2"))
+	c.Assert(err, IsNil)
+	version, err = t.GetTableStatsVersion(2)
+	c.Assert(err, IsNil)
+	c.Assert(version, Equals, int64(2))
+
 	err = t.DropTable(1, 2)
 	c.Assert(err, IsNil)
 
+	stats, err = t.GetTableStats(2)
+	c.Assert(err, IsNil)
+	c.Assert(stats, IsNil)
+	version, err = t.GetTableStatsVersion(2)
+	c.Assert(err, IsNil)
+	c.Assert(version, Equals, int64(0))
+
 	tables, err = t.ListTables(1)
 	c.Assert(err, IsNil)
 	c.Assert(tables, DeepEquals, []*model.TableInfo{tbInfo})
@@ -142,6 +171,10 @@ func (s *testSuite) TestMeta(c *C) {
 	err = t.DropDatabase(1)
 	c.Assert(err, IsNil)
 
+	stats, err = t.GetTableStats(1)
+	c.Assert(err, IsNil)
+	c.Assert(stats, IsNil)
+
 	dbs, err = t.ListDatabases()
 	c.Assert(err, IsNil)
 	c.Assert(dbs, HasLen, 0)
@@ -15,6 +15,7 @@ package model
 
 import (
 	"strings"
+	"time"
 
 	"github.com/pingcap/tidb/util/types"
 )
@@ -68,6 +69,18 @@ func (c *ColumnInfo) Clone() *ColumnInfo {
 	return &nc
 }
 
+const (
+	// TableInfoVersion0 is the version of the tables created before the tables
+	// are versioned, whose TIMESTAMP values in the index keys are the wall
+	// clocks in the local time zone of the server.
+	TableInfoVersion0 uint16 = 0
+	// TableInfoVersion1 is the version of the tables whose TIMESTAMP values in
+	// the index keys are the wall clocks in UTC.
+	TableInfoVersion1 uint16 = 1
+	// CurrLatestTableInfoVersion is the version of the tables created now.
+	CurrLatestTableInfoVersion = TableInfoVersion1
+)
+
 // TableInfo provides meta data describing a DB table.
 type TableInfo struct {
 	ID      int64  `json:"id"`
@@ -78,6 +91,20 @@ type TableInfo struct {
 	Columns []*ColumnInfo `json:"cols"`
 	Indices []*IndexInfo  `json:"index_info"`
 	State   SchemaState   `json:"state"`
+	// View is the definition of a view, it is nil for a base table.
+	View *ViewInfo `json:"view,omitempty"`
+	// Version is the version of the stored format of the table.
+	Version uint16 `json:"version"`
+}
+
+// TimestampIndexLocation returns the time zone of the wall clocks of the
+// TIMESTAMP values in the index keys of the table. The TIMESTAMP values in
+// the rows are always stored in UTC.
+func (t *TableInfo) TimestampIndexLocation() *time.Location {
+	if t.Version < TableInfoVersion1 {
+		return time.Local
+	}
+	return time.UTC
 }
 
 // Clone clones TableInfo.
@@ -93,9 +120,38 @@ func (t *TableInfo) Clone() *TableInfo {
 	for i := range t.Indices {
 		nt.Indices[i] = t.Indices[i].Clone()
 	}
+
+	if t.View != nil {
+		nv := *t.View
+		nt.View = &nv
+	}
 	return &nt
 }
 
+// IsView returns whether the table is a view.
+func (t *TableInfo) IsView() bool {
+	return t.View != nil
+}
+
+// ViewInfo provides meta data describing a view.
+type ViewInfo struct {
+	// Definer is the user who created the view, the tables in the view are read
+	// with the privileges of the definer.
+	Definer string `json:"definer"`
+	// Select is the text of the SELECT statement of the view.
+	Select string `json:"select"`
+	// Fields are the fields of Select when the view is created, the fields
+	// that a wildcard gets from the columns added to the tables later are
+	// not in the view.
+	Fields []*ViewField `json:"fields,omitempty"`
+}
+
+// ViewField is a field of the SELECT statement of a view.
+type ViewField struct {
+	Table CIStr `json:"table"`
+	Name  CIStr `json:"name"`
+}
+
 // IndexColumn provides index column info.
 type IndexColumn struct {
 	Name   CIStr `json:"name"`   // Index name
@@ -14,7 +14,9 @@
 package model
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/util/types"
@@ -77,4 +79,29 @@ func (*testSuite) TestClone(c *C) {
 
 	n := dbInfo.Clone()
 	c.Assert(n, DeepEquals, dbInfo)
+
+	view := &TableInfo{
+		ID:      2,
+		Name:    NewCIStr("v"),
+		Columns: []*ColumnInfo{column},
+		Indices: []*IndexInfo{},
+		View:    &ViewInfo{Definer: "root@localhost", Select: "select c from t"},
+	}
+	nv := view.Clone()
+	c.Assert(nv, DeepEquals, view)
+	c.Assert(nv.IsView(), IsTrue)
+	c.Assert(table.IsView(), IsFalse)
+	nv.View.Select = "select 1"
+	c.Assert(view.View.Select, Equals, "select c from t")
+}
+
+func (*testSuite) TestTimestampIndexLocation(c *C) {
+	// The tables created by the old versions have no version.
+	var old TableInfo
+	c.Assert(json.Unmarshal([]byte(`{"id":1,"name":{"O":"t","L":"t"}}`), &old), IsNil)
+	c.Assert(old.Version, Equals, TableInfoVersion0)
+	c.Assert(old.TimestampIndexLocation(), Equals, time.Local)
+
+	table := &TableInfo{ID: 2, Name: NewCIStr("t2"), Version: CurrLatestTableInfoVersion}
+	c.Assert(table.TimestampIndexLocation(), Equals, time.UTC)
 }
@@ -883,3 +883,13 @@ const (
 	ErrRowInWrongPartition                                          = 1863
 	ErrErrorLast                                                    = 1863
 )
+
+// MySQL error codes of the JSON data type, they are added in MySQL 5.7.
+const (
+	ErrInvalidJSONText         = 3140
+	ErrInvalidJSONPath         = 3143
+	ErrInvalidJSONPathWildcard = 3149
+	ErrJSONUsedAsKey           = 3152
+	ErrJSONVacuousPath         = 3153
+	ErrJSONDocumentNULLKey     = 3158
+)
@@ -879,4 +879,11 @@ var MySQLErrName = map[uint16]string{
 	ErrAlterOperationNotSupportedReasonNotNull:               "cannot silently convert NULL values, as required in this SQLMODE",
 	ErrMustChangePasswordLogin:                               "Your password has expired. To log in you must change it using a client that supports expired passwords.",
 	ErrRowInWrongPartition:                                   "Found a row in wrong partition %s",
+
+	ErrInvalidJSONText:         "Invalid JSON text: %-.192s",
+	ErrInvalidJSONPath:         "Invalid JSON path expression. The error is around character position %d.",
+	ErrInvalidJSONPathWildcard: "In this situation, path expressions may not contain the * and ** tokens.",
+	ErrJSONUsedAsKey:           "JSON column '%-.192s' cannot be used in key specification.",
+	ErrJSONVacuousPath:         "The path expression '$' is not allowed in this context.",
+	ErrJSONDocumentNULLKey:     "JSON documents may not contain NULL member names.",
 }
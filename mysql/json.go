@@ -0,0 +1,637 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// JSON is for mysql json type.
+// Value is nil for the JSON null literal, and bool, int64, uint64, float64,
+// string, []interface{} or map[string]interface{} for the other JSON values.
+// A JSON value is never modified in place, Set and Remove return a new one.
+type JSON struct {
+	Value interface{}
+}
+
+// ParseJSON parses the JSON text s.
+func ParseJSON(s string) (JSON, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return JSON{}, errors.Trace(NewErr(ErrInvalidJSONText, err.Error()))
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return JSON{}, errors.Trace(NewErr(ErrInvalidJSONText, "The document root must not be followed by other values."))
+	}
+
+	v, err := normalizeJSON(v)
+	if err != nil {
+		return JSON{}, errors.Trace(NewErr(ErrInvalidJSONText, err.Error()))
+	}
+	return JSON{Value: v}, nil
+}
+
+// normalizeJSON changes the json.Number values decoded by encoding/json to
+// int64, uint64 or float64.
+func normalizeJSON(v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case json.Number:
+		if n, err := strconv.ParseInt(string(x), 10, 64); err == nil {
+			return n, nil
+		}
+		if n, err := strconv.ParseUint(string(x), 10, 64); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(string(x), 64)
+		return f, errors.Trace(err)
+	case []interface{}:
+		for i, e := range x {
+			e, err := normalizeJSON(e)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			x[i] = e
+		}
+	case map[string]interface{}:
+		for k, e := range x {
+			e, err := normalizeJSON(e)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			x[k] = e
+		}
+	}
+	return v, nil
+}
+
+// ConvertToJSON converts a SQL value to a JSON scalar, a string becomes
+// a JSON string and is not parsed as JSON text.
+func ConvertToJSON(value interface{}) (JSON, error) {
+	switch v := value.(type) {
+	case nil, bool, int64, uint64, float64, string:
+		return JSON{Value: v}, nil
+	case JSON:
+		return v, nil
+	case int:
+		return JSON{Value: int64(v)}, nil
+	case float32:
+		return JSON{Value: float64(v)}, nil
+	case []byte:
+		return JSON{Value: string(v)}, nil
+	case Decimal:
+		f, _ := v.Float64()
+		return JSON{Value: f}, nil
+	case Time:
+		return JSON{Value: v.String()}, nil
+	case Duration:
+		return JSON{Value: v.String()}, nil
+	case Hex:
+		return JSON{Value: v.ToString()}, nil
+	case Bit:
+		return JSON{Value: v.ToString()}, nil
+	case Enum:
+		return JSON{Value: v.String()}, nil
+	case Set:
+		return JSON{Value: v.String()}, nil
+	default:
+		return JSON{}, errors.Errorf("cannot convert %v(type %T) to JSON", value, value)
+	}
+}
+
+// Type returns the type name of j, as returned by JSON_TYPE.
+func (j JSON) Type() string {
+	switch j.Value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		return "BOOLEAN"
+	case int64:
+		return "INTEGER"
+	case uint64:
+		return "UNSIGNED INTEGER"
+	case float64:
+		return "DOUBLE"
+	case string:
+		return "STRING"
+	case []interface{}:
+		return "ARRAY"
+	default:
+		return "OBJECT"
+	}
+}
+
+// String implements fmt.Stringer interface.
+func (j JSON) String() string {
+	var buf bytes.Buffer
+	writeJSON(&buf, j.Value)
+	return buf.String()
+}
+
+// Unquote returns the string without quotes if j is a JSON string,
+// otherwise it returns the JSON text.
+func (j JSON) Unquote() string {
+	if s, ok := j.Value.(string); ok {
+		return s
+	}
+	return j.String()
+}
+
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(x))
+	case int64:
+		buf.WriteString(strconv.FormatInt(x, 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(x, 10))
+	case float64:
+		buf.WriteString(formatJSONFloat(x))
+	case string:
+		writeJSONString(buf, x)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range x {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			writeJSON(buf, e)
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		buf.WriteByte('{')
+		for i, k := range sortedJSONKeys(x) {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			writeJSONString(buf, k)
+			buf.WriteString(": ")
+			writeJSON(buf, x[k])
+		}
+		buf.WriteByte('}')
+	}
+}
+
+// formatJSONFloat formats the double like MySQL, which always writes a
+// fraction part for the doubles that are integral, e.g, 1.0.
+func formatJSONFloat(f float64) string {
+	abs := math.Abs(f)
+	if abs != 0 && (abs < 1e-5 || abs >= 1e15) {
+		return strconv.FormatFloat(f, 'e', -1, 64)
+	}
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, c)
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// sortedJSONKeys returns the keys of an object in the order MySQL uses,
+// shorter keys come first and keys with the same length are sorted bytewise.
+func sortedJSONKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Sort(jsonKeys(keys))
+	return keys
+}
+
+type jsonKeys []string
+
+func (s jsonKeys) Len() int      { return len(s) }
+func (s jsonKeys) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s jsonKeys) Less(i, j int) bool {
+	if len(s[i]) != len(s[j]) {
+		return len(s[i]) < len(s[j])
+	}
+	return s[i] < s[j]
+}
+
+// The type codes of the JSON binary format.
+const (
+	jsonTypeObject  byte = 0x01
+	jsonTypeArray   byte = 0x03
+	jsonTypeLiteral byte = 0x04
+	jsonTypeInt64   byte = 0x09
+	jsonTypeUint64  byte = 0x0a
+	jsonTypeDouble  byte = 0x0b
+	jsonTypeString  byte = 0x0c
+)
+
+// The values of the JSON literals in the JSON binary format.
+const (
+	jsonLiteralNil   byte = 0x00
+	jsonLiteralTrue  byte = 0x01
+	jsonLiteralFalse byte = 0x02
+)
+
+// Marshal returns the binary encoding of j.
+// Every value begins with its type code, numbers are stored as 8 bytes in
+// little endian, strings, arrays and objects begin with their length as
+// an uvarint and the keys of an object are stored in sorted order.
+func (j JSON) Marshal() ([]byte, error) {
+	b, err := marshalJSON(nil, j.Value)
+	return b, errors.Trace(err)
+}
+
+func marshalJSON(b []byte, v interface{}) ([]byte, error) {
+	switch x := v.(type) {
+	case nil:
+		return append(b, jsonTypeLiteral, jsonLiteralNil), nil
+	case bool:
+		if x {
+			return append(b, jsonTypeLiteral, jsonLiteralTrue), nil
+		}
+		return append(b, jsonTypeLiteral, jsonLiteralFalse), nil
+	case int64:
+		return appendUint64(append(b, jsonTypeInt64), uint64(x)), nil
+	case uint64:
+		return appendUint64(append(b, jsonTypeUint64), x), nil
+	case float64:
+		return appendUint64(append(b, jsonTypeDouble), math.Float64bits(x)), nil
+	case string:
+		return appendJSONString(append(b, jsonTypeString), x), nil
+	case []interface{}:
+		b = appendUvarint(append(b, jsonTypeArray), uint64(len(x)))
+		for _, e := range x {
+			var err error
+			b, err = marshalJSON(b, e)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		return b, nil
+	case map[string]interface{}:
+		b = appendUvarint(append(b, jsonTypeObject), uint64(len(x)))
+		for _, k := range sortedJSONKeys(x) {
+			var err error
+			b, err = marshalJSON(appendJSONString(b, k), x[k])
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		return b, nil
+	default:
+		return nil, errors.Errorf("invalid JSON value %v(type %T)", v, v)
+	}
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var data [8]byte
+	binary.LittleEndian.PutUint64(data[:], v)
+	return append(b, data[:]...)
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var data [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(data[:], v)
+	return append(b, data[:n]...)
+}
+
+func appendJSONString(b []byte, s string) []byte {
+	return append(appendUvarint(b, uint64(len(s))), s...)
+}
+
+// Unmarshal decodes the binary encoding b returned by Marshal.
+func (j *JSON) Unmarshal(b []byte) error {
+	v, rest, err := unmarshalJSON(b)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(rest) != 0 {
+		return errors.Errorf("invalid JSON binary, %d bytes left", len(rest))
+	}
+	j.Value = v
+	return nil
+}
+
+var errInvalidJSONBinary = errors.New("invalid JSON binary")
+
+func unmarshalJSON(b []byte) (interface{}, []byte, error) {
+	if len(b) == 0 {
+		return nil, nil, errors.Trace(errInvalidJSONBinary)
+	}
+	tp, b := b[0], b[1:]
+	switch tp {
+	case jsonTypeLiteral:
+		if len(b) == 0 {
+			return nil, nil, errors.Trace(errInvalidJSONBinary)
+		}
+		switch b[0] {
+		case jsonLiteralNil:
+			return nil, b[1:], nil
+		case jsonLiteralTrue:
+			return true, b[1:], nil
+		case jsonLiteralFalse:
+			return false, b[1:], nil
+		}
+	case jsonTypeInt64, jsonTypeUint64, jsonTypeDouble:
+		if len(b) < 8 {
+			return nil, nil, errors.Trace(errInvalidJSONBinary)
+		}
+		v := binary.LittleEndian.Uint64(b)
+		switch tp {
+		case jsonTypeInt64:
+			return int64(v), b[8:], nil
+		case jsonTypeUint64:
+			return v, b[8:], nil
+		default:
+			return math.Float64frombits(v), b[8:], nil
+		}
+	case jsonTypeString:
+		return unmarshalJSONString(b)
+	case jsonTypeArray:
+		n, b, err := unmarshalUvarint(b)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		a := make([]interface{}, 0, n)
+		for i := uint64(0); i < n; i++ {
+			var e interface{}
+			e, b, err = unmarshalJSON(b)
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			a = append(a, e)
+		}
+		return a, b, nil
+	case jsonTypeObject:
+		n, b, err := unmarshalUvarint(b)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		m := make(map[string]interface{}, n)
+		for i := uint64(0); i < n; i++ {
+			var k, e interface{}
+			k, b, err = unmarshalJSONString(b)
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			e, b, err = unmarshalJSON(b)
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			m[k.(string)] = e
+		}
+		return m, b, nil
+	}
+	return nil, nil, errors.Trace(errInvalidJSONBinary)
+}
+
+func unmarshalUvarint(b []byte) (uint64, []byte, error) {
+	v, n := binary.Uvarint(b)
+	if n <= 0 {
+		return 0, nil, errors.Trace(errInvalidJSONBinary)
+	}
+	return v, b[n:], nil
+}
+
+func unmarshalJSONString(b []byte) (interface{}, []byte, error) {
+	n, b, err := unmarshalUvarint(b)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if uint64(len(b)) < n {
+		return nil, nil, errors.Trace(errInvalidJSONBinary)
+	}
+	return string(b[:n]), b[n:], nil
+}
+
+// Compare returns an integer comparing the JSON j to o.
+// Values of different JSON types are ordered by the type precedence of
+// MySQL, BOOLEAN > ARRAY > OBJECT > STRING > INTEGER, DOUBLE > NULL.
+// Arrays are compared element by element. Objects are equal if they have the
+// same keys and values, the order of the unequal objects is deterministic.
+func (j JSON) Compare(o JSON) int {
+	return compareJSON(j.Value, o.Value)
+}
+
+func jsonPrecedence(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case int64, uint64, float64:
+		return 1
+	case string:
+		return 2
+	case map[string]interface{}:
+		return 3
+	case []interface{}:
+		return 4
+	default:
+		return 5
+	}
+}
+
+func compareJSON(a, b interface{}) int {
+	pa, pb := jsonPrecedence(a), jsonPrecedence(b)
+	if pa != pb {
+		return compareInt(pa, pb)
+	}
+
+	switch x := a.(type) {
+	case nil:
+		return 0
+	case bool:
+		y := b.(bool)
+		if x == y {
+			return 0
+		} else if y {
+			return -1
+		}
+		return 1
+	case string:
+		return strings.Compare(x, b.(string))
+	case []interface{}:
+		y := b.([]interface{})
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if n := compareJSON(x[i], y[i]); n != 0 {
+				return n
+			}
+		}
+		return compareInt(len(x), len(y))
+	case map[string]interface{}:
+		y := b.(map[string]interface{})
+		if len(x) != len(y) {
+			return compareInt(len(x), len(y))
+		}
+		xKeys, yKeys := sortedJSONKeys(x), sortedJSONKeys(y)
+		for i := range xKeys {
+			if xKeys[i] != yKeys[i] {
+				if (jsonKeys{xKeys[i], yKeys[i]}).Less(0, 1) {
+					return -1
+				}
+				return 1
+			}
+			if n := compareJSON(x[xKeys[i]], y[yKeys[i]]); n != 0 {
+				return n
+			}
+		}
+		return 0
+	default:
+		return compareJSONNumber(a, b)
+	}
+}
+
+func compareInt(a, b int) int {
+	if a < b {
+		return -1
+	} else if a == b {
+		return 0
+	}
+	return 1
+}
+
+func compareJSONNumber(a, b interface{}) int {
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			if x < y {
+				return -1
+			} else if x == y {
+				return 0
+			}
+			return 1
+		case uint64:
+			if x < 0 {
+				return -1
+			}
+			return compareJSONNumber(uint64(x), y)
+		}
+	case uint64:
+		switch y := b.(type) {
+		case uint64:
+			if x < y {
+				return -1
+			} else if x == y {
+				return 0
+			}
+			return 1
+		case int64:
+			return -compareJSONNumber(y, x)
+		}
+	}
+
+	x, y := jsonNumberToFloat(a), jsonNumberToFloat(b)
+	if x < y {
+		return -1
+	} else if x == y {
+		return 0
+	}
+	return 1
+}
+
+func jsonNumberToFloat(v interface{}) float64 {
+	switch x := v.(type) {
+	case int64:
+		return float64(x)
+	case uint64:
+		return float64(x)
+	default:
+		return x.(float64)
+	}
+}
+
+// Contains reports whether candidate is contained in j, like JSON_CONTAINS.
+// A scalar is contained in an equal scalar, an array is contained in an array
+// if all its elements are contained in it, and a scalar or an object is
+// contained in an array if it is contained in one of its elements.
+// An object is contained in an object if every key of the candidate is in
+// the target and its value is contained in the value of the target.
+func (j JSON) Contains(candidate JSON) bool {
+	return containsJSON(j.Value, candidate.Value)
+}
+
+func containsJSON(target, candidate interface{}) bool {
+	switch x := target.(type) {
+	case map[string]interface{}:
+		y, ok := candidate.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range y {
+			t, ok := x[k]
+			if !ok || !containsJSON(t, v) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		if y, ok := candidate.([]interface{}); ok {
+			for _, v := range y {
+				if !containsJSON(x, v) {
+					return false
+				}
+			}
+			return true
+		}
+		for _, t := range x {
+			if containsJSON(t, candidate) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch candidate.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+	return compareJSON(target, candidate) == 0
+}
@@ -0,0 +1,247 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testJSONSuite{})
+
+type testJSONSuite struct {
+}
+
+func sqlErrorCode(err error) uint16 {
+	if e, ok := errors.Cause(err).(*SQLError); ok {
+		return e.Code
+	}
+	return 0
+}
+
+func mustParseJSON(c *C, s string) JSON {
+	j, err := ParseJSON(s)
+	c.Assert(err, IsNil, Commentf("%s", s))
+	return j
+}
+
+func mustParseJSONPath(c *C, s string) JSONPath {
+	p, err := ParseJSONPath(s)
+	c.Assert(err, IsNil, Commentf("%s", s))
+	return p
+}
+
+func (s *testJSONSuite) TestParseJSON(c *C) {
+	tbl := []struct {
+		Input  string
+		Expect string
+		Type   string
+	}{
+		{`null`, `null`, "NULL"},
+		{` true `, `true`, "BOOLEAN"},
+		{`-3`, `-3`, "INTEGER"},
+		{`18446744073709551615`, `18446744073709551615`, "UNSIGNED INTEGER"},
+		{`1.0`, `1.0`, "DOUBLE"},
+		{`1.5e2`, `150.0`, "DOUBLE"},
+		{`"a\"b\né"`, `"a\"b\né"`, "STRING"},
+		{`[1, "a", [], {}]`, `[1, "a", [], {}]`, "ARRAY"},
+		{`{"bb": 1, "a": {"c": null}, "ab": true}`, `{"a": {"c": null}, "ab": true, "bb": 1}`, "OBJECT"},
+	}
+
+	for _, t := range tbl {
+		j := mustParseJSON(c, t.Input)
+		c.Assert(j.String(), Equals, t.Expect)
+		c.Assert(j.Type(), Equals, t.Type)
+	}
+
+	for _, str := range []string{``, `{`, `[1,]`, `{"a"}`, `abc`, `1 2`, `'a'`} {
+		_, err := ParseJSON(str)
+		c.Assert(sqlErrorCode(err), Equals, uint16(ErrInvalidJSONText), Commentf("%s", str))
+	}
+}
+
+func (s *testJSONSuite) TestJSONCodec(c *C) {
+	for _, str := range []string{
+		`null`, `false`, `-1`, `18446744073709551615`, `3.25`, `""`, `"abc"`,
+		`[1, [2, 3], {"a": null}]`,
+		`{"a": [true, false], "b": {"c": "d"}, "": -2.5}`,
+	} {
+		j := mustParseJSON(c, str)
+		b, err := j.Marshal()
+		c.Assert(err, IsNil)
+
+		var v JSON
+		err = v.Unmarshal(b)
+		c.Assert(err, IsNil)
+		c.Assert(v.Compare(j), Equals, 0)
+		c.Assert(v.String(), Equals, j.String())
+	}
+
+	var v JSON
+	for _, b := range [][]byte{nil, {jsonTypeInt64, 1}, {jsonTypeString, 3, 'a'}, {jsonTypeLiteral, 0, 0}, {0xff}} {
+		c.Assert(v.Unmarshal(b), NotNil)
+	}
+}
+
+func (s *testJSONSuite) TestParseJSONPath(c *C) {
+	for _, str := range []string{
+		`$`, ` $ `, `$.a`, `$."a b"`, `$.a[0]`, `$[ 1 ].b`, `$.*`, `$[*]`, `$**.a`, `$.a.b$_1`,
+	} {
+		mustParseJSONPath(c, str)
+	}
+
+	for _, str := range []string{``, `a`, `$.`, `$.1a`, `$[a]`, `$[1`, `$**`, `$*`, `$."a`, `$a`} {
+		_, err := ParseJSONPath(str)
+		c.Assert(sqlErrorCode(err), Equals, uint16(ErrInvalidJSONPath), Commentf("%s", str))
+	}
+
+	c.Assert(mustParseJSONPath(c, `$.a[1]`).ContainsWildcard(), IsFalse)
+	c.Assert(mustParseJSONPath(c, `$.a[*]`).ContainsWildcard(), IsTrue)
+	c.Assert(mustParseJSONPath(c, `$**.a`).ContainsWildcard(), IsTrue)
+	c.Assert(mustParseJSONPath(c, ` $ `).IsDocument(), IsTrue)
+}
+
+func (s *testJSONSuite) TestJSONExtract(c *C) {
+	j := mustParseJSON(c, `{"a": [1, {"b": 2}], "c": {"d": "e"}, "f g": 3}`)
+	tbl := []struct {
+		Paths  []string
+		Expect string
+	}{
+		{[]string{`$`}, j.String()},
+		{[]string{`$.a`}, `[1, {"b": 2}]`},
+		{[]string{`$.a[1].b`}, `2`},
+		{[]string{`$."f g"`}, `3`},
+		{[]string{`$.c.d[0]`}, `"e"`},
+		{[]string{`$.a[2]`}, ``},
+		{[]string{`$.x`}, ``},
+		{[]string{`$.a[0]`, `$.c.d`}, `[1, "e"]`},
+		{[]string{`$.a[0]`, `$.x`}, `[1]`},
+		{[]string{`$.a[*]`}, `[1, {"b": 2}]`},
+		{[]string{`$.c.*`}, `["e"]`},
+		{[]string{`$**.b`}, `[2]`},
+		{[]string{`$.a[0]`}, `1`},
+	}
+
+	for _, t := range tbl {
+		var paths []JSONPath
+		for _, p := range t.Paths {
+			paths = append(paths, mustParseJSONPath(c, p))
+		}
+		v, found := j.Extract(paths)
+		if t.Expect == "" {
+			c.Assert(found, IsFalse, Commentf("%v", t.Paths))
+			continue
+		}
+		c.Assert(found, IsTrue, Commentf("%v", t.Paths))
+		c.Assert(v.String(), Equals, t.Expect)
+	}
+}
+
+func (s *testJSONSuite) TestJSONSetRemove(c *C) {
+	j := mustParseJSON(c, `{"a": [1, 2], "b": {"c": 3}}`)
+	tblSet := []struct {
+		Path   string
+		Value  interface{}
+		Expect string
+	}{
+		{`$.a[0]`, int64(10), `{"a": [10, 2], "b": {"c": 3}}`},
+		{`$.a[5]`, int64(10), `{"a": [1, 2, 10], "b": {"c": 3}}`},
+		{`$.b.d`, "x", `{"a": [1, 2], "b": {"c": 3, "d": "x"}}`},
+		{`$.b.c[1]`, nil, `{"a": [1, 2], "b": {"c": [3, null]}}`},
+		{`$.b.c[0]`, 1.5, `{"a": [1, 2], "b": {"c": 1.5}}`},
+		{`$.x.y`, int64(1), `{"a": [1, 2], "b": {"c": 3}}`},
+		{`$.a.b`, int64(1), `{"a": [1, 2], "b": {"c": 3}}`},
+		{`$`, int64(1), `1`},
+	}
+
+	for _, t := range tblSet {
+		v, err := ConvertToJSON(t.Value)
+		c.Assert(err, IsNil)
+		c.Assert(j.Set(mustParseJSONPath(c, t.Path), v).String(), Equals, t.Expect, Commentf("%s", t.Path))
+	}
+
+	tblRemove := []struct {
+		Path   string
+		Expect string
+	}{
+		{`$.a[0]`, `{"a": [2], "b": {"c": 3}}`},
+		{`$.a[2]`, `{"a": [1, 2], "b": {"c": 3}}`},
+		{`$.b.c`, `{"a": [1, 2], "b": {}}`},
+		{`$.b`, `{"a": [1, 2]}`},
+		{`$.x.y`, `{"a": [1, 2], "b": {"c": 3}}`},
+	}
+
+	for _, t := range tblRemove {
+		c.Assert(j.Remove(mustParseJSONPath(c, t.Path)).String(), Equals, t.Expect, Commentf("%s", t.Path))
+	}
+
+	// The original document is not changed.
+	c.Assert(j.String(), Equals, `{"a": [1, 2], "b": {"c": 3}}`)
+}
+
+func (s *testJSONSuite) TestJSONContains(c *C) {
+	tbl := []struct {
+		Target    string
+		Candidate string
+		Expect    bool
+	}{
+		{`1`, `1`, true},
+		{`1`, `1.0`, true},
+		{`1`, `"1"`, false},
+		{`1`, `[1]`, false},
+		{`[1, 2, [3, 4]]`, `2`, true},
+		{`[1, 2, [3, 4]]`, `[1, 3]`, true},
+		{`[1, 2, [3, 4]]`, `[5]`, false},
+		{`[1, 2, [3, 4]]`, `[[4]]`, true},
+		{`{"a": 1, "b": {"c": [1, 2]}}`, `{"b": {"c": [2]}}`, true},
+		{`{"a": 1, "b": {"c": [1, 2]}}`, `{"a": 2}`, false},
+		{`{"a": 1}`, `1`, false},
+		{`[{"a": 1}, {"b": 2}]`, `{"b": 2}`, true},
+	}
+
+	for _, t := range tbl {
+		target, candidate := mustParseJSON(c, t.Target), mustParseJSON(c, t.Candidate)
+		c.Assert(target.Contains(candidate), Equals, t.Expect, Commentf("%s %s", t.Target, t.Candidate))
+	}
+}
+
+func (s *testJSONSuite) TestJSONCompare(c *C) {
+	tbl := []struct {
+		Left   string
+		Right  string
+		Expect int
+	}{
+		{`null`, `1`, -1},
+		{`1`, `1.0`, 0},
+		{`-1`, `18446744073709551615`, -1},
+		{`2.5`, `2`, 1},
+		{`"abc"`, `"abd"`, -1},
+		{`"1"`, `1`, 1},
+		{`{}`, `"a"`, 1},
+		{`[]`, `{}`, 1},
+		{`true`, `[1]`, 1},
+		{`false`, `true`, -1},
+		{`[1, 2]`, `[1, 2, 3]`, -1},
+		{`[1, 3]`, `[1, 2, 3]`, 1},
+		{`{"a": 1, "b": 2}`, `{"b": 2, "a": 1}`, 0},
+		{`{"a": 1}`, `{"a": 2}`, -1},
+		{`{"a": 1}`, `{"b": 1}`, -1},
+	}
+
+	for _, t := range tbl {
+		left, right := mustParseJSON(c, t.Left), mustParseJSON(c, t.Right)
+		c.Assert(left.Compare(right), Equals, t.Expect, Commentf("%s %s", t.Left, t.Right))
+		c.Assert(right.Compare(left), Equals, -t.Expect, Commentf("%s %s", t.Right, t.Left))
+	}
+}
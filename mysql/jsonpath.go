@@ -0,0 +1,409 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"encoding/json"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/juju/errors"
+)
+
+type jsonPathLegType byte
+
+const (
+	// jsonPathLegKey is a member of an object, like .a or ."a b".
+	jsonPathLegKey jsonPathLegType = iota
+	// jsonPathLegIndex is an element of an array, like [1].
+	jsonPathLegIndex
+	// jsonPathLegKeyWildcard is all the members of an object, .*.
+	jsonPathLegKeyWildcard
+	// jsonPathLegIndexWildcard is all the elements of an array, [*].
+	jsonPathLegIndexWildcard
+	// jsonPathLegDoubleWildcard is all the paths beginning with the prefix
+	// and ending with the suffix of the leg, like $**.a.
+	jsonPathLegDoubleWildcard
+)
+
+type jsonPathLeg struct {
+	tp    jsonPathLegType
+	key   string
+	index int
+}
+
+// JSONPath is a JSON path expression like '$.a[1]', which begins with $
+// for the document and is followed by the path legs.
+// See https://dev.mysql.com/doc/refman/5.7/en/json-path-syntax.html
+type JSONPath struct {
+	legs []jsonPathLeg
+}
+
+// ParseJSONPath parses the JSON path expression str.
+func ParseJSONPath(str string) (JSONPath, error) {
+	p := &jsonPathParser{str: str}
+	path, err := p.parse()
+	if err != nil {
+		return JSONPath{}, errors.Trace(NewErr(ErrInvalidJSONPath, p.pos))
+	}
+	return path, nil
+}
+
+// ContainsWildcard returns whether the path contains the * or ** tokens.
+func (p JSONPath) ContainsWildcard() bool {
+	for _, leg := range p.legs {
+		if leg.tp != jsonPathLegKey && leg.tp != jsonPathLegIndex {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDocument returns whether the path is $, the whole document.
+func (p JSONPath) IsDocument() bool {
+	return len(p.legs) == 0
+}
+
+var errInvalidJSONPath = errors.New("invalid JSON path")
+
+type jsonPathParser struct {
+	str string
+	pos int
+}
+
+func (p *jsonPathParser) skipSpaces() {
+	for p.pos < len(p.str) && unicode.IsSpace(rune(p.str[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *jsonPathParser) parse() (JSONPath, error) {
+	var path JSONPath
+	p.skipSpaces()
+	if p.pos >= len(p.str) || p.str[p.pos] != '$' {
+		return path, errInvalidJSONPath
+	}
+	p.pos++
+
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.str) {
+			break
+		}
+
+		var (
+			leg jsonPathLeg
+			err error
+		)
+		switch p.str[p.pos] {
+		case '.':
+			p.pos++
+			leg, err = p.parseMember()
+		case '[':
+			p.pos++
+			leg, err = p.parseArrayCell()
+		case '*':
+			p.pos++
+			if p.pos >= len(p.str) || p.str[p.pos] != '*' {
+				return path, errInvalidJSONPath
+			}
+			p.pos++
+			leg = jsonPathLeg{tp: jsonPathLegDoubleWildcard}
+		default:
+			err = errInvalidJSONPath
+		}
+		if err != nil {
+			return path, err
+		}
+		path.legs = append(path.legs, leg)
+	}
+
+	// ** must be followed by another leg.
+	if n := len(path.legs); n > 0 && path.legs[n-1].tp == jsonPathLegDoubleWildcard {
+		return path, errInvalidJSONPath
+	}
+	return path, nil
+}
+
+// parseMember parses the leg after the '.', which is *, an identifier or a
+// double quoted string.
+func (p *jsonPathParser) parseMember() (jsonPathLeg, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.str) {
+		return jsonPathLeg{}, errInvalidJSONPath
+	}
+
+	switch p.str[p.pos] {
+	case '*':
+		p.pos++
+		return jsonPathLeg{tp: jsonPathLegKeyWildcard}, nil
+	case '"':
+		end := p.pos + 1
+		for ; end < len(p.str) && p.str[end] != '"'; end++ {
+			if p.str[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(p.str) {
+			return jsonPathLeg{}, errInvalidJSONPath
+		}
+		var key string
+		if err := json.Unmarshal([]byte(p.str[p.pos:end+1]), &key); err != nil {
+			return jsonPathLeg{}, errInvalidJSONPath
+		}
+		p.pos = end + 1
+		return jsonPathLeg{tp: jsonPathLegKey, key: key}, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.str) {
+		r, size := utf8.DecodeRuneInString(p.str[p.pos:])
+		if !isJSONPathIdentRune(r) {
+			break
+		}
+		p.pos += size
+	}
+	key := p.str[start:p.pos]
+	if len(key) == 0 || unicode.IsDigit(rune(key[0])) {
+		return jsonPathLeg{}, errInvalidJSONPath
+	}
+	return jsonPathLeg{tp: jsonPathLegKey, key: key}, nil
+}
+
+// isJSONPathIdentRune returns whether r can be in an unquoted key name,
+// which is an ECMAScript identifier.
+func isJSONPathIdentRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// parseArrayCell parses the leg after the '[', which is * or an index.
+func (p *jsonPathParser) parseArrayCell() (jsonPathLeg, error) {
+	p.skipSpaces()
+	var leg jsonPathLeg
+	if p.pos < len(p.str) && p.str[p.pos] == '*' {
+		p.pos++
+		leg.tp = jsonPathLegIndexWildcard
+	} else {
+		start := p.pos
+		for p.pos < len(p.str) && p.str[p.pos] >= '0' && p.str[p.pos] <= '9' {
+			p.pos++
+		}
+		index, err := strconv.Atoi(p.str[start:p.pos])
+		if err != nil {
+			return leg, errInvalidJSONPath
+		}
+		leg.tp = jsonPathLegIndex
+		leg.index = index
+	}
+
+	p.skipSpaces()
+	if p.pos >= len(p.str) || p.str[p.pos] != ']' {
+		return leg, errInvalidJSONPath
+	}
+	p.pos++
+	return leg, nil
+}
+
+// Extract returns the values in j matched by the paths. If there is only one
+// path without wildcards, the matched value is returned, otherwise all the
+// matched values are returned in an array. found is false if no value matches.
+func (j JSON) Extract(paths []JSONPath) (ret JSON, found bool) {
+	var values []interface{}
+	wrap := len(paths) > 1
+	for _, path := range paths {
+		values = extractJSON(j.Value, path.legs, values)
+		wrap = wrap || path.ContainsWildcard()
+	}
+
+	if len(values) == 0 {
+		return JSON{}, false
+	}
+	if !wrap {
+		return JSON{Value: values[0]}, true
+	}
+	return JSON{Value: values}, true
+}
+
+func extractJSON(v interface{}, legs []jsonPathLeg, values []interface{}) []interface{} {
+	if len(legs) == 0 {
+		return append(values, v)
+	}
+
+	leg, rest := legs[0], legs[1:]
+	switch leg.tp {
+	case jsonPathLegKey:
+		if m, ok := v.(map[string]interface{}); ok {
+			if e, ok := m[leg.key]; ok {
+				values = extractJSON(e, rest, values)
+			}
+		}
+	case jsonPathLegIndex:
+		if a, ok := v.([]interface{}); ok {
+			if leg.index < len(a) {
+				values = extractJSON(a[leg.index], rest, values)
+			}
+		} else if leg.index == 0 {
+			// A value which is not an array is treated as an array with
+			// the value as the only element.
+			values = extractJSON(v, rest, values)
+		}
+	case jsonPathLegKeyWildcard:
+		if m, ok := v.(map[string]interface{}); ok {
+			for _, k := range sortedJSONKeys(m) {
+				values = extractJSON(m[k], rest, values)
+			}
+		}
+	case jsonPathLegIndexWildcard:
+		if a, ok := v.([]interface{}); ok {
+			for _, e := range a {
+				values = extractJSON(e, rest, values)
+			}
+		}
+	case jsonPathLegDoubleWildcard:
+		values = extractJSON(v, rest, values)
+		switch x := v.(type) {
+		case []interface{}:
+			for _, e := range x {
+				values = extractJSON(e, legs, values)
+			}
+		case map[string]interface{}:
+			for _, k := range sortedJSONKeys(x) {
+				values = extractJSON(x[k], legs, values)
+			}
+		}
+	}
+	return values
+}
+
+// Set returns j with the value at path replaced by v, like JSON_SET.
+// If the path does not exist but its parent does, v is added to the parent
+// object, or appended to the parent array. The path must not contain wildcards.
+func (j JSON) Set(path JSONPath, v JSON) JSON {
+	return JSON{Value: setJSON(j.Value, path.legs, v.Value)}
+}
+
+func setJSON(doc interface{}, legs []jsonPathLeg, v interface{}) interface{} {
+	if len(legs) == 0 {
+		return v
+	}
+
+	leg, rest := legs[0], legs[1:]
+	switch leg.tp {
+	case jsonPathLegKey:
+		m, ok := doc.(map[string]interface{})
+		if !ok {
+			return doc
+		}
+		e, ok := m[leg.key]
+		if !ok && len(rest) > 0 {
+			return doc
+		}
+		m = copyJSONObject(m)
+		if ok {
+			m[leg.key] = setJSON(e, rest, v)
+		} else {
+			m[leg.key] = v
+		}
+		return m
+	case jsonPathLegIndex:
+		a, ok := doc.([]interface{})
+		if !ok {
+			if leg.index == 0 {
+				return setJSON(doc, rest, v)
+			}
+			if len(rest) > 0 {
+				return doc
+			}
+			// The value which is not an array is wrapped in an array first.
+			return []interface{}{doc, v}
+		}
+		if leg.index < len(a) {
+			a = copyJSONArray(a)
+			a[leg.index] = setJSON(a[leg.index], rest, v)
+			return a
+		}
+		if len(rest) > 0 {
+			return doc
+		}
+		return append(copyJSONArray(a), v)
+	}
+	return doc
+}
+
+// Remove returns j with the value at path removed, like JSON_REMOVE.
+// The path must not contain wildcards and must not be $.
+func (j JSON) Remove(path JSONPath) JSON {
+	return JSON{Value: removeJSON(j.Value, path.legs)}
+}
+
+func removeJSON(doc interface{}, legs []jsonPathLeg) interface{} {
+	if len(legs) == 0 {
+		return doc
+	}
+
+	leg, rest := legs[0], legs[1:]
+	switch leg.tp {
+	case jsonPathLegKey:
+		m, ok := doc.(map[string]interface{})
+		if !ok {
+			return doc
+		}
+		e, ok := m[leg.key]
+		if !ok {
+			return doc
+		}
+		m = copyJSONObject(m)
+		if len(rest) == 0 {
+			delete(m, leg.key)
+		} else {
+			m[leg.key] = removeJSON(e, rest)
+		}
+		return m
+	case jsonPathLegIndex:
+		a, ok := doc.([]interface{})
+		if !ok {
+			if leg.index == 0 && len(rest) > 0 {
+				return removeJSON(doc, rest)
+			}
+			return doc
+		}
+		if leg.index >= len(a) {
+			return doc
+		}
+		if len(rest) == 0 {
+			na := make([]interface{}, 0, len(a)-1)
+			na = append(na, a[:leg.index]...)
+			return append(na, a[leg.index+1:]...)
+		}
+		a = copyJSONArray(a)
+		a[leg.index] = removeJSON(a[leg.index], rest)
+		return a
+	}
+	return doc
+}
+
+func copyJSONObject(m map[string]interface{}) map[string]interface{} {
+	nm := make(map[string]interface{}, len(m)+1)
+	for k, v := range m {
+		nm[k] = v
+	}
+	return nm
+}
+
+func copyJSONArray(a []interface{}) []interface{} {
+	na := make([]interface{}, len(a), len(a)+1)
+	copy(na, a)
+	return na
+}
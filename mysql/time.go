@@ -87,9 +87,9 @@ var (
 
 var (
 	// MinDatetime is the minimum for mysql datetime type.
-	MinDatetime = time.Date(1000, 1, 1, 0, 0, 0, 0, time.Local)
+	MinDatetime = time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC)
 	// MaxDatetime is the maximum for mysql datetime type.
-	MaxDatetime = time.Date(9999, 12, 31, 23, 59, 59, 999999, time.Local)
+	MaxDatetime = time.Date(9999, 12, 31, 23, 59, 59, 999999, time.UTC)
 
 	// MinTimestamp is the minimum for mysql timestamp type.
 	MinTimestamp = time.Date(1970, 1, 1, 0, 0, 1, 0, time.UTC)
@@ -98,6 +98,8 @@ var (
 )
 
 // Time is the struct for handling datetime, timestamp and date.
+// The wall clock of Time is always held in UTC, a timestamp is the wall clock
+// in the time zone of the session, see ConvertTimeZone.
 // TODO: check if need a NewTime function to set Fsp default value?
 type Time struct {
 	time.Time
@@ -107,9 +109,9 @@ type Time struct {
 	Fsp int
 }
 
-// CurrentTime returns current time with type tp.
+// CurrentTime returns current time of the server time zone with type tp.
 func CurrentTime(tp uint8) Time {
-	return Time{Time: time.Now(), Type: tp, Fsp: 0}
+	return Time{Time: WallClock(time.Now(), time.Local), Type: tp, Fsp: 0}
 }
 
 func (t Time) String() string {
@@ -146,17 +148,17 @@ func (t Time) Marshal() ([]byte, error) {
 	)
 
 	switch t.Type {
-	case TypeDatetime, TypeDate:
+	case TypeDatetime, TypeDate, TypeTimestamp:
 		// We must use t's Zone not current Now Zone,
 		// For EDT/EST, even we create the time with time.Local location,
 		// we may still have a different zone with current Now time.
 		_, offset := t.Zone()
-		// For datetime and date type, we have a trick to marshal.
+		// We always marshal the wall clock.
 		// e.g, if local time is 2010-10-10T10:10:10 UTC+8
 		// we will change this to 2010-10-10T10:10:10 UTC and then marshal.
+		// A timestamp is converted to the wall clock in UTC before it is stored,
+		// see ConvertTimeZone.
 		b, err = t.Time.Add(time.Duration(offset) * time.Second).UTC().MarshalBinary()
-	case TypeTimestamp:
-		b, err = t.Time.UTC().MarshalBinary()
 	default:
 		err = errors.Errorf("invalid time type %d", t.Type)
 	}
@@ -168,9 +170,9 @@ func (t Time) Marshal() ([]byte, error) {
 	return b, nil
 }
 
-// Unmarshal decodes the binary data into Time with current local time.
+// Unmarshal decodes the binary data into Time.
 func (t *Time) Unmarshal(b []byte) error {
-	return t.UnmarshalInLocation(b, time.Local)
+	return t.UnmarshalInLocation(b, time.UTC)
 }
 
 // UnmarshalInLocation decodes the binary data
@@ -242,7 +244,7 @@ func (t Time) Convert(tp uint8) (Time, error) {
 		return nt, nil
 	case TypeDate:
 		year, month, day := t.Time.Date()
-		return Time{Time: time.Date(year, month, day, 0, 0, 0, 0, time.Local),
+		return Time{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC),
 			Type: TypeDate, Fsp: 0}, nil
 	default:
 		return Time{Time: ZeroTime, Type: tp}, errors.Errorf("invalid time type %d", tp)
@@ -493,7 +495,7 @@ func newTime(year int, month int, day int, hour int, minute int, second int, fra
 		return ZeroTime, errors.Trace(err)
 	}
 
-	return time.Date(year, time.Month(month), day, hour, minute, second, frac*1000, time.Local), nil
+	return time.Date(year, time.Month(month), day, hour, minute, second, frac*1000, time.UTC), nil
 }
 
 // See https://dev.mysql.com/doc/refman/5.7/en/two-digit-years.html
@@ -578,9 +580,9 @@ func (d Duration) ToNumber() Decimal {
 // ConvertToTime converts duration to Time.
 // Tp is TypeDatetime, TypeTimestamp and TypeDate.
 func (d Duration) ConvertToTime(tp uint8) (Time, error) {
-	year, month, day := time.Now().Date()
+	year, month, day := CurrentTime(TypeDate).Date()
 	// just use current year, month and day.
-	n := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	n := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 	n = n.Add(d.Duration)
 
 	t := Time{
@@ -1375,3 +1377,489 @@ func ExtractTimeValue(unit string, format string) (int64, int64, int64, time.Dur
 		return 0, 0, 0, 0, errors.Errorf("invalid singel timeunit - %s", unit)
 	}
 }
+
+// MonthNames is the English names of the months.
+var MonthNames = []string{
+	"January", "February", "March",
+	"April", "May", "June",
+	"July", "August", "September",
+	"October", "November", "December",
+}
+
+// The week behaviour flags of calcWeek.
+const (
+	// weekMondayFirst means the week starts on Monday, otherwise on Sunday.
+	weekMondayFirst = 1 << iota
+	// weekYear means the week number is 1..53 and the week belongs to the
+	// previous or next year, otherwise the week number is 0..53.
+	weekYear
+	// weekFirstWeekday means the week 1 is the first week with the first day
+	// of the week in the year, otherwise the first week with 4 or more days.
+	weekFirstWeekday
+)
+
+func daysInYear(year int) int {
+	if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+		return 366
+	}
+	return 365
+}
+
+// calcWeek returns the year and the week number of t with the week behaviour,
+// it is ported from calc_week of MySQL.
+func calcWeek(t time.Time, behaviour int) (int, int) {
+	mondayFirst := behaviour&weekMondayFirst != 0
+	weekYear := behaviour&weekYear != 0
+	firstWeekday := behaviour&weekFirstWeekday != 0
+
+	year := t.Year()
+	// weekday is the weekday of the first day of year, 0 is the first day of a week.
+	weekday := int(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC).Weekday())
+	if mondayFirst {
+		weekday = (weekday + 6) % 7
+	}
+	// days is the day offset from the first day of year.
+	days := t.YearDay() - 1
+
+	if t.Month() == 1 && t.Day() <= 7-weekday {
+		if !weekYear && ((firstWeekday && weekday != 0) || (!firstWeekday && weekday >= 4)) {
+			return year, 0
+		}
+		weekYear = true
+		year--
+		n := daysInYear(year)
+		days += n
+		weekday = (weekday + 53*7 - n) % 7
+	}
+
+	if (firstWeekday && weekday != 0) || (!firstWeekday && weekday >= 4) {
+		days -= 7 - weekday
+	} else {
+		days += weekday
+	}
+	if weekYear && days >= 52*7 {
+		weekday = (weekday + daysInYear(year)) % 7
+		if (!firstWeekday && weekday < 4) || (firstWeekday && weekday == 0) {
+			return year + 1, 1
+		}
+	}
+	return year, days/7 + 1
+}
+
+// DateFormat returns t formatted with the DATE_FORMAT specifiers in layout.
+// ErrInvalidTimeFormat is returned if a name or week specifier is used for
+// zero time.
+// See: https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_date-format
+func (t Time) DateFormat(layout string) (string, error) {
+	var buf bytes.Buffer
+	inPattern := false
+	for _, c := range layout {
+		if !inPattern {
+			if c == '%' {
+				inPattern = true
+			} else {
+				buf.WriteRune(c)
+			}
+			continue
+		}
+		inPattern = false
+		if err := t.formatSpecifier(c, &buf); err != nil {
+			return "", errors.Trace(err)
+		}
+	}
+	return buf.String(), nil
+}
+
+func (t Time) formatSpecifier(c rune, buf *bytes.Buffer) error {
+	var year, month, day, hour, minute, second, micro int
+	if !t.IsZero() {
+		var m time.Month
+		year, m, day = t.Date()
+		month = int(m)
+		hour, minute, second = t.Clock()
+		micro = t.Nanosecond() / 1000
+	}
+	switch c {
+	case 'a', 'b', 'D', 'j', 'M', 'U', 'u', 'V', 'v', 'W', 'w', 'X', 'x':
+		if t.IsZero() {
+			return errors.Trace(ErrInvalidTimeFormat)
+		}
+	}
+
+	switch c {
+	case 'a':
+		buf.WriteString(t.Weekday().String()[:3])
+	case 'b':
+		buf.WriteString(MonthNames[month-1][:3])
+	case 'c':
+		fmt.Fprintf(buf, "%d", month)
+	case 'D':
+		fmt.Fprintf(buf, "%d%s", day, ordinalSuffix(day))
+	case 'd':
+		fmt.Fprintf(buf, "%02d", day)
+	case 'e':
+		fmt.Fprintf(buf, "%d", day)
+	case 'f':
+		fmt.Fprintf(buf, "%06d", micro)
+	case 'H':
+		fmt.Fprintf(buf, "%02d", hour)
+	case 'h', 'I':
+		fmt.Fprintf(buf, "%02d", hour12(hour))
+	case 'i':
+		fmt.Fprintf(buf, "%02d", minute)
+	case 'j':
+		fmt.Fprintf(buf, "%03d", t.YearDay())
+	case 'k':
+		fmt.Fprintf(buf, "%d", hour)
+	case 'l':
+		fmt.Fprintf(buf, "%d", hour12(hour))
+	case 'M':
+		buf.WriteString(MonthNames[month-1])
+	case 'm':
+		fmt.Fprintf(buf, "%02d", month)
+	case 'p':
+		buf.WriteString(meridiem(hour))
+	case 'r':
+		fmt.Fprintf(buf, "%02d:%02d:%02d %s", hour12(hour), minute, second, meridiem(hour))
+	case 'S', 's':
+		fmt.Fprintf(buf, "%02d", second)
+	case 'T':
+		fmt.Fprintf(buf, "%02d:%02d:%02d", hour, minute, second)
+	case 'U':
+		_, week := calcWeek(t.Time, weekFirstWeekday)
+		fmt.Fprintf(buf, "%02d", week)
+	case 'u':
+		_, week := calcWeek(t.Time, weekMondayFirst)
+		fmt.Fprintf(buf, "%02d", week)
+	case 'V':
+		_, week := calcWeek(t.Time, weekYear|weekFirstWeekday)
+		fmt.Fprintf(buf, "%02d", week)
+	case 'v':
+		_, week := calcWeek(t.Time, weekYear|weekMondayFirst)
+		fmt.Fprintf(buf, "%02d", week)
+	case 'W':
+		buf.WriteString(t.Weekday().String())
+	case 'w':
+		fmt.Fprintf(buf, "%d", t.Weekday())
+	case 'X':
+		year, _ := calcWeek(t.Time, weekYear|weekFirstWeekday)
+		fmt.Fprintf(buf, "%04d", year)
+	case 'x':
+		year, _ := calcWeek(t.Time, weekYear|weekMondayFirst)
+		fmt.Fprintf(buf, "%04d", year)
+	case 'Y':
+		fmt.Fprintf(buf, "%04d", year)
+	case 'y':
+		fmt.Fprintf(buf, "%02d", year%100)
+	default:
+		// %% and the unknown specifiers output the character itself.
+		buf.WriteRune(c)
+	}
+	return nil
+}
+
+func ordinalSuffix(day int) string {
+	if day/10 == 1 {
+		return "th"
+	}
+	switch day % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	default:
+		return "th"
+	}
+}
+
+func hour12(hour int) int {
+	if hour%12 == 0 {
+		return 12
+	}
+	return hour % 12
+}
+
+func meridiem(hour int) string {
+	if hour%24 < 12 {
+		return "AM"
+	}
+	return "PM"
+}
+
+// TimeFormat returns d formatted with the time specifiers of DATE_FORMAT in
+// layout, ErrInvalidTimeFormat is returned for a date specifier.
+// See: https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_time-format
+func (d Duration) TimeFormat(layout string) (string, error) {
+	sign, hour, minute, second, micro := splitDuration(d.Duration)
+	var buf bytes.Buffer
+	if sign < 0 {
+		buf.WriteByte('-')
+	}
+	inPattern := false
+	for _, c := range layout {
+		if !inPattern {
+			if c == '%' {
+				inPattern = true
+			} else {
+				buf.WriteRune(c)
+			}
+			continue
+		}
+		inPattern = false
+		switch c {
+		case 'f':
+			fmt.Fprintf(&buf, "%06d", micro)
+		case 'H':
+			fmt.Fprintf(&buf, "%02d", hour)
+		case 'h', 'I':
+			fmt.Fprintf(&buf, "%02d", hour12(hour))
+		case 'i':
+			fmt.Fprintf(&buf, "%02d", minute)
+		case 'k':
+			fmt.Fprintf(&buf, "%d", hour)
+		case 'l':
+			fmt.Fprintf(&buf, "%d", hour12(hour))
+		case 'p':
+			buf.WriteString(meridiem(hour))
+		case 'r':
+			fmt.Fprintf(&buf, "%02d:%02d:%02d %s", hour12(hour), minute, second, meridiem(hour))
+		case 'S', 's':
+			fmt.Fprintf(&buf, "%02d", second)
+		case 'T':
+			fmt.Fprintf(&buf, "%02d:%02d:%02d", hour, minute, second)
+		case 'a', 'b', 'c', 'D', 'd', 'e', 'j', 'M', 'm', 'U', 'u', 'V', 'v', 'W', 'w', 'X', 'x', 'Y', 'y':
+			return "", errors.Trace(ErrInvalidTimeFormat)
+		default:
+			buf.WriteRune(c)
+		}
+	}
+	return buf.String(), nil
+}
+
+// timeParts is the parts of a time parsed by StrToDate.
+type timeParts struct {
+	year, month, day, yearDay  int
+	hour, minute, second, frac int
+	// hour12 is true if the hour is in 12-hour format, pm is true for PM.
+	hour12, pm bool
+	// hasTime is true if there is a time specifier, hasFrac is true for %f.
+	hasTime, hasFrac bool
+}
+
+// StrToDate parses str with the DATE_FORMAT specifiers in format, it is the
+// inverse of DateFormat. The result is a DATE if there is no time specifier
+// in format, a DATETIME otherwise. As the NO_ZERO_DATE and NO_ZERO_IN_DATE
+// SQL modes, ErrInvalidTimeFormat is returned if the month or day is missing.
+// See: https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_str-to-date
+func StrToDate(str string, format string) (Time, error) {
+	var p timeParts
+	if err := p.parse(str, format); err != nil {
+		return ZeroDatetime, errors.Trace(err)
+	}
+
+	if p.yearDay > 0 && p.month == 0 && p.day == 0 {
+		if p.yearDay > daysInYear(p.year) {
+			return ZeroDatetime, errors.Trace(ErrInvalidTimeFormat)
+		}
+		t := time.Date(p.year, 1, p.yearDay, 0, 0, 0, 0, time.UTC)
+		p.month, p.day = int(t.Month()), t.Day()
+	}
+	if p.hour12 {
+		if p.hour < 1 || p.hour > 12 {
+			return ZeroDatetime, errors.Trace(ErrInvalidTimeFormat)
+		}
+		p.hour %= 12
+		if p.pm {
+			p.hour += 12
+		}
+	}
+	if err := checkTime(p.year, p.month, p.day, p.hour, p.minute, p.second, p.frac); err != nil {
+		return ZeroDatetime, errors.Trace(err)
+	}
+	if p.day > time.Date(p.year, time.Month(p.month)+1, 0, 0, 0, 0, 0, time.UTC).Day() {
+		return ZeroDatetime, errors.Trace(ErrInvalidTimeFormat)
+	}
+
+	t := Time{
+		Time: time.Date(p.year, time.Month(p.month), p.day, p.hour, p.minute, p.second, p.frac*1000, time.UTC),
+		Type: TypeDate,
+	}
+	if p.hasTime {
+		t.Type = TypeDatetime
+		if p.hasFrac {
+			t.Fsp = MaxFsp
+		}
+	}
+	return t, nil
+}
+
+// parse parses str with format, the spaces in str are skipped before each
+// specifier or literal, and the rest of format is ignored after str is used up.
+func (p *timeParts) parse(str string, format string) error {
+	for i := 0; i < len(format); i++ {
+		str = strings.TrimLeftFunc(str, unicode.IsSpace)
+		if str == "" {
+			return nil
+		}
+		c := format[i]
+		if c != '%' || i == len(format)-1 {
+			if unicode.IsSpace(rune(c)) {
+				continue
+			}
+			if str[0] != c {
+				return errors.Trace(ErrInvalidTimeFormat)
+			}
+			str = str[1:]
+			continue
+		}
+		i++
+		var err error
+		if str, err = p.parseSpecifier(format[i], str); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (p *timeParts) parseSpecifier(c byte, str string) (string, error) {
+	var err error
+	switch c {
+	case 'Y':
+		return parseDigits(str, 4, &p.year)
+	case 'y':
+		str, err = parseDigits(str, 2, &p.year)
+		p.year = adjustYear(p.year)
+		return str, errors.Trace(err)
+	case 'm', 'c':
+		return parseDigits(str, 2, &p.month)
+	case 'd', 'e':
+		return parseDigits(str, 2, &p.day)
+	case 'D':
+		if str, err = parseDigits(str, 2, &p.day); err != nil {
+			return str, errors.Trace(err)
+		}
+		if len(str) < 2 {
+			return str, errors.Trace(ErrInvalidTimeFormat)
+		}
+		return str[2:], nil
+	case 'j':
+		return parseDigits(str, 3, &p.yearDay)
+	case 'M', 'b':
+		for i, name := range MonthNames {
+			if c == 'b' {
+				name = name[:3]
+			}
+			if len(str) >= len(name) && strings.EqualFold(str[:len(name)], name) {
+				p.month = i + 1
+				return str[len(name):], nil
+			}
+		}
+		return str, errors.Trace(ErrInvalidTimeFormat)
+	case 'W', 'a':
+		// The weekday is consumed but not used.
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			name := d.String()
+			if c == 'a' {
+				name = name[:3]
+			}
+			if len(str) >= len(name) && strings.EqualFold(str[:len(name)], name) {
+				return str[len(name):], nil
+			}
+		}
+		return str, errors.Trace(ErrInvalidTimeFormat)
+	case 'H', 'k':
+		p.hasTime = true
+		return parseDigits(str, 2, &p.hour)
+	case 'h', 'I', 'l':
+		p.hasTime, p.hour12 = true, true
+		return parseDigits(str, 2, &p.hour)
+	case 'i':
+		p.hasTime = true
+		return parseDigits(str, 2, &p.minute)
+	case 'S', 's':
+		p.hasTime = true
+		return parseDigits(str, 2, &p.second)
+	case 'f':
+		p.hasTime, p.hasFrac = true, true
+		n := len(str) - len(strings.TrimLeftFunc(str, unicode.IsDigit))
+		if n > 6 {
+			n = 6
+		}
+		if str, err = parseDigits(str, n, &p.frac); err != nil {
+			return str, errors.Trace(err)
+		}
+		// ".5" is 500000 microseconds.
+		p.frac *= int(math.Pow10(6 - n))
+		return str, nil
+	case 'p':
+		p.hasTime = true
+		if len(str) >= 2 {
+			switch strings.ToUpper(str[:2]) {
+			case "AM":
+				return str[2:], nil
+			case "PM":
+				p.pm = true
+				return str[2:], nil
+			}
+		}
+		return str, errors.Trace(ErrInvalidTimeFormat)
+	case 'r':
+		return p.parseFormat(str, "%I:%i:%S %p")
+	case 'T':
+		return p.parseFormat(str, "%H:%i:%S")
+	case '%':
+		if str[0] != '%' {
+			return str, errors.Trace(ErrInvalidTimeFormat)
+		}
+		return str[1:], nil
+	default:
+		return str, errors.Errorf("unsupported format specifier %%%c", c)
+	}
+}
+
+// parseFormat parses the prefix of str with format, and returns the rest of str.
+func (p *timeParts) parseFormat(str string, format string) (string, error) {
+	for i := 0; i < len(format); i++ {
+		str = strings.TrimLeftFunc(str, unicode.IsSpace)
+		c := format[i]
+		if c == ' ' {
+			continue
+		}
+		if str == "" {
+			return str, errors.Trace(ErrInvalidTimeFormat)
+		}
+		if c != '%' {
+			if str[0] != c {
+				return str, errors.Trace(ErrInvalidTimeFormat)
+			}
+			str = str[1:]
+			continue
+		}
+		i++
+		var err error
+		if str, err = p.parseSpecifier(format[i], str); err != nil {
+			return str, errors.Trace(err)
+		}
+	}
+	return str, nil
+}
+
+// parseDigits parses at most n leading digits of str to v, and returns the rest
+// of str. At least one digit is required.
+func parseDigits(str string, n int, v *int) (string, error) {
+	i := 0
+	for i < n && i < len(str) && str[i] >= '0' && str[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return str, errors.Trace(ErrInvalidTimeFormat)
+	}
+	x, err := strconv.Atoi(str[:i])
+	if err != nil {
+		return str, errors.Trace(err)
+	}
+	*v = x
+	return str[i:], nil
+}
@@ -324,7 +324,7 @@ func (s *testTimeSuite) TestCodec(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(t.String(), Not(Equals), t1.String())
 
-	err = t1.UnmarshalInLocation(b, time.Local)
+	err = t1.UnmarshalInLocation(b, time.UTC)
 	c.Assert(err, IsNil)
 	c.Assert(t.String(), Equals, t1.String())
 
@@ -598,8 +598,8 @@ func (s *testTimeSuite) TestConvert(c *C) {
 	for _, t := range tblDuration {
 		v, err := ParseDuration(t.Input, t.Fsp)
 		c.Assert(err, IsNil)
-		year, month, day := time.Now().Date()
-		n := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+		year, month, day := CurrentTime(TypeDate).Date()
+		n := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 		t, err := v.ConvertToTime(TypeDatetime)
 		c.Assert(err, IsNil)
 		c.Assert(t.Time.Sub(n), Equals, v.Duration)
@@ -694,3 +694,162 @@ func (s *testTimeSuite) TestParseDateFormat(c *C) {
 		c.Assert(r, DeepEquals, t.Result)
 	}
 }
+
+func (s *testTimeSuite) TestDateFormat(c *C) {
+	tbl := []struct {
+		Input  string
+		Format string
+		Expect string
+	}{
+		{"2009-10-04 22:23:00", "%W %M %Y", "Sunday October 2009"},
+		{"2007-10-04 22:23:00", "%H:%i:%s", "22:23:00"},
+		{"1900-10-04 22:23:00", "%D %y %a %d %m %b %j", "4th 00 Thu 04 10 Oct 277"},
+		{"1997-10-04 22:23:00", "%H %k %I %r %T %S %w", "22 22 10 10:23:00 PM 22:23:00 00 6"},
+		{"1999-01-01", "%X %V", "1998 52"},
+		{"2010-01-03 00:00:00.123456", "%U %u %V %v %X %x %f", "01 00 01 53 2010 2009 123456"},
+		{"2011-11-01 01:02:03", "%c/%e %l:%i %p %% %q", "11/1 1:02 AM % q"},
+		{"2011-11-22", "%D %D", "22nd 22nd"},
+	}
+
+	for _, t := range tbl {
+		tm, err := ParseTime(t.Input, TypeDatetime, MaxFsp)
+		c.Assert(err, IsNil)
+		str, err := tm.DateFormat(t.Format)
+		c.Assert(err, IsNil)
+		c.Assert(str, Equals, t.Expect, Commentf("%s %s", t.Input, t.Format))
+	}
+
+	zero := ZeroDatetime
+	str, err := zero.DateFormat("%Y-%m-%d %H:%i:%s")
+	c.Assert(err, IsNil)
+	c.Assert(str, Equals, "0000-00-00 00:00:00")
+	for _, layout := range []string{"%W", "%M", "%a", "%b", "%U", "%X"} {
+		_, err = zero.DateFormat(layout)
+		c.Assert(err, NotNil)
+	}
+}
+
+func (s *testTimeSuite) TestTimeFormat(c *C) {
+	tbl := []struct {
+		Input  string
+		Format string
+		Expect string
+	}{
+		{"100:00:00", "%H %k %h %I %l", "100 100 04 04 4"},
+		{"-10:11:12.123", "%H:%i:%s.%f %p", "-10:11:12.123000 AM"},
+		{"23:59:59", "%r %T", "11:59:59 PM 23:59:59"},
+	}
+
+	for _, t := range tbl {
+		d, err := ParseDuration(t.Input, MaxFsp)
+		c.Assert(err, IsNil)
+		str, err := d.TimeFormat(t.Format)
+		c.Assert(err, IsNil)
+		c.Assert(str, Equals, t.Expect, Commentf("%s %s", t.Input, t.Format))
+	}
+
+	d, err := ParseDuration("10:10:10", 0)
+	c.Assert(err, IsNil)
+	_, err = d.TimeFormat("%Y")
+	c.Assert(err, NotNil)
+}
+
+func (s *testTimeSuite) TestStrToDate(c *C) {
+	tbl := []struct {
+		Input  string
+		Format string
+		Expect string
+	}{
+		{"01,5,2013", "%d,%m,%Y", "2013-05-01"},
+		{"May 1, 2013", "%M %d,%Y", "2013-05-01"},
+		{"Jan 3rd 99", "%b %D %y", "1999-01-03"},
+		{"2015-12-31 11:22:33 pm", "%Y-%m-%d %h:%i:%s %p", "2015-12-31 23:22:33"},
+		{"2015-12-31 12:00:00 AM", "%Y-%m-%d %r", "2015-12-31 00:00:00"},
+		{"2015/12/31 10:20:30.5", "%Y/%m/%d %T.%f", "2015-12-31 10:20:30.500000"},
+		{"2016 60", "%Y %j", "2016-02-29"},
+		{"20151231", "%Y%m%d", "2015-12-31"},
+	}
+
+	for _, t := range tbl {
+		tm, err := StrToDate(t.Input, t.Format)
+		c.Assert(err, IsNil, Commentf("%s %s", t.Input, t.Format))
+		c.Assert(tm.String(), Equals, t.Expect, Commentf("%s %s", t.Input, t.Format))
+	}
+
+	errTbl := []struct {
+		Input  string
+		Format string
+	}{
+		{"2015", "%Y"},
+		{"2015-02-30", "%Y-%m-%d"},
+		{"2015-13-01", "%Y-%m-%d"},
+		{"2015-12-01x", "%Y-%m-%dy"},
+		{"Foo 1, 2013", "%M %d,%Y"},
+		{"2015-12-31 13:00:00 PM", "%Y-%m-%d %h:%i:%s %p"},
+	}
+
+	for _, t := range errTbl {
+		_, err := StrToDate(t.Input, t.Format)
+		c.Assert(err, NotNil, Commentf("%s %s", t.Input, t.Format))
+	}
+}
+
+func (s *testTimeSuite) TestParseTimeZone(c *C) {
+	loc, err := ParseTimeZone("SYSTEM")
+	c.Assert(err, IsNil)
+	c.Assert(loc, Equals, time.Local)
+
+	tbl := []struct {
+		Input  string
+		Offset int
+	}{
+		{"+08:00", 8 * 3600},
+		{"-05:30", -(5*3600 + 30*60)},
+		{"+0:00", 0},
+		{"+13:00", 13 * 3600},
+		{"-12:59", -(12*3600 + 59*60)},
+		{"UTC", 0},
+	}
+
+	for _, t := range tbl {
+		loc, err := ParseTimeZone(t.Input)
+		c.Assert(err, IsNil, Commentf("%s", t.Input))
+		_, offset := time.Date(2015, 1, 1, 0, 0, 0, 0, loc).Zone()
+		c.Assert(offset, Equals, t.Offset, Commentf("%s", t.Input))
+	}
+
+	for _, str := range []string{"", "Local", "+13:01", "-13:00", "+8", "+08:60", "08:00", "Foo/Bar"} {
+		_, err := ParseTimeZone(str)
+		c.Assert(err, NotNil, Commentf("%s", str))
+	}
+}
+
+func (s *testTimeSuite) TestConvertTimeZone(c *C) {
+	utc8 := time.FixedZone("+08:00", 8*3600)
+	tbl := []struct {
+		Input  string
+		From   *time.Location
+		To     *time.Location
+		Expect string
+	}{
+		{"2004-01-01 12:00:00", time.UTC, utc8, "2004-01-01 20:00:00"},
+		{"2004-01-01 02:00:00", utc8, time.UTC, "2003-12-31 18:00:00"},
+		{"0000-00-00 00:00:00", time.UTC, utc8, "0000-00-00 00:00:00"},
+	}
+
+	for _, t := range tbl {
+		v, err := ParseTime(t.Input, TypeDatetime, 0)
+		c.Assert(err, IsNil)
+		c.Assert(v.ConvertTimeZone(t.From, t.To).String(), Equals, t.Expect)
+	}
+
+	// The daylight saving time is used by named time zones.
+	ny, err := time.LoadLocation("America/New_York")
+	c.Assert(err, IsNil)
+	v, err := ParseTime("2015-07-01 12:00:00.5", TypeTimestamp, 1)
+	c.Assert(err, IsNil)
+	c.Assert(v.ConvertTimeZone(ny, time.UTC).String(), Equals, "2015-07-01 16:00:00.5")
+	v, err = ParseTime("2015-01-01 12:00:00", TypeTimestamp, 0)
+	c.Assert(err, IsNil)
+	c.Assert(v.ConvertTimeZone(time.UTC, ny).String(), Equals, "2015-01-01 07:00:00")
+}
@@ -0,0 +1,106 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// SystemTimeZone is the time_zone value for the time zone of the server.
+const SystemTimeZone = "SYSTEM"
+
+// The range of the time zone offsets, see
+// https://dev.mysql.com/doc/refman/5.7/en/time-zone-support.html
+const (
+	minTimeZoneOffset = -(12*60 + 59) * 60
+	maxTimeZoneOffset = 13 * 60 * 60
+)
+
+// ParseTimeZone returns the location for the time_zone value str, which is
+// SYSTEM, an offset from UTC like '+10:00' or a named time zone like
+// 'Europe/Helsinki', the named time zones are loaded from the tz database.
+func ParseTimeZone(str string) (*time.Location, error) {
+	str = strings.TrimSpace(str)
+	if strings.EqualFold(str, SystemTimeZone) {
+		return time.Local, nil
+	}
+
+	if len(str) > 0 && (str[0] == '+' || str[0] == '-') {
+		offset, ok := parseTimeZoneOffset(str)
+		if !ok {
+			return nil, errors.Trace(NewErr(ErrUnknownTimeZone, str))
+		}
+		return time.FixedZone(str, offset), nil
+	}
+
+	// Local is the name of the server time zone in Go, but not in MySQL.
+	if str == "" || str == "Local" {
+		return nil, errors.Trace(NewErr(ErrUnknownTimeZone, str))
+	}
+	loc, err := time.LoadLocation(str)
+	if err != nil {
+		return nil, errors.Trace(NewErr(ErrUnknownTimeZone, str))
+	}
+	return loc, nil
+}
+
+// parseTimeZoneOffset parses the offset like '+10:00' to seconds.
+func parseTimeZoneOffset(str string) (int, bool) {
+	hm := strings.Split(str[1:], ":")
+	if len(hm) != 2 || len(hm[1]) != 2 {
+		return 0, false
+	}
+
+	var hour, minute int
+	if rest, err := parseDigits(hm[0], 2, &hour); err != nil || rest != "" {
+		return 0, false
+	}
+	if rest, err := parseDigits(hm[1], 2, &minute); err != nil || rest != "" || minute > 59 {
+		return 0, false
+	}
+	offset := (hour*60 + minute) * 60
+	if str[0] == '-' {
+		offset = -offset
+	}
+	if offset < minTimeZoneOffset || offset > maxTimeZoneOffset {
+		return 0, false
+	}
+	return offset, true
+}
+
+// WallClock returns the time shown by a wall clock in loc at the instant t.
+// Like all the Time values, the result holds the wall clock in UTC, so that it
+// is not changed by the time zone of the server.
+func WallClock(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	return time.Date(year, month, day, hour, minute, second, t.Nanosecond(), time.UTC)
+}
+
+// ConvertTimeZone treats t as the wall clock in from, and returns the wall
+// clock in to at the same instant. The zero time is not converted.
+func (t Time) ConvertTimeZone(from, to *time.Location) Time {
+	if t.IsZero() || from == to {
+		return t
+	}
+
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	instant := time.Date(year, month, day, hour, minute, second, t.Nanosecond(), from)
+	return Time{Time: WallClock(instant, to), Type: t.Type, Fsp: t.Fsp}
+}
@@ -34,6 +34,9 @@ const (
 	TypeBit
 )
 
+// TypeJSON is the type code for the JSON type added in MySQL 5.7.
+const TypeJSON byte = 0xf5
+
 // MySQL type informations.
 const (
 	TypeNewDecimal byte = iota + 0xf6
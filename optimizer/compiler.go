@@ -64,6 +64,10 @@ func (com *Compiler) Compile(node ast.Node) (stmt.Statement, error) {
 		return convertCreateTable(c, v)
 	case *ast.DropTableStmt:
 		return convertDropTable(c, v)
+	case *ast.CreateViewStmt:
+		return convertCreateView(c, v)
+	case *ast.DropViewStmt:
+		return convertDropView(c, v)
 	case *ast.CreateIndexStmt:
 		return convertCreateIndex(c, v)
 	case *ast.DropIndexStmt:
@@ -72,6 +76,8 @@ func (com *Compiler) Compile(node ast.Node) (stmt.Statement, error) {
 		return convertAlterTable(c, v)
 	case *ast.TruncateTableStmt:
 		return convertTruncateTable(c, v)
+	case *ast.AnalyzeTableStmt:
+		return convertAnalyzeTable(c, v)
 	case *ast.ExplainStmt:
 		return convertExplain(c, v)
 	case *ast.PrepareStmt:
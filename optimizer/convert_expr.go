@@ -36,6 +36,10 @@ type expressionConverter struct {
 	exprMap      map[ast.Node]expression.Expression
 	paramMarkers paramMarkers
 	err          error
+	// ctes are the common table expressions in scope, the innermost ones are the last.
+	ctes []*cteScope
+	// subqueries is the nesting depth of the subquery being converted.
+	subqueries int
 }
 
 func newExpressionConverter() *expressionConverter {
@@ -116,6 +120,8 @@ func (c *expressionConverter) Leave(in ast.Node) (out ast.Node, ok bool) {
 		c.funcDateArith(v)
 	case *ast.AggregateFuncExpr:
 		c.aggregateFunc(v)
+	case *ast.WindowFuncExpr:
+		c.windowFunc(v)
 	}
 	return in, c.err == nil
 }
@@ -166,6 +172,8 @@ func (c *expressionConverter) caseExpr(v *ast.CaseExpr) {
 }
 
 func (c *expressionConverter) subquery(v *ast.SubqueryExpr) {
+	c.subqueries++
+	defer func() { c.subqueries-- }()
 	oldSubquery := &subquery.SubQuery{}
 	switch x := v.Query.(type) {
 	case *ast.SelectStmt:
@@ -426,3 +434,49 @@ func (c *expressionConverter) aggregateFunc(v *ast.AggregateFuncExpr) {
 	}
 	c.exprMap[v] = oldAggregate
 }
+
+func (c *expressionConverter) windowFunc(v *ast.WindowFuncExpr) {
+	var args, partitionBy []expression.Expression
+	for _, val := range v.Args {
+		args = append(args, c.exprMap[val])
+	}
+	for _, val := range v.Spec.PartitionBy {
+		partitionBy = append(partitionBy, c.exprMap[val.Expr])
+	}
+	var orderBy []*expression.WindowOrderItem
+	if v.Spec.OrderBy != nil {
+		for _, val := range v.Spec.OrderBy.Items {
+			orderBy = append(orderBy, &expression.WindowOrderItem{Expr: c.exprMap[val.Expr], Asc: !val.Desc})
+		}
+	}
+	var frame *expression.WindowFrame
+	if f := v.Spec.Frame; f != nil {
+		frame = &expression.WindowFrame{
+			Range: f.Type == ast.Ranges,
+			Start: c.frameBound(f.Start),
+			End:   c.frameBound(f.End),
+		}
+	}
+	oldWindowFunc, err := expression.NewWindowFunc(v.F, args, v.Distinct, partitionBy, orderBy, frame)
+	if err != nil {
+		c.err = err
+		return
+	}
+	c.exprMap[v] = oldWindowFunc
+}
+
+func (c *expressionConverter) frameBound(v *ast.FrameBound) expression.FrameBound {
+	b := expression.FrameBound{UnBounded: v.UnBounded}
+	switch v.Type {
+	case ast.Preceding:
+		b.Type = expression.BoundPreceding
+	case ast.Following:
+		b.Type = expression.BoundFollowing
+	default:
+		b.Type = expression.BoundCurrentRow
+	}
+	if v.Expr != nil {
+		b.N = v.Expr.GetValue()
+	}
+	return b
+}
@@ -14,6 +14,8 @@
 package optimizer
 
 import (
+	"strings"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/ddl"
@@ -21,6 +23,7 @@ import (
 	"github.com/pingcap/tidb/field"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/parser/coldef"
+	"github.com/pingcap/tidb/plan/plans"
 	"github.com/pingcap/tidb/rset/rsets"
 	"github.com/pingcap/tidb/stmt"
 	"github.com/pingcap/tidb/stmt/stmts"
@@ -111,6 +114,7 @@ func convertDelete(converter *expressionConverter, v *ast.DeleteStmt) (*stmts.De
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	oldRefs.Modify = true
 	oldDelete.Refs = oldRefs
 	for _, val := range v.Tables {
 		tableIdent := table.Ident{Schema: val.Schema, Name: val.Name}
@@ -147,6 +151,7 @@ func convertUpdate(converter *expressionConverter, v *ast.UpdateStmt) (*stmts.Up
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	oldUpdate.TableRefs.Modify = true
 	if v.Where != nil {
 		oldUpdate.Where, err = convertExpr(converter, v.Where)
 		if err != nil {
@@ -182,6 +187,12 @@ func getInnerFromParentheses(expr ast.ExprNode) ast.ExprNode {
 }
 
 func convertSelect(converter *expressionConverter, s *ast.SelectStmt) (*stmts.SelectStmt, error) {
+	if s.With != nil {
+		defer converter.popCTEs(len(converter.ctes))
+		if err := convertWith(converter, s.With); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	oldSelect := &stmts.SelectStmt{
 		Distinct: s.Distinct,
 		Text:     s.Text(),
@@ -277,6 +288,12 @@ func convertSelect(converter *expressionConverter, s *ast.SelectStmt) (*stmts.Se
 }
 
 func convertUnion(converter *expressionConverter, u *ast.UnionStmt) (*stmts.UnionStmt, error) {
+	if u.With != nil {
+		defer converter.popCTEs(len(converter.ctes))
+		if err := convertWith(converter, u.With); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	oldUnion := &stmts.UnionStmt{
 		Text: u.Text(),
 	}
@@ -313,6 +330,155 @@ func convertUnion(converter *expressionConverter, u *ast.UnionStmt) (*stmts.Unio
 	return oldUnion, nil
 }
 
+// cteScope is a common table expression in scope when a query is converted.
+type cteScope struct {
+	cte *rsets.CTE
+	// defining is true if the recursive common table expression is being converted.
+	defining bool
+	// subqueries is the subquery nesting depth of the WITH clause.
+	subqueries int
+	// refs is the number of references converted.
+	refs int
+}
+
+// findCTE returns the common table expression in scope referenced by the table name.
+func (c *expressionConverter) findCTE(tn *ast.TableName) *cteScope {
+	if tn.Schema.L != "" {
+		return nil
+	}
+	for i := len(c.ctes) - 1; i >= 0; i-- {
+		if strings.EqualFold(c.ctes[i].cte.Name, tn.Name.L) {
+			return c.ctes[i]
+		}
+	}
+	return nil
+}
+
+// popCTEs removes the common table expressions in scope after the first n ones.
+func (c *expressionConverter) popCTEs(n int) {
+	c.ctes = c.ctes[:n]
+}
+
+// convertWith puts the common table expressions of the WITH clause in scope.
+// A common table expression can refer to the previous ones, and to itself if
+// the WITH clause is recursive.
+func convertWith(converter *expressionConverter, w *ast.WithClause) error {
+	names := make(map[string]struct{}, len(w.CTEs))
+	for _, v := range w.CTEs {
+		if _, ok := names[v.Name.L]; ok {
+			return errors.Errorf("Not unique table/alias: '%s'", v.Name.O)
+		}
+		names[v.Name.L] = struct{}{}
+		oldCTE := &rsets.CTE{Name: v.Name.O}
+		for _, col := range v.ColNames {
+			oldCTE.ColNames = append(oldCTE.ColNames, col.O)
+		}
+		scope := &cteScope{cte: oldCTE, subqueries: converter.subqueries}
+		if !w.IsRecursive {
+			var err error
+			if oldCTE.Query, err = convertResultSet(converter, v.Query.Query); err != nil {
+				return errors.Trace(err)
+			}
+			converter.ctes = append(converter.ctes, scope)
+			continue
+		}
+		converter.ctes = append(converter.ctes, scope)
+		scope.defining = true
+		err := convertRecursiveCTE(converter, scope, v.Query.Query)
+		scope.defining = false
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func convertResultSet(converter *expressionConverter, node ast.ResultSetNode) (stmt.Statement, error) {
+	switch x := node.(type) {
+	case *ast.SelectStmt:
+		return convertSelect(converter, x)
+	case *ast.UnionStmt:
+		return convertUnion(converter, x)
+	}
+	return nil, errors.Errorf("invalid query %T", node)
+}
+
+// convertRecursiveCTE converts the query of a common table expression in a
+// WITH RECURSIVE clause. The query of a recursive one is a union, the query
+// blocks referring to the common table expression are the recursive part, the
+// ones before them are the seed.
+func convertRecursiveCTE(converter *expressionConverter, scope *cteScope, query ast.ResultSetNode) error {
+	u, ok := query.(*ast.UnionStmt)
+	if !ok || u.With != nil {
+		var err error
+		scope.cte.Query, err = convertResultSet(converter, query)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if scope.refs > 0 {
+			return errors.Errorf("Recursive Common Table Expression '%s' should contain a UNION", scope.cte.Name)
+		}
+		return nil
+	}
+
+	var seeds, recursives []*stmts.SelectStmt
+	for _, val := range u.Selects {
+		refs := scope.refs
+		oldSelect, err := convertSelect(converter, val)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if scope.refs > refs {
+			recursives = append(recursives, oldSelect)
+			continue
+		}
+		if len(recursives) > 0 {
+			return errors.Errorf("Recursive Common Table Expression '%s' should have one or more non-recursive query blocks followed by one or more recursive ones", scope.cte.Name)
+		}
+		seeds = append(seeds, oldSelect)
+	}
+	if len(recursives) == 0 {
+		var err error
+		scope.cte.Query, err = convertUnion(converter, u)
+		return errors.Trace(err)
+	}
+	if len(seeds) == 0 {
+		return errors.Errorf("Recursive Common Table Expression '%s' should have one or more non-recursive query blocks followed by one or more recursive ones", scope.cte.Name)
+	}
+	if u.OrderBy != nil || u.Limit != nil {
+		return errors.Errorf("ORDER BY / LIMIT over UNION in recursive Common Table Expression '%s' is not supported", scope.cte.Name)
+	}
+	scope.cte.Seed = newUnionOfSelects(seeds, u.Distinct)
+	scope.cte.Recursive = newUnionOfSelects(recursives, u.Distinct)
+	scope.cte.Distinct = u.Distinct
+	return nil
+}
+
+// newUnionOfSelects returns the union of the selects, it is the select itself
+// if there is only one.
+func newUnionOfSelects(selects []*stmts.SelectStmt, distinct bool) stmt.Statement {
+	if len(selects) == 1 {
+		return selects[0]
+	}
+	sep := " UNION ALL "
+	if distinct {
+		sep = " UNION "
+	}
+	texts := make([]string, len(selects))
+	for i, s := range selects {
+		texts[i] = s.Text
+	}
+	oldUnion := &stmts.UnionStmt{
+		Selects:   selects,
+		Distincts: make([]bool, len(selects)-1),
+		Text:      strings.Join(texts, sep),
+	}
+	for i := range oldUnion.Distincts {
+		oldUnion.Distincts[i] = distinct
+	}
+	return oldUnion
+}
+
 func convertJoin(converter *expressionConverter, join *ast.Join) (*rsets.JoinRset, error) {
 	oldJoin := &rsets.JoinRset{}
 	switch join.Tp {
@@ -369,7 +535,18 @@ func convertTableSource(converter *expressionConverter, ts *ast.TableSource) (*r
 	oldTs.Name = ts.AsName.O
 	switch src := ts.Source.(type) {
 	case *ast.TableName:
+		if scope := converter.findCTE(src); scope != nil {
+			if scope.defining && converter.subqueries > scope.subqueries {
+				return nil, errors.Errorf("In recursive query block of Recursive Common Table Expression '%s', the recursive table must be referenced only once, and not in any subquery", scope.cte.Name)
+			}
+			scope.refs++
+			oldTs.Source = scope.cte
+			return oldTs, nil
+		}
 		oldTs.Source = table.Ident{Schema: src.Schema, Name: src.Name}
+		for _, h := range ts.IndexHints {
+			oldTs.IndexHints = append(oldTs.IndexHints, convertIndexHint(h))
+		}
 	case *ast.SelectStmt:
 		oldSelect, err := convertSelect(converter, src)
 		if err != nil {
@@ -386,6 +563,19 @@ func convertTableSource(converter *expressionConverter, ts *ast.TableSource) (*r
 	return oldTs, nil
 }
 
+func convertIndexHint(h *ast.IndexHint) *plans.IndexHint {
+	oldHint := &plans.IndexHint{Names: h.IndexNames}
+	switch h.HintType {
+	case ast.HintUse:
+		oldHint.Type = plans.UseIndex
+	case ast.HintIgnore:
+		oldHint.Type = plans.IgnoreIndex
+	case ast.HintForce:
+		oldHint.Type = plans.ForceIndex
+	}
+	return oldHint
+}
+
 func convertGroupBy(converter *expressionConverter, gb *ast.GroupByClause) (*rsets.GroupByRset, error) {
 	oldGroupBy := &rsets.GroupByRset{
 		By: make([]expression.Expression, len(gb.Items)),
@@ -606,6 +796,35 @@ func convertDropTable(converter *expressionConverter, v *ast.DropTableStmt) (*st
 	return oldDropTable, nil
 }
 
+func convertCreateView(converter *expressionConverter, v *ast.CreateViewStmt) (*stmts.CreateViewStmt, error) {
+	oldSelect, err := convertSelect(converter, v.Select)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &stmts.CreateViewStmt{
+		OrReplace: v.OrReplace,
+		Ident:     table.Ident{Schema: v.ViewName.Schema, Name: v.ViewName.Name},
+		Cols:      v.Cols,
+		Select:    oldSelect,
+		Text:      v.Text(),
+	}, nil
+}
+
+func convertDropView(converter *expressionConverter, v *ast.DropViewStmt) (*stmts.DropViewStmt, error) {
+	oldDropView := &stmts.DropViewStmt{
+		IfExists: v.IfExists,
+		Text:     v.Text(),
+	}
+	oldDropView.ViewIdents = make([]table.Ident, len(v.Views))
+	for i, val := range v.Views {
+		oldDropView.ViewIdents[i] = table.Ident{
+			Schema: val.Schema,
+			Name:   val.Name,
+		}
+	}
+	return oldDropView, nil
+}
+
 func convertCreateIndex(converter *expressionConverter, v *ast.CreateIndexStmt) (*stmts.CreateIndexStmt, error) {
 	oldCreateIndex := &stmts.CreateIndexStmt{
 		IndexName: v.IndexName,
@@ -751,9 +970,25 @@ func convertTruncateTable(converter *expressionConverter, v *ast.TruncateTableSt
 	}, nil
 }
 
+func convertAnalyzeTable(converter *expressionConverter, v *ast.AnalyzeTableStmt) (*stmts.AnalyzeTableStmt, error) {
+	oldAnalyze := &stmts.AnalyzeTableStmt{
+		Text: v.Text(),
+	}
+	oldAnalyze.TableIdents = make([]table.Ident, len(v.TableNames))
+	for i, val := range v.TableNames {
+		oldAnalyze.TableIdents[i] = table.Ident{
+			Schema: val.Schema,
+			Name:   val.Name,
+		}
+	}
+	return oldAnalyze, nil
+}
+
 func convertExplain(converter *expressionConverter, v *ast.ExplainStmt) (*stmts.ExplainStmt, error) {
 	oldExplain := &stmts.ExplainStmt{
-		Text: v.Text(),
+		Analyze: v.Analyze,
+		Format:  v.Format,
+		Text:    v.Text(),
 	}
 	var err error
 	switch x := v.Stmt.(type) {
@@ -856,6 +1091,8 @@ func convertShow(converter *expressionConverter, v *ast.ShowStmt) (*stmts.ShowSt
 		oldShow.Target = stmt.ShowColumns
 	case ast.ShowCreateTable:
 		oldShow.Target = stmt.ShowCreateTable
+	case ast.ShowCreateView:
+		oldShow.Target = stmt.ShowCreateView
 	case ast.ShowDatabases:
 		oldShow.Target = stmt.ShowDatabases
 	case ast.ShowTables:
@@ -872,6 +1109,8 @@ func convertShow(converter *expressionConverter, v *ast.ShowStmt) (*stmts.ShowSt
 		oldShow.Target = stmt.ShowTableStatus
 	case ast.ShowTriggers:
 		oldShow.Target = stmt.ShowTriggers
+	case ast.ShowStats:
+		oldShow.Target = stmt.ShowStats
 	case ast.ShowNone:
 		oldShow.Target = stmt.ShowNone
 	}
@@ -521,6 +521,8 @@ func coerceArithmetic(a interface{}) (interface{}, error) {
 		return x.ToNumber(), nil
 	case mysql.Set:
 		return x.ToNumber(), nil
+	case mysql.JSON:
+		return coerceArithmetic(x.Value)
 	default:
 		return x, nil
 	}
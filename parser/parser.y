@@ -58,18 +58,24 @@ import (
 
 
 	abs		"ABS"
+	acos		"ACOS"
 	add		"ADD"
 	addDate		"ADDDATE"
 	after		"AFTER"
 	all 		"ALL"
 	alter		"ALTER"
+	analyze		"ANALYZE"
 	and		"AND"
 	andand		"&&"
 	andnot		"&^"
 	any 		"ANY"
 	as		"AS"
+	ascii		"ASCII"
 	asc		"ASC"
+	asin		"ASIN"
 	at		"AT"
+	atan		"ATAN"
+	atan2		"ATAN2"
 	autoIncrement	"AUTO_INCREMENT"
 	avg		"AVG"
 	avgRowLength	"AVG_ROW_LENGTH"
@@ -80,7 +86,11 @@ import (
 	byteType	"BYTE"
 	caseKwd		"CASE"
 	cast		"CAST"
+	ceil		"CEIL"
+	ceiling		"CEILING"
 	character	"CHARACTER"
+	charLength	"CHAR_LENGTH"
+	characterLength	"CHARACTER_LENGTH"
 	charsetKwd	"CHARSET"
 	check 		"CHECK"
 	checksum	"CHECKSUM"
@@ -96,25 +106,37 @@ import (
 	concatWs	"CONCAT_WS"
 	connection 	"CONNECTION"
 	constraint	"CONSTRAINT"
+	conv		"CONV"
 	convert		"CONVERT"
+	convertTz	"CONVERT_TZ"
+	cos		"COS"
+	cot		"COT"
 	count		"COUNT"
 	create		"CREATE"
+	crc32		"CRC32"
 	cross 		"CROSS"
+	cumeDist	"CUME_DIST"
 	curDate 	"CURDATE"
+	current		"CURRENT"
 	currentDate 	"CURRENT_DATE"
 	currentUser	"CURRENT_USER"
 	database	"DATABASE"
 	databases	"DATABASES"
+	dateDiff	"DATEDIFF"
 	dateAdd		"DATE_ADD"
+	dateFormat	"DATE_FORMAT"
 	dateSub		"DATE_SUB"
 	day		"DAY"
+	dayname		"DAYNAME"
 	dayofmonth	"DAYOFMONTH"
 	dayofweek	"DAYOFWEEK"
 	dayofyear	"DAYOFYEAR"
 	deallocate	"DEALLOCATE"
 	defaultKwd	"DEFAULT"
+	degrees		"DEGREES"
 	delayed		"DELAYED"
 	deleteKwd	"DELETE"
+	denseRank	"DENSE_RANK"
 	desc		"DESC"
 	describe	"DESCRIBE"
 	distinct	"DISTINCT"
@@ -124,6 +146,7 @@ import (
 	dual 		"DUAL"
 	duplicate	"DUPLICATE"
 	elseKwd		"ELSE"
+	elt		"ELT"
 	end		"END"
 	engine		"ENGINE"
 	engines		"ENGINES"
@@ -132,24 +155,49 @@ import (
 	escape 		"ESCAPE"
 	execute		"EXECUTE"
 	exists		"EXISTS"
+	exp		"EXP"
 	explain		"EXPLAIN"
 	extract		"EXTRACT"
+	firstValue	"FIRST_VALUE"
+	following	"FOLLOWING"
+	lag		"LAG"
+	lastValue	"LAST_VALUE"
+	lead		"LEAD"
+	nthValue	"NTH_VALUE"
+	ntile		"NTILE"
+	over		"OVER"
+	partition	"PARTITION"
+	percentRank	"PERCENT_RANK"
+	preceding	"PRECEDING"
+	rangeKwd	"RANGE"
+	rank		"RANK"
+	rows		"ROWS"
+	rowNumber	"ROW_NUMBER"
+	unbounded	"UNBOUNDED"
 	falseKwd	"false"
 	fields		"FIELDS"
+	fieldKwd	"FIELD"
+	findInSet	"FIND_IN_SET"
 	first		"FIRST"
+	floor		"FLOOR"
+	force		"FORCE"
 	foreign		"FOREIGN"
+	format		"FORMAT"
 	forKwd		"FOR"
 	foundRows	"FOUND_ROWS"
 	from		"FROM"
+	fromUnixTime	"FROM_UNIXTIME"
 	full		"FULL"
 	fulltext	"FULLTEXT"
 	ge		">="
 	global		"GLOBAL"
 	grant		"GRANT"
 	grants		"GRANTS"
+	greatest	"GREATEST"
 	group		"GROUP"
 	groupConcat	"GROUP_CONCAT"
 	having		"HAVING"
+	hex		"HEX"
 	highPriority	"HIGH_PRIORITY"
 	hour		"HOUR"
 	identified	"IDENTIFIED"
@@ -160,24 +208,44 @@ import (
 	index		"INDEX"
 	inner 		"INNER"
 	insert		"INSERT"
+	instr		"INSTR"
 	interval	"INTERVAL"
 	into		"INTO"
 	is		"IS"
 	join		"JOIN"
+	jsonArray	"JSON_ARRAY"
+	jsonContains	"JSON_CONTAINS"
+	jsonExtract	"JSON_EXTRACT"
+	jsonObject	"JSON_OBJECT"
+	jsonRemove	"JSON_REMOVE"
+	jsonSet	"JSON_SET"
+	jsonTypeFunc	"JSON_TYPE"
+	jsonUnquote	"JSON_UNQUOTE"
+	jss		"->"
+	juss		"->>"
 	key		"KEY"
 	keyBlockSize	"KEY_BLOCK_SIZE"
+	lastDay		"LAST_DAY"
 	le		"<="
 	leading		"LEADING"
+	least		"LEAST"
 	left		"LEFT"
 	length		"LENGTH"
 	like		"LIKE"
 	limit		"LIMIT"
+	ln		"LN"
 	local		"LOCAL"
 	locate		"LOCATE"
 	lock		"LOCK"
+	log		"LOG"
+	log10		"LOG10"
+	log2		"LOG2"
 	lower 		"LOWER"
 	lowPriority	"LOW_PRIORITY"
+	lpad		"LPAD"
 	lsh		"<<"
+	ltrim		"LTRIM"
+	makeDate	"MAKEDATE"
 	max		"MAX"
 	maxRows		"MAX_ROWS"
 	microsecond	"MICROSECOND"
@@ -187,6 +255,7 @@ import (
 	mod 		"MOD"
 	mode		"MODE"
 	month		"MONTH"
+	monthname	"MONTHNAME"
 	names		"NAMES"
 	national	"NATIONAL"
 	neq		"!="
@@ -203,22 +272,31 @@ import (
 	oror		"||"
 	outer		"OUTER"
 	password	"PASSWORD"
+	pi		"PI"
 	placeholder	"PLACEHOLDER"
+	pow		"POW"
+	power		"POWER"
 	prepare		"PREPARE"
 	primary		"PRIMARY"
 	quarter		"QUARTER"
 	quick		"QUICK"
+	radians		"RADIANS"
 	rand		"RAND"
 	read		"READ"
+	recursive	"RECURSIVE"
 	references	"REFERENCES"
 	regexp		"REGEXP"
 	repeat		"REPEAT"
 	replace		"REPLACE"
+	reverse		"REVERSE"
 	right		"RIGHT"
 	rlike		"RLIKE"
 	rollback	"ROLLBACK"
+	round		"ROUND"
 	row 		"ROW"
+	rpad		"RPAD"
 	rsh		">>"
+	rtrim		"RTRIM"
 	schema		"SCHEMA"
 	schemas		"SCHEMAS"
 	second		"SECOND"
@@ -227,10 +305,17 @@ import (
 	set		"SET"
 	share		"SHARE"
 	show		"SHOW"
+	sign		"SIGN"
 	signed		"SIGNED"
+	sin		"SIN"
 	some 		"SOME"
+	space		"SPACE"
+	sqrt		"SQRT"
 	start		"START"
+	stats		"STATS"
 	status		"STATUS"
+	strcmp		"STRCMP"
+	strToDate	"STR_TO_DATE"
 	stringType	"string"
 	subDate		"SUBDATE"
 	substring	"SUBSTRING"
@@ -240,7 +325,11 @@ import (
 	sysDate		"SYSDATE"
 	tableKwd	"TABLE"
 	tables		"TABLES"
+	tan		"TAN"
 	then		"THEN"
+	timeFormat	"TIME_FORMAT"
+	timestampAdd	"TIMESTAMPADD"
+	timestampDiff	"TIMESTAMPDIFF"
 	to		"TO"
 	trailing	"TRAILING"
 	transaction	"TRANSACTION"
@@ -251,7 +340,9 @@ import (
 	underscoreCS	"UNDERSCORE_CHARSET"
 	unknown 	"UNKNOWN"
 	union		"UNION"
+	unhex		"UNHEX"
 	unique		"UNIQUE"
+	unixTimestamp	"UNIX_TIMESTAMP"
 	unlock		"UNLOCK"
 	unsigned	"UNSIGNED"
 	update		"UPDATE"
@@ -263,12 +354,14 @@ import (
 	value		"VALUE"
 	values		"VALUES"
 	variables	"VARIABLES"
+	view		"VIEW"
 	warnings	"WARNINGS"
 	week		"WEEK"
 	weekday		"WEEKDAY"
 	weekofyear	"WEEKOFYEAR"
 	when		"WHEN"
 	where		"WHERE"
+	with		"WITH"
 	write		"WRITE"
 	xor 		"XOR"
 	yearweek	"YEARWEEK"
@@ -314,6 +407,7 @@ import (
 	textType	"TEXT"
 	mediumtextType	"MEDIUMTEXT"
 	longtextType	"LONGTEXT"
+	jsonType	"JSON"
 	
 	int16Type	"int16"
 	int24Type	"int24"
@@ -346,6 +440,7 @@ import (
 
 %type   <item>
 	AlterTableStmt		"Alter table statement"
+	AnalyzeTableStmt	"Analyze table statement"
 	AlterTableSpec	"Alter table specification"
 	AlterTableSpecList	"Alter table specification list"
 	AnyOrAll		"Any or All for subquery"
@@ -380,6 +475,7 @@ import (
 	DatabaseOptionListOpt	"CREATE Database specification list opt"
 	CreateTableStmt		"CREATE TABLE statement"
 	CreateUserStmt		"CREATE User statement"
+	CreateViewStmt		"CREATE VIEW statement"
 	CrossOpt		"Cross join option"
 	DateArithOpt		"Date arith dateadd or datesub option"
 	DateArithMultiFormsOpt	"Date arith adddate or subdate option"
@@ -398,6 +494,7 @@ import (
 	DropDatabaseStmt	"DROP DATABASE statement"
 	DropIndexStmt		"DROP INDEX statement"
 	DropTableStmt		"DROP TABLE statement"
+	DropViewStmt		"DROP VIEW statement"
 	EmptyStmt		"empty statement"
 	EqOpt			"= or empty"
 	EscapedTableRef 	"escaped table reference"
@@ -412,6 +509,9 @@ import (
 	PredicateExpr		"Predicate expression factor"
 	Field			"field expression"
 	FieldAsName		"Field alias name"
+	FrameBound		"window frame bound"
+	FrameClauseOpt		"optional window frame clause"
+	FrameUnit		"window frame unit, ROWS or RANGE"
 	FieldAsNameOpt		"Field alias name opt"
 	FieldList		"field expression list"
 	TableRefsClause		"Table references clause"
@@ -420,6 +520,7 @@ import (
 	FunctionCallConflict	"Function call with reserved keyword as function name"
 	FunctionCallKeyword	"Function call with keyword as function name"
 	FunctionCallNonKeyword	"Function call with nonkeyword as function name"
+	FunctionCallWindow	"Function call with window"
 	FunctionNameConflict	"Built-in function call names which are conflict with keywords"
 	FuncDatetimePrec	"Function datetime precision"
 	GlobalScope		"The scope of variable"
@@ -432,7 +533,13 @@ import (
 	IgnoreOptional		"IGNORE or empty"
 	IndexColName		"Index column name"
 	IndexColNameList	"List of index column name"
+	IndexHint		"index hint"
+	IndexHintList		"index hint list"
+	IndexHintListOpt	"index hint list optional"
+	IndexHintType		"index hint type"
 	IndexName		"index name"
+	IndexNameList		"index name list"
+	IndexNameListOpt	"index name list optional"
 	IndexType		"index type"
 	InsertIntoStmt		"INSERT INTO statement"
 	InsertValues		"Rest part of INSERT/REPLACE INTO statement"
@@ -459,9 +566,11 @@ import (
 	OptFull			"Full or empty"
 	OptInteger		"Optional Integer keyword"
 	Order			"ORDER BY clause optional collation specification"
+	OrReplace		"optional OR REPLACE clause"
 	OrderBy			"ORDER BY clause"
 	ByItem			"BY item"
 	OrderByOptional		"Optional ORDER BY clause optional"
+	OverClause		"OVER clause of window function"
 	ByList			"BY list"
 	OuterOpt		"optional OUTER clause"
 	QuickOptional		"QUICK or empty"
@@ -536,14 +645,22 @@ import (
 	VariableAssignment	"set variable value"
 	VariableAssignmentList	"set variable value list"
 	Variable		"User or system variable"
+	ViewColumnList		"column name list of view or common table expression"
+	ViewColumnListOpt	"optional column name list of view or common table expression"
 	WhereClause		"WHERE clause"
 	WhereClauseOptional	"Optinal WHERE clause"
+	WithClause		"WITH clause"
+	WithList		"common table expression list"
+	WithSelectStmt		"SELECT or UNION statement with WITH clause"
+	CommonTableExpr		"common table expression"
 
 	Identifier		"identifier or unreserved keyword"
 	UnReservedKeyword	"MySQL unreserved keywords"
 	NotKeywordToken		"Tokens not mysql keyword but treated specially"
 
+	PartitionByOpt		"optional PARTITION BY clause of window"
 	WhenClause		"When clause"
+	WindowSpec		"window specification"
 	WhenClauseList		"When clause list"
 	ElseOpt			"Optional else clause"
 	ExpressionOpt		"Optional expression"
@@ -1267,6 +1384,70 @@ TableOrTables:
 	"TABLE"
 |	"TABLES"
 
+/*******************************************************************
+ *
+ *  Create View Statement
+ *
+ *  Example:
+ *	CREATE OR REPLACE VIEW v (a, b) AS SELECT c, d FROM t
+ *  See: https://dev.mysql.com/doc/refman/5.7/en/create-view.html
+ *******************************************************************/
+CreateViewStmt:
+	"CREATE" OrReplace "VIEW" TableName ViewColumnListOpt "AS" SelectStmt
+	{
+		l := yylex.(*lexer)
+		sel := $7.(*ast.SelectStmt)
+		// The lookahead token is ';' or the end of src.
+		startOffset := l.startOffset(yyS[yypt].offset)
+		sel.SetText(strings.TrimRight(l.src[startOffset:l.i], "; \t\r\n"))
+		$$ = &ast.CreateViewStmt{
+			OrReplace:	$2.(bool),
+			ViewName:	$4.(*ast.TableName),
+			Cols:		$5.([]model.CIStr),
+			Select:		sel,
+		}
+		if l.root {
+			break
+		}
+	}
+
+OrReplace:
+	{
+		$$ = false
+	}
+|	"OR" "REPLACE"
+	{
+		$$ = true
+	}
+
+ViewColumnListOpt:
+	{
+		$$ = []model.CIStr(nil)
+	}
+|	'(' ViewColumnList ')'
+	{
+		$$ = $2
+	}
+
+ViewColumnList:
+	Identifier
+	{
+		$$ = []model.CIStr{model.NewCIStr($1.(string))}
+	}
+|	ViewColumnList ',' Identifier
+	{
+		$$ = append($1.([]model.CIStr), model.NewCIStr($3.(string)))
+	}
+
+DropViewStmt:
+	"DROP" "VIEW" IfExists TableNameList
+	{
+		$$ = &ast.DropViewStmt{IfExists: $3.(bool), Views: $4.([]*ast.TableName)}
+		if yylex.(*lexer).root {
+			break
+		}
+	}
+
 EqOpt:
 	{
 	}
@@ -1309,6 +1490,18 @@ ExplainStmt:
 	{
 		$$ = &ast.ExplainStmt{Stmt: $2.(ast.StmtNode)}
 	}
+|	ExplainSym "ANALYZE" SelectStmt
+	{
+		$$ = &ast.ExplainStmt{Stmt: $3.(ast.StmtNode), Analyze: true}
+	}
+|	ExplainSym "FORMAT" "=" Identifier ExplainableStmt
+	{
+		$$ = &ast.ExplainStmt{Stmt: $5.(ast.StmtNode), Format: strings.ToLower($4.(string))}
+	}
+|	ExplainSym "FORMAT" "=" stringLit ExplainableStmt
+	{
+		$$ = &ast.ExplainStmt{Stmt: $5.(ast.StmtNode), Format: strings.ToLower($4.(string))}
+	}
 
 LengthNum:
 	NUM
@@ -1661,14 +1854,22 @@ UnReservedKeyword:
 |	"START" | "STATUS" | "GLOBAL" | "TABLES"| "TEXT" | "TIME" | "TIMESTAMP" | "TRANSACTION" | "TRUNCATE" | "UNKNOWN"
 |	"VALUE" | "WARNINGS" | "YEAR" |	"MODE" | "WEEK" | "ANY" | "SOME" | "USER" | "IDENTIFIED" | "COLLATION"
 |	"COMMENT" | "AVG_ROW_LENGTH" | "CONNECTION" | "CHECKSUM" | "COMPRESSION" | "KEY_BLOCK_SIZE" | "MAX_ROWS" | "MIN_ROWS"
-|	"NATIONAL" | "ROW" | "QUARTER" | "ESCAPE" | "GRANTS" | "FIELDS" | "TRIGGERS"
+|	"NATIONAL" | "ROW" | "QUARTER" | "ESCAPE" | "GRANTS" | "FIELDS" | "TRIGGERS" | "STATS" | "FORMAT" | "VIEW"
+|	"CURRENT" | "FOLLOWING" | "PRECEDING" | "UNBOUNDED" | "JSON"
 
 NotKeywordToken:
 	"ABS" | "ADDDATE" | "COALESCE" | "CONCAT" | "CONCAT_WS" | "COUNT" | "DAY" | "DATE_ADD" | "DATE_SUB" | "DAYOFMONTH"
 |	"DAYOFWEEK" | "DAYOFYEAR" | "FOUND_ROWS" | "GROUP_CONCAT"| "HOUR" | "IFNULL" | "LENGTH" | "LOCATE" | "MAX"
 |	"MICROSECOND" | "MIN" | "MINUTE" | "NULLIF" | "MONTH" | "NOW" | "RAND" | "SECOND" | "SQL_CALC_FOUND_ROWS"
 |	"SUBDATE" | "SUBSTRING" %prec lowerThanLeftParen | "SUBSTRING_INDEX" | "SUM" | "TRIM" | "WEEKDAY" | "WEEKOFYEAR"
-|	"YEARWEEK"
+|	"YEARWEEK" | "CUME_DIST" | "DENSE_RANK" | "FIRST_VALUE" | "LAG" | "LAST_VALUE" | "LEAD" | "NTH_VALUE" | "NTILE"
+|	"PERCENT_RANK" | "RANK" | "ROW_NUMBER" | "ASCII" | "CHAR_LENGTH" | "CHARACTER_LENGTH" | "ELT" | "FIELD"
+|	"FIND_IN_SET" | "HEX" | "INSTR" | "LPAD" | "LTRIM" | "REVERSE" | "RPAD" | "RTRIM" | "SPACE" | "STRCMP" | "UNHEX"
+|	"ACOS" | "ASIN" | "ATAN" | "ATAN2" | "CEIL" | "CEILING" | "CONV" | "COS" | "COT" | "CRC32" | "DEGREES" | "EXP" | "FLOOR" | "GREATEST" | "LEAST"
+|	"LN" | "LOG" | "LOG10" | "LOG2" | "PI" | "POW" | "POWER" | "RADIANS" | "ROUND" | "SIGN" | "SIN" | "SQRT" | "TAN"
+|	"DATEDIFF" | "DATE_FORMAT" | "DAYNAME" | "FROM_UNIXTIME" | "LAST_DAY" | "MAKEDATE"
+|	"MONTHNAME" | "STR_TO_DATE" | "TIME_FORMAT" | "TIMESTAMPADD" | "TIMESTAMPDIFF" | "UNIX_TIMESTAMP" | "CONVERT_TZ"
+|	"JSON_ARRAY" | "JSON_CONTAINS" | "JSON_EXTRACT" | "JSON_OBJECT" | "JSON_REMOVE" | "JSON_SET" | "JSON_TYPE" | "JSON_UNQUOTE"
 
 /************************************************************************************
  *
@@ -1875,6 +2076,19 @@ Operand:
 	{
 		$$ = &ast.ColumnNameExpr{Name: $1.(*ast.ColumnName)}
 	}
+|	ColumnName "->" stringLit
+	{
+		// col->'path' is the same as JSON_EXTRACT(col, 'path').
+		args := []ast.ExprNode{&ast.ColumnNameExpr{Name: $1.(*ast.ColumnName)}, ast.NewValueExpr($3)}
+		$$ = &ast.FuncCallExpr{FnName: "JSON_EXTRACT", Args: args}
+	}
+|	ColumnName "->>" stringLit
+	{
+		// col->>'path' is the same as JSON_UNQUOTE(JSON_EXTRACT(col, 'path')).
+		args := []ast.ExprNode{&ast.ColumnNameExpr{Name: $1.(*ast.ColumnName)}, ast.NewValueExpr($3)}
+		extract := &ast.FuncCallExpr{FnName: "JSON_EXTRACT", Args: args}
+		$$ = &ast.FuncCallExpr{FnName: "JSON_UNQUOTE", Args: []ast.ExprNode{extract}}
+	}
 |	'(' Expression ')'
 	{
 		l := yylex.(*lexer)
@@ -2005,9 +2219,10 @@ Function:
 |	FunctionCallNonKeyword
 |	FunctionCallConflict
 |	FunctionCallAgg
+|	FunctionCallWindow
 
 FunctionNameConflict:
-	"DATABASE" | "SCHEMA" | "IF" | "LEFT" | "REPEAT" | "CURRENT_USER" | "CURRENT_DATE"
+	"DATABASE" | "SCHEMA" | "IF" | "LEFT" | "REPEAT" | "CURRENT_USER" | "CURRENT_DATE" | "RIGHT" | "MOD"
 
 FunctionCallConflict:
 	FunctionNameConflict '(' ExpressionListOpt ')' 
@@ -2024,6 +2239,142 @@ FunctionCallConflict:
 		$$ = &ast.FuncCallExpr{FnName: $1.(string)}
 	}
 
+/*******************************************************************
+ *
+ *  Window Functions
+ *
+ *  Example:
+ *	SUM(c2) OVER (PARTITION BY c1 ORDER BY c3 ROWS BETWEEN 1 PRECEDING AND CURRENT ROW)
+ *  See: https://dev.mysql.com/doc/refman/8.0/en/window-functions.html
+ *******************************************************************/
+FunctionCallWindow:
+	FunctionCallAgg OverClause
+	{
+		x := $1.(*ast.AggregateFuncExpr)
+		$$ = &ast.WindowFuncExpr{F: x.F, Args: x.Args, Distinct: x.Distinct, Spec: $2.(*ast.WindowSpec)}
+	}
+|	"CUME_DIST" '(' ')' OverClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1.(string), Spec: $4.(*ast.WindowSpec)}
+	}
+|	"DENSE_RANK" '(' ')' OverClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1.(string), Spec: $4.(*ast.WindowSpec)}
+	}
+|	"PERCENT_RANK" '(' ')' OverClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1.(string), Spec: $4.(*ast.WindowSpec)}
+	}
+|	"RANK" '(' ')' OverClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1.(string), Spec: $4.(*ast.WindowSpec)}
+	}
+|	"ROW_NUMBER" '(' ')' OverClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1.(string), Spec: $4.(*ast.WindowSpec)}
+	}
+|	"NTILE" '(' Expression ')' OverClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}, Spec: $5.(*ast.WindowSpec)}
+	}
+|	"FIRST_VALUE" '(' Expression ')' OverClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}, Spec: $5.(*ast.WindowSpec)}
+	}
+|	"LAST_VALUE" '(' Expression ')' OverClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}, Spec: $5.(*ast.WindowSpec)}
+	}
+|	"NTH_VALUE" '(' Expression ',' Expression ')' OverClause
+	{
+		args := []ast.ExprNode{$3.(ast.ExprNode), $5.(ast.ExprNode)}
+		$$ = &ast.WindowFuncExpr{F: $1.(string), Args: args, Spec: $7.(*ast.WindowSpec)}
+	}
+|	"LAG" '(' ExpressionList ')' OverClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1.(string), Args: $3.([]ast.ExprNode), Spec: $5.(*ast.WindowSpec)}
+	}
+|	"LEAD" '(' ExpressionList ')' OverClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1.(string), Args: $3.([]ast.ExprNode), Spec: $5.(*ast.WindowSpec)}
+	}
+
+OverClause:
+	"OVER" '(' WindowSpec ')'
+	{
+		$$ = $3
+	}
+
+WindowSpec:
+	PartitionByOpt OrderByOptional FrameClauseOpt
+	{
+		spec := &ast.WindowSpec{}
+		if $1 != nil {
+			spec.PartitionBy = $1.([]*ast.ByItem)
+		}
+		if $2 != nil {
+			spec.OrderBy = $2.(*ast.OrderByClause)
+		}
+		if $3 != nil {
+			spec.Frame = $3.(*ast.FrameClause)
+		}
+		$$ = spec
+	}
+
+PartitionByOpt:
+	{
+		$$ = nil
+	}
+|	"PARTITION" "BY" ByList
+	{
+		$$ = $3
+	}
+
+FrameClauseOpt:
+	{
+		$$ = nil
+	}
+|	FrameUnit FrameBound
+	{
+		$$ = &ast.FrameClause{Type: $1.(ast.FrameType), Start: $2.(*ast.FrameBound), End: &ast.FrameBound{Type: ast.CurrentRow}}
+	}
+|	FrameUnit "BETWEEN" FrameBound "AND" FrameBound
+	{
+		$$ = &ast.FrameClause{Type: $1.(ast.FrameType), Start: $3.(*ast.FrameBound), End: $5.(*ast.FrameBound)}
+	}
+
+FrameUnit:
+	"ROWS"
+	{
+		$$ = ast.Rows
+	}
+|	"RANGE"
+	{
+		$$ = ast.Ranges
+	}
+
+FrameBound:
+	"UNBOUNDED" "PRECEDING"
+	{
+		$$ = &ast.FrameBound{Type: ast.Preceding, UnBounded: true}
+	}
+|	"UNBOUNDED" "FOLLOWING"
+	{
+		$$ = &ast.FrameBound{Type: ast.Following, UnBounded: true}
+	}
+|	"CURRENT" "ROW"
+	{
+		$$ = &ast.FrameBound{Type: ast.CurrentRow}
+	}
+|	NumLiteral "PRECEDING"
+	{
+		$$ = &ast.FrameBound{Type: ast.Preceding, Expr: ast.NewValueExpr($1)}
+	}
+|	NumLiteral "FOLLOWING"
+	{
+		$$ = &ast.FrameBound{Type: ast.Following, Expr: ast.NewValueExpr($1)}
+	}
+
 DistinctOpt:
 	{
 		$$ = false
@@ -2079,10 +2430,22 @@ FunctionCallKeyword:
 			FunctionType: ast.CastConvertFunction,
 		}	
 	}
+|	"CHAR" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
 |	"DATE" '(' Expression ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
 	}
+|	"FORMAT" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"TRUNCATE" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
 |	"USER" '(' ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string)}
@@ -2106,6 +2469,10 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
 	}
+|	"CONVERT_TZ" '(' Expression ',' Expression ',' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode), $5.(ast.ExprNode), $7.(ast.ExprNode)}}
+	}
 |	"CURDATE" '(' ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string)}
@@ -2122,6 +2489,58 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
 	}
+|	"ACOS" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"ASCII" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"ASIN" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"ATAN" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"ATAN2" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"CEIL" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"CEILING" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"CHAR_LENGTH" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"CHARACTER_LENGTH" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"CONV" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"COS" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"COT" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"CRC32" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
 |	"CONCAT" '(' ExpressionList ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
@@ -2130,6 +2549,18 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
 	}
+|	"DEGREES" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"DATE_FORMAT" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"DATEDIFF" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
 |	"DAY" '(' Expression ')'
 	{
 		$$ =  &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
@@ -2138,6 +2569,10 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
 	}
+|	"DAYNAME" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
 |	"DAYOFMONTH" '(' Expression ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
@@ -2164,6 +2599,14 @@ FunctionCallNonKeyword:
 			DateArithInterval: $5.(ast.DateArithInterval),
 		}
 	}
+|	"ELT" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"EXP" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
 |	"EXTRACT" '(' TimeUnit "FROM" Expression ')'
 	{
 		$$ = &ast.FuncExtractExpr{
@@ -2171,10 +2614,34 @@ FunctionCallNonKeyword:
 			Date: $5.(ast.ExprNode),
 		}
 	}
+|	"FIELD" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"FIND_IN_SET" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"FLOOR" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
 |	"FOUND_ROWS" '(' ')'
 	{
 		$$ =  &ast.FuncCallExpr{FnName: $1.(string)}
 	}
+|	"FROM_UNIXTIME" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"GREATEST" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"HEX" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
 |	"HOUR" '(' Expression ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
@@ -2183,10 +2650,70 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
 	}
+|	"INSTR" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_ARRAY" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_CONTAINS" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_EXTRACT" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_OBJECT" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_REMOVE" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_SET" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_TYPE" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"JSON_UNQUOTE" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"LAST_DAY" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"LEAST" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
 |	"LENGTH" '(' Expression ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
 	}
+|	"LN" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"LOG" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"LOG10" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"LOG2" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
 |	"LOCATE" '(' Expression ',' Expression ')'
 	{
 		$$ = &ast.FuncLocateExpr{
@@ -2206,6 +2733,18 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
 	}
+|	"LPAD" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"LTRIM" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"MAKEDATE" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
 |	"MICROSECOND" '(' Expression ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
@@ -2218,6 +2757,10 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
 	}
+|	"MONTHNAME" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
 |	"NOW" '(' ExpressionOpt ')'
 	{
 		args := []ast.ExprNode{}
@@ -2230,6 +2773,22 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
 	}
+|	"PI" '(' ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string)}
+	}
+|	"POW" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"POWER" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"RADIANS" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
 |	"RAND" '(' ExpressionOpt ')'
 	{
 
@@ -2244,10 +2803,50 @@ FunctionCallNonKeyword:
 		args := []ast.ExprNode{$3.(ast.ExprNode), $5.(ast.ExprNode), $7.(ast.ExprNode)}
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: args}
 	}
+|	"REVERSE" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"ROUND" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"RPAD" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"RTRIM" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
 |	"SECOND" '(' Expression ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
 	}
+|	"SIGN" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"SIN" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"SQRT" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"SPACE" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"STRCMP" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"STR_TO_DATE" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
 |	"SUBSTRING" '(' Expression ',' Expression ')'
 	{
 		$$ = &ast.FuncSubstringExpr{
@@ -2294,6 +2893,28 @@ FunctionCallNonKeyword:
 		}
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: args}
 	}
+|	"TAN" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"TIME_FORMAT" '(' ExpressionList ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: $3.([]ast.ExprNode)}
+	}
+|	"TIMESTAMPADD" '(' TimeUnit ',' Expression ',' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{
+			FnName: $1.(string),
+			Args:   []ast.ExprNode{ast.NewValueExpr($3), $5.(ast.ExprNode), $7.(ast.ExprNode)},
+		}
+	}
+|	"TIMESTAMPDIFF" '(' TimeUnit ',' Expression ',' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{
+			FnName: $1.(string),
+			Args:   []ast.ExprNode{ast.NewValueExpr($3), $5.(ast.ExprNode), $7.(ast.ExprNode)},
+		}
+	}
 |	"TRIM" '(' Expression ')'
 	{
 		$$ = &ast.FuncTrimExpr{
@@ -2322,6 +2943,18 @@ FunctionCallNonKeyword:
 			Direction: $3.(ast.TrimDirectionType),
 		}	
 	}
+|	"UNHEX" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"UNIX_TIMESTAMP" '(' ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string)}
+	}
+|	"UNIX_TIMESTAMP" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
 |	"UPPER" '(' Expression ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: $1.(string), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
@@ -2866,9 +3499,9 @@ TableRef:
 	}
 
 TableFactor:
-	TableName TableAsNameOpt
+	TableName TableAsNameOpt IndexHintListOpt
 	{
-		$$ = &ast.TableSource{Source: $1.(*ast.TableName), AsName: $2.(model.CIStr)}
+		$$ = &ast.TableSource{Source: $1.(*ast.TableName), AsName: $2.(model.CIStr), IndexHints: $3.([]*ast.IndexHint)}
 	}
 |	'(' SelectStmt ')' TableAsName
 	{
@@ -2882,11 +3515,86 @@ TableFactor:
 	{
 		$$ = &ast.TableSource{Source: $2.(*ast.UnionStmt), AsName: $4.(model.CIStr)}
 	}
+|	'(' WithSelectStmt ')' TableAsName
+	{
+		if st, ok := $2.(*ast.SelectStmt); ok {
+			l := yylex.(*lexer)
+			endOffset := l.endOffset(yyS[yypt-1].offset)
+			l.SetLastSelectFieldText(st, endOffset)
+		}
+		$$ = &ast.TableSource{Source: $2.(ast.ResultSetNode), AsName: $4.(model.CIStr)}
+	}
 |	'(' TableRefs ')'
 	{
 		$$ = $2
 	}
 
+IndexHintListOpt:
+	{
+		$$ = []*ast.IndexHint(nil)
+	}
+|	IndexHintList
+	{
+		$$ = $1
+	}
+
+IndexHintList:
+	IndexHint
+	{
+		$$ = []*ast.IndexHint{$1.(*ast.IndexHint)}
+	}
+|	IndexHintList IndexHint
+	{
+		$$ = append($1.([]*ast.IndexHint), $2.(*ast.IndexHint))
+	}
+
+IndexHint:
+	IndexHintType KeyOrIndex '(' IndexNameListOpt ')'
+	{
+		$$ = &ast.IndexHint{HintType: $1.(ast.IndexHintType), IndexNames: $4.([]model.CIStr)}
+	}
+
+IndexHintType:
+	"USE"
+	{
+		$$ = ast.HintUse
+	}
+|	"IGNORE"
+	{
+		$$ = ast.HintIgnore
+	}
+|	"FORCE"
+	{
+		$$ = ast.HintForce
+	}
+
+IndexNameListOpt:
+	{
+		$$ = []model.CIStr{}
+	}
+|	IndexNameList
+	{
+		$$ = $1
+	}
+
+IndexNameList:
+	Identifier
+	{
+		$$ = []model.CIStr{model.NewCIStr($1.(string))}
+	}
+|	"PRIMARY"
+	{
+		$$ = []model.CIStr{model.NewCIStr("PRIMARY")}
+	}
+|	IndexNameList ',' Identifier
+	{
+		$$ = append($1.([]model.CIStr), model.NewCIStr($3.(string)))
+	}
+|	IndexNameList ',' "PRIMARY"
+	{
+		$$ = append($1.([]model.CIStr), model.NewCIStr("PRIMARY"))
+	}
+
 TableAsNameOpt:
 	{
 		$$ = model.CIStr{}
@@ -3038,6 +3746,70 @@ SubSelect:
 		s.SetText(src[yyS[yypt-1].offset-1:yyS[yypt].offset-1])
 		$$ = &ast.SubqueryExpr{Query: s}
 	}
+|	'(' WithSelectStmt ')'
+	{
+		s := $2.(ast.ResultSetNode)
+		l := yylex.(*lexer)
+		if st, ok := s.(*ast.SelectStmt); ok {
+			endOffset := l.endOffset(yyS[yypt].offset)
+			l.SetLastSelectFieldText(st, endOffset)
+		}
+		// See the implemention of yyParse function
+		s.SetText(l.src[yyS[yypt-1].offset-1:yyS[yypt].offset-1])
+		$$ = &ast.SubqueryExpr{Query: s}
+	}
+
+/*******************************************************************
+ *
+ *  WITH Clause
+ *
+ *  Example:
+ *	WITH RECURSIVE cte (n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM cte WHERE n < 5) SELECT * FROM cte
+ *  See: https://dev.mysql.com/doc/refman/8.0/en/with.html
+ *******************************************************************/
+WithSelectStmt:
+	WithClause SelectStmt
+	{
+		st := $2.(*ast.SelectStmt)
+		st.With = $1.(*ast.WithClause)
+		$$ = st
+	}
+|	WithClause UnionStmt
+	{
+		st := $2.(*ast.UnionStmt)
+		st.With = $1.(*ast.WithClause)
+		$$ = st
+	}
+
+WithClause:
+	"WITH" WithList
+	{
+		$$ = &ast.WithClause{CTEs: $2.([]*ast.CommonTableExpression)}
+	}
+|	"WITH" "RECURSIVE" WithList
+	{
+		$$ = &ast.WithClause{IsRecursive: true, CTEs: $3.([]*ast.CommonTableExpression)}
+	}
+
+WithList:
+	CommonTableExpr
+	{
+		$$ = []*ast.CommonTableExpression{$1.(*ast.CommonTableExpression)}
+	}
+|	WithList ',' CommonTableExpr
+	{
+		$$ = append($1.([]*ast.CommonTableExpression), $3.(*ast.CommonTableExpression))
+	}
+
+CommonTableExpr:
+	Identifier ViewColumnListOpt "AS" SubSelect
+	{
+		$$ = &ast.CommonTableExpression{
+			Name:		model.NewCIStr($1.(string)),
+			ColNames:	$2.([]model.CIStr),
+			Query:		$4.(*ast.SubqueryExpr),
+		}
+	}
 
 // See: https://dev.mysql.com/doc/refman/5.7/en/innodb-locking-reads.html
 SelectLockOpt:
@@ -3384,6 +4156,13 @@ ShowStmt:
 			Table:	$4.(*ast.TableName),
 		}
 	}
+|	"SHOW" "CREATE" "VIEW" TableName
+	{
+		$$ = &ast.ShowStmt{
+			Tp:	ast.ShowCreateView,
+			Table:	$4.(*ast.TableName),
+		}
+	}
 |	"SHOW" "GRANTS"
 	{
 		// See: https://dev.mysql.com/doc/refman/5.7/en/show-grants.html
@@ -3412,6 +4191,13 @@ ShowStmt:
 		}
 		$$ = stmt
 	}
+|	"SHOW" "STATS" ShowTableAliasOpt
+	{
+		$$ = &ast.ShowStmt{
+			Tp:	ast.ShowStats,
+			Table:	$3.(*ast.TableName),
+		}
+	}
 
 ShowLikeOrWhereOpt:
 	{
@@ -3474,6 +4260,7 @@ ShowTableAliasOpt:
 Statement:
 	EmptyStmt
 |	AlterTableStmt
+|	AnalyzeTableStmt
 |	BeginTransactionStmt
 |	CommitStmt
 |	DeallocateStmt
@@ -3484,10 +4271,12 @@ Statement:
 |	CreateIndexStmt
 |	CreateTableStmt
 |	CreateUserStmt
+|	CreateViewStmt
 |	DoStmt
 |	DropDatabaseStmt
 |	DropIndexStmt
 |	DropTableStmt
+|	DropViewStmt
 |	GrantStmt
 |	InsertIntoStmt
 |	PreparedStmt
@@ -3495,6 +4284,7 @@ Statement:
 |	ReplaceIntoStmt
 |	SelectStmt
 |	UnionStmt
+|	WithSelectStmt
 |	SetStmt
 |	ShowStmt
 |	TruncateTableStmt
@@ -3511,6 +4301,7 @@ Statement:
 
 ExplainableStmt:
 	SelectStmt
+|	WithSelectStmt
 |	DeleteFromStmt
 |	UpdateStmt
 |	InsertIntoStmt
@@ -3663,6 +4454,19 @@ TruncateTableStmt:
 		$$ = &ast.TruncateTableStmt{Table: $3.(*ast.TableName)}
 	}
 
+/*******************************************************************
+ *
+ *  Analyze Table Statement
+ *
+ *  Example:
+ *	ANALYZE TABLE t1, t2
+ *******************************************************************/
+AnalyzeTableStmt:
+	"ANALYZE" "TABLE" TableNameList
+	{
+		$$ = &ast.AnalyzeTableStmt{TableNames: $3.([]*ast.TableName)}
+	}
+
 /*************************************Type Begin***************************************/
 Type:
 	NumericType
@@ -3677,6 +4481,11 @@ Type:
 	{
 		$$ = $1
 	}
+|	"JSON"
+	{
+		x := types.NewFieldType(mysql.TypeJSON)
+		$$ = x
+	}
 |	"float32"
 	{
 		x := types.NewFieldType($1.(byte))
@@ -40,6 +40,7 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"value", "warnings", "year", "now", "substring", "mode", "any", "some", "user", "identified",
 		"collation", "comment", "avg_row_length", "checksum", "compression", "connection", "key_block_size",
 		"max_rows", "min_rows", "national", "row", "quarter", "escape", "grants", "status", "fields", "triggers",
+		"stats", "format",
 	}
 	for _, kw := range unreservedKws {
 		src := fmt.Sprintf("SELECT %s FROM tbl;", kw)
@@ -252,6 +253,35 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{`SHOW COLUMNS FROM City;`, true},
 		{`SHOW FIELDS FROM City;`, true},
 		{`SHOW TRIGGERS LIKE 't'`, true},
+		{`SHOW STATS FROM t`, true},
+		{`SHOW STATS IN db.t`, true},
+		{`SHOW STATS`, false},
+
+		// For analyze table statement
+		{"ANALYZE TABLE t", true},
+		{"ANALYZE TABLE t1, db.t2", true},
+		{"ANALYZE TABLE", false},
+
+		// For explain analyze statement
+		{"EXPLAIN ANALYZE SELECT * FROM t WHERE c > 1", true},
+		{"DESC ANALYZE SELECT c, COUNT(*) FROM t GROUP BY c", true},
+		{"EXPLAIN ANALYZE DELETE FROM t", false},
+		{"EXPLAIN ANALYZE t", false},
+
+		// For index hints
+		{"SELECT * FROM t USE INDEX (i1) WHERE c > 1", true},
+		{"SELECT * FROM t AS t1 USE KEY (i1, PRIMARY), t2 IGNORE INDEX (i2) FORCE INDEX (i3)", true},
+		{"SELECT * FROM t USE INDEX () JOIN t2 FORCE KEY (i2) ON t.c = t2.c", true},
+		{"UPDATE t IGNORE INDEX (i1) SET c = 1 WHERE c = 2", true},
+		{"SELECT * FROM t USE INDEX", false},
+		{"SELECT * FROM t FORCE INDEX ()", true},
+		{"SELECT * FROM t IGNORE INDEX (i1,)", false},
+
+		// For explain format statement
+		{"EXPLAIN FORMAT=JSON SELECT * FROM t WHERE c > 1", true},
+		{"EXPLAIN FORMAT = 'json' DELETE FROM t WHERE c = 1", true},
+		{"DESC FORMAT=TRADITIONAL UPDATE t SET c = 1", true},
+		{"EXPLAIN FORMAT=JSON t", false},
 
 		// For default value
 		{"CREATE TABLE sbtest (id INTEGER UNSIGNED NOT NULL AUTO_INCREMENT, k integer UNSIGNED DEFAULT '0' NOT NULL, c char(120) DEFAULT '' NOT NULL, pad char(60) DEFAULT '' NOT NULL, PRIMARY KEY  (id) )", true},
@@ -341,6 +371,38 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 
 		{`SELECT REPLACE('www.mysql.com', 'w', 'Ww')`, true},
 
+		{`SELECT LPAD('hi', 5, '?'), RPAD('hi', 5, '?'), LTRIM('  a'), RTRIM('a  ')`, true},
+		{`SELECT RIGHT('abc', 2), REVERSE('abc'), INSTR('foobar', 'bar'), STRCMP('a', 'b')`, true},
+		{`SELECT ASCII('a'), CHAR(77, 121), CHAR_LENGTH('abc'), CHARACTER_LENGTH('abc'), SPACE(3)`, true},
+		{`SELECT HEX('abc'), UNHEX('616263'), FORMAT(1234.5, 2), FORMAT(1234.5, 2, 'en_US')`, true},
+		{`SELECT FIELD('b', 'a', 'b'), FIND_IN_SET('b', 'a,b'), ELT(2, 'a', 'b')`, true},
+		{`SELECT hex, space FROM t`, true},
+
+		{`SELECT ROUND(1.298, 1), ROUND(-1.58), TRUNCATE(1.223, 1), FLOOR(1.5), CEIL(1.5), CEILING(-1.5)`, true},
+		{`SELECT MOD(29, 9), 29 MOD 9, POW(2, 2), POWER(2, -2), SQRT(4), EXP(2), SIGN(-32), PI()`, true},
+		{`SELECT LN(2), LOG(2), LOG(2, 65536), LOG2(65536), LOG10(100), CONV('a', 16, 2), CRC32('MySQL')`, true},
+		{`SELECT SIN(PI()), COS(PI()), TAN(PI()), COT(12), ASIN(0.2), ACOS(1), ATAN(2), ATAN(-2, 2), ATAN2(PI(), 0)`, true},
+		{`SELECT DEGREES(PI()), RADIANS(90), GREATEST(2, 0), LEAST(2, 0)`, true},
+		{`SELECT log, round, sign FROM t`, true},
+
+		{`SELECT DATE_FORMAT('2009-10-04 22:23:00', '%W %M %Y'), TIME_FORMAT('100:00:00', '%H %k %h %I %l')`, true},
+		{`SELECT STR_TO_DATE('01,5,2013', '%d,%m,%Y'), UNIX_TIMESTAMP(), UNIX_TIMESTAMP('2015-11-13 10:20:19')`, true},
+		{`SELECT FROM_UNIXTIME(1447430881), FROM_UNIXTIME(1447430881, '%Y %D %M'), DATEDIFF('2007-12-31', '2007-12-30')`, true},
+		{`SELECT TIMESTAMPDIFF(MONTH, '2003-02-01', '2003-05-01'), TIMESTAMPADD(WEEK, 1, '2003-01-02')`, true},
+		{`SELECT LAST_DAY('2003-02-05'), MAKEDATE(2011, 31), DAYNAME('2007-02-03'), MONTHNAME('2008-02-03')`, true},
+		{`SELECT TIMESTAMPDIFF(FOO, '2003-02-01', '2003-05-01')`, false},
+		{`SELECT dayname, monthname FROM t`, true},
+		{`SELECT CONVERT_TZ('2004-01-01 12:00:00', '+00:00', '+10:00'), CONVERT_TZ('2004-01-01 12:00:00', 'GMT', 'MET')`, true},
+		{`SET time_zone = '+08:00'`, true},
+
+		{`SELECT JSON_EXTRACT('{"a": 1}', '$.a', '$.b'), JSON_TYPE('[1]'), JSON_UNQUOTE('"a"')`, true},
+		{`SELECT JSON_SET('{}', '$.a', 1), JSON_REMOVE('[1, 2]', '$[0]'), JSON_CONTAINS('[1]', '1', '$')`, true},
+		{`SELECT JSON_ARRAY(), JSON_ARRAY(1, 'a'), JSON_OBJECT(), JSON_OBJECT('a', 1)`, true},
+		{`SELECT c->'$.a', t.c->>'$.b' FROM t WHERE c->'$.a' > 1`, true},
+		{`SELECT c->1 FROM t`, false},
+		{`CREATE TABLE t (c JSON)`, true},
+		{`SELECT json FROM t`, true},
+
 		{`SELECT LOCATE('bar', 'foobarbar');`, true},
 		{`SELECT LOCATE('bar', 'foobarbar', 5);`, true},
 
@@ -588,10 +650,30 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"drop tables xxx, yyy", true},
 		{"drop table if exists xxx", true},
 		{"drop table if not exists xxx", false},
+		// For create/drop view
+		{"create view v as select * from t", true},
+		{"create or replace view test.v (a, b) as select c, d from t where c > 1;", true},
+		{"create view v as select 1 union select 2", false},
+		{"create view v", false},
+		{"drop view v", true},
+		{"drop view if exists v, test.w", true},
+		{"show create view v", true},
+		// View is an unreserved keyword.
+		{"create table view (view int)", true},
 	}
 	s.RunTest(c, table)
 }
 
+func (s *testParserSuite) TestCreateViewText(c *C) {
+	l := NewLexer("create view v as select c from t where c > 1 ; select 1")
+	c.Assert(yyParse(l), Equals, 0)
+	stmts := l.Stmts()
+	c.Assert(stmts, HasLen, 2)
+	v, ok := stmts[0].(*ast.CreateViewStmt)
+	c.Assert(ok, IsTrue)
+	c.Assert(v.Select.Text(), Equals, "select c from t where c > 1")
+}
+
 func (s *testParserSuite) TestType(c *C) {
 	table := []testCase{
 		// For time fsp
@@ -681,6 +763,44 @@ func (s *testParserSuite) TestSubquery(c *C) {
 	}
 	s.RunTest(c, table)
 }
+func (s *testParserSuite) TestWith(c *C) {
+	table := []testCase{
+		{"with t as (select 1) select * from t", true},
+		{"with t (a, b) as (select 1, 2), u as (select a from t) select * from t, u", true},
+		{"with recursive t (n) as (select 1 union all select n + 1 from t where n < 5) select * from t", true},
+		{"with t as (select 1) select * from t union select 2", true},
+		{"select * from (with t as (select 1) select * from t) as x", true},
+		{"select (with t as (select 1) select * from t)", true},
+		{"explain with t as (select 1) select * from t", true},
+		{"with t as select 1 select * from t", false},
+		{"with recursive as (select 1) select 1", false},
+		{"with t as (select 1)", false},
+	}
+	s.RunTest(c, table)
+}
+
+func (s *testParserSuite) TestWindowFunction(c *C) {
+	table := []testCase{
+		{"select row_number() over (), rank() over (order by c), dense_rank() over (partition by a, b order by c desc) from t", true},
+		{"select percent_rank() over (order by c), cume_dist() over (order by c), ntile(4) over (order by c) from t", true},
+		{"select lag(c) over (order by c), lead(c, 2, 0) over (partition by a order by c) from t", true},
+		{"select first_value(c) over w, c from t", false},
+		{"select nth_value(c, 2) over (order by c rows between unbounded preceding and unbounded following) from t", true},
+		{"select sum(c) over (partition by a order by b rows between 1 preceding and 1 following) from t", true},
+		{"select count(*) over (order by b range between 2 preceding and current row) from t", true},
+		{"select avg(c) over (rows unbounded preceding), max(c) over (order by b range current row) from t", true},
+		{"select c, sum(c) over (order by c) as s from t order by s", true},
+		{"select sum(c) over () from t", true},
+		{"select rank() from t", false},
+		{"select rank() over from t", false},
+		{"select sum(c) over (rows between a preceding and current row) from t", false},
+		// The names of window functions are not reserved.
+		{"select rank, row_number, lag from t", true},
+		{"select current, preceding, following, unbounded from t", true},
+	}
+	s.RunTest(c, table)
+}
+
 func (s *testParserSuite) TestUnion(c *C) {
 	table := []testCase{
 		{"select c1 from t1 union select c2 from t2", true},
@@ -0,0 +1,327 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/column"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+const (
+	// pseudoRowCount is the row count assumed for a table we know nothing about.
+	pseudoRowCount = 10000
+	// pseudoEqualRate is the divisor of the row count for an equal condition.
+	pseudoEqualRate = 1000
+	// pseudoLessRate is the divisor of the row count for an open range condition.
+	pseudoLessRate = 3
+	// pseudoBetweenRate is the divisor of the row count for a closed range condition.
+	pseudoBetweenRate = 40
+	// selectionFactor is the ratio of rows that pass a filter we can't estimate.
+	selectionFactor = 0.8
+
+	// tableScanFactor is the cost to read a row in a full table scan.
+	tableScanFactor = 1.0
+	// indexScanFactor is the cost to read an index entry.
+	indexScanFactor = 0.5
+	// rowLookupFactor is the cost to fetch a row by its handle from an index entry.
+	rowLookupFactor = 1.0
+)
+
+// estimation is the estimated output row count and execution cost of a plan.
+type estimation struct {
+	rowCount float64
+	cost     float64
+}
+
+// tableStats loads the statistics of table t, it returns nil if the table
+// has not been analyzed or the statistics can't be loaded. The statistics
+// are decoded once for each statistics version in the domain.
+func tableStats(ctx context.Context, t table.Table) *statistics.Table {
+	txn, err := ctx.GetTxn(false)
+	if err != nil || txn == nil {
+		return nil
+	}
+	var st *statistics.Table
+	if do := sessionctx.GetDomain(ctx); do != nil {
+		st, err = do.StatsCache().Load(txn, t.TableID())
+	} else {
+		st, err = statistics.Load(txn, t.TableID())
+	}
+	if err != nil {
+		log.Warnf("load statistics of table %s failed: %v", t.TableName(), err)
+		return nil
+	}
+	return st
+}
+
+// tableRowCount returns the estimated row count of table t.
+func tableRowCount(ctx context.Context, t table.Table) float64 {
+	if st := tableStats(ctx, t); st != nil {
+		return float64(st.Count)
+	}
+	return pseudoRowCount
+}
+
+// estimate estimates the output row count and cost of plan p.
+// Plans we can't reason about are treated like a full table scan.
+func estimate(ctx context.Context, p plan.Plan) estimation {
+	switch x := p.(type) {
+	case *NullPlan:
+		return estimation{}
+	case *TableDefaultPlan:
+		rows := tableRowCount(ctx, x.T)
+		return estimation{rowCount: rows, cost: rows * tableScanFactor}
+	case *indexPlan:
+		rows := x.estimateRowCount(ctx)
+		if x.covering {
+			return estimation{rowCount: rows, cost: rows * indexScanFactor}
+		}
+		return estimation{rowCount: rows, cost: rows * (indexScanFactor + rowLookupFactor)}
+	case *RowStackFromPlan:
+		return estimate(ctx, x.Src)
+	case *analyzePlan:
+		return estimate(ctx, x.Plan)
+	case *reorderedJoinPlan:
+		return estimate(ctx, x.Src)
+	case *ViewFieldsPlan:
+		return estimate(ctx, x.Src)
+	case *FilterDefaultPlan:
+		est := estimate(ctx, x.Plan)
+		est.rowCount *= selectionFactor
+		return est
+	case *SemiJoinPlan:
+		est := estimate(ctx, x.Src)
+		est.rowCount *= selectionFactor
+		return est
+	case *JoinPlan:
+		left := estimate(ctx, x.Left)
+		if x.Right == nil {
+			return left
+		}
+		right := estimate(ctx, x.Right)
+		// The right side is scanned once for every row from the left side.
+		return estimation{
+			rowCount: left.rowCount * right.rowCount,
+			cost:     left.cost + left.rowCount*right.cost,
+		}
+	}
+	return estimation{rowCount: pseudoRowCount, cost: pseudoRowCount * tableScanFactor}
+}
+
+// estimateRowCount returns the estimated number of rows in all spans of the index plan.
+// The histogram of the index is used if the table has been analyzed. For a multi-column
+// index, the histogram of the span column is used instead, and the selectivities of
+// the equal values of the leading columns are multiplied as if they are independent.
+func (r *indexPlan) estimateRowCount(ctx context.Context) float64 {
+	total := float64(pseudoRowCount)
+	var hist *statistics.Column
+	st := tableStats(ctx, r.src)
+	if st != nil {
+		total = float64(st.Count)
+		if len(r.cols) > 1 {
+			hist = st.Column(r.col.Name.O)
+		} else {
+			hist = st.Index(r.idxName)
+		}
+	}
+	var rows float64
+	for _, span := range r.spans {
+		n, ok := r.statsSpanRowCount(hist, span)
+		if !ok {
+			n = r.estimateSpanRowCount(span, total)
+		}
+		rows += n
+	}
+	for i, v := range r.eqVals {
+		rows *= r.equalSelectivity(st, r.cols[i], v, total)
+	}
+	if rows > total {
+		rows = total
+	}
+	return rows
+}
+
+// equalSelectivity returns the ratio of rows whose column col equals to v.
+func (r *indexPlan) equalSelectivity(st *statistics.Table, col *column.Col, v interface{}, total float64) float64 {
+	if st == nil || total == 0 {
+		return 1.0 / pseudoEqualRate
+	}
+	hist := st.Column(col.Name.O)
+	if hist == nil {
+		return 1.0 / pseudoEqualRate
+	}
+	b, err := codec.EncodeKey(v)
+	if err != nil {
+		return 1.0 / pseudoEqualRate
+	}
+	return hist.EqualRowCount(b) / total
+}
+
+func (r *indexPlan) estimateSpanRowCount(span *indexSpan, total float64) float64 {
+	lowUnbounded := span.lowVal == minNotNullVal
+	highUnbounded := span.highVal == maxVal
+	switch {
+	case indexCompare(span.lowVal, span.highVal) == 0 && !span.lowExclude && !span.highExclude:
+		if r.unique && span.lowVal != nil && len(r.eqVals)+1 >= len(r.cols) {
+			return 1
+		}
+		return total / pseudoEqualRate
+	case lowUnbounded && highUnbounded:
+		return total
+	case lowUnbounded || highUnbounded:
+		return total / pseudoLessRate
+	default:
+		return total / pseudoBetweenRate
+	}
+}
+
+// statsSpanRowCount estimates the row count of the span with the histogram,
+// ok is false if the histogram is nil or the span values can't be encoded.
+func (r *indexPlan) statsSpanRowCount(hist *statistics.Column, span *indexSpan) (n float64, ok bool) {
+	if hist == nil {
+		return 0, false
+	}
+	high, err := r.rowsBefore(hist, span.highVal, !span.highExclude)
+	if err != nil {
+		return 0, false
+	}
+	low, err := r.rowsBefore(hist, span.lowVal, span.lowExclude)
+	if err != nil {
+		return 0, false
+	}
+	if high < low {
+		return 0, true
+	}
+	return high - low, true
+}
+
+// rowsBefore returns the number of rows less than the index value v,
+// or less than or equal to v if inclusive is true.
+// Null values are less than minNotNullVal, and maxVal is greater than any value.
+func (r *indexPlan) rowsBefore(hist *statistics.Column, v interface{}, inclusive bool) (float64, error) {
+	nulls := float64(hist.NullCount)
+	switch v {
+	case nil:
+		if inclusive {
+			return nulls, nil
+		}
+		return 0, nil
+	case minNotNullVal:
+		return nulls, nil
+	case maxVal:
+		return nulls + hist.NotNullCount(), nil
+	}
+	v, err := types.Convert(v, &r.col.FieldType)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	b, err := codec.EncodeKey(v)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	n := nulls + hist.LessRowCount(b)
+	if inclusive {
+		n += hist.EqualRowCount(b)
+	}
+	return n, nil
+}
+
+type accessPathCandidate struct {
+	offset int
+	cost   float64
+}
+
+type byCost []accessPathCandidate
+
+func (c byCost) Len() int           { return len(c) }
+func (c byCost) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c byCost) Less(i, j int) bool { return c[i].cost < c[j].cost }
+
+// ChooseAccessPath filters src with the conjunctive conditions conds, choosing
+// the cheapest access path for it. Each condition is first applied to src alone
+// and the resulting plans are costed, the ones not cheaper than scanning src are
+// dropped unless src is a table with a FORCE INDEX hint. Then the conditions are
+// applied to src in ascending order of cost, so the most selective index is
+// picked and the following conditions can only narrow it down further.
+// It returns the new plan and the conditions that were not used by it.
+func ChooseAccessPath(ctx context.Context, src plan.Plan, conds []expression.Expression) (plan.Plan, []expression.Expression, error) {
+	srcCost := estimate(ctx, src).cost
+	t, ok := src.(*TableDefaultPlan)
+	force := ok && t.forceIndex()
+	var candidates []accessPathCandidate
+	for i, cond := range conds {
+		p, filtered, err := src.Filter(ctx, cond)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if !filtered {
+			continue
+		}
+		if cost := estimate(ctx, p).cost; force || cost < srcCost {
+			candidates = append(candidates, accessPathCandidate{offset: i, cost: cost})
+		}
+	}
+	sort.Stable(byCost(candidates))
+
+	used := make([]bool, len(conds))
+	p := src
+	for _, cand := range candidates {
+		p2, filtered, err := p.Filter(ctx, conds[cand.offset])
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if filtered {
+			p = p2
+			used[cand.offset] = true
+		}
+	}
+	// A condition which can't be used alone may be used after others,
+	// e.g. a condition on the second column of a multi-column index.
+	for changed := len(candidates) > 0; changed; {
+		changed = false
+		for i, cond := range conds {
+			if used[i] {
+				continue
+			}
+			p2, filtered, err := p.Filter(ctx, cond)
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			if filtered {
+				p = p2
+				used[i] = true
+				changed = true
+			}
+		}
+	}
+
+	var rest []expression.Expression
+	for i, cond := range conds {
+		if !used[i] {
+			rest = append(rest, cond)
+		}
+	}
+	return p, rest, nil
+}
@@ -0,0 +1,737 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans_test
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/column"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/plan/plans"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/format"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/types"
+)
+
+type testCostSuite struct {
+	tbl table.Table
+}
+
+var _ = Suite(&testCostSuite{})
+
+func (s *testCostSuite) SetUpSuite(c *C) {
+	var cols []*column.Col
+	for i, name := range []string{"c1", "c2", "c3"} {
+		cols = append(cols, &column.Col{
+			ColumnInfo: model.ColumnInfo{
+				ID:        int64(i),
+				Name:      model.NewCIStr(name),
+				Offset:    i,
+				FieldType: *types.NewFieldType(mysql.TypeLonglong),
+			},
+		})
+	}
+	s.tbl = tables.NewTable(3, "t", cols, &simpleAllocator{})
+	for _, idx := range []struct {
+		name   string
+		offset int
+		unique bool
+	}{
+		{"c1", 0, false},
+		{"c2", 1, true},
+	} {
+		s.tbl.AddIndex(&column.IndexedCol{
+			IndexInfo: model.IndexInfo{
+				Name:    model.NewCIStr(idx.name),
+				Table:   model.NewCIStr("t"),
+				Columns: []*model.IndexColumn{{Name: model.NewCIStr(idx.name), Offset: idx.offset}},
+				Unique:  idx.unique,
+			},
+			X: kv.NewKVIndex("i", idx.name, idx.unique),
+		})
+	}
+}
+
+func (s *testCostSuite) newTablePlan() plan.Plan {
+	var fields []*field.ResultField
+	for _, col := range s.tbl.Cols() {
+		fields = append(fields, field.ColToResultField(col, "t"))
+	}
+	return &plans.TableDefaultPlan{T: s.tbl, Fields: fields}
+}
+
+func newCompare(op opcode.Op, name string, val interface{}) expression.Expression {
+	return &expression.BinaryOperation{
+		Op: op,
+		L:  &expression.Ident{CIStr: model.NewCIStr(name)},
+		R:  expression.Value{Val: val},
+	}
+}
+
+func explainPlan(p plan.Plan) string {
+	var buf bytes.Buffer
+	p.Explain(format.IndentFormatter(&buf, "\t"))
+	return buf.String()
+}
+
+func (s *testCostSuite) TestChooseAccessPath(c *C) {
+	ctx := mock.NewContext()
+
+	// The unique point lookup on c2 is cheaper than the range on c1.
+	c1Range := newCompare(opcode.GT, "c1", 5)
+	c2Equal := newCompare(opcode.EQ, "c2", 3)
+	p, rest, err := plans.ChooseAccessPath(ctx, s.newTablePlan(), []expression.Expression{c1Range, c2Equal})
+	c.Assert(err, IsNil)
+	c.Assert(explainPlan(p), Matches, `(?s).*using index "c2".*`)
+	c.Assert(rest, DeepEquals, []expression.Expression{c1Range})
+
+	// An equal condition on a non-unique index beats an open range on a unique one.
+	c1Equal := newCompare(opcode.EQ, "c1", 5)
+	c2Range := newCompare(opcode.LT, "c2", 3)
+	p, rest, err = plans.ChooseAccessPath(ctx, s.newTablePlan(), []expression.Expression{c2Range, c1Equal})
+	c.Assert(err, IsNil)
+	c.Assert(explainPlan(p), Matches, `(?s).*using index "c1".*`)
+	c.Assert(rest, DeepEquals, []expression.Expression{c2Range})
+
+	// Conditions on the chosen index column narrow it down further.
+	c1Upper := newCompare(opcode.LT, "c1", 10)
+	p, rest, err = plans.ChooseAccessPath(ctx, s.newTablePlan(), []expression.Expression{c1Range, c2Range, c1Upper})
+	c.Assert(err, IsNil)
+	c.Assert(explainPlan(p), Matches, `(?s).*using index "c1" where c1 in \(5,10\).*`)
+	c.Assert(rest, DeepEquals, []expression.Expression{c2Range})
+
+	// No index can be used for c3.
+	c3Equal := newCompare(opcode.EQ, "c3", 1)
+	src := s.newTablePlan()
+	p, rest, err = plans.ChooseAccessPath(ctx, src, []expression.Expression{c3Equal})
+	c.Assert(err, IsNil)
+	c.Assert(p, Equals, src)
+	c.Assert(rest, HasLen, 1)
+}
+
+// txnContext is a mocked context with a transaction.
+type txnContext struct {
+	*mock.Context
+	txn kv.Transaction
+}
+
+func (ctx *txnContext) GetTxn(forceNew bool) (kv.Transaction, error) {
+	return ctx.txn, nil
+}
+
+func (s *testCostSuite) TestChooseAccessPathWithStats(c *C) {
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	ctx := &txnContext{Context: mock.NewContext(), txn: txn}
+
+	encode := func(v interface{}) []byte {
+		b, err := codec.EncodeKey(v)
+		c.Assert(err, IsNil)
+		return b
+	}
+	// Most rows have c1 = 5, and only a few rows have c2 < 3.
+	st := &statistics.Table{
+		TableID: s.tbl.TableID(),
+		Count:   1000,
+		Indices: []*statistics.Column{
+			{
+				Name:    "c1",
+				NDV:     2,
+				Buckets: []statistics.Bucket{{Count: 900, Value: encode(int64(5)), Repeats: 900}, {Count: 1000, Value: encode(int64(6)), Repeats: 100}},
+			},
+			{
+				Name:    "c2",
+				NDV:     1000,
+				Buckets: []statistics.Bucket{{Count: 10, Value: encode(int64(9)), Repeats: 1}, {Count: 1000, Value: encode(int64(999)), Repeats: 1}},
+			},
+		},
+	}
+	c.Assert(statistics.Save(txn, st), IsNil)
+
+	c1Equal := newCompare(opcode.EQ, "c1", 5)
+	c2Range := newCompare(opcode.LT, "c2", 3)
+	p, rest, err := plans.ChooseAccessPath(ctx, s.newTablePlan(), []expression.Expression{c2Range, c1Equal})
+	c.Assert(err, IsNil)
+	c.Assert(explainPlan(p), Matches, `(?s).*using index "c2".*`)
+	c.Assert(rest, DeepEquals, []expression.Expression{c1Equal})
+
+	// Reading most rows by the index c1 costs more than scanning the table.
+	src := s.newTablePlan()
+	p, rest, err = plans.ChooseAccessPath(ctx, src, []expression.Expression{c1Equal})
+	c.Assert(err, IsNil)
+	c.Assert(p, Equals, src)
+	c.Assert(rest, DeepEquals, []expression.Expression{c1Equal})
+
+	// USE INDEX still prefers the cheaper table scan, but FORCE INDEX doesn't.
+	hint := &plans.IndexHint{Type: plans.UseIndex, Names: []model.CIStr{model.NewCIStr("c1")}}
+	src.(*plans.TableDefaultPlan).IndexHints = []*plans.IndexHint{hint}
+	p, rest, err = plans.ChooseAccessPath(ctx, src, []expression.Expression{c1Equal})
+	c.Assert(err, IsNil)
+	c.Assert(p, Equals, src)
+	c.Assert(rest, HasLen, 1)
+	hint.Type = plans.ForceIndex
+	p, rest, err = plans.ChooseAccessPath(ctx, src, []expression.Expression{c1Equal})
+	c.Assert(err, IsNil)
+	c.Assert(explainPlan(p), Matches, `(?s).*using index "c1".*`)
+	c.Assert(rest, HasLen, 0)
+}
+
+// newCompositeTable creates a table with a multi-column index "tc" on (tenant, created).
+func newCompositeTable(c *C, ctx *txnContext, id int64, unique bool) *plans.TableDefaultPlan {
+	var cols []*column.Col
+	for j, name := range []string{"tenant", "created", "v"} {
+		cols = append(cols, &column.Col{
+			ColumnInfo: model.ColumnInfo{
+				ID:        int64(j),
+				Name:      model.NewCIStr(name),
+				Offset:    j,
+				FieldType: *types.NewFieldType(mysql.TypeLonglong),
+			},
+		})
+	}
+	tbl := tables.NewTable(id, "t", cols, &simpleAllocator{})
+	tbl.AddIndex(&column.IndexedCol{
+		IndexInfo: model.IndexInfo{
+			Name:  model.NewCIStr("tc"),
+			Table: model.NewCIStr("t"),
+			Columns: []*model.IndexColumn{
+				{Name: model.NewCIStr("tenant"), Offset: 0},
+				{Name: model.NewCIStr("created"), Offset: 1},
+			},
+			Unique: unique,
+		},
+		X: kv.NewKVIndex(fmt.Sprintf("i%d", id), "tc", unique),
+	})
+	for _, d := range [][]interface{}{
+		{int64(1), int64(-5), int64(1)},
+		{int64(1), nil, int64(2)},
+		{int64(1), int64(3), int64(3)},
+		{int64(1), int64(7), int64(4)},
+		{int64(2), int64(1), int64(5)},
+		{int64(2), int64(4), int64(6)},
+		{int64(0), int64(4), int64(7)},
+	} {
+		_, err := tbl.AddRecord(ctx, d)
+		c.Assert(err, IsNil)
+	}
+	var fields []*field.ResultField
+	for _, col := range tbl.Cols() {
+		fields = append(fields, field.ColToResultField(col, "t"))
+	}
+	return &plans.TableDefaultPlan{T: tbl, Fields: fields}
+}
+
+func fetchRows(c *C, ctx context.Context, p plan.Plan) []string {
+	var result []string
+	for {
+		row, err := p.Next(ctx)
+		c.Assert(err, IsNil)
+		if row == nil {
+			break
+		}
+		c.Assert(row.RowKeys, HasLen, 1)
+		result = append(result, fmt.Sprint(row.Data))
+	}
+	c.Assert(p.Close(), IsNil)
+	return result
+}
+
+func (s *testCostSuite) TestCompositeIndex(c *C) {
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	ctx := &txnContext{Context: mock.NewContext(), txn: txn}
+	variable.BindSessionVars(ctx)
+
+	for i, unique := range []bool{false, true} {
+		src := newCompositeTable(c, ctx, int64(20+i), unique)
+		testcases := []struct {
+			conds   []expression.Expression
+			explain string
+			result  []string
+		}{
+			{
+				[]expression.Expression{newCompare(opcode.GT, "created", 2), newCompare(opcode.EQ, "tenant", 1)},
+				`using index "tc" where tenant = 1 and created in \(2,\+inf\]`,
+				[]string{"[1 3 3]", "[1 7 4]"},
+			},
+			{
+				[]expression.Expression{newCompare(opcode.EQ, "tenant", 1), newCompare(opcode.LE, "created", 3)},
+				`using index "tc" where tenant = 1 and created in \[-inf,3\]`,
+				[]string{"[1 -5 1]", "[1 3 3]"},
+			},
+			{
+				[]expression.Expression{newCompare(opcode.EQ, "tenant", 2), newCompare(opcode.EQ, "created", 4)},
+				`using index "tc" where tenant = 2 and created in \[4,4\]`,
+				[]string{"[2 4 6]"},
+			},
+			{
+				[]expression.Expression{newCompare(opcode.EQ, "tenant", 2)},
+				`using index "tc" where tenant in \[2,2\]`,
+				[]string{"[2 1 5]", "[2 4 6]"},
+			},
+		}
+		for _, tc := range testcases {
+			p, rest, err := plans.ChooseAccessPath(ctx, src, tc.conds)
+			c.Assert(err, IsNil)
+			c.Assert(rest, HasLen, 0)
+			c.Assert(explainPlan(p), Matches, "(?s).*"+tc.explain+".*")
+			result := fetchRows(c, ctx, p)
+			c.Assert(result, DeepEquals, tc.result, Commentf("unique %v, %s", unique, tc.explain))
+		}
+	}
+}
+
+func (s *testCostSuite) TestIndexHints(c *C) {
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	ctx := &txnContext{Context: mock.NewContext(), txn: txn}
+	variable.BindSessionVars(ctx)
+
+	src := newCompositeTable(c, ctx, 55, false)
+	conds := []expression.Expression{newCompare(opcode.EQ, "tenant", 1)}
+	testcases := []struct {
+		hints    []*plans.IndexHint
+		useIndex bool
+	}{
+		{nil, true},
+		{[]*plans.IndexHint{{Type: plans.UseIndex, Names: []model.CIStr{model.NewCIStr("tc")}}}, true},
+		{[]*plans.IndexHint{{Type: plans.ForceIndex, Names: []model.CIStr{model.NewCIStr("TC")}}}, true},
+		{[]*plans.IndexHint{{Type: plans.UseIndex}}, false},
+		{[]*plans.IndexHint{{Type: plans.IgnoreIndex, Names: []model.CIStr{model.NewCIStr("tc")}}}, false},
+		{[]*plans.IndexHint{
+			{Type: plans.UseIndex, Names: []model.CIStr{model.NewCIStr("tc")}},
+			{Type: plans.IgnoreIndex, Names: []model.CIStr{model.NewCIStr("tc")}},
+		}, false},
+	}
+	for _, tc := range testcases {
+		c.Assert(plans.CheckIndexHints("t", src.T.Indices(), tc.hints), IsNil)
+		src.IndexHints = tc.hints
+		p, rest, err := plans.ChooseAccessPath(ctx, src, conds)
+		c.Assert(err, IsNil)
+		if tc.useIndex {
+			c.Assert(rest, HasLen, 0)
+			c.Assert(explainPlan(p), Matches, `(?s).*using index "tc".*`)
+			c.Assert(fetchRows(c, ctx, p), HasLen, 4)
+		} else {
+			c.Assert(rest, HasLen, 1)
+			c.Assert(p, Equals, src)
+		}
+	}
+
+	hints := []*plans.IndexHint{{Type: plans.UseIndex, Names: []model.CIStr{model.NewCIStr("tc"), model.NewCIStr("x")}}}
+	err = plans.CheckIndexHints("t", src.T.Indices(), hints)
+	c.Assert(plans.ErrUnknownIndex.Equal(err), IsTrue)
+	c.Assert(err, ErrorMatches, ".*Key 'x' doesn't exist in table 't'")
+	c.Assert(hints[0].String(), Equals, "USE INDEX (tc, x)")
+}
+
+func (s *testCostSuite) TestCoveringIndex(c *C) {
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	ctx := &txnContext{Context: mock.NewContext(), txn: txn}
+	variable.BindSessionVars(ctx)
+
+	for i, unique := range []bool{false, true} {
+		src := newCompositeTable(c, ctx, int64(30+i), unique)
+		conds := []expression.Expression{newCompare(opcode.EQ, "tenant", 1), newCompare(opcode.GE, "created", 3)}
+		newPlan := func() plan.Plan {
+			p, _, err := plans.ChooseAccessPath(ctx, src, conds)
+			c.Assert(err, IsNil)
+			return p
+		}
+
+		// Column v is not in the index.
+		p := newPlan()
+		c.Assert(plans.UseCoveringIndex(p, []expression.Expression{newIdent("created"), newIdent("v")}), IsFalse)
+		c.Assert(fetchRows(c, ctx, p), DeepEquals, []string{"[1 3 3]", "[1 7 4]"})
+
+		p = newPlan()
+		filter := &plans.FilterDefaultPlan{Plan: p, Expr: newCompare(opcode.NE, "v", 0)}
+		c.Assert(plans.UseCoveringIndex(filter, []expression.Expression{newIdent("created")}), IsFalse)
+
+		// The values of v are not read from the rows.
+		p = newPlan()
+		c.Assert(plans.UseCoveringIndex(p, []expression.Expression{newIdent("t.created"), newCompare(opcode.GT, "tenant", 0)}), IsTrue)
+		c.Assert(explainPlan(p), Matches, `(?s).*using index "tc" only where tenant = 1 and created in \[3,\+inf\].*`)
+		c.Assert(fetchRows(c, ctx, p), DeepEquals, []string{"[1 3 <nil>]", "[1 7 <nil>]"})
+	}
+}
+
+func (s *testCostSuite) TestTimestampIndex(c *C) {
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	ctx := &txnContext{Context: mock.NewContext(), txn: txn}
+	variable.BindSessionVars(ctx)
+	variable.GetSessionVars(ctx).Systems[variable.TimeZone] = "+08:00"
+
+	ts, err := mysql.ParseTimestamp("2015-01-01 10:00:00")
+	c.Assert(err, IsNil)
+	// The TIMESTAMP values read from the rows and the index entries are the same
+	// in the tables of all the versions.
+	for i, version := range []uint16{model.TableInfoVersion0, model.CurrLatestTableInfoVersion} {
+		tbl := newTimestampTable(int64(34+i), version)
+		h, err := tbl.AddRecord(ctx, []interface{}{int64(1), ts})
+		c.Assert(err, IsNil)
+		checkTimestampRow(c, ctx, tbl, h, ts)
+	}
+}
+
+func (s *testCostSuite) TestTimestampUpgrade(c *C) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+8", 8*3600)
+	defer func() { time.Local = local }()
+
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	ctx := &txnContext{Context: mock.NewContext(), txn: txn}
+	variable.BindSessionVars(ctx)
+	variable.GetSessionVars(ctx).Systems[variable.TimeZone] = mysql.SystemTimeZone
+
+	// The old versions store the instant of a TIMESTAMP value in the row, and
+	// its wall clock in the local time zone in the index key.
+	tbl := newTimestampTable(36, model.TableInfoVersion0)
+	h := int64(1)
+	c.Assert(tbl.LockRow(ctx, h), IsNil)
+	cols := tbl.Cols()
+	c.Assert(tbl.SetColValue(txn, tbl.RecordKey(h, cols[0]), int64(1)), IsNil)
+	instant := mysql.Time{Time: time.Date(2015, 1, 1, 2, 0, 0, 0, time.UTC), Type: mysql.TypeTimestamp}
+	c.Assert(tbl.SetColValue(txn, tbl.RecordKey(h, cols[1]), instant), IsNil)
+	wallClock := mysql.Time{Time: time.Date(2015, 1, 1, 10, 0, 0, 0, time.UTC), Type: mysql.TypeTimestamp}
+	c.Assert(tbl.Indices()[0].X.Create(txn, []interface{}{wallClock}, h), IsNil)
+
+	ts, err := mysql.ParseTimestamp("2015-01-01 10:00:00")
+	c.Assert(err, IsNil)
+	checkTimestampRow(c, ctx, tbl, h, ts)
+}
+
+// newTimestampTable returns a table of the version with an id column and a
+// TIMESTAMP column ts, which is indexed.
+func newTimestampTable(id int64, version uint16) *tables.Table {
+	tbInfo := &model.TableInfo{ID: id, Name: model.NewCIStr("t"), Version: version}
+	for j, tp := range []byte{mysql.TypeLonglong, mysql.TypeTimestamp} {
+		tbInfo.Columns = append(tbInfo.Columns, &model.ColumnInfo{
+			ID:        int64(j),
+			Name:      model.NewCIStr([]string{"id", "ts"}[j]),
+			Offset:    j,
+			FieldType: *types.NewFieldType(tp),
+		})
+	}
+	tbInfo.Indices = []*model.IndexInfo{{
+		Name:    model.NewCIStr("ts"),
+		Table:   model.NewCIStr("t"),
+		Columns: []*model.IndexColumn{{Name: model.NewCIStr("ts"), Offset: 1}},
+	}}
+	return tables.TableFromMeta(&simpleAllocator{}, tbInfo).(*tables.Table)
+}
+
+// checkTimestampRow checks that the TIMESTAMP value of the row h read from the
+// row and from the index are both ts.
+func checkTimestampRow(c *C, ctx context.Context, tbl table.Table, h int64, ts mysql.Time) {
+	row, err := tbl.Row(ctx, h)
+	c.Assert(err, IsNil)
+	c.Assert(row[1].(mysql.Time).String(), Equals, ts.String())
+
+	var fields []*field.ResultField
+	for _, col := range tbl.Cols() {
+		fields = append(fields, field.ColToResultField(col, "t"))
+	}
+	src := &plans.TableDefaultPlan{T: tbl, Fields: fields}
+	p, _, err := plans.ChooseAccessPath(ctx, src, []expression.Expression{newCompare(opcode.EQ, "ts", ts)})
+	c.Assert(err, IsNil)
+	c.Assert(plans.UseCoveringIndex(p, []expression.Expression{newIdent("ts")}), IsTrue)
+	r, err := p.Next(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(r, NotNil)
+	v, ok := r.Data[1].(mysql.Time)
+	c.Assert(ok, IsTrue)
+	c.Assert(v.String(), Equals, ts.String())
+	c.Assert(p.Close(), IsNil)
+}
+
+func newIdent(name string) expression.Expression {
+	return &expression.Ident{CIStr: model.NewCIStr(name)}
+}
+
+func (s *testCostSuite) TestIndexOrder(c *C) {
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	ctx := &txnContext{Context: mock.NewContext(), txn: txn}
+	variable.BindSessionVars(ctx)
+
+	asc := []string{"[0 4 7]", "[1 <nil> 2]", "[1 -5 1]", "[1 3 3]", "[1 7 4]", "[2 1 5]", "[2 4 6]"}
+	desc := make([]string, len(asc))
+	for i, row := range asc {
+		desc[len(asc)-1-i] = row
+	}
+	for i, unique := range []bool{false, true} {
+		src := newCompositeTable(c, ctx, int64(40+i), unique)
+		selectList := &plans.SelectList{FromFields: src.Fields}
+		by := []expression.Expression{newFromIdent("tenant", 0), newFromIdent("created", 1)}
+
+		// The index can't return the rows in mixed directions.
+		p := &plans.RowStackFromPlan{Src: src}
+		c.Assert(plans.UseIndexOrder(p, by, []bool{true, false}, selectList), IsFalse)
+		c.Assert(plans.UseIndexOrder(p, by[1:], []bool{true}, selectList), IsFalse)
+		c.Assert(p.Src, Equals, src)
+
+		c.Assert(plans.UseIndexOrder(p, by, []bool{true, true}, selectList), IsTrue)
+		c.Assert(explainPlan(p), Matches, `(?s).*using index "tc" where tenant in \[<nil>,\+inf\].*`)
+		c.Assert(fetchRows(c, ctx, p), DeepEquals, asc)
+
+		p = &plans.RowStackFromPlan{Src: src}
+		c.Assert(plans.UseIndexOrder(p, by[:1], []bool{false}, selectList), IsTrue)
+		c.Assert(explainPlan(p), Matches, `(?s).*in descending order.*`)
+		c.Assert(fetchRows(c, ctx, p), DeepEquals, desc)
+
+		// The column bound by an equal condition is skipped.
+		conds := []expression.Expression{newCompare(opcode.EQ, "tenant", 1)}
+		ip, _, err := plans.ChooseAccessPath(ctx, src, conds)
+		c.Assert(err, IsNil)
+		p = &plans.RowStackFromPlan{Src: ip}
+		c.Assert(plans.UseIndexOrder(p, by[1:], []bool{false}, selectList), IsTrue)
+		c.Assert(fetchRows(c, ctx, p), DeepEquals, []string{"[1 7 4]", "[1 3 3]", "[1 -5 1]", "[1 <nil> 2]"})
+
+		// The null values are not in the range.
+		conds = append(conds, newCompare(opcode.LT, "created", 3.5))
+		ip, _, err = plans.ChooseAccessPath(ctx, src, conds)
+		c.Assert(err, IsNil)
+		p = &plans.RowStackFromPlan{Src: ip}
+		c.Assert(plans.UseIndexOrder(p, by, []bool{false, false}, selectList), IsTrue)
+		c.Assert(fetchRows(c, ctx, p), DeepEquals, []string{"[1 3 3]", "[1 -5 1]"})
+
+		// The spans of a not equal condition cost as much as the table scan, so the
+		// index is applied by Filter.
+		ip, filtered, err := src.Filter(ctx, newCompare(opcode.NE, "tenant", 1))
+		c.Assert(err, IsNil)
+		c.Assert(filtered, IsTrue)
+		p = &plans.RowStackFromPlan{Src: ip}
+		c.Assert(plans.UseIndexOrder(p, by, []bool{false, false}, selectList), IsTrue)
+		c.Assert(fetchRows(c, ctx, p), DeepEquals, []string{"[2 4 6]", "[2 1 5]", "[0 4 7]"})
+
+		// The rows are read from the index only.
+		p = &plans.RowStackFromPlan{Src: src}
+		c.Assert(plans.UseIndexOrder(p, by, []bool{false, false}, selectList), IsTrue)
+		c.Assert(plans.UseCoveringIndex(p, by), IsTrue)
+		c.Assert(fetchRows(c, ctx, p)[:2], DeepEquals, []string{"[2 4 <nil>]", "[2 1 <nil>]"})
+	}
+}
+
+func newFromIdent(name string, index int) *expression.Ident {
+	return &expression.Ident{
+		CIStr:      model.NewCIStr(name),
+		ReferScope: expression.IdentReferFromTable,
+		ReferIndex: index,
+	}
+}
+
+func (s *testCostSuite) TestIndexEndpoints(c *C) {
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	ctx := &txnContext{Context: mock.NewContext(), txn: txn}
+	variable.BindSessionVars(ctx)
+
+	newSelectList := func(fromFields []*field.ResultField, fs ...string) *plans.SelectList {
+		selectList := &plans.SelectList{FromFields: fromFields}
+		for i := 0; i < len(fs); i += 2 {
+			call, err := expression.NewCall(fs[i], []expression.Expression{newFromIdent(fs[i+1], 0)}, false)
+			c.Assert(err, IsNil)
+			selectList.Fields = append(selectList.Fields, &field.Field{Expr: call})
+		}
+		return selectList
+	}
+
+	src := newCompositeTable(c, ctx, 50, false)
+	p, ok := plans.UseIndexEndpoints(&plans.RowStackFromPlan{Src: src},
+		newSelectList(src.Fields, "min", "tenant", "max", "tenant"))
+	c.Assert(ok, IsTrue)
+	c.Assert(explainPlan(p), Matches, `(?s).*from the beginning the end of index "tc".*`)
+	c.Assert(fetchRows(c, ctx, p), DeepEquals, []string{"[0 4 7]", "[2 4 6]"})
+	// It can be read again.
+	c.Assert(fetchRows(c, ctx, p), DeepEquals, []string{"[0 4 7]", "[2 4 6]"})
+
+	// The null values are skipped.
+	conds := []expression.Expression{newCompare(opcode.EQ, "tenant", 1)}
+	ip, _, err := plans.ChooseAccessPath(ctx, src, conds)
+	c.Assert(err, IsNil)
+	p, ok = plans.UseIndexEndpoints(&plans.RowStackFromPlan{Src: ip},
+		newSelectList(src.Fields, "MIN", "created"))
+	c.Assert(ok, IsTrue)
+	c.Assert(fetchRows(c, ctx, p), DeepEquals, []string{"[1 -5 1]"})
+
+	// There is no not null value.
+	conds = []expression.Expression{newCompare(opcode.EQ, "tenant", 3)}
+	ip, _, err = plans.ChooseAccessPath(ctx, src, conds)
+	c.Assert(err, IsNil)
+	p, ok = plans.UseIndexEndpoints(&plans.RowStackFromPlan{Src: ip},
+		newSelectList(src.Fields, "min", "created", "max", "created"))
+	c.Assert(ok, IsTrue)
+	c.Assert(fetchRows(c, ctx, p), HasLen, 0)
+
+	// Only MIN and MAX of the same column are answered by the index endpoints.
+	for _, fs := range [][]string{
+		{"min", "tenant", "max", "created"},
+		{"count", "tenant"},
+		{"max", "v"},
+	} {
+		_, ok = plans.UseIndexEndpoints(&plans.RowStackFromPlan{Src: src}, newSelectList(src.Fields, fs...))
+		c.Assert(ok, IsFalse)
+	}
+}
+
+// batchCountingTxn counts the calls of BatchPrefetch.
+type batchCountingTxn struct {
+	kv.Transaction
+	batches int
+}
+
+func (txn *batchCountingTxn) BatchPrefetch(keys []kv.Key) error {
+	txn.batches++
+	return txn.Transaction.BatchPrefetch(keys)
+}
+
+func (s *testCostSuite) TestInList(c *C) {
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	ctx := &txnContext{Context: mock.NewContext(), txn: txn}
+	variable.BindSessionVars(ctx)
+
+	newInList := func(name string, vals ...interface{}) expression.Expression {
+		x := &expression.PatternIn{Expr: &expression.Ident{CIStr: model.NewCIStr(name)}}
+		for _, v := range vals {
+			x.List = append(x.List, expression.Value{Val: v})
+		}
+		return x
+	}
+	defer func(n int) { plans.IndexBatchSize = n }(plans.IndexBatchSize)
+
+	for i, unique := range []bool{false, true} {
+		src := newCompositeTable(c, ctx, int64(60+i), unique)
+		testcases := []struct {
+			conds   []expression.Expression
+			explain string
+			result  []string
+		}{
+			{
+				[]expression.Expression{newInList("tenant", int64(2), int64(0), nil, int64(2))},
+				`using index "tc" where tenant in \[0,0\] \[2,2\]`,
+				[]string{"[0 4 7]", "[2 1 5]", "[2 4 6]"},
+			},
+			{
+				[]expression.Expression{newCompare(opcode.GT, "tenant", 0), newInList("tenant", int64(3), int64(0), int64(2))},
+				`using index "tc" where tenant in \[2,2\] \[3,3\]`,
+				[]string{"[2 1 5]", "[2 4 6]"},
+			},
+			{
+				[]expression.Expression{newInList("tenant", int64(1)), newCompare(opcode.GE, "created", 5)},
+				`using index "tc" where tenant = 1 and created in \[5,\+inf\]`,
+				[]string{"[1 7 4]"},
+			},
+		}
+		for _, batchSize := range []int{1024, 1} {
+			plans.IndexBatchSize = batchSize
+			for _, tc := range testcases {
+				p, rest, err := plans.ChooseAccessPath(ctx, src, tc.conds)
+				c.Assert(err, IsNil)
+				c.Assert(rest, HasLen, 0)
+				c.Assert(explainPlan(p), Matches, "(?s).*"+tc.explain+".*")
+				result := fetchRows(c, ctx, p)
+				c.Assert(result, DeepEquals, tc.result, Commentf("unique %v, %s", unique, tc.explain))
+			}
+		}
+
+		// The rows of all the points are read with one batch read.
+		counting := &batchCountingTxn{Transaction: txn}
+		countingCtx := &txnContext{Context: ctx.Context, txn: counting}
+		plans.IndexBatchSize = 1024
+		p, _, err := plans.ChooseAccessPath(countingCtx, src, []expression.Expression{newInList("tenant", int64(0), int64(2))})
+		c.Assert(err, IsNil)
+		c.Assert(fetchRows(c, countingCtx, p), HasLen, 3)
+		c.Assert(counting.batches, Equals, 1)
+
+		counting.batches = 0
+		plans.IndexBatchSize = 2
+		c.Assert(fetchRows(c, countingCtx, p), HasLen, 3)
+		c.Assert(counting.batches, Equals, 2)
+
+		// All the values are null.
+		p, rest, err := plans.ChooseAccessPath(ctx, src, []expression.Expression{newInList("tenant", nil)})
+		c.Assert(err, IsNil)
+		c.Assert(rest, HasLen, 0)
+		c.Assert(fetchRows(c, ctx, p), HasLen, 0)
+
+		// IN list on a column without index, or with non-constant values, is not used.
+		for _, cond := range []expression.Expression{
+			newInList("v", int64(1)),
+			&expression.PatternIn{Expr: newIdent("tenant"), List: []expression.Expression{newIdent("v")}},
+			&expression.PatternIn{Expr: newIdent("tenant"), List: []expression.Expression{expression.Value{Val: int64(1)}}, Not: true},
+		} {
+			p, rest, err = plans.ChooseAccessPath(ctx, src, []expression.Expression{cond})
+			c.Assert(err, IsNil)
+			c.Assert(p, Equals, src)
+			c.Assert(rest, HasLen, 1)
+		}
+	}
+}
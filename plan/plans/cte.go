@@ -0,0 +1,279 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/kv/memkv"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/format"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var (
+	_ plan.Plan = (*RecursiveCTEPlan)(nil)
+	_ plan.Plan = (*CTEWorkTablePlan)(nil)
+)
+
+// defaultCTEMaxRecursionDepth is used if cte_max_recursion_depth system variable
+// is not a valid number.
+const defaultCTEMaxRecursionDepth = 1000
+
+// cteMaxRecursionDepth returns the max number of iterations of a recursive common
+// table expression, it is cte_max_recursion_depth system variable of the session.
+func cteMaxRecursionDepth(ctx context.Context) int {
+	var value string
+	if vars := variable.GetSessionVars(ctx); vars != nil {
+		value = vars.Systems[variable.CTEMaxRecursionDepth]
+	}
+	if value == "" {
+		value = variable.GetSysVar(variable.CTEMaxRecursionDepth).Value
+	}
+	depth, err := strconv.Atoi(value)
+	if err != nil || depth < 0 {
+		return defaultCTEMaxRecursionDepth
+	}
+	return depth
+}
+
+// CTEWorkTablePlan reads the rows produced by the last iteration of a recursive
+// common table expression, it is the reference to the common table expression
+// in its recursive part.
+type CTEWorkTablePlan struct {
+	Name   string
+	Fields []*field.ResultField
+
+	rows   []*plan.Row
+	cursor int
+}
+
+// Explain implements plan.Plan Explain interface.
+func (r *CTEWorkTablePlan) Explain(w format.Formatter) {
+	w.Format("┌Iterate rows of last iteration of %s\n└Output field names %v\n", r.Name, field.RFQNames(r.Fields))
+}
+
+// GetFields implements plan.Plan GetFields interface.
+func (r *CTEWorkTablePlan) GetFields() []*field.ResultField {
+	return r.Fields
+}
+
+// Filter implements plan.Plan Filter interface.
+func (r *CTEWorkTablePlan) Filter(ctx context.Context, expr expression.Expression) (plan.Plan, bool, error) {
+	return r, false, nil
+}
+
+// Next implements plan.Plan Next interface.
+func (r *CTEWorkTablePlan) Next(ctx context.Context) (*plan.Row, error) {
+	if r.cursor == len(r.rows) {
+		return nil, nil
+	}
+	row := &plan.Row{Data: r.rows[r.cursor].Data}
+	r.cursor++
+	return row, nil
+}
+
+// Close implements plan.Plan Close interface.
+func (r *CTEWorkTablePlan) Close() error {
+	r.cursor = 0
+	return nil
+}
+
+// RecursiveCTEPlan evaluates a recursive common table expression. The rows of
+// Seed are the rows of the first iteration, then Recursive is executed on the
+// rows of the last iteration, which are read by WorkTable, until an iteration
+// returns no rows.
+type RecursiveCTEPlan struct {
+	Name      string
+	Seed      plan.Plan
+	Recursive plan.Plan
+	WorkTable *CTEWorkTablePlan
+	// Distinct is true for UNION DISTINCT, the duplicate rows are ignored.
+	Distinct bool
+	Fields   []*field.ResultField
+
+	started bool
+	done    bool
+	depth   int
+	rows    []*plan.Row
+	cursor  int
+	seen    memkv.Temp
+}
+
+// Explain implements plan.Plan Explain interface.
+func (r *RecursiveCTEPlan) Explain(w format.Formatter) {
+	r.Seed.Explain(w)
+	r.Recursive.Explain(w)
+	w.Format("┌Recursive %s, distinct %v\n└Output field names %v\n", r.Name, r.Distinct, field.RFQNames(r.Fields))
+}
+
+// GetFields implements plan.Plan GetFields interface.
+func (r *RecursiveCTEPlan) GetFields() []*field.ResultField {
+	return r.Fields
+}
+
+// Filter implements plan.Plan Filter interface.
+func (r *RecursiveCTEPlan) Filter(ctx context.Context, expr expression.Expression) (plan.Plan, bool, error) {
+	return r, false, nil
+}
+
+// Next implements plan.Plan Next interface.
+func (r *RecursiveCTEPlan) Next(ctx context.Context) (*plan.Row, error) {
+	if !r.started {
+		r.started = true
+		if err := r.fetchSeed(ctx); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	for r.cursor == len(r.rows) {
+		if r.done {
+			return nil, nil
+		}
+		if err := r.iterate(ctx); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	row := r.rows[r.cursor]
+	r.cursor++
+	return row, nil
+}
+
+func (r *RecursiveCTEPlan) fetchSeed(ctx context.Context) error {
+	if r.Distinct {
+		var err error
+		if r.seen, err = memkv.CreateTemp(true); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for {
+		row, err := r.Seed.Next(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		ok, err := r.add(row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if ok {
+			r.rows = append(r.rows, row)
+		}
+	}
+	// The field types are known after the seed is executed, e.g. select null.
+	for i, f := range r.Fields {
+		if f.Col.FieldType.Tp == 0 {
+			f.Col.FieldType = r.Seed.GetFields()[i].Col.FieldType
+		}
+	}
+	r.done = len(r.rows) == 0
+	return nil
+}
+
+// iterate executes the recursive part on the rows of the last iteration.
+func (r *RecursiveCTEPlan) iterate(ctx context.Context) error {
+	r.WorkTable.rows = r.rows
+	r.WorkTable.cursor = 0
+	r.rows, r.cursor = nil, 0
+	if err := r.Recursive.Close(); err != nil {
+		return errors.Trace(err)
+	}
+
+	srcFields := r.Recursive.GetFields()
+	if len(srcFields) != len(r.Fields) {
+		return errors.New("The used SELECT statements have a different number of columns")
+	}
+	for {
+		row, err := r.Recursive.Next(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		for i := range row.Data {
+			// The value is casted as the same type of the seed in corresponding position.
+			rf := r.Fields[i]
+			if rf.Col.FieldType.Tp > 0 && rf.Col.FieldType.Tp != mysql.TypeNull {
+				if row.Data[i], err = types.Convert(row.Data[i], &rf.Col.FieldType); err != nil {
+					return errors.Trace(err)
+				}
+			} else if tp := srcFields[i].Col.FieldType.Tp; tp > 0 {
+				rf.Col.FieldType.Tp = tp
+			}
+		}
+		ok, err := r.add(row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if ok {
+			r.rows = append(r.rows, row)
+		}
+	}
+	if len(r.rows) == 0 {
+		r.done = true
+		return nil
+	}
+	r.depth++
+	if max := cteMaxRecursionDepth(ctx); r.depth > max {
+		return errors.Errorf("Recursive query aborted after %d iterations. Try increasing @@cte_max_recursion_depth to a larger value.", r.depth)
+	}
+	return nil
+}
+
+// add returns false if the row is a duplicate one of a UNION DISTINCT.
+func (r *RecursiveCTEPlan) add(row *plan.Row) (bool, error) {
+	if !r.Distinct {
+		return true, nil
+	}
+	v, err := r.seen.Get(row.Data)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if len(v) > 0 {
+		return false, nil
+	}
+	if err = r.seen.Set(row.Data, []interface{}{true}); err != nil {
+		return false, errors.Trace(err)
+	}
+	return true, nil
+}
+
+// Close implements plan.Plan Close interface.
+func (r *RecursiveCTEPlan) Close() error {
+	r.started, r.done = false, false
+	r.depth, r.cursor = 0, 0
+	r.rows = nil
+	r.WorkTable.rows = nil
+	r.WorkTable.cursor = 0
+	var err error
+	if r.seen != nil {
+		err = r.seen.Drop()
+		r.seen = nil
+	}
+	if serr := r.Seed.Close(); serr != nil && err == nil {
+		err = serr
+	}
+	if rerr := r.Recursive.Close(); rerr != nil && err == nil {
+		err = rerr
+	}
+	return errors.Trace(err)
+}
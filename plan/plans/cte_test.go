@@ -0,0 +1,143 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans_test
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/plan/plans"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/format"
+	"github.com/pingcap/tidb/util/mock"
+)
+
+// testStepPlan returns n + step for each row n of Src less than max.
+type testStepPlan struct {
+	Src  plan.Plan
+	step int64
+	max  int64
+}
+
+func (p *testStepPlan) Explain(w format.Formatter) {}
+
+func (p *testStepPlan) GetFields() []*field.ResultField {
+	return p.Src.GetFields()
+}
+
+func (p *testStepPlan) Filter(ctx context.Context, expr expression.Expression) (plan.Plan, bool, error) {
+	return p, false, nil
+}
+
+func (p *testStepPlan) Next(ctx context.Context) (*plan.Row, error) {
+	for {
+		row, err := p.Src.Next(ctx)
+		if row == nil || err != nil {
+			return nil, err
+		}
+		if n := row.Data[0].(int64); n < p.max {
+			return &plan.Row{Data: []interface{}{n + p.step}}, nil
+		}
+	}
+}
+
+func (p *testStepPlan) Close() error {
+	return p.Src.Close()
+}
+
+type testCTESuite struct{}
+
+var _ = Suite(&testCTESuite{})
+
+func (s *testCTESuite) newPlan(seeds []int64, step, max int64, distinct bool) *plans.RecursiveCTEPlan {
+	var rows []*testRowData
+	for i, n := range seeds {
+		rows = append(rows, &testRowData{int64(i), []interface{}{n}})
+	}
+	seed := &testTablePlan{rows, []string{"n"}, 0}
+	work := &plans.CTEWorkTablePlan{Name: "t", Fields: seed.GetFields()}
+	return &plans.RecursiveCTEPlan{
+		Name:      "t",
+		Seed:      seed,
+		Recursive: &testStepPlan{Src: work, step: step, max: max},
+		WorkTable: work,
+		Distinct:  distinct,
+		Fields:    seed.GetFields(),
+	}
+}
+
+func (s *testCTESuite) fetch(ctx context.Context, p plan.Plan) ([]int64, error) {
+	var ns []int64
+	for {
+		row, err := p.Next(ctx)
+		if err != nil {
+			return ns, err
+		}
+		if row == nil {
+			return ns, nil
+		}
+		ns = append(ns, row.Data[0].(int64))
+	}
+}
+
+func (s *testCTESuite) TestRecursiveCTE(c *C) {
+	ctx := mock.NewContext()
+	p := s.newPlan([]int64{1}, 1, 5, false)
+	ns, err := s.fetch(ctx, p)
+	c.Assert(err, IsNil)
+	c.Assert(ns, DeepEquals, []int64{1, 2, 3, 4, 5})
+
+	// The plan can be executed again after it is closed.
+	c.Assert(p.Close(), IsNil)
+	ns, err = s.fetch(ctx, p)
+	c.Assert(err, IsNil)
+	c.Assert(ns, DeepEquals, []int64{1, 2, 3, 4, 5})
+
+	// No iteration if the seed is empty.
+	p = s.newPlan(nil, 1, 5, false)
+	ns, err = s.fetch(ctx, p)
+	c.Assert(err, IsNil)
+	c.Assert(ns, HasLen, 0)
+
+	// The duplicate rows are ignored by UNION DISTINCT, so the iterations end.
+	p = s.newPlan([]int64{1, 2}, 0, 5, true)
+	ns, err = s.fetch(ctx, p)
+	c.Assert(err, IsNil)
+	c.Assert(ns, DeepEquals, []int64{1, 2})
+
+	p = s.newPlan([]int64{1, 3}, 2, 6, true)
+	ns, err = s.fetch(ctx, p)
+	c.Assert(err, IsNil)
+	c.Assert(ns, DeepEquals, []int64{1, 3, 5, 7})
+	c.Assert(p.Close(), IsNil)
+}
+
+func (s *testCTESuite) TestRecursionDepth(c *C) {
+	ctx := mock.NewContext()
+	variable.BindSessionVars(ctx)
+	variable.GetSessionVars(ctx).Systems[variable.CTEMaxRecursionDepth] = "3"
+
+	p := s.newPlan([]int64{1}, 1, 4, false)
+	ns, err := s.fetch(ctx, p)
+	c.Assert(err, IsNil)
+	c.Assert(ns, DeepEquals, []int64{1, 2, 3, 4})
+
+	p = s.newPlan([]int64{1}, 1, 5, false)
+	ns, err = s.fetch(ctx, p)
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*aborted after 4 iterations.*")
+	c.Assert(ns, DeepEquals, []int64{1, 2, 3, 4})
+}
@@ -31,11 +31,18 @@ var (
 )
 
 // DistinctDefaultPlan e.g. SELECT distinct(id) FROM t;
+// If the distinct rows exceed the memory quota of the session, the rows not
+// in memory are sorted on disk, and the duplicated ones are skipped when read.
 type DistinctDefaultPlan struct {
 	*SelectList
-	Src    plan.Plan
-	rows   []*plan.Row
-	cursor int
+	Src      plan.Plan
+	rows     []*plan.Row
+	cursor   int
+	memUsage int64
+	tracker  *memTracker
+	// sorter sorts the rows which are not in memory.
+	sorter  *externalSorter
+	lastKey []interface{}
 }
 
 // Explain implements the plan.Plan Explain interface.
@@ -57,15 +64,37 @@ func (r *DistinctDefaultPlan) Next(ctx context.Context) (row *plan.Row, err erro
 			return nil, errors.Trace(err)
 		}
 	}
-	if r.cursor == len(r.rows) {
+	if r.cursor < len(r.rows) {
+		row = r.rows[r.cursor]
+		r.cursor++
+	} else if r.sorter != nil {
+		row, err = r.nextSpilledRow()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if row == nil {
 		return
 	}
-	row = r.rows[r.cursor]
 	updateRowStack(ctx, row.Data, row.FromData)
-	r.cursor++
 	return
 }
 
+// nextSpilledRow returns the next distinct row sorted on disk.
+func (r *DistinctDefaultPlan) nextSpilledRow() (*plan.Row, error) {
+	for {
+		sRow, err := r.sorter.next()
+		if sRow == nil || err != nil {
+			return nil, errors.Trace(err)
+		}
+		if r.lastKey != nil && compareSortKeys(r.lastKey, sRow.Key, r.sorter.ascs) == 0 {
+			continue
+		}
+		r.lastKey = sRow.Key
+		return sRow.Row, nil
+	}
+}
+
 func (r *DistinctDefaultPlan) fetchAll(ctx context.Context) error {
 	t, err := memkv.CreateTemp(true)
 	if err != nil {
@@ -76,11 +105,15 @@ func (r *DistinctDefaultPlan) fetchAll(ctx context.Context) error {
 			err = derr
 		}
 	}()
+	r.tracker = getMemTracker(ctx)
 	for {
 		row, err := r.Src.Next(ctx)
-		if row == nil || err != nil {
+		if err != nil {
 			return errors.Trace(err)
 		}
+		if row == nil {
+			break
+		}
 		var v []interface{}
 		// get distinct key
 		key := row.Data[0:r.HiddenFieldOffset]
@@ -89,19 +122,45 @@ func (r *DistinctDefaultPlan) fetchAll(ctx context.Context) error {
 			return errors.Trace(err)
 		}
 
-		if len(v) == 0 {
+		if len(v) == 0 && r.sorter != nil {
+			// the rows in memory exceed the quota, the row is checked later.
+			if err := r.sorter.add(key, row); err != nil {
+				return errors.Trace(err)
+			}
+		} else if len(v) == 0 {
 			// no group for key, save data for this group
 			r.rows = append(r.rows, row)
 			if err := t.Set(key, []interface{}{true}); err != nil {
 				return errors.Trace(err)
 			}
+			n := rowMemUsage(row) + valuesMemUsage(row.FromData)
+			r.memUsage += n
+			if r.tracker.consume(n) {
+				r.sorter = newExternalSorter(ctx, allAscs(len(key)))
+			}
 		}
 	}
+	if r.sorter != nil {
+		return errors.Trace(r.sorter.finish())
+	}
+	return nil
 }
 
 // Close implements plan.Plan Close interface.
 func (r *DistinctDefaultPlan) Close() error {
 	r.rows = nil
 	r.cursor = 0
+	if r.tracker != nil {
+		r.tracker.release(r.memUsage)
+	}
+	r.memUsage = 0
+	r.lastKey = nil
+	if r.sorter != nil {
+		err := r.sorter.close()
+		r.sorter = nil
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 	return r.Src.Close()
 }
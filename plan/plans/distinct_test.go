@@ -20,6 +20,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/plan/plans"
 	"github.com/pingcap/tidb/rset/rsets"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/mock"
 )
 
@@ -67,3 +68,34 @@ func (t *testDistinctSuit) TestDistinct(c *C) {
 
 	c.Assert(reflect.DeepEqual(r, expected), Equals, true)
 }
+
+func (t *testDistinctSuit) TestDistinctSpill(c *C) {
+	var data []*testRowData
+	for i := 0; i < 30; i++ {
+		data = append(data, &testRowData{int64(i), []interface{}{int64(i * 7 % 10), "hello"}})
+	}
+	tblPlan := &testTablePlan{data, []string{"id", "name"}, 0}
+	p := plans.DistinctDefaultPlan{
+		SelectList: &plans.SelectList{
+			HiddenFieldOffset: len(tblPlan.GetFields()),
+		},
+		Src: tblPlan,
+	}
+
+	// Only the first row is in memory, the others are sorted on disk.
+	ctx := mock.NewContext()
+	variable.BindSessionVars(ctx)
+	variable.GetSessionVars(ctx).Systems[variable.TiDBMemQuota] = "1"
+	rset := rsets.Recordset{Plan: &p, Ctx: ctx}
+	var ids []interface{}
+	err := rset.Do(func(data []interface{}) (bool, error) {
+		c.Assert(data[1], Equals, "hello")
+		ids = append(ids, data[0])
+		return true, nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(ids, DeepEquals, []interface{}{
+		int64(0), int64(1), int64(2), int64(3), int64(4),
+		int64(5), int64(6), int64(7), int64(8), int64(9),
+	})
+}
@@ -0,0 +1,296 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/format"
+)
+
+var (
+	_ plan.Plan = (*ExplainAnalyzePlan)(nil)
+	_ plan.Plan = (*analyzePlan)(nil)
+)
+
+// ExplainAnalyzePlan executes the plan Src, and explains it with the runtime
+// statistics of each plan node: the rows it returns, the calls of its Next
+// method, the time spent in them and the kv reads issued by them. The time
+// and the kv reads of a plan node include the ones of its source plans.
+type ExplainAnalyzePlan struct {
+	Src    plan.Plan
+	root   plan.Plan
+	lines  []string
+	cursor int
+}
+
+// Explain implements the plan.Plan Explain interface.
+func (r *ExplainAnalyzePlan) Explain(w format.Formatter) {
+	// Do nothing
+}
+
+// GetFields implements the plan.Plan GetFields interface.
+func (r *ExplainAnalyzePlan) GetFields() []*field.ResultField {
+	return []*field.ResultField{{Name: ""}}
+}
+
+// Filter implements the plan.Plan Filter interface.
+func (r *ExplainAnalyzePlan) Filter(ctx context.Context, expr expression.Expression) (plan.Plan, bool, error) {
+	return r, false, nil
+}
+
+// Next implements plan.Plan Next interface.
+func (r *ExplainAnalyzePlan) Next(ctx context.Context) (row *plan.Row, err error) {
+	if r.lines == nil {
+		if r.root == nil {
+			r.root = analyzePlanTree(ctx, r.Src)
+		}
+		if err = r.execute(ctx); err != nil {
+			return nil, errors.Trace(err)
+		}
+		var buf bytes.Buffer
+		w := format.IndentFormatter(&buf, "│   ")
+		r.root.Explain(w)
+		r.lines = strings.Split(string(buf.Bytes()), "\n")
+	}
+	if r.cursor == len(r.lines)-1 {
+		return
+	}
+	row = &plan.Row{
+		Data: []interface{}{r.lines[r.cursor]},
+	}
+	r.cursor++
+	return
+}
+
+// execute reads all the rows from the plan and drops them.
+func (r *ExplainAnalyzePlan) execute(ctx context.Context) error {
+	defer r.root.Close()
+	for {
+		row, err := r.root.Next(ctx)
+		if row == nil || err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
+
+// Close implements plan.Plan Close interface.
+func (r *ExplainAnalyzePlan) Close() error {
+	r.lines = nil
+	r.cursor = 0
+	return nil
+}
+
+// analyzeStats is the runtime statistics of a plan node.
+type analyzeStats struct {
+	rows    int64
+	calls   int64
+	time    time.Duration
+	kvReads int64
+}
+
+// analyzePlan collects the runtime statistics of the plan node it wraps.
+type analyzePlan struct {
+	plan.Plan
+	analyzeStats
+	ctx *analyzeContext
+}
+
+// analyzePlanTree wraps each node of the plan tree p with an analyzePlan.
+// The joins choose how to join the rows before their source plans are
+// wrapped, so the choices are the same as the ones without EXPLAIN ANALYZE.
+func analyzePlanTree(ctx context.Context, p plan.Plan) plan.Plan {
+	if p == nil {
+		return nil
+	}
+	switch x := p.(type) {
+	case *SelectFieldsDefaultPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+	case *SelectLockPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+	case *FilterDefaultPlan:
+		x.Plan = analyzePlanTree(ctx, x.Plan)
+	case *RowStackFromPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+	case *JoinPlan:
+		x.chooseJoiner(ctx)
+		x.Left = analyzePlanTree(ctx, x.Left)
+		x.Right = analyzePlanTree(ctx, x.Right)
+	case *GroupByDefaultPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+	case *HavingPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+	case *WindowPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+	case *DistinctDefaultPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+	case *OrderByDefaultPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+	case *OffsetDefaultPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+	case *LimitDefaultPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+	case *SelectFinalPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+	case *UnionPlan:
+		for i, src := range x.Srcs {
+			x.Srcs[i] = analyzePlanTree(ctx, src)
+		}
+	case *RecursiveCTEPlan:
+		x.Seed = analyzePlanTree(ctx, x.Seed)
+		x.Recursive = analyzePlanTree(ctx, x.Recursive)
+	case *indexEndpointsPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+	case *reorderedJoinPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+	case *ViewFieldsPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+	case *SemiJoinPlan:
+		x.Src = analyzePlanTree(ctx, x.Src)
+		x.Inner = analyzePlanTree(ctx, x.Inner)
+	}
+	return &analyzePlan{Plan: p}
+}
+
+// unwrapAnalyzePlan returns the plan wrapped by p if p is an analyzePlan,
+// otherwise it returns p.
+func unwrapAnalyzePlan(p plan.Plan) plan.Plan {
+	if x, ok := p.(*analyzePlan); ok {
+		return x.Plan
+	}
+	return p
+}
+
+// Explain implements plan.Plan Explain interface.
+// The statistics follow the lines written by the plan node itself, the plan
+// nodes which write nothing, e.g. RowStackFromPlan, are skipped.
+func (r *analyzePlan) Explain(w format.Formatter) {
+	if x, ok := w.(*analyzeFormatter); ok {
+		w = x.Formatter
+	}
+	aw := &analyzeFormatter{Formatter: w}
+	r.Plan.Explain(aw)
+	if aw.written {
+		w.Format("└Actual rows %d, %d calls of Next, time %v, %d kv reads\n",
+			r.rows, r.calls, r.time, r.kvReads)
+	}
+}
+
+// Next implements plan.Plan Next interface.
+func (r *analyzePlan) Next(ctx context.Context) (*plan.Row, error) {
+	if r.ctx == nil || r.ctx.Context != ctx {
+		r.ctx = &analyzeContext{Context: ctx, stats: &r.analyzeStats}
+	}
+	start := time.Now()
+	row, err := r.Plan.Next(r.ctx)
+	r.time += time.Since(start)
+	r.calls++
+	if row != nil {
+		r.rows++
+	}
+	return row, errors.Trace(err)
+}
+
+// analyzeFormatter records whether a plan node writes its own lines,
+// the source plan nodes write to the underlying Formatter.
+type analyzeFormatter struct {
+	format.Formatter
+	written bool
+}
+
+// Write implements io.Writer Write interface.
+func (w *analyzeFormatter) Write(p []byte) (int, error) {
+	w.written = true
+	return w.Formatter.Write(p)
+}
+
+// Format implements format.Formatter Format interface.
+func (w *analyzeFormatter) Format(format string, args ...interface{}) (int, error) {
+	w.written = true
+	return w.Formatter.Format(format, args...)
+}
+
+// analyzeContext counts the kv reads of the transaction got from it.
+type analyzeContext struct {
+	context.Context
+	stats *analyzeStats
+}
+
+// GetTxn implements context.Context GetTxn interface.
+func (c *analyzeContext) GetTxn(forceNew bool) (kv.Transaction, error) {
+	txn, err := c.Context.GetTxn(forceNew)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &analyzeTxn{Transaction: txn, stats: c.stats}, nil
+}
+
+// analyzeTxn is a transaction which counts its reads.
+type analyzeTxn struct {
+	kv.Transaction
+	stats *analyzeStats
+}
+
+// Get implements kv.Transaction Get interface.
+func (txn *analyzeTxn) Get(k kv.Key) ([]byte, error) {
+	txn.stats.kvReads++
+	return txn.Transaction.Get(k)
+}
+
+// BatchPrefetch implements kv.Transaction BatchPrefetch interface.
+func (txn *analyzeTxn) BatchPrefetch(keys []kv.Key) error {
+	txn.stats.kvReads++
+	return txn.Transaction.BatchPrefetch(keys)
+}
+
+// Seek implements kv.Transaction Seek interface.
+func (txn *analyzeTxn) Seek(k kv.Key) (kv.Iterator, error) {
+	txn.stats.kvReads++
+	it, err := txn.Transaction.Seek(k)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &analyzeIter{Iterator: it, stats: txn.stats}, nil
+}
+
+// SeekReverse implements kv.Transaction SeekReverse interface.
+func (txn *analyzeTxn) SeekReverse(k kv.Key) (kv.Iterator, error) {
+	txn.stats.kvReads++
+	it, err := txn.Transaction.SeekReverse(k)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &analyzeIter{Iterator: it, stats: txn.stats}, nil
+}
+
+// analyzeIter is an iterator which counts its reads, the seek reads the
+// first entry and each call of Next reads the next one.
+type analyzeIter struct {
+	kv.Iterator
+	stats *analyzeStats
+}
+
+// Next implements kv.Iterator Next interface.
+func (it *analyzeIter) Next() error {
+	it.stats.kvReads++
+	return it.Iterator.Next()
+}
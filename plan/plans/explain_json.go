@@ -0,0 +1,323 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/parser/coldef"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/format"
+)
+
+var _ plan.Plan = (*ExplainJSONPlan)(nil)
+
+// ExplainNode is a node of the plan tree explained in JSON format.
+type ExplainNode struct {
+	// Type is the operator type, e.g. "TableScan", "IndexScan" and "Join".
+	Type  string `json:"type"`
+	Table string `json:"table,omitempty"`
+	// Index is the index used by an index scan or an index join.
+	Index string `json:"index,omitempty"`
+	// IndexEquals are the equal conditions on the leading index columns,
+	// e.g. "a = 1", and Spans are the ranges of the next index column.
+	IndexEquals []string `json:"index_equals,omitempty"`
+	SpanColumn  string   `json:"span_column,omitempty"`
+	Spans       []string `json:"spans,omitempty"`
+	Covering    bool     `json:"covering,omitempty"`
+	Descending  bool     `json:"descending,omitempty"`
+	// Filter is the WHERE or HAVING condition of a filter, or the ON condition of a join.
+	Filter        string `json:"filter,omitempty"`
+	JoinType      string `json:"join_type,omitempty"`
+	JoinAlgorithm string `json:"join_algorithm,omitempty"`
+	// GroupBy and OrderBy are the expressions to group and sort the rows by.
+	GroupBy []string `json:"group_by,omitempty"`
+	OrderBy []string `json:"order_by,omitempty"`
+	// Exprs are the expressions evaluated for each output row.
+	Exprs []string `json:"exprs,omitempty"`
+	// Count is the row count of a limit or an offset.
+	Count         *uint64        `json:"count,omitempty"`
+	Fields        []string       `json:"fields,omitempty"`
+	EstimatedRows float64        `json:"estimated_rows"`
+	Children      []*ExplainNode `json:"children,omitempty"`
+}
+
+// ExplainJSONPlan explains the plan Src as a JSON tree in a single row.
+type ExplainJSONPlan struct {
+	Src plan.Plan
+	// Stmt is the type of the root node above Src, e.g. "Update" and "Delete",
+	// it is empty if Src is the root.
+	Stmt string
+	done bool
+}
+
+// Explain implements the plan.Plan Explain interface.
+func (r *ExplainJSONPlan) Explain(w format.Formatter) {
+	// Do nothing
+}
+
+// GetFields implements the plan.Plan GetFields interface.
+func (r *ExplainJSONPlan) GetFields() []*field.ResultField {
+	return []*field.ResultField{{Name: ""}}
+}
+
+// Filter implements the plan.Plan Filter interface.
+func (r *ExplainJSONPlan) Filter(ctx context.Context, expr expression.Expression) (plan.Plan, bool, error) {
+	return r, false, nil
+}
+
+// Next implements plan.Plan Next interface.
+func (r *ExplainJSONPlan) Next(ctx context.Context) (*plan.Row, error) {
+	if r.done {
+		return nil, nil
+	}
+	r.done = true
+	var node *ExplainNode
+	if r.Src != nil {
+		node = explainJSONNode(ctx, r.Src)
+	}
+	if r.Stmt != "" {
+		root := &ExplainNode{Type: r.Stmt}
+		if node != nil {
+			root.EstimatedRows = node.EstimatedRows
+			root.Children = []*ExplainNode{node}
+		}
+		node = root
+	}
+	b, err := json.MarshalIndent(node, "", "  ")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &plan.Row{Data: []interface{}{string(b)}}, nil
+}
+
+// Close implements plan.Plan Close interface.
+func (r *ExplainJSONPlan) Close() error {
+	r.done = false
+	return nil
+}
+
+// explainJSONNode returns the explain tree of plan p. The plan nodes which
+// only pass the rows through, e.g. RowStackFromPlan, are skipped.
+func explainJSONNode(ctx context.Context, p plan.Plan) *ExplainNode {
+	p = unwrapAnalyzePlan(p)
+	node := &ExplainNode{}
+	var srcs []plan.Plan
+	switch x := p.(type) {
+	case *RowStackFromPlan:
+		return explainJSONNode(ctx, x.Src)
+	case *SelectFinalPlan:
+		return explainJSONNode(ctx, x.Src)
+	case *reorderedJoinPlan:
+		return explainJSONNode(ctx, x.Src)
+	case *ViewFieldsPlan:
+		return explainJSONNode(ctx, x.Src)
+	case *SelectLockPlan:
+		if x.Lock != coldef.SelectLockForUpdate {
+			return explainJSONNode(ctx, x.Src)
+		}
+		node.Type = "Lock"
+		srcs = []plan.Plan{x.Src}
+	case *JoinPlan:
+		if x.Right == nil {
+			return explainJSONNode(ctx, x.Left)
+		}
+		node.Type = "Join"
+		node.JoinType = x.Type
+		if x.On != nil {
+			node.Filter = x.On.String()
+		}
+		if ij := x.explainedIndexJoiner(); ij != nil {
+			node.JoinAlgorithm = "index"
+			node.Index = ij.index.Name.O
+		} else if x.canHashJoin() {
+			node.JoinAlgorithm = "hash"
+		} else {
+			node.JoinAlgorithm = "cartesian"
+		}
+		srcs = []plan.Plan{x.Left, x.Right}
+	case *SemiJoinPlan:
+		node.Type = "Join"
+		node.JoinType = x.joinType()
+		node.Filter = x.Expr.String()
+		if x.lookup != nil {
+			node.JoinAlgorithm = "index"
+			node.Index = x.lookup.index.Name.O
+		} else {
+			node.JoinAlgorithm = "hash"
+		}
+		srcs = []plan.Plan{x.Src, x.Inner}
+	case *TableDefaultPlan:
+		node.Type = "TableScan"
+		node.Table = x.T.TableName().O
+	case *TableNilPlan:
+		node.Type = "TableScan"
+		node.Table = x.T.TableName().O
+	case *indexPlan:
+		node.Type = "IndexScan"
+		node.Table = x.src.TableName().O
+		node.Index = x.idxName
+		for i, v := range x.eqVals {
+			node.IndexEquals = append(node.IndexEquals, fmt.Sprintf("%s = %v", x.cols[i].Name.L, v))
+		}
+		node.SpanColumn = x.col.Name.L
+		for _, span := range x.spans {
+			node.Spans = append(node.Spans, span.String())
+		}
+		node.Covering = x.covering
+		node.Descending = x.desc
+	case *indexEndpointsPlan:
+		node.Type = "IndexEndpoints"
+		node.Table = x.index.src.TableName().O
+		node.Index = x.index.idxName
+		srcs = []plan.Plan{x.Src}
+	case *FilterDefaultPlan:
+		node.Type = "Filter"
+		node.Filter = x.Expr.String()
+		srcs = []plan.Plan{x.Plan}
+	case *HavingPlan:
+		node.Type = "Filter"
+		node.Filter = x.Expr.String()
+		srcs = []plan.Plan{x.Src}
+	case *SelectFieldsDefaultPlan:
+		node.Type = "Projection"
+		node.Exprs = fieldExprs(x.Fields)
+		srcs = []plan.Plan{x.Src}
+	case *SelectFromDualPlan:
+		node.Type = "Dual"
+		node.Exprs = fieldExprs(x.Fields)
+	case *GroupByDefaultPlan:
+		node.Type = "Aggregate"
+		for _, by := range x.By {
+			node.GroupBy = append(node.GroupBy, by.String())
+		}
+		node.Exprs = fieldExprs(x.Fields)
+		srcs = []plan.Plan{x.Src}
+	case *WindowPlan:
+		node.Type = "Window"
+		for _, w := range x.Funcs {
+			node.Exprs = append(node.Exprs, w.String())
+		}
+		srcs = []plan.Plan{x.Src}
+	case *DistinctDefaultPlan:
+		node.Type = "Distinct"
+		srcs = []plan.Plan{x.Src}
+	case *OrderByDefaultPlan:
+		node.Type = "Sort"
+		for i, by := range x.By {
+			order := "ASC"
+			if !x.Ascs[i] {
+				order = "DESC"
+			}
+			node.OrderBy = append(node.OrderBy, fmt.Sprintf("%s %s", by, order))
+		}
+		srcs = []plan.Plan{x.Src}
+	case *OffsetDefaultPlan:
+		node.Type = "Offset"
+		node.Count = &x.Count
+		srcs = []plan.Plan{x.Src}
+	case *LimitDefaultPlan:
+		node.Type = "Limit"
+		node.Count = &x.Count
+		srcs = []plan.Plan{x.Src}
+	case *UnionPlan:
+		node.Type = "Union"
+		srcs = x.Srcs
+	case *RecursiveCTEPlan:
+		node.Type = "RecursiveCTE"
+		node.Table = x.Name
+		srcs = []plan.Plan{x.Seed, x.Recursive}
+	case *CTEWorkTablePlan:
+		node.Type = "CTEWorkTable"
+		node.Table = x.Name
+	default:
+		node.Type = strings.TrimPrefix(fmt.Sprintf("%T", p), "*plans.")
+	}
+	node.Fields = fieldNames(p.GetFields())
+	for _, src := range srcs {
+		node.Children = append(node.Children, explainJSONNode(ctx, src))
+	}
+	node.EstimatedRows = explainRowCount(ctx, p, node.Children)
+	return node
+}
+
+// explainRowCount returns the estimated row count of plan p whose source
+// plans are explained as children.
+func explainRowCount(ctx context.Context, p plan.Plan, children []*ExplainNode) float64 {
+	var src float64
+	if len(children) > 0 {
+		src = children[0].EstimatedRows
+	}
+	switch x := p.(type) {
+	case *TableDefaultPlan, *indexPlan, *NullPlan:
+		return estimate(ctx, x).rowCount
+	case *TableNilPlan:
+		return tableRowCount(ctx, x.T)
+	case *JoinPlan:
+		return src * children[1].EstimatedRows
+	case *FilterDefaultPlan, *HavingPlan, *SemiJoinPlan:
+		return src * selectionFactor
+	case *GroupByDefaultPlan:
+		if len(x.By) == 0 {
+			return 1
+		}
+		return src
+	case *indexEndpointsPlan:
+		return float64(len(x.descs))
+	case *SelectFromDualPlan:
+		return 1
+	case *OffsetDefaultPlan:
+		if src < float64(x.Count) {
+			return 0
+		}
+		return src - float64(x.Count)
+	case *LimitDefaultPlan:
+		if src > float64(x.Count) {
+			return float64(x.Count)
+		}
+		return src
+	case *UnionPlan:
+		var rows float64
+		for _, child := range children {
+			rows += child.EstimatedRows
+		}
+		return rows
+	}
+	if len(children) > 0 {
+		return src
+	}
+	return pseudoRowCount
+}
+
+func fieldExprs(fields []*field.Field) []string {
+	exprs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		exprs = append(exprs, f.String())
+	}
+	return exprs
+}
+
+func fieldNames(fields []*field.ResultField) []string {
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
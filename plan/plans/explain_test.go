@@ -15,9 +15,15 @@ package plans_test
 
 import (
 	"database/sql"
+	"encoding/json"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/plan/plans"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/mock"
 )
 
 type testExplainSuit struct{}
@@ -70,4 +76,85 @@ func (t *testExplainSuit) TestExplain(c *C) {
 	c.Assert(len(s), Greater, 0)
 	s = mustExplain(c, testDB, "explain select * from tt order by id desc;")
 	c.Assert(len(s), Greater, 0)
+	s = mustExplain(c, testDB, "explain analyze select * from tt2 where id > 0 order by id desc;")
+	c.Assert(s, Matches, `(?s).*Actual rows 1, 2 calls of Next, time .*, 1 kv reads.*`)
+	s = mustExplain(c, testDB, "explain format=json select * from tt2 where id > 0 order by id desc;")
+	c.Assert(s, Matches, `(?s).*"type": "IndexScan".*"spans": \[\s*"\(0,\+inf\]".*"descending": true.*`)
+}
+
+func (t *testExplainSuit) TestExplainAnalyze(c *C) {
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	ctx := &txnContext{Context: mock.NewContext(), txn: txn}
+	variable.BindSessionVars(ctx)
+
+	src := newCompositeTable(c, ctx, 60, false)
+	conds := []expression.Expression{newCompare(opcode.EQ, "tenant", 1)}
+	ip, _, err := plans.ChooseAccessPath(ctx, src, conds)
+	c.Assert(err, IsNil)
+	p := &plans.ExplainAnalyzePlan{
+		Src: &plans.LimitDefaultPlan{Count: 2, Src: &plans.RowStackFromPlan{Src: ip}},
+	}
+	var lines []string
+	for {
+		row, err := p.Next(ctx)
+		c.Assert(err, IsNil)
+		if row == nil {
+			break
+		}
+		lines = append(lines, row.Data[0].(string))
+	}
+	c.Assert(p.Close(), IsNil)
+	c.Assert(lines, HasLen, 6)
+	c.Assert(lines[0], Matches, `┌Iterate rows of table "t" using index "tc".*`)
+	// The index plan seeks the index once, moves to the next entry twice, and
+	// reads 3 columns of each row.
+	c.Assert(lines[2], Matches, `└Actual rows 2, 2 calls of Next, time .*, 9 kv reads`)
+	c.Assert(lines[3], Matches, `┌Limit 2 records.*`)
+	c.Assert(lines[5], Matches, `└Actual rows 2, 3 calls of Next, time .*, 9 kv reads`)
+}
+
+func (t *testExplainSuit) TestExplainJSON(c *C) {
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	ctx := &txnContext{Context: mock.NewContext(), txn: txn}
+	variable.BindSessionVars(ctx)
+
+	src := newCompositeTable(c, ctx, 60, false)
+	conds := []expression.Expression{newCompare(opcode.EQ, "tenant", 1)}
+	ip, _, err := plans.ChooseAccessPath(ctx, src, conds)
+	c.Assert(err, IsNil)
+	p := &plans.ExplainJSONPlan{
+		Src: &plans.LimitDefaultPlan{Count: 2, Src: &plans.RowStackFromPlan{Src: ip}},
+	}
+	row, err := p.Next(ctx)
+	c.Assert(err, IsNil)
+	var node plans.ExplainNode
+	c.Assert(json.Unmarshal([]byte(row.Data[0].(string)), &node), IsNil)
+	row, err = p.Next(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(row, IsNil)
+	c.Assert(p.Close(), IsNil)
+
+	c.Assert(node.Type, Equals, "Limit")
+	c.Assert(*node.Count, Equals, uint64(2))
+	c.Assert(node.EstimatedRows, Equals, float64(2))
+	// RowStackFromPlan is skipped.
+	c.Assert(node.Children, HasLen, 1)
+	scan := node.Children[0]
+	c.Assert(scan.Type, Equals, "IndexScan")
+	c.Assert(scan.Table, Equals, "t")
+	c.Assert(scan.Index, Equals, "tc")
+	c.Assert(scan.SpanColumn, Equals, "tenant")
+	c.Assert(scan.Spans, DeepEquals, []string{"[1,1]"})
+	c.Assert(scan.EstimatedRows, Equals, float64(10))
+	c.Assert(scan.Children, HasLen, 0)
 }
@@ -0,0 +1,267 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/codec"
+)
+
+// minRunShare is the inverse of the min share of the memory quota held by the
+// rows of a spilled run.
+const minRunShare = 16
+
+// externalSorter sorts rows by their keys. The rows are sorted in memory until
+// the memory of the session exceeds the quota, then each batch of sorted rows
+// is spilled to disk as a run, and the runs are merged when the rows are read.
+type externalSorter struct {
+	ascs    []bool
+	tracker *memTracker
+
+	table    *orderByTable
+	memUsage int64
+	spill    *spillStore
+	runs     int64
+
+	cursor int
+	merger *runMerger
+}
+
+func newExternalSorter(ctx context.Context, ascs []bool) *externalSorter {
+	return &externalSorter{
+		ascs:    ascs,
+		tracker: getMemTracker(ctx),
+		table:   &orderByTable{Ascs: ascs},
+	}
+}
+
+// allAscs returns the sort directions of n keys which are all ascending.
+func allAscs(n int) []bool {
+	ascs := make([]bool, n)
+	for i := range ascs {
+		ascs[i] = true
+	}
+	return ascs
+}
+
+// add adds a row with its sort key, it must be called before finish.
+func (s *externalSorter) add(key []interface{}, row *plan.Row) error {
+	s.table.Rows = append(s.table.Rows, &orderByRow{Key: key, Row: row})
+	n := valuesMemUsage(key) + rowMemUsage(row) + valuesMemUsage(row.FromData)
+	s.memUsage += n
+	// A run is spilled only if it has a share of the quota, or the runs would be
+	// tiny when the memory of the session is held by other operators.
+	if !s.tracker.consume(n) || s.memUsage < s.tracker.quota/minRunShare {
+		return nil
+	}
+	return errors.Trace(s.spillRun())
+}
+
+// spillRun sorts the rows in memory and spills them to disk as a run.
+func (s *externalSorter) spillRun() error {
+	if s.spill == nil {
+		spill, err := newSpillStore()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		s.spill = spill
+	}
+	sort.Sort(s.table)
+	prefix := codec.EncodeInt(nil, s.runs)
+	for _, r := range s.table.Rows {
+		if err := s.spill.put(prefix, encodeSortRow(r)); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	s.runs++
+	s.table.Rows = nil
+	s.tracker.release(s.memUsage)
+	s.memUsage = 0
+	return nil
+}
+
+// finish sorts all the added rows, then they can be read by next.
+func (s *externalSorter) finish() error {
+	if s.spill == nil {
+		sort.Sort(s.table)
+		return nil
+	}
+	if len(s.table.Rows) > 0 {
+		if err := s.spillRun(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	s.merger = &runMerger{ascs: s.ascs}
+	for i := int64(0); i < s.runs; i++ {
+		cur, err := s.spill.seek(codec.EncodeInt(nil, i))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		src := &runSource{cur: cur}
+		if err = src.read(); err != nil {
+			cur.close()
+			return errors.Trace(err)
+		}
+		if src.head == nil {
+			continue
+		}
+		s.merger.sources = append(s.merger.sources, src)
+	}
+	heap.Init(s.merger)
+	return nil
+}
+
+// next returns the next row in order, or nil if there is no more row.
+func (s *externalSorter) next() (*orderByRow, error) {
+	if s.merger != nil {
+		r, err := s.merger.next()
+		return r, errors.Trace(err)
+	}
+	if s.cursor == len(s.table.Rows) {
+		return nil, nil
+	}
+	r := s.table.Rows[s.cursor]
+	s.cursor++
+	return r, nil
+}
+
+// close releases the rows and removes the spilled runs.
+func (s *externalSorter) close() error {
+	s.table.Rows = nil
+	s.tracker.release(s.memUsage)
+	s.memUsage = 0
+	s.cursor = 0
+	if s.merger != nil {
+		s.merger.close()
+		s.merger = nil
+	}
+	if s.spill == nil {
+		return nil
+	}
+	err := s.spill.close()
+	s.spill = nil
+	s.runs = 0
+	return errors.Trace(err)
+}
+
+// runSource is a spilled run being merged, head is the next row of the run.
+type runSource struct {
+	cur  *spillCursor
+	head *orderByRow
+}
+
+func (src *runSource) read() error {
+	row, err := src.cur.next()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	src.head = nil
+	if row != nil {
+		src.head = decodeSortRow(row)
+	}
+	return nil
+}
+
+// runMerger merges the sorted runs with a heap of their head rows.
+type runMerger struct {
+	ascs    []bool
+	sources []*runSource
+}
+
+// Len implements heap.Interface Len interface.
+func (m *runMerger) Len() int {
+	return len(m.sources)
+}
+
+// Less implements heap.Interface Less interface.
+func (m *runMerger) Less(i, j int) bool {
+	return compareSortKeys(m.sources[i].head.Key, m.sources[j].head.Key, m.ascs) < 0
+}
+
+// Swap implements heap.Interface Swap interface.
+func (m *runMerger) Swap(i, j int) {
+	m.sources[i], m.sources[j] = m.sources[j], m.sources[i]
+}
+
+// Push implements heap.Interface Push interface.
+func (m *runMerger) Push(x interface{}) {
+	m.sources = append(m.sources, x.(*runSource))
+}
+
+// Pop implements heap.Interface Pop interface.
+func (m *runMerger) Pop() interface{} {
+	n := len(m.sources)
+	src := m.sources[n-1]
+	m.sources = m.sources[:n-1]
+	return src
+}
+
+// next returns the least head row of the runs, and reads the next row of its run.
+func (m *runMerger) next() (*orderByRow, error) {
+	if len(m.sources) == 0 {
+		return nil, nil
+	}
+	src := m.sources[0]
+	r := src.head
+	if err := src.read(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if src.head == nil {
+		heap.Pop(m)
+	} else {
+		heap.Fix(m, 0)
+	}
+	return r, nil
+}
+
+func (m *runMerger) close() {
+	for _, src := range m.sources {
+		src.cur.close()
+	}
+	m.sources = nil
+}
+
+// encodeSortRow packs the key, the data and the from data of a row to be
+// spilled into the data of a row, after the lengths of the key and the data.
+func encodeSortRow(r *orderByRow) *plan.Row {
+	data := make([]interface{}, 0, 2+len(r.Key)+len(r.Row.Data)+len(r.Row.FromData))
+	data = append(data, int64(len(r.Key)), int64(len(r.Row.Data)))
+	data = append(data, r.Key...)
+	data = append(data, r.Row.Data...)
+	data = append(data, r.Row.FromData...)
+	return &plan.Row{Data: data, RowKeys: r.Row.RowKeys}
+}
+
+// decodeSortRow unpacks the row packed by encodeSortRow.
+func decodeSortRow(row *plan.Row) *orderByRow {
+	keyLen := int(row.Data[0].(int64))
+	dataLen := int(row.Data[1].(int64))
+	vals := row.Data[2:]
+	r := &orderByRow{
+		Key: vals[:keyLen],
+		Row: &plan.Row{
+			Data:    vals[keyLen : keyLen+dataLen],
+			RowKeys: row.RowKeys,
+		},
+	}
+	if fromData := vals[keyLen+dataLen:]; len(fromData) > 0 {
+		r.Row.FromData = fromData
+	}
+	return r
+}
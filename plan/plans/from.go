@@ -103,7 +103,9 @@ func (r *TableNilPlan) Close() error {
 type TableDefaultPlan struct {
 	T      table.Table
 	Fields []*field.ResultField
-	iter   kv.Iterator
+	// IndexHints limit the indices which can be used to read the table.
+	IndexHints []*IndexHint
+	iter       kv.Iterator
 }
 
 // Explain implements the plan.Plan Explain interface.
@@ -136,7 +138,7 @@ func (r *TableDefaultPlan) filterBinOp(ctx context.Context, x *expression.Binary
 		return nil, false, errors.Errorf("No such column: %s", cn)
 	}
 
-	ix := t.FindIndexByColName(cn)
+	ix := findIndexByLeadingCol(r.indices(), cn)
 	if ix == nil { // Column cn has no index.
 		return r, false, nil
 	}
@@ -147,6 +149,7 @@ func (r *TableDefaultPlan) filterBinOp(ctx context.Context, x *expression.Binary
 	}
 	return &indexPlan{
 		src:     t,
+		cols:    indexColumns(t, ix),
 		col:     c,
 		unique:  ix.Unique,
 		idxName: ix.Name.O,
@@ -168,7 +171,7 @@ func (r *TableDefaultPlan) filterIdent(ctx context.Context, x *expression.Ident,
 		}
 
 		ix := t.Indices()[xi]
-		if ix == nil { // Column cn has no index.
+		if ix == nil || !r.usableIndex(ix) { // Column cn has no index.
 			return r, false, nil
 		}
 		var spans []*indexSpan
@@ -179,6 +182,7 @@ func (r *TableDefaultPlan) filterIdent(ctx context.Context, x *expression.Ident,
 		}
 		return &indexPlan{
 			src:     t,
+			cols:    indexColumns(t, ix),
 			col:     v,
 			unique:  ix.Unique,
 			idxName: ix.Name.L,
@@ -203,7 +207,7 @@ func (r *TableDefaultPlan) filterIsNull(ctx context.Context, x *expression.IsNul
 
 	cn := cns[0]
 	t := r.T
-	ix := t.FindIndexByColName(cn)
+	ix := findIndexByLeadingCol(r.indices(), cn)
 	if ix == nil { // Column cn has no index.
 		return r, false, nil
 	}
@@ -216,6 +220,7 @@ func (r *TableDefaultPlan) filterIsNull(ctx context.Context, x *expression.IsNul
 	}
 	return &indexPlan{
 		src:     t,
+		cols:    indexColumns(t, ix),
 		col:     col,
 		unique:  ix.Unique,
 		idxName: ix.Name.L,
@@ -224,6 +229,34 @@ func (r *TableDefaultPlan) filterIsNull(ctx context.Context, x *expression.IsNul
 	}, true, nil
 }
 
+// findIndexByLeadingCol finds an index in indices whose first column is cn. A unique
+// single column index is preferred for point lookups, otherwise the index with the most
+// columns is chosen, as conditions on its following columns can narrow down the scan.
+func findIndexByLeadingCol(indices []*column.IndexedCol, cn string) *column.IndexedCol {
+	if ix := findSingleColumnIndex(indices, cn); ix != nil && ix.Unique {
+		return ix
+	}
+	var found *column.IndexedCol
+	for _, ix := range indices {
+		if ix == nil || len(ix.Columns) == 0 || ix.Columns[0].Name.L != strings.ToLower(cn) {
+			continue
+		}
+		if found == nil || len(ix.Columns) > len(found.Columns) {
+			found = ix
+		}
+	}
+	return found
+}
+
+// indexColumns returns the columns of index ix in table t.
+func indexColumns(t table.Table, ix *column.IndexedCol) []*column.Col {
+	cols := make([]*column.Col, 0, len(ix.Columns))
+	for _, ic := range ix.Columns {
+		cols = append(cols, t.Cols()[ic.Offset])
+	}
+	return cols
+}
+
 // FilterForUpdateAndDelete is for updating and deleting (without checking return
 // columns), in order to check whether if we can use IndexPlan or not.
 func (r *TableDefaultPlan) FilterForUpdateAndDelete(ctx context.Context, expr expression.Expression) (plan.Plan, bool, error) {
@@ -253,6 +286,8 @@ func (r *TableDefaultPlan) filter(ctx context.Context, expr expression.Expressio
 		return r.filterIdent(ctx, x, true)
 	case *expression.IsNull:
 		return r.filterIsNull(ctx, x)
+	case *expression.PatternIn:
+		return r.filterPatternIn(ctx, x)
 	case *expression.UnaryOperation:
 		if x.Op != '!' {
 			break
@@ -33,7 +33,9 @@ var (
 )
 
 // GroupByDefaultPlan handles GROUP BY statement, GroupByDefaultPlan uses an
-// in-memory table to aggregate values.
+// in-memory table to aggregate values. If the groups exceed the memory quota
+// of the session, the rows of new groups are sorted by group keys on disk, and
+// aggregated group by group after the groups in memory.
 //
 // Table: Subject_Selection
 // Subject   Semester   Attendee
@@ -51,8 +53,13 @@ type GroupByDefaultPlan struct {
 	Src plan.Plan
 	By  []expression.Expression
 
-	rows   []*groupRow
-	cursor int
+	rows     []*groupRow
+	cursor   int
+	memUsage int64
+	tracker  *memTracker
+	// sorter sorts the rows of the groups which are not in memory.
+	sorter     *externalSorter
+	spilledRow *orderByRow
 }
 
 // Explain implements plan.Plan Explain interface.
@@ -234,17 +241,73 @@ func (r *GroupByDefaultPlan) Next(ctx context.Context) (row *plan.Row, err error
 			return nil, errors.Trace(err)
 		}
 	}
-	if r.cursor == len(r.rows) {
+	var gRow *groupRow
+	if r.cursor < len(r.rows) {
+		gRow = r.rows[r.cursor]
+		r.cursor++
+	} else if r.sorter != nil {
+		gRow, err = r.nextSpilledGroup(ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if gRow == nil {
 		return
 	}
-
-	gRow := r.rows[r.cursor]
-	r.cursor++
 	row = gRow.Row
 	updateRowStack(ctx, row.Data, row.FromData)
 	return
 }
 
+// nextSpilledGroup aggregates the next group of the rows sorted by group keys.
+func (r *GroupByDefaultPlan) nextSpilledGroup(ctx context.Context) (*groupRow, error) {
+	var (
+		gRow *groupRow
+		key  []interface{}
+	)
+	for {
+		if r.spilledRow == nil {
+			sRow, err := r.sorter.next()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if sRow == nil {
+				break
+			}
+			r.spilledRow = sRow
+		}
+		if gRow != nil && compareSortKeys(key, r.spilledRow.Key, r.sorter.ascs) != 0 {
+			break
+		}
+		sRow := r.spilledRow
+		r.spilledRow = nil
+		srcRow := sRow.Row
+		row := &plan.Row{
+			Data:     make([]interface{}, len(r.Fields)),
+			FromData: srcRow.Data,
+		}
+		evalArgs := map[interface{}]interface{}{}
+		if err := r.evalNoneAggFields(ctx, row.Data, evalArgs, srcRow.Data); err != nil {
+			return nil, errors.Trace(err)
+		}
+		updateRowStack(ctx, row.Data, row.FromData)
+		if gRow == nil {
+			gRow = &groupRow{Row: row, Args: evalArgs}
+			key = sRow.Key
+		}
+		if err := r.evalAggFields(ctx, row.Data, gRow.Args, srcRow.Data); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if gRow == nil {
+		return nil, nil
+	}
+	if err := r.evalAggDone(ctx, gRow.Row.Data, gRow.Args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return gRow, nil
+}
+
 func (r *GroupByDefaultPlan) fetchAll(ctx context.Context) error {
 	// TODO: now we have to use this to save group key -> row index
 	// later we will serialize group by items into a string key and then use a map instead.
@@ -259,6 +322,7 @@ func (r *GroupByDefaultPlan) fetchAll(ctx context.Context) error {
 		}
 	}()
 	k := make([]interface{}, len(r.By))
+	r.tracker = getMemTracker(ctx)
 	for {
 		srcRow, err1 := r.Src.Next(ctx)
 		if err1 != nil {
@@ -293,7 +357,14 @@ func (r *GroupByDefaultPlan) fetchAll(ctx context.Context) error {
 		}
 
 		index := 0
-		if len(v) == 0 {
+		if len(v) == 0 && r.sorter != nil {
+			// the groups in memory exceed the quota, the row is aggregated later.
+			key := append([]interface{}(nil), k...)
+			if err := r.sorter.add(key, srcRow); err != nil {
+				return errors.Trace(err)
+			}
+			continue
+		} else if len(v) == 0 {
 			// no group for key, save data for this group
 			index = len(r.rows)
 			r.rows = append(r.rows, &groupRow{Row: row, Args: evalArgs})
@@ -301,6 +372,11 @@ func (r *GroupByDefaultPlan) fetchAll(ctx context.Context) error {
 			if err := t.Set(k, []interface{}{index}); err != nil {
 				return errors.Trace(err)
 			}
+			n := rowMemUsage(row) + valuesMemUsage(row.FromData) + valuesMemUsage(k)
+			r.memUsage += n
+			if r.tracker.consume(n) {
+				r.sorter = newExternalSorter(ctx, allAscs(len(k)))
+			}
 		} else {
 			// we have already saved data in the group by key, use this
 			index = v[0].(int)
@@ -314,6 +390,11 @@ func (r *GroupByDefaultPlan) fetchAll(ctx context.Context) error {
 			return errors.Trace(err)
 		}
 	}
+	if r.sorter != nil {
+		if err := r.sorter.finish(); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	if len(r.rows) == 0 {
 		// empty table
 		var out []interface{}
@@ -338,5 +419,17 @@ func (r *GroupByDefaultPlan) fetchAll(ctx context.Context) error {
 func (r *GroupByDefaultPlan) Close() error {
 	r.rows = nil
 	r.cursor = 0
+	if r.tracker != nil {
+		r.tracker.release(r.memUsage)
+	}
+	r.memUsage = 0
+	r.spilledRow = nil
+	if r.sorter != nil {
+		err := r.sorter.close()
+		r.sorter = nil
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 	return r.Src.Close()
 }
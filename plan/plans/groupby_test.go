@@ -20,6 +20,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/plan/plans"
 	"github.com/pingcap/tidb/rset/rsets"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/mock"
 )
 
@@ -109,3 +110,62 @@ func (t *testGroupBySuite) TestGroupBy(c *C) {
 		return true, nil
 	})
 }
+
+func (t *testGroupBySuite) TestGroupBySpill(c *C) {
+	var data []*testRowData
+	for i := 0; i < 30; i++ {
+		data = append(data, &testRowData{int64(i), []interface{}{int64(i % 7), int64(i)}})
+	}
+	tblPlan := &testTablePlan{data, []string{"id", "v"}, 0}
+	sl := &plans.SelectList{
+		Fields: []*field.Field{
+			{
+				Expr: &expression.Ident{
+					CIStr:      model.NewCIStr("id"),
+					ReferScope: expression.IdentReferFromTable,
+					ReferIndex: 0,
+				},
+			},
+			{
+				Expr: &expression.Call{
+					F: "count",
+					Args: []expression.Expression{
+						&expression.Ident{
+							CIStr:      model.NewCIStr("v"),
+							ReferScope: expression.IdentReferFromTable,
+							ReferIndex: 1,
+						},
+					},
+				},
+			},
+		},
+		AggFields: map[int]struct{}{1: {}},
+	}
+	groupbyPlan := &plans.GroupByDefaultPlan{
+		SelectList: sl,
+		Src:        tblPlan,
+		By: []expression.Expression{
+			&expression.Ident{
+				CIStr:      model.NewCIStr("id"),
+				ReferScope: expression.IdentReferFromTable,
+				ReferIndex: 0,
+			},
+		},
+	}
+
+	// Only the first group is in memory, the others are sorted on disk.
+	ctx := mock.NewContext()
+	variable.BindSessionVars(ctx)
+	variable.GetSessionVars(ctx).Systems[variable.TiDBMemQuota] = "1"
+	rset := rsets.Recordset{Plan: groupbyPlan, Ctx: ctx}
+	var ret []interface{}
+	err := rset.Do(func(data []interface{}) (bool, error) {
+		ret = append(ret, data...)
+		return true, nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(ret, DeepEquals, []interface{}{
+		int64(0), int64(5), int64(1), int64(5), int64(2), int64(4), int64(3), int64(4),
+		int64(4), int64(4), int64(5), int64(4), int64(6), int64(4),
+	})
+}
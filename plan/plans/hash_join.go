@@ -0,0 +1,438 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// Key classes of the join key values. Values of the same class are hashed
+// in a way that equal values get the same hash key. Values of different
+// classes may still be equal after type conversion, so they are compared
+// with the ON condition one by one.
+const (
+	keyClassNumber = 1 << iota
+	keyClassString
+	keyClassTime
+	keyClassDuration
+	keyClassOther
+)
+
+// hashJoiner joins rows with a hash table on the equal conditions of ON expression.
+// The rows of the build side are put into the hash table, and each row from the
+// probe side looks up its matched rows in the hash table. The build rows are
+// spilled to disk when the memory of the session exceeds the quota.
+type hashJoiner struct {
+	// buildRight is true if the right plan is the build side.
+	buildRight bool
+	// buildKeys and probeKeys are the offsets of join keys in build and probe rows.
+	buildKeys []int
+	probeKeys []int
+	// keyClasses is the bit set of key classes of build side values at each key.
+	keyClasses []int
+
+	table    map[string][]*plan.Row
+	memUsage int64
+	tracker  *memTracker
+	spill    *spillStore
+}
+
+// equalJoinKeys extracts the column offsets of the equal conditions
+// `left.col = right.col` from ON expression, the offsets of right columns
+// are relative to the right row.
+func (r *JoinPlan) equalJoinKeys() (leftKeys []int, rightKeys []int) {
+	leftLen := len(r.Left.GetFields())
+	for _, cond := range SplitConjuncts(r.On) {
+		x, ok := cond.(*expression.BinaryOperation)
+		if !ok || x.Op != opcode.EQ {
+			continue
+		}
+		l, ok1 := unwrapIdent(x.L)
+		rr, ok2 := unwrapIdent(x.R)
+		if !ok1 || !ok2 {
+			continue
+		}
+		if !r.isJoinedIdent(l) || !r.isJoinedIdent(rr) {
+			continue
+		}
+		if l.ReferIndex >= leftLen {
+			l, rr = rr, l
+		}
+		if l.ReferIndex >= leftLen || rr.ReferIndex < leftLen {
+			// Both sides reference the same plan.
+			continue
+		}
+		leftKeys = append(leftKeys, l.ReferIndex)
+		rightKeys = append(rightKeys, rr.ReferIndex-leftLen)
+	}
+	return
+}
+
+func (r *JoinPlan) isJoinedIdent(i *expression.Ident) bool {
+	return i.ReferScope == expression.IdentReferFromTable && i.ReferIndex >= 0 && i.ReferIndex < len(r.Fields)
+}
+
+func unwrapIdent(e expression.Expression) (*expression.Ident, bool) {
+	i, ok := UnwrapPExpr(e).(*expression.Ident)
+	return i, ok
+}
+
+// canHashJoin returns whether the join can be done by hash, that is
+// there are equal conditions between the two plans in ON expression.
+func (r *JoinPlan) canHashJoin() bool {
+	if r.Right == nil || r.On == nil {
+		return false
+	}
+	switch r.Type {
+	case CrossJoin, LeftJoin, RightJoin:
+		leftKeys, _ := r.equalJoinKeys()
+		return len(leftKeys) > 0
+	}
+	return false
+}
+
+// newHashJoiner returns a hashJoiner for the join, or nil if the join can't be done by hash.
+func (r *JoinPlan) newHashJoiner(ctx context.Context) *hashJoiner {
+	if !r.canHashJoin() {
+		return nil
+	}
+	leftKeys, rightKeys := r.equalJoinKeys()
+	hj := &hashJoiner{}
+	switch r.Type {
+	case LeftJoin:
+		hj.buildRight = true
+	case RightJoin:
+		hj.buildRight = false
+	default:
+		// Build the hash table with the smaller side.
+		hj.buildRight = estimate(ctx, r.Right).rowCount <= estimate(ctx, r.Left).rowCount
+	}
+	if hj.buildRight {
+		hj.buildKeys, hj.probeKeys = rightKeys, leftKeys
+	} else {
+		hj.buildKeys, hj.probeKeys = leftKeys, rightKeys
+	}
+	return hj
+}
+
+func keyClass(v interface{}) int {
+	switch v.(type) {
+	case int64, uint64, float64, int, float32, bool, mysql.Decimal:
+		return keyClassNumber
+	case string, []byte:
+		return keyClassString
+	case mysql.Time:
+		return keyClassTime
+	case mysql.Duration:
+		return keyClassDuration
+	}
+	return keyClassOther
+}
+
+// hashKeyValue converts v to a value which is the same for all equal values of its class.
+func hashKeyValue(v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case []byte:
+		return string(x), nil
+	case string:
+		return x, nil
+	case mysql.Time:
+		return x.Time.UTC().Format(time.RFC3339Nano), nil
+	case mysql.Duration:
+		return int64(x.Duration), nil
+	}
+	f, err := types.ToFloat64(v)
+	return f, errors.Trace(err)
+}
+
+// hashKey returns the hash key of the row, hasNull is true if any key value is null.
+// The class of each key value is also returned.
+func hashKey(row *plan.Row, keys []int, classes []int) (key []byte, hasNull bool, err error) {
+	vals := make([]interface{}, len(keys))
+	for i, offset := range keys {
+		vals[i] = row.Data[offset]
+	}
+	return hashKeyValues(vals, classes)
+}
+
+// hashKeyValues returns the hash key of the values, hasNull is true if any value
+// is null. The class of each value is also returned.
+func hashKeyValues(vals []interface{}, classes []int) (key []byte, hasNull bool, err error) {
+	hashVals := make([]interface{}, len(vals))
+	for i, v := range vals {
+		if v == nil {
+			return nil, true, nil
+		}
+		classes[i] = keyClass(v)
+		if classes[i] == keyClassOther {
+			continue
+		}
+		hashVals[i], err = hashKeyValue(v)
+		if err != nil {
+			return nil, false, errors.Trace(err)
+		}
+	}
+	key, err = codec.EncodeKey(hashVals...)
+	return key, false, errors.Trace(err)
+}
+
+// rowMemUsage estimates the memory used by the row.
+func rowMemUsage(row *plan.Row) int64 {
+	size := int64(48+32*len(row.RowKeys)) + valuesMemUsage(row.Data)
+	for _, rk := range row.RowKeys {
+		size += int64(len(rk.Key))
+	}
+	return size
+}
+
+// valuesMemUsage estimates the memory used by the values.
+func valuesMemUsage(vals []interface{}) int64 {
+	size := int64(16 * len(vals))
+	for _, v := range vals {
+		switch x := v.(type) {
+		case string:
+			size += int64(len(x))
+		case []byte:
+			size += int64(len(x))
+		case mysql.Decimal:
+			size += 32
+		case mysql.Time:
+			size += 24
+		}
+	}
+	return size
+}
+
+// build reads all the rows from the build side into the hash table.
+func (hj *hashJoiner) build(ctx context.Context, src plan.Plan) error {
+	hj.tracker = getMemTracker(ctx)
+	hj.table = make(map[string][]*plan.Row)
+	hj.keyClasses = make([]int, len(hj.buildKeys))
+	classes := make([]int, len(hj.buildKeys))
+	for {
+		row, err := src.Next(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		key, hasNull, err := hashKey(row, hj.buildKeys, classes)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if hasNull {
+			// A null key never matches.
+			continue
+		}
+		for i, c := range classes {
+			hj.keyClasses[i] |= c
+		}
+		// Copy the row data, the source plan may reuse it.
+		row = &plan.Row{
+			Data:    append([]interface{}(nil), row.Data...),
+			RowKeys: row.RowKeys,
+		}
+		if err = hj.put(key, row); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if hj.spill != nil {
+		return errors.Trace(hj.spill.flush())
+	}
+	return nil
+}
+
+func (hj *hashJoiner) put(key []byte, row *plan.Row) error {
+	if hj.spill != nil {
+		return errors.Trace(hj.spill.put(key, row))
+	}
+	hj.table[string(key)] = append(hj.table[string(key)], row)
+	n := rowMemUsage(row) + int64(len(key))
+	hj.memUsage += n
+	if !hj.tracker.consume(n) {
+		return nil
+	}
+
+	// Move all the rows to the spill store.
+	spill, err := newSpillStore()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	hj.spill = spill
+	for k, rows := range hj.table {
+		for _, row := range rows {
+			if err = spill.put([]byte(k), row); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	hj.table = nil
+	hj.tracker.release(hj.memUsage)
+	hj.memUsage = 0
+	return nil
+}
+
+// probe calls fn with each build row which may match the probe row.
+func (hj *hashJoiner) probe(row *plan.Row, fn func(buildRow *plan.Row) error) error {
+	classes := make([]int, len(hj.probeKeys))
+	key, hasNull, err := hashKey(row, hj.probeKeys, classes)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if hasNull {
+		return nil
+	}
+
+	scanAll := false
+	for i, c := range classes {
+		if c == keyClassOther || hj.keyClasses[i]&^c != 0 {
+			scanAll = true
+			break
+		}
+	}
+
+	if hj.spill != nil {
+		if scanAll {
+			key = nil
+		}
+		return errors.Trace(hj.spill.iterate(key, func(buildRow *plan.Row) (bool, error) {
+			return true, errors.Trace(fn(buildRow))
+		}))
+	}
+	if !scanAll {
+		for _, buildRow := range hj.table[string(key)] {
+			if err = fn(buildRow); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	}
+	for _, rows := range hj.table {
+		for _, buildRow := range rows {
+			if err = fn(buildRow); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return nil
+}
+
+func (hj *hashJoiner) close() error {
+	hj.table = nil
+	if hj.tracker != nil {
+		hj.tracker.release(hj.memUsage)
+	}
+	hj.memUsage = 0
+	if hj.spill != nil {
+		err := hj.spill.close()
+		hj.spill = nil
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// nextHashJoin gets the next joined row with the hash joiner.
+func (r *JoinPlan) nextHashJoin(ctx context.Context) (row *plan.Row, err error) {
+	hj := r.hashJoiner
+	if !r.hashBuilt {
+		buildPlan := r.Left
+		if hj.buildRight {
+			buildPlan = r.Right
+		}
+		if err = hj.build(ctx, buildPlan); err != nil {
+			return nil, errors.Trace(err)
+		}
+		r.hashBuilt = true
+	}
+	probePlan := r.Right
+	if hj.buildRight {
+		probePlan = r.Left
+	}
+	// Only outer joins keep the unmatched probe rows.
+	outer := r.Type != CrossJoin
+	for {
+		if r.cursor < len(r.matchedRows) {
+			row = r.matchedRows[r.cursor]
+			r.cursor++
+			return
+		}
+		var probeRow *plan.Row
+		probeRow, err = probePlan.Next(ctx)
+		if probeRow == nil || err != nil {
+			return nil, errors.Trace(err)
+		}
+		r.cursor = 0
+		r.matchedRows = nil
+		err = hj.probe(probeRow, func(buildRow *plan.Row) error {
+			left, right := probeRow, buildRow
+			if !hj.buildRight {
+				left, right = buildRow, probeRow
+			}
+			joined, err1 := r.joinRows(ctx, left, right)
+			if err1 != nil || joined == nil {
+				return errors.Trace(err1)
+			}
+			r.matchedRows = append(r.matchedRows, joined)
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(r.matchedRows) == 0 && outer {
+			r.matchedRows = append(r.matchedRows, r.padNullRow(probeRow, hj.buildRight))
+		}
+	}
+}
+
+// joinRows joins the left and right rows, it returns nil if the joined row
+// doesn't satisfy ON condition.
+func (r *JoinPlan) joinRows(ctx context.Context, left, right *plan.Row) (*plan.Row, error) {
+	// To prevent outer modify the slice. See comment in findMatchedRows.
+	joined := make([]interface{}, 0, len(left.Data)+len(right.Data))
+	joined = append(append(joined, left.Data...), right.Data...)
+	r.evalArgs[expression.ExprEvalIdentReferFunc] = func(name string, scope int, index int) (interface{}, error) {
+		return joined[index], nil
+	}
+	b, err := expression.EvalBoolExpr(ctx, r.On, r.evalArgs)
+	if err != nil || !b {
+		return nil, errors.Trace(err)
+	}
+	keys := make([]*plan.RowKeyEntry, 0, len(left.RowKeys)+len(right.RowKeys))
+	keys = append(append(keys, left.RowKeys...), right.RowKeys...)
+	return &plan.Row{Data: joined, RowKeys: keys}, nil
+}
+
+// padNullRow pads the unmatched row of outer join with null values,
+// isLeft is true if the row is from the left plan.
+func (r *JoinPlan) padNullRow(row *plan.Row, isLeft bool) *plan.Row {
+	data := make([]interface{}, 0, len(r.Fields))
+	if isLeft {
+		data = append(data, row.Data...)
+		data = append(data, make([]interface{}, len(r.Fields)-len(row.Data))...)
+	} else {
+		data = append(data, make([]interface{}, len(r.Fields)-len(row.Data))...)
+		data = append(data, row.Data...)
+	}
+	return &plan.Row{Data: data, RowKeys: row.RowKeys}
+}
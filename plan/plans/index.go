@@ -15,6 +15,7 @@ package plans
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/column"
@@ -22,8 +23,10 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/field"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/format"
@@ -60,6 +63,29 @@ type indexSpan struct {
 	highExclude bool
 }
 
+// String implements fmt.Stringer interface.
+func (span *indexSpan) String() string {
+	open := "["
+	close := "]"
+	if span.lowExclude {
+		open = "("
+	}
+	if span.highExclude {
+		close = ")"
+	}
+	return fmt.Sprintf("%s%v,%v%s", open, span.lowVal, span.highVal, close)
+}
+
+// isPoint returns whether the span has only one value.
+func (span *indexSpan) isPoint() bool {
+	return !span.lowExclude && !span.highExclude && indexCompare(span.lowVal, span.highVal) == 0
+}
+
+// isEmpty returns whether the span has no value, e.g. (1,1].
+func (span *indexSpan) isEmpty() bool {
+	return (span.lowExclude || span.highExclude) && indexCompare(span.lowVal, span.highVal) == 0
+}
+
 // cut off the range less than val and return the new span.
 // the new span may be nil if val is larger than span's high value.
 func (span *indexSpan) cutOffLow(val interface{}, exclude bool) *indexSpan {
@@ -122,9 +148,16 @@ func (span *indexSpan) cutOffHigh(val interface{}, exclude bool) *indexSpan {
 	}
 }
 
+// indexPlan scans the rows of an index within spans. For a multi-column index,
+// the leading columns may be bound to single values by equal conditions, then
+// the spans are on the next column.
 type indexPlan struct {
 	src        table.Table
-	col        *column.Col
+	cols       []*column.Col // all the columns of the index.
+	eqVals     []interface{} // values of the leading columns bound by equal conditions.
+	col        *column.Col   // the column which the spans are on, it follows the eqVals.
+	covering   bool          // read the column values from the index entries, not the rows.
+	desc       bool          // read the rows in descending order of the index.
 	unique     bool
 	idxName    string
 	idx        kv.Index
@@ -132,6 +165,8 @@ type indexPlan struct {
 	cursor     int
 	skipLowCmp bool
 	iter       kv.IndexIterator
+	batchRows  []*plan.Row    // the rows read in a batch, see readBatch.
+	tsLoc      *time.Location // the time zone of the TIMESTAMP values in the index keys.
 }
 
 // comparison function that takes minNotNullVal and maxVal into account.
@@ -140,7 +175,7 @@ func indexCompare(a interface{}, b interface{}) int {
 		return 0
 	} else if b == nil {
 		return 1
-	} else if b == nil {
+	} else if a == nil {
 		return -1
 	}
 
@@ -173,17 +208,20 @@ func indexCompare(a interface{}, b interface{}) int {
 
 // Explain implements plan.Plan Explain interface.
 func (r *indexPlan) Explain(w format.Formatter) {
-	w.Format("┌Iterate rows of table %q using index %q where %s in ", r.src.TableName(), r.idxName, r.col.Name.L)
+	w.Format("┌Iterate rows of table %q using index %q ", r.src.TableName(), r.idxName)
+	if r.covering {
+		w.Format("only ")
+	}
+	w.Format("where ")
+	for i, v := range r.eqVals {
+		w.Format("%s = %v and ", r.cols[i].Name.L, v)
+	}
+	w.Format("%s in ", r.col.Name.L)
 	for _, span := range r.spans {
-		open := "["
-		close := "]"
-		if span.lowExclude {
-			open = "("
-		}
-		if span.highExclude {
-			close = ")"
-		}
-		w.Format("%s%v,%v%s ", open, span.lowVal, span.highVal, close)
+		w.Format("%s ", span)
+	}
+	if r.desc {
+		w.Format("in descending order")
 	}
 	w.Format("\n└Output field names %v\n", field.RFQNames(r.GetFields()))
 }
@@ -211,7 +249,7 @@ func (r *indexPlan) Filter(ctx context.Context, expr expression.Expression) (pla
 			break
 		}
 		if r.col.ColumnInfo.Name.L != cname {
-			break
+			return r.filterNextColumn(cname, x.Op, val)
 		}
 		seekVal, err := types.Convert(val, &r.col.FieldType)
 		if err != nil {
@@ -236,6 +274,11 @@ func (r *indexPlan) Filter(ctx context.Context, expr expression.Expression) (pla
 			break
 		}
 		spans = filterSpans(r.spans, toSpans(opcode.EQ, nil, nil))
+	case *expression.PatternIn:
+		var err error
+		if spans, err = r.filterPatternIn(x); err != nil {
+			return nil, false, errors.Trace(err)
+		}
 	}
 
 	if spans == nil {
@@ -244,6 +287,8 @@ func (r *indexPlan) Filter(ctx context.Context, expr expression.Expression) (pla
 
 	return &indexPlan{
 		src:     r.src,
+		cols:    r.cols,
+		eqVals:  r.eqVals,
 		col:     r.col,
 		unique:  r.unique,
 		idxName: r.idxName,
@@ -252,6 +297,48 @@ func (r *indexPlan) Filter(ctx context.Context, expr expression.Expression) (pla
 	}, true, nil
 }
 
+// filterNextColumn narrows down the plan with a condition on the index column
+// following r.col. It is only possible if r.col is bound to a single value,
+// which then becomes one of the equal values of the leading columns.
+func (r *indexPlan) filterNextColumn(cname string, op opcode.Op, val interface{}) (plan.Plan, bool, error) {
+	next := len(r.eqVals) + 1
+	if next >= len(r.cols) || r.cols[next].Name.L != cname || val == nil || len(r.spans) != 1 {
+		return r, false, nil
+	}
+	span := r.spans[0]
+	if !span.isPoint() {
+		return r, false, nil
+	}
+	if span.lowVal == nil || span.lowVal == minNotNullVal || span.lowVal == maxVal {
+		return r, false, nil
+	}
+	eqVal, err := types.Convert(span.lowVal, &r.col.FieldType)
+	if err != nil {
+		return r, false, nil
+	}
+	if n, err := types.Compare(eqVal, span.lowVal); err != nil || n != 0 {
+		// The value is changed by conversion, no row matches it.
+		return r, false, nil
+	}
+	col := r.cols[next]
+	seekVal, err := types.Convert(val, &col.FieldType)
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	eqVals := make([]interface{}, 0, next)
+	eqVals = append(append(eqVals, r.eqVals...), eqVal)
+	return &indexPlan{
+		src:     r.src,
+		cols:    r.cols,
+		eqVals:  eqVals,
+		col:     col,
+		unique:  r.unique,
+		idxName: r.idxName,
+		idx:     r.idx,
+		spans:   toSpans(op, val, seekVal),
+	}, true, nil
+}
+
 // return the intersection range between origin and filter.
 func filterSpans(origin []*indexSpan, filter []*indexSpan) []*indexSpan {
 	newSpans := make([]*indexSpan, 0, len(filter))
@@ -262,7 +349,7 @@ func filterSpans(origin []*indexSpan, filter []*indexSpan) []*indexSpan {
 				continue
 			}
 			newSpan = newSpan.cutOffHigh(fSpan.highVal, fSpan.highExclude)
-			if newSpan == nil {
+			if newSpan == nil || newSpan.isEmpty() {
 				continue
 			}
 			newSpans = append(newSpans, newSpan)
@@ -329,20 +416,46 @@ func toSpans(op opcode.Op, val, seekVal interface{}) []*indexSpan {
 
 // Next implements plan.Plan Next interface.
 func (r *indexPlan) Next(ctx context.Context) (*plan.Row, error) {
+	if r.isMultiPoint() {
+		return r.nextBatchRow(ctx)
+	}
+	var (
+		h      int64
+		idxKey []interface{}
+		err    error
+	)
+	if r.desc {
+		h, idxKey, err = r.prevEntry(ctx)
+	} else {
+		h, idxKey, err = r.nextEntry(ctx)
+	}
+	if idxKey == nil || err != nil {
+		return nil, errors.Trace(err)
+	}
+	if r.covering {
+		return r.indexRow(h, idxKey)
+	}
+	return r.lookupRow(ctx, h)
+}
+
+// nextEntry returns the handle and the index values of the next index entry
+// in the spans, idxKey is nil if there is no more entry.
+func (r *indexPlan) nextEntry(ctx context.Context) (h int64, idxKey []interface{}, err error) {
 	for {
 		if r.cursor == len(r.spans) {
-			return nil, nil
+			return 0, nil, nil
 		}
 		span := r.spans[r.cursor]
 		if r.isPointLookup(span) {
 			// Do point lookup on index will prevent prefetch cost.
-			row, err := r.pointLookup(ctx, span.seekVal)
+			var found bool
+			h, found, err = r.pointLookup(ctx, span.seekVal)
 			if err != nil {
-				return nil, errors.Trace(err)
+				return 0, nil, errors.Trace(err)
 			}
 			r.cursor++
-			if row != nil {
-				return row, nil
+			if found {
+				return h, r.seekValues(span.seekVal), nil
 			}
 			continue
 		}
@@ -352,20 +465,29 @@ func (r *indexPlan) Next(ctx context.Context) (*plan.Row, error) {
 				seekVal = []byte{}
 			}
 			var txn kv.Transaction
-			txn, err := ctx.GetTxn(false)
+			txn, err = ctx.GetTxn(false)
 			if err != nil {
-				return nil, errors.Trace(err)
+				return 0, nil, errors.Trace(err)
 			}
-			r.iter, _, err = r.idx.Seek(txn, []interface{}{seekVal})
+			r.iter, _, err = r.idx.Seek(txn, r.storedValues(ctx, r.seekValues(seekVal)))
 			if err != nil {
-				return nil, types.EOFAsNil(err)
+				return 0, nil, types.EOFAsNil(err)
 			}
 		}
-		idxKey, h, err := r.iter.Next()
+		idxKey, h, err = r.iter.Next()
 		if err != nil {
-			return nil, types.EOFAsNil(err)
+			return 0, nil, types.EOFAsNil(err)
+		}
+		if idxKey, err = r.sessionValues(ctx, idxKey); err != nil {
+			return 0, nil, errors.Trace(err)
 		}
-		val := idxKey[0]
+		if !r.matchEqVals(idxKey) {
+			// The entries with the equal values of the leading columns are
+			// contiguous in the index, so this span has finished iteration.
+			r.nextSpan()
+			continue
+		}
+		val := idxKey[len(r.eqVals)]
 		if !r.skipLowCmp {
 			cmp := indexCompare(val, span.lowVal)
 			if cmp < 0 || (cmp == 0 && span.lowExclude) {
@@ -377,19 +499,153 @@ func (r *indexPlan) Next(ctx context.Context) (*plan.Row, error) {
 		if cmp > 0 || (cmp == 0 && span.highExclude) {
 			// This span has finished iteration.
 			// Move to the next span.
-			r.iter.Close()
-			r.iter = nil
+			r.nextSpan()
+			continue
+		}
+		return h, idxKey, nil
+	}
+}
+
+// prevEntry returns the handle and the index values of the next index entry
+// in the spans in descending order, idxKey is nil if there is no more entry.
+func (r *indexPlan) prevEntry(ctx context.Context) (h int64, idxKey []interface{}, err error) {
+	for {
+		if r.cursor == len(r.spans) {
+			return 0, nil, nil
+		}
+		span := r.spans[len(r.spans)-1-r.cursor]
+		if r.isPointLookup(span) {
+			var found bool
+			h, found, err = r.pointLookup(ctx, span.seekVal)
+			if err != nil {
+				return 0, nil, errors.Trace(err)
+			}
 			r.cursor++
-			r.skipLowCmp = false
+			if found {
+				return h, r.seekValues(span.seekVal), nil
+			}
 			continue
 		}
-		var row *plan.Row
-		row, err = r.lookupRow(ctx, h)
+		if r.iter == nil {
+			var txn kv.Transaction
+			txn, err = ctx.GetTxn(false)
+			if err != nil {
+				return 0, nil, errors.Trace(err)
+			}
+			r.iter, err = r.idx.SeekReverse(txn, r.storedValues(ctx, r.seekReverseValues(span.highVal)))
+			if err != nil {
+				return 0, nil, types.EOFAsNil(err)
+			}
+		}
+		idxKey, h, err = r.iter.Next()
+		if err != nil {
+			return 0, nil, types.EOFAsNil(err)
+		}
+		if idxKey, err = r.sessionValues(ctx, idxKey); err != nil {
+			return 0, nil, errors.Trace(err)
+		}
+		if !r.matchEqVals(idxKey) {
+			r.nextSpan()
+			continue
+		}
+		val := idxKey[len(r.eqVals)]
+		cmp := indexCompare(val, span.highVal)
+		if cmp > 0 || (cmp == 0 && span.highExclude) {
+			continue
+		}
+		cmp = indexCompare(val, span.lowVal)
+		if cmp < 0 || (cmp == 0 && span.lowExclude) {
+			// This span has finished iteration.
+			// Move to the previous span.
+			r.nextSpan()
+			continue
+		}
+		return h, idxKey, nil
+	}
+}
+
+// seekReverseValues returns the leading index values to seek reversely for
+// the span ending at highVal. The index values are the same type as the column
+// values, so highVal is converted. If it can't be converted to a value not less
+// than itself, the span is sought from the end of the entries with r.eqVals.
+func (r *indexPlan) seekReverseValues(highVal interface{}) []interface{} {
+	vals := append([]interface{}(nil), r.eqVals...)
+	if highVal == nil || highVal == maxVal || highVal == minNotNullVal {
+		return vals
+	}
+	v, err := types.Convert(highVal, &r.col.FieldType)
+	if err != nil {
+		return vals
+	}
+	if n, err := types.Compare(v, highVal); err != nil || n < 0 {
+		return vals
+	}
+	return append(vals, v)
+}
+
+func (r *indexPlan) nextSpan() {
+	r.iter.Close()
+	r.iter = nil
+	r.cursor++
+	r.skipLowCmp = false
+}
+
+// seekValues returns the index values to seek for the span starting at seekVal.
+// The columns after r.col are padded with nil, which is less than any other value.
+func (r *indexPlan) seekValues(seekVal interface{}) []interface{} {
+	vals := make([]interface{}, len(r.cols))
+	copy(vals, r.eqVals)
+	vals[len(r.eqVals)] = seekVal
+	return vals
+}
+
+// storedValues converts the TIMESTAMP values in vals from the time zone of the
+// session to the one in which they are stored in the index.
+func (r *indexPlan) storedValues(ctx context.Context, vals []interface{}) []interface{} {
+	loc, stored := variable.GetTimeZone(ctx), r.timestampLocation()
+	for i, v := range vals {
+		if x, ok := v.(mysql.Time); ok && x.Type == mysql.TypeTimestamp {
+			vals[i] = x.ConvertTimeZone(loc, stored)
+		}
+	}
+	return vals
+}
+
+// sessionValues converts the TIMESTAMP values decoded from an index key, which
+// are decoded as strings, to the times in the time zone of the session, so that
+// they are the same as the ones read from the rows.
+func (r *indexPlan) sessionValues(ctx context.Context, idxKey []interface{}) ([]interface{}, error) {
+	loc, stored := variable.GetTimeZone(ctx), r.timestampLocation()
+	for i, col := range r.cols {
+		if col.Tp != mysql.TypeTimestamp || i >= len(idxKey) || idxKey[i] == nil {
+			continue
+		}
+		v, err := types.Convert(idxKey[i], &col.FieldType)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		return row, nil
+		idxKey[i] = v.(mysql.Time).ConvertTimeZone(stored, loc)
 	}
+	return idxKey, nil
+}
+
+// timestampLocation returns the time zone of the wall clocks of the TIMESTAMP
+// values in the index keys.
+func (r *indexPlan) timestampLocation() *time.Location {
+	if r.tsLoc == nil {
+		r.tsLoc = r.src.Meta().TimestampIndexLocation()
+	}
+	return r.tsLoc
+}
+
+// matchEqVals returns whether the leading values of the index key equal to r.eqVals.
+func (r *indexPlan) matchEqVals(idxKey []interface{}) bool {
+	for i, v := range r.eqVals {
+		if indexCompare(idxKey[i], v) != 0 {
+			return false
+		}
+	}
+	return true
 }
 
 func (r *indexPlan) isPointLookup(span *indexSpan) bool {
@@ -397,6 +653,10 @@ func (r *indexPlan) isPointLookup(span *indexSpan) bool {
 	if !equalOp || !r.unique || span.lowVal == nil {
 		return false
 	}
+	if len(r.eqVals)+1 < len(r.cols) {
+		// Not all the columns of the unique index are bound.
+		return false
+	}
 	n, err := types.Compare(span.seekVal, span.lowVal)
 	if err != nil {
 		return false
@@ -419,28 +679,128 @@ func (r *indexPlan) lookupRow(ctx context.Context, h int64) (*plan.Row, error) {
 	return row, nil
 }
 
+// indexRow returns the row with the column values of the index entry,
+// the columns not in the index are nil. The row is not read from the table.
+func (r *indexPlan) indexRow(h int64, idxKey []interface{}) (*plan.Row, error) {
+	row := &plan.Row{Data: make([]interface{}, len(r.src.Cols()))}
+	for i, col := range r.cols {
+		// The values decoded from the index key may lose their types, e.g. a
+		// datetime is decoded as a string, so convert them back.
+		v, err := types.Convert(idxKey[i], &col.FieldType)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row.Data[col.Offset] = v
+	}
+	rowKey := &plan.RowKeyEntry{
+		Tbl: r.src,
+		Key: string(r.src.RecordKey(h, nil)),
+	}
+	row.RowKeys = append(row.RowKeys, rowKey)
+	return row, nil
+}
+
+// UseCoveringIndex makes the index plan under p read the column values from the
+// index entries instead of the table rows, if the index has all the columns
+// mentioned by exprs and the filters between them. It returns false if there is
+// no such index plan.
+func UseCoveringIndex(p plan.Plan, exprs []expression.Expression) bool {
+	for {
+		switch x := p.(type) {
+		case *RowStackFromPlan:
+			p = x.Src
+		case *SelectLockPlan:
+			p = x.Src
+		case *JoinPlan:
+			if x.Right != nil {
+				return false
+			}
+			p = x.Left
+		case *FilterDefaultPlan:
+			exprs = append(exprs, x.Expr)
+			p = x.Plan
+		case *SemiJoinPlan:
+			exprs = append(exprs, x.OuterKeys...)
+			p = x.Src
+		case *indexPlan:
+			if !x.covers(exprs) {
+				return false
+			}
+			x.covering = true
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// covers returns whether all the columns mentioned by exprs are in the index.
+func (r *indexPlan) covers(exprs []expression.Expression) bool {
+	for _, col := range r.cols {
+		if columnKeyClass(col) == keyClassOther {
+			// The column value can't be restored from the index key.
+			return false
+		}
+	}
+	v := newCoveredColumnsVisitor(r)
+	for _, e := range exprs {
+		if _, err := e.Accept(v); err != nil || !v.covered {
+			return false
+		}
+	}
+	return true
+}
+
+// coveredColumnsVisitor checks whether the identifiers of an expression are all index columns.
+// A subquery may reference the columns in outer query, so it is never covered.
+type coveredColumnsVisitor struct {
+	expression.BaseVisitor
+	index   *indexPlan
+	covered bool
+}
+
+func newCoveredColumnsVisitor(index *indexPlan) *coveredColumnsVisitor {
+	v := &coveredColumnsVisitor{index: index, covered: true}
+	v.BaseVisitor.V = v
+	return v
+}
+
+// VisitIdent implements Visitor interface.
+func (v *coveredColumnsVisitor) VisitIdent(i *expression.Ident) (expression.Expression, error) {
+	_, tname, cname := field.SplitQualifiedName(i.L)
+	if tname != "" && tname != v.index.src.TableName().L {
+		v.covered = false
+		return i, nil
+	}
+	if column.FindCol(v.index.cols, cname) == nil {
+		v.covered = false
+	}
+	return i, nil
+}
+
+// VisitSubQuery implements Visitor interface.
+func (v *coveredColumnsVisitor) VisitSubQuery(sq expression.SubQuery) (expression.Expression, error) {
+	v.covered = false
+	return sq, nil
+}
+
 // pointLookup do not seek index but call Exists method to get a handle, which is cheaper.
-func (r *indexPlan) pointLookup(ctx context.Context, val interface{}) (*plan.Row, error) {
+func (r *indexPlan) pointLookup(ctx context.Context, val interface{}) (int64, bool, error) {
 	txn, err := ctx.GetTxn(false)
 	if err != nil {
-		return nil, errors.Trace(err)
+		return 0, false, errors.Trace(err)
 	}
 	var exist bool
 	var h int64
 	// We expect a kv.ErrKeyExists Error because we pass -1 as the handle which is not equal to the existed handle.
-	exist, h, err = r.idx.Exist(txn, []interface{}{val}, -1)
+	exist, h, err = r.idx.Exist(txn, r.storedValues(ctx, r.seekValues(val)), -1)
 	if !exist {
-		return nil, errors.Trace(err)
+		return 0, false, errors.Trace(err)
 	}
 	if terror.ErrorNotEqual(kv.ErrKeyExists, err) {
-		return nil, errors.Trace(err)
+		return 0, false, errors.Trace(err)
 	}
-	var row *plan.Row
-	row, err = r.lookupRow(ctx, h)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-	return row, nil
+	return h, true, nil
 }
 
 // Close implements plan.Plan Close interface.
@@ -451,5 +811,6 @@ func (r *indexPlan) Close() error {
 	}
 	r.cursor = 0
 	r.skipLowCmp = false
+	r.batchRows = nil
 	return nil
 }
@@ -0,0 +1,157 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/format"
+)
+
+var _ plan.Plan = (*indexEndpointsPlan)(nil)
+
+// indexEndpointsPlan returns the rows with the minimum and the maximum not null
+// values of a column, by reading from each end of the index on the column.
+// It is the source of an aggregate plan which has only MIN and MAX of the column.
+type indexEndpointsPlan struct {
+	Src    plan.Plan
+	index  *indexPlan
+	offset int    // the offset of the column in the rows of src.
+	descs  []bool // the directions to read the index.
+	cursor int
+}
+
+// UseIndexEndpoints returns a plan which reads only the first and the last
+// index entries from src, if the select fields are only MIN and MAX of an
+// indexed column without GROUP BY. The aggregate of the rows it returns is the
+// same as the aggregate of all the rows in src. It returns false if there is
+// no such index.
+func UseIndexEndpoints(src plan.Plan, selectList *SelectList) (plan.Plan, bool) {
+	var (
+		name    string
+		offset  = -1
+		hasMin  bool
+		hasMax  bool
+		minMaxF = map[string]*bool{"min": &hasMin, "max": &hasMax}
+	)
+	for _, f := range selectList.Fields {
+		c, ok := f.Expr.(*expression.Call)
+		if !ok || len(c.Args) != 1 {
+			return src, false
+		}
+		has, ok := minMaxF[strings.ToLower(c.F)]
+		if !ok {
+			return src, false
+		}
+		ident, ok := c.Args[0].(*expression.Ident)
+		if !ok {
+			return src, false
+		}
+		idx := field.GetResultFieldIndex(ident.L, selectList.FromFields)
+		if len(idx) != 1 || (offset >= 0 && idx[0] != offset) {
+			return src, false
+		}
+		offset = idx[0]
+		name = selectList.FromFields[offset].Col.Name.L
+		*has = true
+	}
+	if offset < 0 {
+		return src, false
+	}
+
+	index := useIndexOrder(src, []string{name}, false)
+	if index == nil {
+		return src, false
+	}
+	if index.col.Name.L == name && !(len(index.spans) == 1 && index.spans[0].isPoint()) {
+		// Skip the null values which are ignored by MIN and MAX.
+		index.spans = filterSpans(index.spans, toSpans(opcode.GE, minNotNullVal, nil))
+	}
+	p := &indexEndpointsPlan{Src: src, index: index, offset: offset}
+	if hasMin {
+		p.descs = append(p.descs, false)
+	}
+	if hasMax {
+		p.descs = append(p.descs, true)
+	}
+	return p, true
+}
+
+// Explain implements plan.Plan Explain interface.
+func (r *indexEndpointsPlan) Explain(w format.Formatter) {
+	r.Src.Explain(w)
+	w.Format("┌Read the first not null value of %s from", r.index.col.Name.L)
+	for _, desc := range r.descs {
+		if desc {
+			w.Format(" the end")
+		} else {
+			w.Format(" the beginning")
+		}
+	}
+	w.Format(" of index %q\n└Output field names %v\n", r.index.idxName, field.RFQNames(r.GetFields()))
+}
+
+// GetFields implements plan.Plan GetFields interface.
+func (r *indexEndpointsPlan) GetFields() []*field.ResultField {
+	return r.Src.GetFields()
+}
+
+// Filter implements plan.Plan Filter interface.
+func (r *indexEndpointsPlan) Filter(ctx context.Context, expr expression.Expression) (plan.Plan, bool, error) {
+	return r, false, nil
+}
+
+// Next implements plan.Plan Next interface.
+func (r *indexEndpointsPlan) Next(ctx context.Context) (*plan.Row, error) {
+	for r.cursor < len(r.descs) {
+		r.index.desc = r.descs[r.cursor]
+		r.cursor++
+		row, err := r.firstNotNull(ctx)
+		if err != nil || row == nil {
+			// If there is no not null value, there is none from the other end either.
+			r.cursor = len(r.descs)
+			return nil, errors.Trace(err)
+		}
+		return row, nil
+	}
+	return nil, nil
+}
+
+// firstNotNull returns the first row from src whose column value is not null,
+// then closes src to read from the other end of the index.
+func (r *indexEndpointsPlan) firstNotNull(ctx context.Context) (*plan.Row, error) {
+	defer r.Src.Close()
+	for {
+		row, err := r.Src.Next(ctx)
+		if err != nil || row == nil {
+			return nil, errors.Trace(err)
+		}
+		if row.Data[r.offset] != nil {
+			return row, nil
+		}
+	}
+}
+
+// Close implements plan.Plan Close interface.
+func (r *indexEndpointsPlan) Close() error {
+	r.cursor = 0
+	r.index.desc = false
+	return r.Src.Close()
+}
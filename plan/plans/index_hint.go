@@ -0,0 +1,150 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb/column"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/terror"
+)
+
+// IndexHintType is the type of an index hint.
+type IndexHintType int
+
+// Index hint types.
+const (
+	UseIndex IndexHintType = iota + 1
+	IgnoreIndex
+	ForceIndex
+)
+
+// Optimizer error codes.
+const (
+	CodeUnknownIndex terror.ErrCode = iota + 1
+)
+
+// ErrUnknownIndex is returned when an index hint names an index the table doesn't have.
+var ErrUnknownIndex = terror.ClassOptimizer.New(CodeUnknownIndex, "unknown index")
+
+// IndexHint is an index hint of a table, e.g. USE INDEX (idx).
+// USE INDEX limits the indices the planner can use to the named ones, USE INDEX ()
+// with no names makes it use none. IGNORE INDEX prevents it from using the named
+// indices. FORCE INDEX is like USE INDEX, but a full table scan is only used if none
+// of the named indices can be used, even if the scan is cheaper.
+// See: https://dev.mysql.com/doc/refman/5.7/en/index-hints.html
+type IndexHint struct {
+	Type  IndexHintType
+	Names []model.CIStr
+}
+
+// String implements fmt.Stringer interface.
+func (h *IndexHint) String() string {
+	var typ string
+	switch h.Type {
+	case UseIndex:
+		typ = "USE"
+	case IgnoreIndex:
+		typ = "IGNORE"
+	case ForceIndex:
+		typ = "FORCE"
+	}
+	names := make([]string, 0, len(h.Names))
+	for _, name := range h.Names {
+		names = append(names, name.O)
+	}
+	return fmt.Sprintf("%s INDEX (%s)", typ, strings.Join(names, ", "))
+}
+
+// CheckIndexHints checks that the indices named by hints are in indices of table tableName.
+func CheckIndexHints(tableName string, indices []*column.IndexedCol, hints []*IndexHint) error {
+	for _, h := range hints {
+		for _, name := range h.Names {
+			if findIndexByName(indices, name) == nil {
+				return ErrUnknownIndex.Gen("Key '%s' doesn't exist in table '%s'", name.O, tableName)
+			}
+		}
+	}
+	return nil
+}
+
+func findIndexByName(indices []*column.IndexedCol, name model.CIStr) *column.IndexedCol {
+	for _, ix := range indices {
+		if ix != nil && ix.Name.L == name.L {
+			return ix
+		}
+	}
+	return nil
+}
+
+// indices returns the indices of the table which can be used by the planner
+// according to the index hints.
+func (r *TableDefaultPlan) indices() []*column.IndexedCol {
+	if len(r.IndexHints) == 0 {
+		return r.T.Indices()
+	}
+	var indices []*column.IndexedCol
+	for _, ix := range r.T.Indices() {
+		if ix != nil && r.usableIndex(ix) {
+			indices = append(indices, ix)
+		}
+	}
+	return indices
+}
+
+// forceIndex returns whether the index hints force an index to be used instead
+// of a full table scan.
+func (r *TableDefaultPlan) forceIndex() bool {
+	for _, h := range r.IndexHints {
+		if h.Type == ForceIndex {
+			return true
+		}
+	}
+	return false
+}
+
+// usableIndex returns whether index ix can be used according to the index hints.
+func (r *TableDefaultPlan) usableIndex(ix *column.IndexedCol) bool {
+	limited, listed := false, false
+	for _, h := range r.IndexHints {
+		found := false
+		for _, name := range h.Names {
+			if name.L == ix.Name.L {
+				found = true
+			}
+		}
+		switch h.Type {
+		case IgnoreIndex:
+			if found {
+				return false
+			}
+		case UseIndex, ForceIndex:
+			limited = true
+			listed = listed || found
+		}
+	}
+	return !limited || listed
+}
+
+// findSingleColumnIndex returns the first index in indices with only the column cn.
+func findSingleColumnIndex(indices []*column.IndexedCol, cn string) *column.IndexedCol {
+	for _, ix := range indices {
+		if ix != nil && len(ix.Columns) == 1 && strings.EqualFold(ix.Columns[0].Name.L, cn) {
+			return ix
+		}
+	}
+	return nil
+}
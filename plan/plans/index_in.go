@@ -0,0 +1,237 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/column"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// IndexBatchSize is the max number of rows read in a batch by an index plan
+// with multiple point spans, e.g. for `c IN (1, 2, 3)`.
+var IndexBatchSize = 1024
+
+// inListColumn returns the column name of `c IN (v1, v2, ...)` whose values are all constant.
+func inListColumn(x *expression.PatternIn) (string, bool) {
+	if x.Not || x.Sel != nil {
+		return "", false
+	}
+	ident, ok := x.Expr.(*expression.Ident)
+	if !ok {
+		return "", false
+	}
+	for _, v := range x.List {
+		if !v.IsStatic() {
+			return "", false
+		}
+	}
+	return ident.L, true
+}
+
+// inListSpans returns the point spans of the values in IN list on column c, they
+// are ordered by the values without duplicates. The null values are skipped as
+// they never match.
+func inListSpans(x *expression.PatternIn, c *column.Col) ([]*indexSpan, error) {
+	var spans []*indexSpan
+	for _, e := range x.List {
+		v, err := e.Eval(nil, nil)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if v == nil {
+			continue
+		}
+		seekVal, err := types.Convert(v, &c.FieldType)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		spans = append(spans, toSpans(opcode.EQ, v, seekVal)...)
+	}
+	sort.Sort(bySpanLow(spans))
+	var points []*indexSpan
+	for _, span := range spans {
+		if len(points) > 0 && indexCompare(points[len(points)-1].lowVal, span.lowVal) == 0 {
+			continue
+		}
+		points = append(points, span)
+	}
+	return points, nil
+}
+
+type bySpanLow []*indexSpan
+
+func (s bySpanLow) Len() int           { return len(s) }
+func (s bySpanLow) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s bySpanLow) Less(i, j int) bool { return indexCompare(s[i].lowVal, s[j].lowVal) < 0 }
+
+// filterPatternIn returns an index plan with a point span for each value of IN list,
+// if the column has an index.
+func (r *TableDefaultPlan) filterPatternIn(ctx context.Context, x *expression.PatternIn) (plan.Plan, bool, error) {
+	name, ok := inListColumn(x)
+	if !ok {
+		return r, false, nil
+	}
+	_, tn, cn := field.SplitQualifiedName(name)
+	t := r.T
+	if tn != "" && tn != t.TableName().L {
+		return r, false, nil
+	}
+	c := column.FindCol(t.Cols(), cn)
+	if c == nil {
+		return nil, false, errors.Errorf("No such column: %s", cn)
+	}
+	ix := findIndexByLeadingCol(r.indices(), cn)
+	if ix == nil {
+		return r, false, nil
+	}
+	spans, err := inListSpans(x, c)
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	if len(spans) == 0 {
+		// All the values are null.
+		return &NullPlan{r.GetFields()}, true, nil
+	}
+	return &indexPlan{
+		src:     t,
+		cols:    indexColumns(t, ix),
+		col:     c,
+		unique:  ix.Unique,
+		idxName: ix.Name.O,
+		idx:     ix.X,
+		spans:   spans,
+	}, true, nil
+}
+
+// filterPatternIn narrows down the spans of the index plan to the values of IN list.
+func (r *indexPlan) filterPatternIn(x *expression.PatternIn) ([]*indexSpan, error) {
+	name, ok := inListColumn(x)
+	if !ok {
+		return nil, nil
+	}
+	_, tname, cname := field.SplitQualifiedName(name)
+	if (tname != "" && r.src.TableName().L != tname) || r.col.Name.L != cname {
+		return nil, nil
+	}
+	spans, err := inListSpans(x, r.col)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The result is not nil even if no value is in the spans.
+	points := []*indexSpan{}
+	for _, span := range spans {
+		if len(filterSpans(r.spans, []*indexSpan{span})) > 0 {
+			points = append(points, span)
+		}
+	}
+	return points, nil
+}
+
+// isMultiPoint returns whether the plan reads the rows of multiple point spans,
+// which are read in batches.
+func (r *indexPlan) isMultiPoint() bool {
+	if len(r.spans) < 2 || r.covering || r.desc {
+		return false
+	}
+	for _, span := range r.spans {
+		if !span.isPoint() {
+			return false
+		}
+	}
+	return true
+}
+
+// nextBatchRow returns the next row read in a batch.
+func (r *indexPlan) nextBatchRow(ctx context.Context) (*plan.Row, error) {
+	if len(r.batchRows) == 0 {
+		if err := r.readBatch(ctx); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(r.batchRows) == 0 {
+			return nil, nil
+		}
+	}
+	row := r.batchRows[0]
+	r.batchRows = r.batchRows[1:]
+	return row, nil
+}
+
+// readBatch reads the handles of at most IndexBatchSize index entries, then reads
+// the rows of them with one batch read. For a unique index, the index entries of
+// all the points are read with one batch read first.
+func (r *indexPlan) readBatch(ctx context.Context) error {
+	txn, err := ctx.GetTxn(false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if r.cursor == 0 && r.unique {
+		var keys []kv.Key
+		for _, span := range r.spans {
+			if !r.isPointLookup(span) {
+				continue
+			}
+			key, _, err := r.idx.GenIndexKey(r.storedValues(ctx, r.seekValues(span.seekVal)), 0)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			keys = append(keys, key)
+		}
+		if len(keys) > 0 {
+			if err = txn.BatchPrefetch(keys); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+
+	var handles []int64
+	for len(handles) < IndexBatchSize {
+		h, idxKey, err := r.nextEntry(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if idxKey == nil {
+			break
+		}
+		handles = append(handles, h)
+	}
+	if len(handles) == 0 {
+		return nil
+	}
+	keys := make([]kv.Key, 0, len(handles)*len(r.src.Cols()))
+	for _, h := range handles {
+		for _, col := range r.src.Cols() {
+			keys = append(keys, kv.Key(r.src.RecordKey(h, col)))
+		}
+	}
+	if err = txn.BatchPrefetch(keys); err != nil {
+		return errors.Trace(err)
+	}
+	for _, h := range handles {
+		row, err := r.lookupRow(ctx, h)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		r.batchRows = append(r.batchRows, row)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/column"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// indexJoiner joins rows by looking up the inner table with the index on the
+// join key once for each outer row, instead of scanning the whole inner table.
+type indexJoiner struct {
+	// innerRight is true if the right plan is the inner side.
+	innerRight bool
+	// outerKey is the offset of the join key in outer rows.
+	outerKey int
+	inner    *TableDefaultPlan
+	col      *column.Col
+	index    *column.IndexedCol
+}
+
+// newIndexJoiner returns an indexJoiner for the join, or nil if the inner side
+// is not a table with an index on the column of an equal condition in ON expression.
+// The inner side of an outer join is the side padded with nulls, a cross join
+// prefers the right plan as the inner side.
+func (r *JoinPlan) newIndexJoiner() *indexJoiner {
+	if r.Right == nil || r.On == nil {
+		return nil
+	}
+	leftKeys, rightKeys := r.equalJoinKeys()
+	if len(leftKeys) == 0 {
+		return nil
+	}
+	switch r.Type {
+	case LeftJoin:
+		return newIndexJoinerOn(r.Right, true, leftKeys, rightKeys)
+	case RightJoin:
+		return newIndexJoinerOn(r.Left, false, rightKeys, leftKeys)
+	case CrossJoin:
+		if ij := newIndexJoinerOn(r.Right, true, leftKeys, rightKeys); ij != nil {
+			return ij
+		}
+		return newIndexJoinerOn(r.Left, false, rightKeys, leftKeys)
+	}
+	return nil
+}
+
+func newIndexJoinerOn(inner plan.Plan, innerRight bool, outerKeys, innerKeys []int) *indexJoiner {
+	t, ok := inner.(*TableDefaultPlan)
+	if !ok {
+		return nil
+	}
+	cols := t.T.Cols()
+	for i, offset := range innerKeys {
+		if offset >= len(cols) {
+			continue
+		}
+		col := cols[offset]
+		if columnKeyClass(col) == keyClassOther {
+			continue
+		}
+		ix := findSingleColumnIndex(t.indices(), col.Name.L)
+		if ix == nil {
+			continue
+		}
+		return &indexJoiner{
+			innerRight: innerRight,
+			outerKey:   outerKeys[i],
+			inner:      t,
+			col:        col,
+			index:      ix,
+		}
+	}
+	return nil
+}
+
+// columnKeyClass returns the key class of the values stored in column col.
+func columnKeyClass(col *column.Col) int {
+	switch col.Tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong,
+		mysql.TypeFloat, mysql.TypeDouble, mysql.TypeDecimal, mysql.TypeNewDecimal:
+		return keyClassNumber
+	case mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeString,
+		mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeBlob:
+		return keyClassString
+	case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
+		return keyClassTime
+	case mysql.TypeDuration:
+		return keyClassDuration
+	}
+	return keyClassOther
+}
+
+// lookupPlan returns the plan to read the inner rows which may match the outer
+// row. It is an index plan seeking the key value, or a point lookup if the index
+// is unique. If the key value can't be compared with the column values by index
+// order, e.g. a string compared with a number column, the whole inner table is
+// scanned and the rows are checked by ON expression.
+func (ij *indexJoiner) lookupPlan(outerRow *plan.Row) (plan.Plan, error) {
+	v := outerRow.Data[ij.outerKey]
+	if v == nil {
+		// A null key never matches.
+		return nil, nil
+	}
+	if keyClass(v) != columnKeyClass(ij.col) {
+		return ij.inner, nil
+	}
+	seekVal, err := types.Convert(v, &ij.col.FieldType)
+	if err != nil {
+		return ij.inner, nil
+	}
+	n, err := types.Compare(seekVal, v)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if n != 0 {
+		// The value is changed by conversion, e.g. 1.5 for an integer column,
+		// no value in the column is equal to it.
+		return nil, nil
+	}
+	return &indexPlan{
+		src:     ij.inner.T,
+		cols:    indexColumns(ij.inner.T, ij.index),
+		col:     ij.col,
+		unique:  ij.index.Unique,
+		idxName: ij.index.Name.O,
+		idx:     ij.index.X,
+		spans:   toSpans(opcode.EQ, seekVal, seekVal),
+	}, nil
+}
+
+// nextIndexJoin gets the next joined row with the index joiner.
+func (r *JoinPlan) nextIndexJoin(ctx context.Context) (row *plan.Row, err error) {
+	ij := r.indexJoiner
+	outerPlan := r.Right
+	if ij.innerRight {
+		outerPlan = r.Left
+	}
+	// Only outer joins keep the unmatched outer rows.
+	outer := r.Type != CrossJoin
+	for {
+		if r.cursor < len(r.matchedRows) {
+			row = r.matchedRows[r.cursor]
+			r.cursor++
+			return
+		}
+		var outerRow *plan.Row
+		outerRow, err = outerPlan.Next(ctx)
+		if outerRow == nil || err != nil {
+			return nil, errors.Trace(err)
+		}
+		r.cursor = 0
+		r.matchedRows = nil
+		var p plan.Plan
+		p, err = ij.lookupPlan(outerRow)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if p != nil {
+			if err = r.findIndexJoinRows(ctx, outerRow, p); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if len(r.matchedRows) == 0 && outer {
+			r.matchedRows = append(r.matchedRows, r.padNullRow(outerRow, ij.innerRight))
+		}
+	}
+}
+
+// findIndexJoinRows joins the outer row with the inner rows read from p.
+func (r *JoinPlan) findIndexJoinRows(ctx context.Context, outerRow *plan.Row, p plan.Plan) error {
+	defer p.Close()
+	for {
+		innerRow, err := p.Next(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if innerRow == nil {
+			return nil
+		}
+		left, right := outerRow, innerRow
+		if !r.indexJoiner.innerRight {
+			left, right = innerRow, outerRow
+		}
+		joined, err := r.joinRows(ctx, left, right)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if joined != nil {
+			r.matchedRows = append(r.matchedRows, joined)
+		}
+	}
+}
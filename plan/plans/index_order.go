@@ -0,0 +1,198 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"github.com/pingcap/tidb/column"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/table"
+)
+
+// UseIndexOrder makes the plan src return the rows in the order of by and ascs,
+// by reading the rows from an index whose leading columns are the order by columns,
+// so the rows need not to be sorted and the read can stop early at LIMIT.
+// The order by columns bound by equal conditions are skipped. It returns false
+// if src is not a single table plan or there is no such index.
+func UseIndexOrder(src plan.Plan, by []expression.Expression, ascs []bool, selectList *SelectList) bool {
+	if len(by) == 0 {
+		return false
+	}
+	for _, asc := range ascs {
+		if asc != ascs[0] {
+			// The index can only be read in one direction.
+			return false
+		}
+	}
+	names := make([]string, 0, len(by))
+	for _, e := range by {
+		name := orderByColumnName(e, selectList)
+		if name == "" {
+			return false
+		}
+		names = append(names, name)
+	}
+
+	return useIndexOrder(src, names, !ascs[0]) != nil
+}
+
+// useIndexOrder makes src read the rows in the order of the columns with names,
+// it returns the index plan reading the rows, or nil if there is no such index.
+func useIndexOrder(src plan.Plan, names []string, desc bool) *indexPlan {
+	p, set := indexOrderSource(src)
+	switch x := p.(type) {
+	case *indexPlan:
+		if !x.matchOrder(names) {
+			return nil
+		}
+		x.desc = desc
+		return x
+	case *TableDefaultPlan:
+		ix := findIndexByOrder(x.T, x.indices(), names)
+		if ix == nil {
+			return nil
+		}
+		cols := indexColumns(x.T, ix)
+		ip := &indexPlan{
+			src:     x.T,
+			cols:    cols,
+			col:     cols[0],
+			unique:  ix.Unique,
+			idxName: ix.Name.O,
+			idx:     ix.X,
+			spans:   []*indexSpan{{lowVal: nil, highVal: maxVal}},
+			desc:    desc,
+		}
+		set(ip)
+		return ip
+	}
+	return nil
+}
+
+// indexOrderSource returns the plan reading the table rows under src, and the
+// function to replace it in its parent plan. The plans between them must keep
+// the order of rows.
+func indexOrderSource(src plan.Plan) (plan.Plan, func(plan.Plan)) {
+	p := src
+	var set func(plan.Plan)
+	for {
+		switch x := p.(type) {
+		case *SelectFieldsDefaultPlan:
+			p, set = x.Src, func(np plan.Plan) { x.Src = np }
+		case *SelectLockPlan:
+			p, set = x.Src, func(np plan.Plan) { x.Src = np }
+		case *FilterDefaultPlan:
+			p, set = x.Plan, func(np plan.Plan) { x.Plan = np }
+		case *RowStackFromPlan:
+			p, set = x.Src, func(np plan.Plan) { x.Src = np }
+		case *JoinPlan:
+			if x.Right != nil {
+				return nil, nil
+			}
+			p, set = x.Left, func(np plan.Plan) { x.Left = np }
+		default:
+			if set == nil {
+				return nil, nil
+			}
+			return p, set
+		}
+	}
+}
+
+// orderByColumnName returns the name of the table column which the order by
+// expression e refers to, or an empty string if e is not a column.
+func orderByColumnName(e expression.Expression, selectList *SelectList) string {
+	var f *field.Field
+	switch x := e.(type) {
+	case *expression.Ident:
+		switch x.ReferScope {
+		case expression.IdentReferFromTable:
+			return selectList.FromFields[x.ReferIndex].Col.Name.L
+		case expression.IdentReferSelectList:
+			f = selectList.Fields[x.ReferIndex]
+		default:
+			return ""
+		}
+	case *expression.Position:
+		f = selectList.Fields[x.N-1]
+	default:
+		return ""
+	}
+	ident, ok := f.Expr.(*expression.Ident)
+	if !ok {
+		return ""
+	}
+	idx := field.GetResultFieldIndex(ident.L, selectList.FromFields)
+	if len(idx) != 1 {
+		return ""
+	}
+	return selectList.FromFields[idx[0]].Col.Name.L
+}
+
+// matchOrder returns whether the rows read by the index plan are in the order
+// of the columns with names. The columns bound by equal conditions have only
+// one value, so they are skipped.
+func (r *indexPlan) matchOrder(names []string) bool {
+	bound := len(r.eqVals)
+	if len(r.spans) == 1 && r.spans[0].isPoint() {
+		bound++
+	}
+	var rest []string
+	for _, name := range names {
+		if column.FindCol(r.cols[:bound], name) == nil {
+			rest = append(rest, name)
+		}
+	}
+	cols := r.cols[bound:]
+	return columnsHavePrefix(cols, rest) && orderedColumns(cols[:len(rest)])
+}
+
+// findIndexByOrder returns the index in indices of table t whose leading columns
+// are the columns with names.
+func findIndexByOrder(t table.Table, indices []*column.IndexedCol, names []string) *column.IndexedCol {
+	for _, ix := range indices {
+		if ix == nil {
+			continue
+		}
+		cols := indexColumns(t, ix)
+		if columnsHavePrefix(cols, names) && orderedColumns(cols[:len(names)]) {
+			return ix
+		}
+	}
+	return nil
+}
+
+// orderedColumns returns whether the index values of cols are ordered the
+// same way as the column values.
+func orderedColumns(cols []*column.Col) bool {
+	for _, col := range cols {
+		if columnKeyClass(col) == keyClassOther {
+			return false
+		}
+	}
+	return true
+}
+
+func columnsHavePrefix(cols []*column.Col, names []string) bool {
+	if len(names) > len(cols) {
+		return false
+	}
+	for i, name := range names {
+		if cols[i].Name.L != name {
+			return false
+		}
+	}
+	return true
+}
@@ -66,6 +66,11 @@ type JoinPlan struct {
 	matchedRows []*plan.Row
 	cursor      int
 	evalArgs    map[interface{}]interface{}
+
+	indexJoiner   *indexJoiner
+	hashJoiner    *hashJoiner
+	joinerChecked bool
+	hashBuilt     bool
 }
 
 // Explain implements plan.Plan Explain interface.
@@ -77,7 +82,13 @@ func (r *JoinPlan) Explain(w format.Formatter) {
 		return
 	}
 
-	w.Format("┌Compute %s Cartesian product of\n", r.Type)
+	if ij := r.explainedIndexJoiner(); ij != nil {
+		w.Format("┌Compute %s index join on %s using index %q of\n", r.Type, r.On, ij.index.Name.O)
+	} else if r.canHashJoin() {
+		w.Format("┌Compute %s hash join on %s of\n", r.Type, r.On)
+	} else {
+		w.Format("┌Compute %s Cartesian product of\n", r.Type)
+	}
 
 	r.explainNode(w, r.Left)
 	r.explainNode(w, r.Right)
@@ -86,7 +97,7 @@ func (r *JoinPlan) Explain(w format.Formatter) {
 }
 
 func (r *JoinPlan) explainNode(w format.Formatter, node plan.Plan) {
-	sel := !isTableOrIndex(node)
+	sel := !isTableOrIndex(unwrapAnalyzePlan(node))
 	if sel {
 		w.Format("┌Iterate all rows of virtual table\n")
 	}
@@ -144,6 +155,13 @@ func (r *JoinPlan) Next(ctx context.Context) (row *plan.Row, err error) {
 	if r.evalArgs == nil {
 		r.evalArgs = map[interface{}]interface{}{}
 	}
+	r.chooseJoiner(ctx)
+	if r.indexJoiner != nil {
+		return r.nextIndexJoin(ctx)
+	}
+	if r.hashJoiner != nil {
+		return r.nextHashJoin(ctx)
+	}
 	switch r.Type {
 	case LeftJoin:
 		return r.nextLeftJoin(ctx)
@@ -154,6 +172,29 @@ func (r *JoinPlan) Next(ctx context.Context) (row *plan.Row, err error) {
 	}
 }
 
+// chooseJoiner chooses how to join the rows when the join is executed for the
+// first time, the choice is kept until the plan is discarded.
+func (r *JoinPlan) chooseJoiner(ctx context.Context) {
+	if r.Right == nil || r.joinerChecked {
+		return
+	}
+	// Prefer looking up the inner table by index to building a hash table.
+	r.indexJoiner = r.newIndexJoiner()
+	if r.indexJoiner == nil {
+		r.hashJoiner = r.newHashJoiner(ctx)
+	}
+	r.joinerChecked = true
+}
+
+// explainedIndexJoiner returns the chosen index joiner, or the one that
+// would be chosen if the join has not been executed.
+func (r *JoinPlan) explainedIndexJoiner() *indexJoiner {
+	if r.joinerChecked {
+		return r.indexJoiner
+	}
+	return r.newIndexJoiner()
+}
+
 func (r *JoinPlan) nextLeftJoin(ctx context.Context) (row *plan.Row, err error) {
 	visitor := newJoinIdentVisitor(0)
 	for {
@@ -353,6 +394,12 @@ func (r *JoinPlan) Close() error {
 	r.tempPlan = nil
 	r.matchedRows = nil
 	r.cursor = 0
+	if r.hashJoiner != nil {
+		if err := r.hashJoiner.close(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	r.hashBuilt = false
 	if r.Right != nil {
 		r.Right.Close()
 	}
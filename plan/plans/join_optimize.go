@@ -0,0 +1,508 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/format"
+)
+
+var _ plan.Plan = (*reorderedJoinPlan)(nil)
+
+// OptimizeJoin rewrites the join plan under src before the physical planning.
+// The conjuncts of WHERE expression and ON expressions are pushed down to the
+// lowest plan that can evaluate them, where they may choose an index, then the
+// inner joins of three or more tables are reordered to join the tables with the
+// least estimated rows first.
+// It returns false if src is not a join of two or more tables, then src is
+// returned unchanged and the WHERE expression must be planned by the caller.
+func OptimizeJoin(ctx context.Context, src plan.Plan, where expression.Expression) (plan.Plan, bool, error) {
+	rs, ok := src.(*RowStackFromPlan)
+	p := src
+	if ok {
+		p = rs.Src
+	}
+	join, ok := p.(*JoinPlan)
+	if !ok || join.Right == nil {
+		return src, false, nil
+	}
+	var conds []expression.Expression
+	if where != nil {
+		conds = SplitConjuncts(where)
+	}
+	p, rest, err := pushDownConds(ctx, join, conds)
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	p = reorderJoin(ctx, p)
+	if rs != nil {
+		rs.Src = p
+		p = rs
+	}
+	if len(rest) == 0 {
+		return p, true, nil
+	}
+	// The conditions left may have subqueries which read the current row
+	// from the row stack, so they are evaluated after RowStackFromPlan.
+	return &FilterDefaultPlan{Plan: p, Expr: andConds(rest)}, true, nil
+}
+
+// pushDownConds pushes the conjunctive conditions conds on the rows of plan p
+// to its source plans. It returns the new plan, and the conditions which must
+// be evaluated on the rows of it.
+func pushDownConds(ctx context.Context, p plan.Plan, conds []expression.Expression) (plan.Plan, []expression.Expression, error) {
+	join, ok := p.(*JoinPlan)
+	if !ok || join.Right == nil {
+		return p, conds, nil
+	}
+	leftLen := len(join.Left.GetFields())
+	var leftConds, rightConds, onConds, rest []expression.Expression
+	for _, cond := range conds {
+		left, right, ok := condSides(cond, leftLen)
+		switch {
+		case !ok:
+			rest = append(rest, cond)
+		case left && !right && join.Type != RightJoin:
+			leftConds = append(leftConds, cond)
+		case right && !left && join.Type != LeftJoin:
+			rightConds = append(rightConds, cond)
+		case left && right && join.Type == CrossJoin:
+			onConds = append(onConds, cond)
+		default:
+			rest = append(rest, cond)
+		}
+	}
+	if join.On != nil {
+		var keep []expression.Expression
+		for _, cond := range SplitConjuncts(join.On) {
+			left, right, ok := condSides(cond, leftLen)
+			switch {
+			case ok && left && !right && join.Type == CrossJoin:
+				leftConds = append(leftConds, cond)
+			case ok && right && !left && join.Type != RightJoin:
+				rightConds = append(rightConds, cond)
+				if join.Type == LeftJoin {
+					// The rows of the right plan are filtered by it, but the
+					// left join still needs an ON condition to match them.
+					keep = append(keep, cond)
+				}
+			case ok && left && !right && join.Type == RightJoin:
+				leftConds = append(leftConds, cond)
+				keep = append(keep, cond)
+			default:
+				keep = append(keep, cond)
+			}
+		}
+		onConds = append(keep, onConds...)
+	}
+	join.On = andConds(onConds)
+
+	var err error
+	if join.Left, err = pushDownSide(ctx, join.Left, join.Fields[:leftLen], leftConds, 0); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if join.Right, err = pushDownSide(ctx, join.Right, join.Fields[leftLen:], rightConds, leftLen); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return join, rest, nil
+}
+
+// pushDownSide pushes the conditions down to the source plan p of a join.
+// The columns of p start at offset in the rows of the join, fields are the
+// fields of them in the join.
+func pushDownSide(ctx context.Context, p plan.Plan, fields []*field.ResultField, conds []expression.Expression, offset int) (plan.Plan, error) {
+	if _, ok := p.(*JoinPlan); ok {
+		// Keep the qualified names, which may be needed by the joins below.
+		fields = nil
+	}
+	for i, cond := range conds {
+		conds[i] = shiftColumns(cond, -offset, fields)
+	}
+	p, rest, err := pushDownConds(ctx, p, conds)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if _, ok := p.(*JoinPlan); !ok && len(rest) > 0 {
+		// Choose an index for the conditions on the rows from a table.
+		if p, rest, err = ChooseAccessPath(ctx, p, rest); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if len(rest) > 0 {
+		p = &FilterDefaultPlan{Plan: p, Expr: andConds(rest)}
+	}
+	return p, nil
+}
+
+// condSides returns whether cond mentions the columns of the left plan and the
+// right plan of a join, whose left plan has leftLen columns. ok is false if cond
+// can't be moved, that is it has a subquery or refers to an outer query.
+func condSides(cond expression.Expression, leftLen int) (left, right, ok bool) {
+	v := &columnOffsetsVisitor{movable: true}
+	v.BaseVisitor.V = v
+	if _, err := cond.Accept(v); err != nil || !v.movable || len(v.offsets) == 0 {
+		return false, false, false
+	}
+	for _, offset := range v.offsets {
+		if offset < leftLen {
+			left = true
+		} else {
+			right = true
+		}
+	}
+	return left, right, true
+}
+
+// columnOffsetsVisitor collects the offsets of the columns an expression mentions.
+type columnOffsetsVisitor struct {
+	expression.BaseVisitor
+	offsets []int
+	movable bool
+}
+
+// VisitIdent implements expression.Visitor VisitIdent interface.
+func (v *columnOffsetsVisitor) VisitIdent(i *expression.Ident) (expression.Expression, error) {
+	if i.ReferScope != expression.IdentReferFromTable {
+		v.movable = false
+	}
+	v.offsets = append(v.offsets, i.ReferIndex)
+	return i, nil
+}
+
+// VisitSubQuery implements expression.Visitor VisitSubQuery interface.
+func (v *columnOffsetsVisitor) VisitSubQuery(sq expression.SubQuery) (expression.Expression, error) {
+	v.movable = false
+	return sq, nil
+}
+
+// shiftColumns returns a copy of cond whose column offsets are added by delta.
+// If fields is not nil, the column names are replaced by the names of fields
+// at the new offsets without the table names, so they can be used by the
+// Filter method of a table plan whose table has an alias name.
+func shiftColumns(cond expression.Expression, delta int, fields []*field.ResultField) expression.Expression {
+	return mapColumns(cond, func(offset int) int { return offset + delta }, fields)
+}
+
+// mapColumns returns a copy of cond whose column offsets are mapped by f.
+func mapColumns(cond expression.Expression, f func(int) int, fields []*field.ResultField) expression.Expression {
+	v := &columnMapVisitor{f: f, fields: fields}
+	v.BaseVisitor.V = v
+	e, _ := cond.Clone().Accept(v)
+	return e
+}
+
+// columnMapVisitor maps the offsets of the columns an expression mentions.
+type columnMapVisitor struct {
+	expression.BaseVisitor
+	f      func(int) int
+	fields []*field.ResultField
+}
+
+// VisitIdent implements expression.Visitor VisitIdent interface.
+func (v *columnMapVisitor) VisitIdent(i *expression.Ident) (expression.Expression, error) {
+	i.ReferIndex = v.f(i.ReferIndex)
+	if v.fields != nil {
+		i.CIStr = model.NewCIStr(v.fields[i.ReferIndex].Name)
+	}
+	return i, nil
+}
+
+// andConds joins the conjunctive conditions with AND operator, it returns nil
+// if there is no condition.
+func andConds(conds []expression.Expression) expression.Expression {
+	if len(conds) == 0 {
+		return nil
+	}
+	e := conds[0]
+	for _, cond := range conds[1:] {
+		e = expression.NewBinaryOperation(opcode.AndAnd, e, cond)
+	}
+	return e
+}
+
+// joinLeaf is a source plan of a tree of inner joins.
+type joinLeaf struct {
+	p      plan.Plan
+	fields []*field.ResultField
+	// offset is the offset of its columns in the rows of the join tree.
+	offset int
+	rows   float64
+}
+
+// joinCond is an ON condition of a tree of inner joins, whose column
+// offsets are the offsets in the rows of the join tree.
+type joinCond struct {
+	expr   expression.Expression
+	leaves []int
+}
+
+// reorderJoin reorders the inner joins in plan p.
+func reorderJoin(ctx context.Context, p plan.Plan) plan.Plan {
+	switch x := p.(type) {
+	case *JoinPlan:
+		if x.Right == nil {
+			return p
+		}
+		if x.Type != CrossJoin {
+			x.Left = reorderJoin(ctx, x.Left)
+			x.Right = reorderJoin(ctx, x.Right)
+			return x
+		}
+		var (
+			leaves []*joinLeaf
+			conds  []expression.Expression
+		)
+		flattenJoin(x, 0, x.Fields, &leaves, &conds)
+		for _, leaf := range leaves {
+			leaf.p = reorderJoin(ctx, leaf.p)
+		}
+		return reorderLeaves(ctx, x, leaves, conds)
+	case *FilterDefaultPlan:
+		x.Plan = reorderJoin(ctx, x.Plan)
+	}
+	return p
+}
+
+// flattenJoin collects the source plans and the ON conditions of the tree of
+// inner joins p, whose columns start at offset in the rows of the tree.
+func flattenJoin(p plan.Plan, offset int, fields []*field.ResultField, leaves *[]*joinLeaf, conds *[]expression.Expression) {
+	join, ok := p.(*JoinPlan)
+	if !ok || join.Right == nil || join.Type != CrossJoin {
+		*leaves = append(*leaves, &joinLeaf{p: p, fields: fields, offset: offset})
+		return
+	}
+	if join.On != nil {
+		for _, cond := range SplitConjuncts(join.On) {
+			*conds = append(*conds, shiftColumns(cond, offset, nil))
+		}
+	}
+	leftLen := len(join.Left.GetFields())
+	flattenJoin(join.Left, offset, fields[:leftLen], leaves, conds)
+	flattenJoin(join.Right, offset+leftLen, fields[leftLen:], leaves, conds)
+}
+
+// reorderLeaves joins the leaves of the inner join tree p greedily. It starts
+// from the leaf with the least estimated rows, then joins the leaf with the least
+// estimated rows among the ones having ON conditions with the joined leaves, or
+// among all the leaves left if there is no such one.
+// Each leaf must be a table, whose rows have one row key, so the row keys can be
+// put back in the order of the tables with the columns, e.g. for UPDATE statement.
+func reorderLeaves(ctx context.Context, p *JoinPlan, leaves []*joinLeaf, exprs []expression.Expression) plan.Plan {
+	if len(leaves) < 3 {
+		return p
+	}
+	for _, leaf := range leaves {
+		if !isTableOrIndex(unwrapFilterPlan(leaf.p)) {
+			return p
+		}
+		leaf.rows = estimate(ctx, leaf.p).rowCount
+	}
+	conds := make([]*joinCond, 0, len(exprs))
+	for _, e := range exprs {
+		conds = append(conds, &joinCond{expr: e, leaves: condLeaves(e, leaves)})
+	}
+
+	joined := make([]bool, len(leaves))
+	order := make([]int, 0, len(leaves))
+	for len(order) < len(leaves) {
+		best, bestConnected := -1, false
+		for i, leaf := range leaves {
+			if joined[i] {
+				continue
+			}
+			connected := len(order) > 0 && isConnected(conds, joined, i)
+			if best < 0 || (connected && !bestConnected) ||
+				(connected == bestConnected && leaf.rows < leaves[best].rows) {
+				best, bestConnected = i, connected
+			}
+		}
+		joined[best] = true
+		order = append(order, best)
+	}
+	if isIdentityOrder(order) {
+		return p
+	}
+
+	// newOffsets maps the offsets of columns in the old rows to the ones in the new rows.
+	newOffsets := make([]int, len(p.Fields))
+	offset := 0
+	for _, i := range order {
+		leaf := leaves[i]
+		for j := range leaf.fields {
+			newOffsets[leaf.offset+j] = offset + j
+		}
+		offset += len(leaf.fields)
+	}
+	mapOffset := func(offset int) int { return newOffsets[offset] }
+
+	var (
+		root   plan.Plan
+		fields []*field.ResultField
+	)
+	placed := make([]bool, len(leaves))
+	used := make([]bool, len(conds))
+	for _, i := range order {
+		leaf := leaves[i]
+		placed[i] = true
+		if root == nil {
+			root, fields = leaf.p, leaf.fields
+			continue
+		}
+		var on []expression.Expression
+		for j, cond := range conds {
+			if !used[j] && allPlaced(cond.leaves, placed) {
+				on = append(on, mapColumns(cond.expr, mapOffset, nil))
+				used[j] = true
+			}
+		}
+		fields = append(append([]*field.ResultField(nil), fields...), leaf.fields...)
+		root = &JoinPlan{
+			Left:   root,
+			Right:  leaf.p,
+			Type:   CrossJoin,
+			On:     andConds(on),
+			Fields: fields,
+		}
+	}
+
+	r := &reorderedJoinPlan{Src: root, Fields: p.Fields, offsets: newOffsets}
+	// keyOrder maps the order of the leaves to the order they are joined.
+	r.keyOrder = make([]int, len(leaves))
+	for pos, i := range order {
+		r.keyOrder[i] = pos
+	}
+	return r
+}
+
+// unwrapFilterPlan returns the plan filtered by the FilterDefaultPlans p.
+func unwrapFilterPlan(p plan.Plan) plan.Plan {
+	for {
+		x, ok := p.(*FilterDefaultPlan)
+		if !ok {
+			return p
+		}
+		p = x.Plan
+	}
+}
+
+// condLeaves returns the leaves whose columns are mentioned by the condition e.
+func condLeaves(e expression.Expression, leaves []*joinLeaf) []int {
+	v := &columnOffsetsVisitor{}
+	v.BaseVisitor.V = v
+	e.Accept(v)
+	var ids []int
+	for i, leaf := range leaves {
+		for _, offset := range v.offsets {
+			if offset >= leaf.offset && offset < leaf.offset+len(leaf.fields) {
+				ids = append(ids, i)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// isConnected returns whether leaf i has ON conditions with the joined leaves.
+func isConnected(conds []*joinCond, joined []bool, i int) bool {
+	for _, cond := range conds {
+		mentioned, others := false, false
+		for _, id := range cond.leaves {
+			if id == i {
+				mentioned = true
+			} else if joined[id] {
+				others = true
+			}
+		}
+		if mentioned && others {
+			return true
+		}
+	}
+	return false
+}
+
+func allPlaced(ids []int, placed []bool) bool {
+	for _, id := range ids {
+		if !placed[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentityOrder(order []int) bool {
+	for i, id := range order {
+		if i != id {
+			return false
+		}
+	}
+	return true
+}
+
+// reorderedJoinPlan returns the rows of the reordered inner joins Src, with
+// the columns and the row keys in the order before the reordering.
+type reorderedJoinPlan struct {
+	Src    plan.Plan
+	Fields []*field.ResultField
+	// offsets are the offsets of the columns in the rows of Src.
+	offsets []int
+	// keyOrder are the offsets of the row keys in the rows of Src.
+	keyOrder []int
+}
+
+// Explain implements plan.Plan Explain interface.
+func (r *reorderedJoinPlan) Explain(w format.Formatter) {
+	r.Src.Explain(w)
+}
+
+// GetFields implements plan.Plan GetFields interface.
+func (r *reorderedJoinPlan) GetFields() []*field.ResultField {
+	return r.Fields
+}
+
+// Filter implements plan.Plan Filter interface.
+func (r *reorderedJoinPlan) Filter(ctx context.Context, expr expression.Expression) (plan.Plan, bool, error) {
+	return r, false, nil
+}
+
+// Next implements plan.Plan Next interface.
+func (r *reorderedJoinPlan) Next(ctx context.Context) (*plan.Row, error) {
+	row, err := r.Src.Next(ctx)
+	if row == nil || err != nil {
+		return nil, errors.Trace(err)
+	}
+	data := make([]interface{}, len(r.offsets))
+	for i, offset := range r.offsets {
+		data[i] = row.Data[offset]
+	}
+	row.Data = data
+	if len(row.RowKeys) == len(r.keyOrder) {
+		keys := make([]*plan.RowKeyEntry, len(r.keyOrder))
+		for i, offset := range r.keyOrder {
+			keys[i] = row.RowKeys[offset]
+		}
+		row.RowKeys = keys
+	}
+	return row, nil
+}
+
+// Close implements plan.Plan Close interface.
+func (r *reorderedJoinPlan) Close() error {
+	return r.Src.Close()
+}
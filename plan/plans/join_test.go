@@ -14,16 +14,25 @@
 package plans_test
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/column"
+	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/plan/plans"
 	"github.com/pingcap/tidb/rset/rsets"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/types"
@@ -270,3 +279,378 @@ func (s *testJoinSuit) TestJoinWithoutIndex(c *C) {
 		tk.MustQuery(v.sql).Check(v.result)
 	}
 }
+
+func (s *testJoinSuit) TestHashJoin(c *C) {
+	left := &testTablePlan{[]*testRowData{
+		{1, []interface{}{int64(1), "a"}},
+		{2, []interface{}{int64(2), "b"}},
+		{3, []interface{}{int64(2), "c"}},
+		{4, []interface{}{nil, "d"}},
+		{5, []interface{}{int64(4), "e"}},
+	}, []string{"id", "name"}, 0}
+	right := &testTablePlan{[]*testRowData{
+		{1, []interface{}{int64(2), "x"}},
+		{2, []interface{}{float64(2), "y"}},
+		// A string is compared with the numbers as a number.
+		{3, []interface{}{"1", "z"}},
+		{4, []interface{}{int64(5), "w"}},
+		{5, []interface{}{nil, "v"}},
+	}, []string{"id", "name"}, 0}
+	on := &expression.BinaryOperation{
+		Op: opcode.EQ,
+		L:  &expression.Ident{CIStr: model.NewCIStr("id"), ReferScope: expression.IdentReferFromTable, ReferIndex: 0},
+		R:  &expression.Ident{CIStr: model.NewCIStr("id"), ReferScope: expression.IdentReferFromTable, ReferIndex: 2},
+	}
+	fields := append(left.GetFields(), right.GetFields()...)
+
+	testcases := []struct {
+		tp     string
+		result []string
+	}{
+		{plans.CrossJoin, []string{
+			"[1 a 1 z]", "[2 b 2 x]", "[2 b 2 y]", "[2 c 2 x]", "[2 c 2 y]",
+		}},
+		{plans.LeftJoin, []string{
+			"[1 a 1 z]", "[2 b 2 x]", "[2 b 2 y]", "[2 c 2 x]", "[2 c 2 y]", "[4 e <nil> <nil>]", "[<nil> d <nil> <nil>]",
+		}},
+		{plans.RightJoin, []string{
+			"[1 a 1 z]", "[2 b 2 x]", "[2 b 2 y]", "[2 c 2 x]", "[2 c 2 y]", "[<nil> <nil> 5 w]", "[<nil> <nil> <nil> v]",
+		}},
+	}
+
+	// The rows are spilled to disk with the tiny memory quota.
+	for _, quota := range []string{"", "1"} {
+		for _, tc := range testcases {
+			p := &plans.JoinPlan{Left: left, Right: right, Type: tc.tp, Fields: fields, On: on}
+			var result []string
+			ctx := mock.NewContext()
+			variable.BindSessionVars(ctx)
+			variable.GetSessionVars(ctx).Systems[variable.TiDBMemQuota] = quota
+			for {
+				row, err := p.Next(ctx)
+				c.Assert(err, IsNil)
+				if row == nil {
+					break
+				}
+				result = append(result, fmt.Sprint(row.Data))
+			}
+			c.Assert(p.Close(), IsNil)
+			sort.Strings(result)
+			c.Assert(result, DeepEquals, tc.result, Commentf("%s join with quota %q", tc.tp, quota))
+		}
+	}
+
+	p := &plans.JoinPlan{Left: left, Right: right, Type: plans.LeftJoin, Fields: fields, On: on}
+	c.Assert(explainPlan(p), Matches, "(?s).*hash join on id = id.*")
+}
+
+func (s *testJoinSuit) TestIndexJoin(c *C) {
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	ctx := &txnContext{Context: mock.NewContext(), txn: txn}
+	variable.BindSessionVars(ctx)
+
+	outer := &testTablePlan{[]*testRowData{
+		{1, []interface{}{int64(1), "a"}},
+		{2, []interface{}{int64(2), "b"}},
+		{3, []interface{}{nil, "c"}},
+		{4, []interface{}{int64(4), "d"}},
+		// A string is compared with the numbers as a number.
+		{5, []interface{}{"2", "e"}},
+		{6, []interface{}{float64(1.5), "f"}},
+	}, []string{"id", "name"}, 0}
+
+	for i, unique := range []bool{false, true} {
+		var cols []*column.Col
+		for j, name := range []string{"id", "val"} {
+			cols = append(cols, &column.Col{
+				ColumnInfo: model.ColumnInfo{
+					ID:        int64(j),
+					Name:      model.NewCIStr(name),
+					Offset:    j,
+					FieldType: *types.NewFieldType(mysql.TypeLonglong),
+				},
+			})
+		}
+		tbl := tables.NewTable(int64(10+i), "t", cols, &simpleAllocator{})
+		tbl.AddIndex(&column.IndexedCol{
+			IndexInfo: model.IndexInfo{
+				Name:    model.NewCIStr("id"),
+				Table:   model.NewCIStr("t"),
+				Columns: []*model.IndexColumn{{Name: model.NewCIStr("id"), Offset: 0}},
+				Unique:  unique,
+			},
+			X: kv.NewKVIndex(fmt.Sprintf("i%d", i), "id", unique),
+		})
+		data := [][]interface{}{{int64(1), int64(10)}, {int64(2), int64(20)}, {int64(3), int64(30)}}
+		if !unique {
+			data = append(data, []interface{}{int64(2), int64(21)})
+		}
+		for _, d := range data {
+			_, err = tbl.AddRecord(ctx, d)
+			c.Assert(err, IsNil)
+		}
+		var fields []*field.ResultField
+		for _, col := range tbl.Cols() {
+			fields = append(fields, field.ColToResultField(col, "t"))
+		}
+		inner := &plans.TableDefaultPlan{T: tbl, Fields: fields}
+
+		twos := []string{"[2 b 2 20]", "[2 e 2 20]"}
+		twosRight := []string{"[2 20 2 b]", "[2 20 2 e]"}
+		if !unique {
+			twos = append(twos, "[2 b 2 21]", "[2 e 2 21]")
+			twosRight = append(twosRight, "[2 21 2 b]", "[2 21 2 e]")
+		}
+		testcases := []struct {
+			tp     string
+			left   plan.Plan
+			right  plan.Plan
+			result []string
+		}{
+			{plans.CrossJoin, outer, inner, append([]string{"[1 a 1 10]"}, twos...)},
+			{plans.LeftJoin, outer, inner, append(append([]string{"[1 a 1 10]"}, twos...),
+				"[1.5 f <nil> <nil>]", "[4 d <nil> <nil>]", "[<nil> c <nil> <nil>]")},
+			{plans.RightJoin, inner, outer, append(append([]string{"[1 10 1 a]"}, twosRight...),
+				"[<nil> <nil> 1.5 f]", "[<nil> <nil> 4 d]", "[<nil> <nil> <nil> c]")},
+		}
+		for _, tc := range testcases {
+			on := &expression.BinaryOperation{
+				Op: opcode.EQ,
+				L:  &expression.Ident{CIStr: model.NewCIStr("id"), ReferScope: expression.IdentReferFromTable, ReferIndex: 0},
+				R:  &expression.Ident{CIStr: model.NewCIStr("id"), ReferScope: expression.IdentReferFromTable, ReferIndex: 2},
+			}
+			fields := append(tc.left.GetFields(), tc.right.GetFields()...)
+			p := &plans.JoinPlan{Left: tc.left, Right: tc.right, Type: tc.tp, Fields: fields, On: on}
+			c.Assert(explainPlan(p), Matches, `(?s).*index join on id = id using index "id".*`)
+			var result []string
+			for {
+				row, err := p.Next(ctx)
+				c.Assert(err, IsNil)
+				if row == nil {
+					break
+				}
+				result = append(result, fmt.Sprint(row.Data))
+			}
+			c.Assert(p.Close(), IsNil)
+			sort.Strings(result)
+			sort.Strings(tc.result)
+			c.Assert(result, DeepEquals, tc.result, Commentf("%s join, unique %v", tc.tp, unique))
+
+			// EXPLAIN ANALYZE executes the same join.
+			p = &plans.JoinPlan{Left: tc.left, Right: tc.right, Type: tc.tp, Fields: fields, On: on}
+			ep := &plans.ExplainAnalyzePlan{Src: p}
+			var lines []string
+			for {
+				row, err := ep.Next(ctx)
+				c.Assert(err, IsNil)
+				if row == nil {
+					break
+				}
+				lines = append(lines, row.Data[0].(string))
+			}
+			c.Assert(ep.Close(), IsNil)
+			c.Assert(strings.Join(lines, "\n"), Matches, `(?s).*index join on id = id using index "id".*Actual rows `+fmt.Sprint(len(tc.result))+`,.*`)
+		}
+	}
+}
+
+func (s *testJoinSuit) TestOptimizeJoin(c *C) {
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	ctx := &txnContext{Context: mock.NewContext(), txn: txn}
+	variable.BindSessionVars(ctx)
+
+	tables := []*plans.TableDefaultPlan{
+		newCompositeTable(c, ctx, 81, false),
+		newCompositeTable(c, ctx, 82, false),
+		newCompositeTable(c, ctx, 83, false),
+	}
+	eq := func(l, r int) expression.Expression {
+		return expression.NewBinaryOperation(opcode.EQ, newFromIdent("tenant", l), newFromIdent("tenant", r))
+	}
+	cmp := func(op opcode.Op, name string, index int, val interface{}) expression.Expression {
+		return expression.NewBinaryOperation(op, newFromIdent(name, index), expression.Value{Val: val})
+	}
+	// newJoin returns t1 join t2 on t1.tenant = t2.tenant join t3 on t2.tenant = t3.tenant,
+	// with the join type of the second join.
+	newJoin := func(tp string) *plans.JoinPlan {
+		var fields []*field.ResultField
+		for _, t := range tables {
+			fields = append(fields, t.Fields...)
+		}
+		left := &plans.JoinPlan{
+			Left:   &plans.TableDefaultPlan{T: tables[0].T, Fields: tables[0].Fields},
+			Right:  &plans.TableDefaultPlan{T: tables[1].T, Fields: tables[1].Fields},
+			Type:   plans.CrossJoin,
+			On:     eq(0, 3),
+			Fields: fields[:6],
+		}
+		return &plans.JoinPlan{
+			Left:   left,
+			Right:  &plans.TableDefaultPlan{T: tables[2].T, Fields: tables[2].Fields},
+			Type:   tp,
+			On:     expression.NewBinaryOperation(opcode.AndAnd, eq(3, 6), cmp(opcode.GT, "v", 8, 5)),
+			Fields: fields,
+		}
+	}
+
+	testcases := []struct {
+		tp      string
+		where   expression.Expression
+		explain string
+	}{
+		// The filtered t3 is joined first.
+		{plans.CrossJoin, expression.NewBinaryOperation(opcode.AndAnd, cmp(opcode.EQ, "tenant", 6, 2), cmp(opcode.LT, "v", 0, 7)),
+			`^┌Compute CROSS hash join .*┌Compute CROSS hash join .*using index "tc" where tenant in \[2,2\].*`},
+		{plans.CrossJoin, nil, ``},
+		// The conditions on the right table of a left join are not pushed down.
+		{plans.LeftJoin, expression.NewBinaryOperation(opcode.AndAnd, cmp(opcode.EQ, "tenant", 6, 2), cmp(opcode.EQ, "tenant", 0, 2)),
+			`^┌Compute LEFT hash join on tenant = tenant && v > 5 .*using index "tc" where tenant in \[2,2\].*Filter on v > 5.*Filter on tenant = 2\n`},
+	}
+	for _, tc := range testcases {
+		var expected plan.Plan = newJoin(tc.tp)
+		if tc.where != nil {
+			expected = &plans.FilterDefaultPlan{Plan: expected, Expr: tc.where}
+		}
+		p, ok, err := plans.OptimizeJoin(ctx, newJoin(tc.tp), tc.where)
+		c.Assert(err, IsNil)
+		c.Assert(ok, IsTrue)
+		c.Assert(explainPlan(p), Matches, "(?s)"+tc.explain+".*")
+		result := fetchJoinRows(c, ctx, p)
+		expectedResult := fetchJoinRows(c, ctx, expected)
+		c.Assert(len(result), Greater, 0)
+		c.Assert(result, DeepEquals, expectedResult)
+	}
+
+	// A single table is not optimized.
+	p, ok, err := plans.OptimizeJoin(ctx, tables[0], nil)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	c.Assert(p, Equals, tables[0])
+}
+
+// fetchJoinRows returns the sorted rows of join plan p with their row keys.
+func fetchJoinRows(c *C, ctx context.Context, p plan.Plan) []string {
+	var result []string
+	for {
+		row, err := p.Next(ctx)
+		c.Assert(err, IsNil)
+		if row == nil {
+			break
+		}
+		var handles []string
+		for _, key := range row.RowKeys {
+			handles = append(handles, key.Key)
+		}
+		result = append(result, fmt.Sprint(row.Data, handles))
+	}
+	c.Assert(p.Close(), IsNil)
+	sort.Strings(result)
+	return result
+}
+
+func (s *testJoinSuit) TestSemiJoin(c *C) {
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	ctx := &txnContext{Context: mock.NewContext(), txn: txn}
+	variable.BindSessionVars(ctx)
+
+	outer := newCompositeTable(c, ctx, 91, false)
+	inner := newCompositeTable(c, ctx, 92, false)
+	keys := func(names ...string) []expression.Expression {
+		offsets := map[string]int{"tenant": 0, "created": 1, "v": 2}
+		var exprs []expression.Expression
+		for _, name := range names {
+			exprs = append(exprs, newFromIdent(name, offsets[name]))
+		}
+		return exprs
+	}
+	rows := []string{"[1 -5 1]", "[1 <nil> 2]", "[1 3 3]", "[1 7 4]", "[2 1 5]", "[2 4 6]", "[0 4 7]"}
+	testcases := []struct {
+		outerKeys []expression.Expression
+		innerKeys []expression.Expression
+		anti      bool
+		nullAware bool
+		result    []string
+	}{
+		// tenant IN (SELECT v FROM inner)
+		{keys("tenant"), keys("v"), false, false, rows[:6]},
+		// tenant NOT IN (SELECT v FROM inner)
+		{keys("tenant"), keys("v"), true, true, rows[6:]},
+		// created NOT IN (SELECT created FROM inner), the subquery has a null value.
+		{keys("created"), keys("created"), true, true, nil},
+		// EXISTS (SELECT * FROM inner WHERE inner.created = outer.v)
+		{keys("v"), keys("created"), false, false, []string{rows[0], rows[2], rows[3], rows[6]}},
+		// NOT EXISTS (SELECT * FROM inner WHERE inner.created = outer.v)
+		{keys("v"), keys("created"), true, false, []string{rows[1], rows[4], rows[5]}},
+		// v NOT IN (SELECT created FROM inner WHERE inner.tenant = outer.tenant)
+		{keys("tenant", "v"), keys("tenant", "created"), true, true, rows[4:]},
+		// created NOT IN (SELECT v FROM inner WHERE inner.tenant = outer.tenant)
+		{keys("tenant", "created"), keys("tenant", "v"), true, true, []string{rows[0], rows[3], rows[4], rows[5], rows[6]}},
+	}
+	// The keys of the inner rows are spilled to disk with the tiny memory quota.
+	for _, quota := range []string{"", "1"} {
+		variable.GetSessionVars(ctx).Systems[variable.TiDBMemQuota] = quota
+		for i, tc := range testcases {
+			p := plans.NewSemiJoinPlan(ctx, outer, inner, tc.outerKeys, tc.innerKeys, tc.anti, tc.nullAware, expression.Value{Val: i})
+			c.Assert(explainPlan(p), Matches, "(?s)┌Compute (SEMI|ANTI) hash join on .*")
+			result := fetchRows(c, ctx, p)
+			c.Assert(result, DeepEquals, tc.result, Commentf("case %d with quota %q", i, quota))
+		}
+	}
+	variable.GetSessionVars(ctx).Systems[variable.TiDBMemQuota] = ""
+
+	// The inner rows are looked up with the index if there are fewer outer rows.
+	var cols []*column.Col
+	for j, name := range []string{"id", "val"} {
+		cols = append(cols, &column.Col{
+			ColumnInfo: model.ColumnInfo{
+				ID:        int64(j),
+				Name:      model.NewCIStr(name),
+				Offset:    j,
+				FieldType: *types.NewFieldType(mysql.TypeLonglong),
+			},
+		})
+	}
+	tbl := tables.NewTable(93, "t", cols, &simpleAllocator{})
+	tbl.AddIndex(&column.IndexedCol{
+		IndexInfo: model.IndexInfo{
+			Name:    model.NewCIStr("id"),
+			Table:   model.NewCIStr("t"),
+			Columns: []*model.IndexColumn{{Name: model.NewCIStr("id"), Offset: 0}},
+		},
+		X: kv.NewKVIndex("i93", "id", false),
+	})
+	for _, d := range [][]interface{}{{int64(1), int64(10)}, {int64(2), int64(20)}, {int64(2), int64(21)}, {int64(3), int64(30)}} {
+		_, err = tbl.AddRecord(ctx, d)
+		c.Assert(err, IsNil)
+	}
+	var fields []*field.ResultField
+	for _, col := range tbl.Cols() {
+		fields = append(fields, field.ColToResultField(col, "t"))
+	}
+	filtered := &plans.FilterDefaultPlan{Plan: outer, Expr: expression.Value{Val: int64(1)}}
+	for _, anti := range []bool{false, true} {
+		inner := &plans.FilterDefaultPlan{Plan: &plans.TableDefaultPlan{T: tbl, Fields: fields}, Expr: expression.NewBinaryOperation(opcode.GT, newFromIdent("val", 1), expression.Value{Val: 20})}
+		p := plans.NewSemiJoinPlan(ctx, filtered, inner, keys("tenant"), keys("tenant"), anti, false, expression.Value{Val: 1})
+		c.Assert(explainPlan(p), Matches, `(?s)┌Compute (SEMI|ANTI) index join on 1 using index "id" of.*`)
+		expected := rows[4:6]
+		if anti {
+			expected = append(append([]string(nil), rows[:4]...), rows[6])
+		}
+		c.Assert(fetchRows(c, ctx, p), DeepEquals, expected)
+	}
+}
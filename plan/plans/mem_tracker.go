@@ -0,0 +1,85 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"strconv"
+
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/sessionctx/variable"
+)
+
+// defaultMemQuota is used if tidb_mem_quota system variable is not a valid size.
+const defaultMemQuota int64 = 64 << 20
+
+// memQuota returns the max memory in bytes used by the operators of a session,
+// it is tidb_mem_quota system variable of the session.
+func memQuota(ctx context.Context) int64 {
+	var value string
+	if vars := variable.GetSessionVars(ctx); vars != nil {
+		value = vars.Systems[variable.TiDBMemQuota]
+	}
+	if value == "" {
+		value = variable.GetSysVar(variable.TiDBMemQuota).Value
+	}
+	quota, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || quota <= 0 {
+		return defaultMemQuota
+	}
+	return quota
+}
+
+// A dummy type to avoid naming collision in context.
+type memTrackerKeyType int
+
+// String defines a Stringer function for debugging and pretty printing.
+func (k memTrackerKeyType) String() string {
+	return "memory tracker"
+}
+
+// memTrackerKey holds the memTracker of a session.
+const memTrackerKey memTrackerKeyType = 0
+
+// memTracker accounts the memory held by the sorting, grouping, distinct and
+// join operators of a session. Each operator consumes the memory of the rows it
+// holds, and spills them to disk when the memory of all the operators of the
+// session exceeds the quota, then the memory is released.
+type memTracker struct {
+	quota    int64
+	consumed int64
+}
+
+// getMemTracker returns the memTracker of the session, with the quota of the
+// current tidb_mem_quota.
+func getMemTracker(ctx context.Context) *memTracker {
+	t, ok := ctx.Value(memTrackerKey).(*memTracker)
+	if !ok {
+		t = &memTracker{}
+		ctx.SetValue(memTrackerKey, t)
+	}
+	t.quota = memQuota(ctx)
+	return t
+}
+
+// consume adds n bytes to the memory of the session, it returns true if the
+// memory exceeds the quota.
+func (t *memTracker) consume(n int64) bool {
+	t.consumed += n
+	return t.consumed > t.quota
+}
+
+// release subtracts n bytes from the memory of the session.
+func (t *memTracker) release(n int64) {
+	t.consumed -= n
+}
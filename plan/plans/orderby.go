@@ -19,7 +19,6 @@ package plans
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 
 	"github.com/juju/errors"
@@ -35,15 +34,15 @@ import (
 var _ plan.Plan = (*OrderByDefaultPlan)(nil)
 
 // OrderByDefaultPlan handles ORDER BY statement, it uses an array to store
-// results temporarily, and sorts them by given expression.
+// results temporarily, and sorts them by given expression. If the results
+// exceed the memory quota of the session, they are sorted on disk.
 type OrderByDefaultPlan struct {
 	*SelectList
 	By   []expression.Expression
 	Ascs []bool
 	Src  plan.Plan
 
-	ordTable *orderByTable
-	cursor   int
+	sorter *externalSorter
 }
 
 // Explain implements plan.Plan Explain interface.
@@ -105,9 +104,14 @@ func (t *orderByTable) Swap(i, j int) {
 
 // Less implements sort.Interface Less interface.
 func (t *orderByTable) Less(i, j int) bool {
-	for index, asc := range t.Ascs {
-		v1 := t.Rows[i].Key[index]
-		v2 := t.Rows[j].Key[index]
+	return compareSortKeys(t.Rows[i].Key, t.Rows[j].Key, t.Ascs) < 0
+}
+
+// compareSortKeys compares the sort keys a and b in the directions of ascs.
+func compareSortKeys(a, b []interface{}, ascs []bool) int {
+	for index, asc := range ascs {
+		v1 := a[index]
+		v2 := b[index]
 
 		ret, err := types.Compare(v1, v2)
 		if err != nil {
@@ -120,31 +124,29 @@ func (t *orderByTable) Less(i, j int) bool {
 			ret = -ret
 		}
 
-		if ret < 0 {
-			return true
-		} else if ret > 0 {
-			return false
+		if ret != 0 {
+			return ret
 		}
 	}
 
-	return false
+	return 0
 }
 
 // Next implements plan.Plan Next interface.
 func (r *OrderByDefaultPlan) Next(ctx context.Context) (row *plan.Row, err error) {
-	if r.ordTable == nil {
-		r.ordTable = &orderByTable{Ascs: r.Ascs}
+	if r.sorter == nil {
+		r.sorter = newExternalSorter(ctx, r.Ascs)
 		err = r.fetchAll(ctx)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
 	}
-	if r.cursor == len(r.ordTable.Rows) {
-		return
+	ordRow, err := r.sorter.next()
+	if ordRow == nil || err != nil {
+		return nil, errors.Trace(err)
 	}
-	row = r.ordTable.Rows[r.cursor].Row
+	row = ordRow.Row
 	updateRowStack(ctx, row.Data, row.FromData)
-	r.cursor++
 	return
 }
 
@@ -174,10 +176,7 @@ func (r *OrderByDefaultPlan) fetchAll(ctx context.Context) error {
 			// TODO: check position invalidation
 			return row.Data[position-1], nil
 		}
-		ordRow := &orderByRow{
-			Row: row,
-			Key: make([]interface{}, 0, len(r.By)),
-		}
+		key := make([]interface{}, 0, len(r.By))
 		for _, by := range r.By {
 			// err1 is used for passing `go tool vet --shadow` check.
 			val, err1 := by.Eval(ctx, evalArgs)
@@ -191,17 +190,23 @@ func (r *OrderByDefaultPlan) fetchAll(ctx context.Context) error {
 				}
 			}
 
-			ordRow.Key = append(ordRow.Key, val)
+			key = append(key, val)
+		}
+		if err = r.sorter.add(key, row); err != nil {
+			return errors.Trace(err)
 		}
-		r.ordTable.Rows = append(r.ordTable.Rows, ordRow)
 	}
-	sort.Sort(r.ordTable)
-	return nil
+	return errors.Trace(r.sorter.finish())
 }
 
 // Close implements plan.Plan Close interface.
 func (r *OrderByDefaultPlan) Close() error {
-	r.ordTable = nil
-	r.cursor = 0
+	if r.sorter != nil {
+		err := r.sorter.close()
+		r.sorter = nil
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 	return r.Src.Close()
 }
@@ -14,12 +14,15 @@
 package plans_test
 
 import (
+	"fmt"
+
 	"github.com/ngaut/log"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/plan/plans"
 	"github.com/pingcap/tidb/rset/rsets"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/mock"
 )
 
@@ -73,3 +76,45 @@ func (t *testOrderBySuit) TestOrderBy(c *C) {
 		log.Error(err)
 	}
 }
+
+func (t *testOrderBySuit) TestOrderBySpill(c *C) {
+	var data []*testRowData
+	for i := 0; i < 50; i++ {
+		data = append(data, &testRowData{int64(i), []interface{}{int64(i * 7 % 50), fmt.Sprint(i)}})
+	}
+	tblPlan := &testTablePlan{data, []string{"id", "name"}, 0}
+	pln := &plans.OrderByDefaultPlan{
+		SelectList: &plans.SelectList{
+			HiddenFieldOffset: len(tblPlan.GetFields()),
+			ResultFields:      tblPlan.GetFields(),
+		},
+		Src: tblPlan,
+		By: []expression.Expression{
+			&expression.Ident{
+				CIStr:      model.NewCIStr("id"),
+				ReferScope: expression.IdentReferSelectList,
+				ReferIndex: 0,
+			},
+		},
+		Ascs: []bool{false},
+	}
+
+	// Each sorted run has only a few rows.
+	ctx := mock.NewContext()
+	variable.BindSessionVars(ctx)
+	variable.GetSessionVars(ctx).Systems[variable.TiDBMemQuota] = "300"
+	rset := rsets.Recordset{Plan: pln, Ctx: ctx}
+	for i := 0; i < 2; i++ {
+		var ids []int64
+		err := rset.Do(func(data []interface{}) (bool, error) {
+			c.Assert(data[1], Equals, fmt.Sprint(data[0].(int64)*43%50))
+			ids = append(ids, data[0].(int64))
+			return true, nil
+		})
+		c.Assert(err, IsNil)
+		c.Assert(ids, HasLen, 50)
+		for j, id := range ids {
+			c.Assert(id, Equals, int64(49-j))
+		}
+	}
+}
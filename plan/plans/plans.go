@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/field"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/format"
@@ -47,6 +48,30 @@ func isTableOrIndex(p plan.Plan) bool {
 	}
 }
 
+// SplitConjuncts splits the expression by AND operator.
+func SplitConjuncts(e expression.Expression) []expression.Expression {
+	switch x := e.(type) {
+	case *expression.PExpr:
+		return SplitConjuncts(x.Expr)
+	case *expression.BinaryOperation:
+		if x.Op == opcode.AndAnd {
+			return append(SplitConjuncts(x.L), SplitConjuncts(x.R)...)
+		}
+	}
+	return []expression.Expression{e}
+}
+
+// UnwrapPExpr returns the expression in the parentheses of e.
+func UnwrapPExpr(e expression.Expression) expression.Expression {
+	for {
+		x, ok := e.(*expression.PExpr)
+		if !ok {
+			return e
+		}
+		e = x.Expr
+	}
+}
+
 // GetIdentValue is a function that evaluate identifier value from row.
 func GetIdentValue(name string, fields []*field.ResultField, row []interface{}) (interface{}, error) {
 	indices := field.GetResultFieldIndex(name, fields)
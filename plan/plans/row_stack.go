@@ -47,8 +47,7 @@ func (p *RowStackFromPlan) Filter(ctx context.Context, expr expression.Expressio
 		return p, false, errors.Trace(err)
 	}
 
-	p.Src = r
-	return p, true, nil
+	return &RowStackFromPlan{Src: r}, true, nil
 }
 
 // Next implements the plan.Plan Next interface.
@@ -96,6 +96,16 @@ func (s *SelectList) GetFields() []*field.ResultField {
 
 // UpdateAggFields adds aggregate function resultfield to select result field list.
 func (s *SelectList) UpdateAggFields(expr expression.Expression) (expression.Expression, error) {
+	return s.updateHiddenFields(expr, true), nil
+}
+
+// UpdateWindowFields adds the expression with window functions to select result field list,
+// it is evaluated by WindowPlan.
+func (s *SelectList) UpdateWindowFields(expr expression.Expression) expression.Expression {
+	return s.updateHiddenFields(expr, false)
+}
+
+func (s *SelectList) updateHiddenFields(expr expression.Expression, agg bool) expression.Expression {
 	// We must add aggregate function to hidden select list
 	// and use a position expression to fetch its value later.
 	name := strings.ToLower(expr.String())
@@ -115,13 +125,15 @@ func (s *SelectList) UpdateAggFields(expr expression.Expression) (expression.Exp
 
 		pos := len(s.Fields)
 
-		s.AggFields[pos-1] = struct{}{}
+		if agg {
+			s.AggFields[pos-1] = struct{}{}
+		}
 
-		return &expression.Position{N: pos, Name: name}, nil
+		return &expression.Position{N: pos, Name: name}
 	}
 
 	// select list has this field, use it directly.
-	return &expression.Position{N: index + 1, Name: name}, nil
+	return &expression.Position{N: index + 1, Name: name}
 }
 
 // CheckAmbiguous checks whether an identifier reference is ambiguous or not in select list.
@@ -0,0 +1,431 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/format"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var _ plan.Plan = (*SemiJoinPlan)(nil)
+
+// SemiJoinPlan returns the rows of Src which have matched rows in Inner (semi join),
+// or the ones which have none (anti join). An outer row matches an inner row if
+// all their keys are equal. It is planned for `IN`, `NOT IN`, `EXISTS` and `NOT EXISTS`
+// subqueries in WHERE clause, Inner is the subquery without the correlated equal
+// conditions, which are turned into the keys.
+//
+// The inner rows are put into a hash table by their keys, or looked up with the
+// index on an inner key column once for each outer row, if the inner side is a
+// table with fewer rows read that way.
+type SemiJoinPlan struct {
+	Src   plan.Plan
+	Inner plan.Plan
+	// OuterKeys are evaluated on the rows of Src, InnerKeys are evaluated on the rows of Inner.
+	OuterKeys []expression.Expression
+	InnerKeys []expression.Expression
+	Anti      bool
+	// NullAware is true for NOT IN, whose last keys are the compared expression and
+	// the subquery field. `x NOT IN (...)` is true only if x is not equal to any value
+	// and there is no null value, or there is no value at all.
+	NullAware bool
+	// Expr is the subquery predicate the plan is planned for.
+	Expr expression.Expression
+
+	lookup   *indexJoiner
+	filters  []*FilterDefaultPlan
+	keys     *keySet
+	corrKeys *keySet // the correlated keys of the inner rows, for NullAware.
+	nullKeys *keySet // the correlated keys of the inner rows whose last key is null, for NullAware.
+	built    bool
+	evalArgs map[interface{}]interface{}
+}
+
+// NewSemiJoinPlan creates a SemiJoinPlan. The inner rows are looked up with an index
+// if Inner is a table, possibly filtered, which has an index on the column of an inner
+// key, and there are fewer outer rows than inner rows. Only the inner keys are read
+// from Inner, so it reads them from an index if the index has all of them.
+func NewSemiJoinPlan(ctx context.Context, src, inner plan.Plan, outerKeys, innerKeys []expression.Expression, anti, nullAware bool, expr expression.Expression) *SemiJoinPlan {
+	r := &SemiJoinPlan{
+		Src:       src,
+		Inner:     inner,
+		OuterKeys: outerKeys,
+		InnerKeys: innerKeys,
+		Anti:      anti,
+		NullAware: nullAware,
+		Expr:      expr,
+	}
+	r.lookup, r.filters = newSemiJoinLookup(ctx, r)
+	if r.lookup == nil {
+		UseCoveringIndex(inner, innerKeys)
+	}
+	return r
+}
+
+// newSemiJoinLookup returns the index joiner to look up the inner rows, and the filters
+// on the inner table from the bottom up.
+func newSemiJoinLookup(ctx context.Context, r *SemiJoinPlan) (*indexJoiner, []*FilterDefaultPlan) {
+	if r.NullAware {
+		// The inner rows with null keys can't be looked up.
+		return nil, nil
+	}
+	var filters []*FilterDefaultPlan
+	p := r.Inner
+	for {
+		x, ok := p.(*FilterDefaultPlan)
+		if !ok {
+			break
+		}
+		filters = append([]*FilterDefaultPlan{x}, filters...)
+		p = x.Plan
+	}
+	if _, ok := p.(*TableDefaultPlan); !ok {
+		return nil, nil
+	}
+	if estimate(ctx, r.Src).rowCount >= estimate(ctx, p).rowCount {
+		return nil, nil
+	}
+	for i, key := range r.InnerKeys {
+		ident, ok := unwrapIdent(key)
+		if !ok || ident.ReferScope != expression.IdentReferFromTable {
+			continue
+		}
+		if ij := newIndexJoinerOn(p, true, []int{i}, []int{ident.ReferIndex}); ij != nil {
+			return ij, filters
+		}
+	}
+	return nil, nil
+}
+
+func (r *SemiJoinPlan) joinType() string {
+	if r.Anti {
+		return "ANTI"
+	}
+	return "SEMI"
+}
+
+// Explain implements plan.Plan Explain interface.
+func (r *SemiJoinPlan) Explain(w format.Formatter) {
+	if r.lookup != nil {
+		w.Format("┌Compute %s index join on %s using index %q of\n", r.joinType(), r.Expr, r.lookup.index.Name.O)
+	} else {
+		w.Format("┌Compute %s hash join on %s of\n", r.joinType(), r.Expr)
+	}
+	for _, p := range []plan.Plan{r.Src, r.Inner} {
+		w.Format("┌Iterate all rows of virtual table\n")
+		p.Explain(w)
+		w.Format("└Output field names %v\n", field.RFQNames(p.GetFields()))
+	}
+	w.Format("└Output field names %v\n", field.RFQNames(r.GetFields()))
+}
+
+// GetFields implements plan.Plan GetFields interface.
+func (r *SemiJoinPlan) GetFields() []*field.ResultField {
+	return r.Src.GetFields()
+}
+
+// Filter implements plan.Plan Filter interface.
+func (r *SemiJoinPlan) Filter(ctx context.Context, expr expression.Expression) (plan.Plan, bool, error) {
+	return r, false, nil
+}
+
+// Next implements plan.Plan Next interface.
+func (r *SemiJoinPlan) Next(ctx context.Context) (row *plan.Row, err error) {
+	if r.evalArgs == nil {
+		r.evalArgs = map[interface{}]interface{}{}
+	}
+	if r.lookup == nil && !r.built {
+		if err = r.build(ctx); err != nil {
+			return nil, errors.Trace(err)
+		}
+		r.built = true
+	}
+	for {
+		row, err = r.Src.Next(ctx)
+		if row == nil || err != nil {
+			return nil, errors.Trace(err)
+		}
+		var (
+			vals []interface{}
+			ok   bool
+		)
+		vals, err = r.evalKeys(ctx, row, r.OuterKeys)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if r.lookup != nil {
+			ok, err = r.lookupMatched(ctx, vals)
+		} else {
+			ok, err = r.matched(vals)
+		}
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if ok != r.Anti {
+			return row, nil
+		}
+	}
+}
+
+// evalKeys evaluates the keys on the row.
+func (r *SemiJoinPlan) evalKeys(ctx context.Context, row *plan.Row, keys []expression.Expression) ([]interface{}, error) {
+	r.evalArgs[expression.ExprEvalIdentReferFunc] = func(name string, scope int, index int) (interface{}, error) {
+		if scope == expression.IdentReferFromTable {
+			return row.Data[index], nil
+		}
+		return getIdentValueFromOuterQuery(ctx, name)
+	}
+	vals := make([]interface{}, len(keys))
+	for i, key := range keys {
+		v, err := key.Eval(ctx, r.evalArgs)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+// build reads the keys of all the inner rows into the key sets.
+func (r *SemiJoinPlan) build(ctx context.Context) error {
+	defer r.Inner.Close()
+	n := len(r.InnerKeys)
+	r.keys = newKeySet(ctx, n)
+	if r.NullAware {
+		r.corrKeys = newKeySet(ctx, n-1)
+		r.nullKeys = newKeySet(ctx, n-1)
+	}
+	for {
+		row, err := r.Inner.Next(ctx)
+		if row == nil || err != nil {
+			return errors.Trace(err)
+		}
+		vals, err := r.evalKeys(ctx, row, r.InnerKeys)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = r.keys.add(vals); err != nil {
+			return errors.Trace(err)
+		}
+		if !r.NullAware {
+			continue
+		}
+		if err = r.corrKeys.add(vals[:n-1]); err != nil {
+			return errors.Trace(err)
+		}
+		if vals[n-1] == nil {
+			if err = r.nullKeys.add(vals[:n-1]); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+}
+
+// matched returns whether the outer keys match the inner rows. For NullAware,
+// it is whether `x IN (...)` is true or null.
+func (r *SemiJoinPlan) matched(vals []interface{}) (bool, error) {
+	if !r.NullAware {
+		return r.keys.has(vals)
+	}
+	n := len(vals)
+	for _, v := range vals[:n-1] {
+		if v == nil {
+			// No inner row is correlated.
+			return false, nil
+		}
+	}
+	if vals[n-1] == nil {
+		// Null compared with any value is null.
+		return r.corrKeys.has(vals[:n-1])
+	}
+	ok, err := r.keys.has(vals)
+	if ok || err != nil {
+		return ok, errors.Trace(err)
+	}
+	return r.nullKeys.has(vals[:n-1])
+}
+
+// lookupMatched returns whether the outer keys match the inner rows looked up with the index.
+func (r *SemiJoinPlan) lookupMatched(ctx context.Context, vals []interface{}) (bool, error) {
+	p, err := r.lookup.lookupPlan(&plan.Row{Data: vals})
+	if p == nil || err != nil {
+		return false, errors.Trace(err)
+	}
+	for _, f := range r.filters {
+		p = &FilterDefaultPlan{Plan: p, Expr: f.Expr}
+	}
+	defer p.Close()
+	for {
+		row, err := p.Next(ctx)
+		if row == nil || err != nil {
+			return false, errors.Trace(err)
+		}
+		innerVals, err := r.evalKeys(ctx, row, r.InnerKeys)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		ok, err := keysEqual(vals, innerVals)
+		if ok || err != nil {
+			return ok, errors.Trace(err)
+		}
+	}
+}
+
+// Close implements plan.Plan Close interface.
+func (r *SemiJoinPlan) Close() error {
+	r.built = false
+	for _, s := range []*keySet{r.keys, r.corrKeys, r.nullKeys} {
+		if s == nil {
+			continue
+		}
+		if err := s.close(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	r.keys, r.corrKeys, r.nullKeys = nil, nil, nil
+	return r.Src.Close()
+}
+
+// keySet is a set of key values. Key values with null are never in the set,
+// as null is not equal to any value. The values are spilled to disk when the
+// memory of the session exceeds the quota.
+type keySet struct {
+	// classes is the bit set of key classes of the values at each key.
+	classes  []int
+	vals     map[string][]interface{}
+	memUsage int64
+	tracker  *memTracker
+	spill    *spillStore
+}
+
+func newKeySet(ctx context.Context, n int) *keySet {
+	return &keySet{
+		classes: make([]int, n),
+		vals:    make(map[string][]interface{}),
+		tracker: getMemTracker(ctx),
+	}
+}
+
+func (s *keySet) add(vals []interface{}) error {
+	classes := make([]int, len(vals))
+	key, hasNull, err := hashKeyValues(vals, classes)
+	if hasNull || err != nil {
+		return errors.Trace(err)
+	}
+	for i, c := range classes {
+		s.classes[i] |= c
+	}
+	if s.spill != nil {
+		return errors.Trace(s.spill.put(key, &plan.Row{Data: vals}))
+	}
+	if _, ok := s.vals[string(key)]; ok {
+		return nil
+	}
+	s.vals[string(key)] = vals
+	n := valuesMemUsage(vals) + int64(len(key))
+	s.memUsage += n
+	if !s.tracker.consume(n) {
+		return nil
+	}
+
+	// Move all the values to the spill store.
+	spill, err := newSpillStore()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	s.spill = spill
+	for k, vals := range s.vals {
+		if err = spill.put([]byte(k), &plan.Row{Data: vals}); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	s.vals = nil
+	s.tracker.release(s.memUsage)
+	s.memUsage = 0
+	return nil
+}
+
+// has returns whether the set has values equal to vals. The values of different
+// key classes are compared one by one.
+func (s *keySet) has(vals []interface{}) (bool, error) {
+	classes := make([]int, len(vals))
+	key, hasNull, err := hashKeyValues(vals, classes)
+	if hasNull || err != nil {
+		return false, errors.Trace(err)
+	}
+	scanAll := false
+	for i, c := range classes {
+		if c == keyClassOther || s.classes[i]&^c != 0 {
+			scanAll = true
+			break
+		}
+	}
+	if s.spill != nil {
+		if scanAll {
+			key = nil
+		}
+		found := false
+		err = s.spill.iterate(key, func(row *plan.Row) (bool, error) {
+			if !scanAll {
+				found = true
+				return false, nil
+			}
+			found, err = keysEqual(vals, row.Data)
+			return !found, errors.Trace(err)
+		})
+		return found, errors.Trace(err)
+	}
+	if !scanAll {
+		_, ok := s.vals[string(key)]
+		return ok, nil
+	}
+	for _, setVals := range s.vals {
+		ok, err := keysEqual(vals, setVals)
+		if ok || err != nil {
+			return ok, errors.Trace(err)
+		}
+	}
+	return false, nil
+}
+
+func (s *keySet) close() error {
+	s.vals = nil
+	s.tracker.release(s.memUsage)
+	s.memUsage = 0
+	if s.spill == nil {
+		return nil
+	}
+	err := s.spill.close()
+	s.spill = nil
+	return errors.Trace(err)
+}
+
+// keysEqual returns whether all the values of x and y are equal.
+func keysEqual(x, y []interface{}) (bool, error) {
+	for i := range x {
+		if x[i] == nil || y[i] == nil {
+			return false, nil
+		}
+		n, err := types.Compare(x[i], y[i])
+		if n != 0 || err != nil {
+			return false, errors.Trace(err)
+		}
+	}
+	return true, nil
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/stmt"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
@@ -121,6 +122,8 @@ func (s *ShowPlan) GetFields() []*field.ResultField {
 			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLonglong}
 	case stmt.ShowCreateTable:
 		names = []string{"Table", "Create Table"}
+	case stmt.ShowCreateView:
+		names = []string{"View", "Create View"}
 	case stmt.ShowGrants:
 		names = []string{fmt.Sprintf("Grants for %s", s.User)}
 	case stmt.ShowTriggers:
@@ -128,6 +131,10 @@ func (s *ShowPlan) GetFields() []*field.ResultField {
 			"sql_mode", "Definer", "character_set_client", "collation_connection", "Database Collation"}
 		types = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar,
 			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar}
+	case stmt.ShowStats:
+		names = []string{"Table", "Column_name", "Is_index", "Row_count", "Distinct_count", "Null_count", "Buckets"}
+		types = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLonglong, mysql.TypeLonglong,
+			mysql.TypeLonglong, mysql.TypeLonglong, mysql.TypeLonglong}
 	}
 	fields := make([]*field.ResultField, 0, len(names))
 	for i, name := range names {
@@ -189,10 +196,14 @@ func (s *ShowPlan) fetchAll(ctx context.Context) error {
 		return s.fetchShowCollation(ctx)
 	case stmt.ShowCreateTable:
 		return s.fetchShowCreateTable(ctx)
+	case stmt.ShowCreateView:
+		return s.fetchShowCreateView(ctx)
 	case stmt.ShowGrants:
 		return s.fetchShowGrants(ctx)
 	case stmt.ShowTriggers:
 		return s.fetchShowTriggers(ctx)
+	case stmt.ShowStats:
+		return s.fetchShowStats(ctx)
 	}
 	return nil
 }
@@ -337,8 +348,14 @@ func (s *ShowPlan) fetchShowTables(ctx context.Context) error {
 
 	// sort for tables
 	var tableNames []string
+	tableTypes := make(map[string]string)
 	for _, v := range is.SchemaTables(dbName) {
 		tableNames = append(tableNames, v.TableName().L)
+		if v.Meta().IsView() {
+			tableTypes[v.TableName().L] = "VIEW"
+		} else {
+			tableTypes[v.TableName().L] = "BASE TABLE"
+		}
 	}
 
 	sort.Strings(tableNames)
@@ -347,9 +364,7 @@ func (s *ShowPlan) fetchShowTables(ctx context.Context) error {
 	for _, v := range tableNames {
 		data := []interface{}{v}
 		if s.Full {
-			// TODO: support "VIEW" later if we have supported view feature.
-			// now, just use "BASE TABLE".
-			data = append(data, "BASE TABLE")
+			data = append(data, tableTypes[v])
 		}
 		// Check like/where clause.
 		if s.Pattern != nil {
@@ -586,6 +601,10 @@ func (s *ShowPlan) fetchShowCreateTable(ctx context.Context) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if tb.Meta().IsView() {
+		s.rows = append(s.rows, &plan.Row{Data: []interface{}{tb.TableName().O, createViewText(tb.Meta())}})
+		return nil
+	}
 
 	// TODO: let the result more like MySQL.
 	var buf bytes.Buffer
@@ -658,6 +677,27 @@ func (s *ShowPlan) fetchShowCreateTable(ctx context.Context) error {
 	return nil
 }
 
+func (s *ShowPlan) fetchShowCreateView(ctx context.Context) error {
+	tb, err := s.getTable(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !tb.Meta().IsView() {
+		return errors.Errorf("'%s.%s' is not VIEW", s.DBName, tb.TableName().O)
+	}
+	s.rows = append(s.rows, &plan.Row{Data: []interface{}{tb.TableName().O, createViewText(tb.Meta())}})
+	return nil
+}
+
+// createViewText returns the CREATE VIEW statement of the view.
+func createViewText(view *model.TableInfo) string {
+	cols := make([]string, 0, len(view.Columns))
+	for _, col := range view.Columns {
+		cols = append(cols, col.Name.O)
+	}
+	return fmt.Sprintf("CREATE VIEW `%s` (`%s`) AS %s", view.Name.O, strings.Join(cols, "`, `"), view.View.Select)
+}
+
 func (s *ShowPlan) fetchShowGrants(ctx context.Context) error {
 	// Get checker
 	checker := privilege.GetPrivilegeChecker(ctx)
@@ -678,3 +718,32 @@ func (s *ShowPlan) fetchShowGrants(ctx context.Context) error {
 func (s *ShowPlan) fetchShowTriggers(ctx context.Context) error {
 	return nil
 }
+
+func (s *ShowPlan) fetchShowStats(ctx context.Context) error {
+	tb, err := s.getTable(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	txn, err := ctx.GetTxn(false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	st, err := statistics.Load(txn, tb.TableID())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if st == nil {
+		// The table has not been analyzed.
+		return nil
+	}
+
+	appendRows := func(cols []*statistics.Column, isIndex int64) {
+		for _, col := range cols {
+			data := []interface{}{tb.TableName().O, col.Name, isIndex, st.Count, col.NDV, col.NullCount, int64(len(col.Buckets))}
+			s.rows = append(s.rows, &plan.Row{Data: data})
+		}
+	}
+	appendRows(st.Columns, 0)
+	appendRows(st.Indices, 1)
+	return nil
+}
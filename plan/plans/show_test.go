@@ -104,6 +104,11 @@ func (p *testShowSuit) TestSimple(c *C) {
 	pln.Target = stmt.ShowCreateTable
 	fls = pln.GetFields()
 	c.Assert(fls, HasLen, 2)
+
+	pln.Target = stmt.ShowStats
+	fls = pln.GetFields()
+	c.Assert(fls, HasLen, 7)
+	c.Assert(fls[3].Col.Tp, Equals, mysql.TypeLonglong)
 }
 
 func (p *testShowSuit) TestShowSysVariables(c *C) {
@@ -362,6 +367,49 @@ func (p *testShowSuit) TestShowTables(c *C) {
 	c.Assert(rows.Err(), NotNil)
 }
 
+func (p *testShowSuit) TestShowStats(c *C) {
+	testDB, err := sql.Open(tidb.DriverName, tidb.EngineGoLevelDBMemory+"/test-show-stats/test")
+	c.Assert(err, IsNil)
+	mustExec(c, testDB, "create table t (a int, b int, index idx_b (b));")
+	mustExec(c, testDB, "insert into t values (1, 1), (2, 1), (3, null);")
+	cnt := mustQuery(c, testDB, `show stats from t;`)
+	c.Assert(cnt, Equals, 0)
+
+	mustExec(c, testDB, "analyze table t;")
+	cnt = mustQuery(c, testDB, `show stats from t;`)
+	c.Assert(cnt, Equals, 3)
+
+	rows, err := testDB.Query(`show stats from t;`)
+	c.Assert(err, IsNil)
+	for rows.Next() {
+		var (
+			tbl, col                  string
+			isIndex, count, ndv, null int64
+			buckets                   int64
+		)
+		err = rows.Scan(&tbl, &col, &isIndex, &count, &ndv, &null, &buckets)
+		c.Assert(err, IsNil)
+		c.Assert(tbl, Equals, "t")
+		c.Assert(count, Equals, int64(3))
+		switch col {
+		case "a":
+			c.Assert(isIndex, Equals, int64(0))
+			c.Assert(ndv, Equals, int64(3))
+			c.Assert(null, Equals, int64(0))
+		case "b", "idx_b":
+			c.Assert(ndv, Equals, int64(1))
+			c.Assert(null, Equals, int64(1))
+			c.Assert(buckets, Equals, int64(1))
+		}
+	}
+	c.Assert(rows.Err(), IsNil)
+	rows.Close()
+
+	rows, _ = testDB.Query(`show stats from abc;`)
+	rows.Next()
+	c.Assert(rows.Err(), NotNil)
+}
+
 func (p *testShowSuit) TestShowGrants(c *C) {
 	se := newSession(c, p.store, p.dbName)
 	ctx, _ := se.(context.Context)
@@ -0,0 +1,406 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/store/localstore/engine"
+	"github.com/pingcap/tidb/store/localstore/goleveldb"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/codec"
+)
+
+// spillStore is a temporary kv storage on disk for the rows which can't be
+// held in memory by an operator. It is removed when closed.
+type spillStore struct {
+	dir    string
+	db     engine.DB
+	batch  engine.Batch
+	seq    int64
+	tables []table.Table // tables referenced by the row keys of spilled rows.
+}
+
+// spillBatchSize is the number of rows written to the spill store in a batch.
+const spillBatchSize = 256
+
+func newSpillStore() (*spillStore, error) {
+	dir, err := ioutil.TempDir("", "tidb-spill")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	db, err := goleveldb.Driver{}.Open(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, errors.Trace(err)
+	}
+	return &spillStore{dir: dir, db: db}, nil
+}
+
+// put writes the row under the prefix, rows with the same prefix are
+// kept in the order they are put.
+func (s *spillStore) put(prefix []byte, row *plan.Row) error {
+	if s.batch == nil {
+		s.batch = s.db.NewBatch()
+	}
+	key := codec.EncodeBytes(nil, prefix)
+	key = codec.EncodeInt(key, s.seq)
+	value, err := s.encodeRow(row)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	s.batch.Put(key, value)
+	s.seq++
+	if s.seq%spillBatchSize == 0 {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *spillStore) flush() error {
+	if s.batch == nil {
+		return nil
+	}
+	err := s.db.Commit(s.batch)
+	s.batch = nil
+	return errors.Trace(err)
+}
+
+// iterate calls fn with the rows put under the prefix, or all the rows
+// if prefix is nil, until fn returns false or an error.
+func (s *spillStore) iterate(prefix []byte, fn func(row *plan.Row) (bool, error)) error {
+	cur, err := s.seek(prefix)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer cur.close()
+	for {
+		row, err := cur.next()
+		if row == nil || err != nil {
+			return errors.Trace(err)
+		}
+		more, err := fn(row)
+		if !more || err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
+
+// spillCursor reads the rows put under a prefix one by one, several cursors
+// can be used at the same time.
+type spillCursor struct {
+	s     *spillStore
+	it    engine.Iterator
+	start []byte
+}
+
+// seek returns a cursor of the rows put under the prefix, or all the rows
+// if prefix is nil.
+func (s *spillStore) seek(prefix []byte) (*spillCursor, error) {
+	if err := s.flush(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	var start []byte
+	if prefix != nil {
+		start = codec.EncodeBytes(nil, prefix)
+	}
+	it, err := s.db.Seek(start)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &spillCursor{s: s, it: it, start: start}, nil
+}
+
+// next returns the next row, or nil if there is no more row.
+func (c *spillCursor) next() (*plan.Row, error) {
+	if c.it == nil || !c.it.Next() || !bytes.HasPrefix(c.it.Key(), c.start) {
+		c.close()
+		return nil, nil
+	}
+	row, err := c.s.decodeRow(c.it.Value())
+	return row, errors.Trace(err)
+}
+
+func (c *spillCursor) close() {
+	if c.it != nil {
+		c.it.Release()
+		c.it = nil
+	}
+}
+
+func (s *spillStore) close() error {
+	err := s.db.Close()
+	os.RemoveAll(s.dir)
+	return errors.Trace(err)
+}
+
+func (s *spillStore) tableIndex(t table.Table) int {
+	for i, tbl := range s.tables {
+		if tbl == t {
+			return i
+		}
+	}
+	s.tables = append(s.tables, t)
+	return len(s.tables) - 1
+}
+
+func (s *spillStore) encodeRow(row *plan.Row) ([]byte, error) {
+	b, err := encodeSpillValues(nil, row.Data)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	b = codec.EncodeInt(b, int64(len(row.RowKeys)))
+	for _, rk := range row.RowKeys {
+		b = codec.EncodeInt(b, int64(s.tableIndex(rk.Tbl)))
+		b = codec.EncodeBytes(b, []byte(rk.Key))
+	}
+	return b, nil
+}
+
+func (s *spillStore) decodeRow(b []byte) (*plan.Row, error) {
+	b, data, err := decodeSpillValues(b)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	row := &plan.Row{Data: data}
+	b, n, err := codec.DecodeInt(b)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for i := int64(0); i < n; i++ {
+		var idx int64
+		b, idx, err = codec.DecodeInt(b)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		var key []byte
+		b, key, err = codec.DecodeBytes(b)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row.RowKeys = append(row.RowKeys, &plan.RowKeyEntry{Tbl: s.tables[idx], Key: string(key)})
+	}
+	return row, nil
+}
+
+// Type flags of the values encoded by encodeSpillValues.
+const (
+	spillNil byte = iota
+	spillInt
+	spillUint
+	spillFloat32
+	spillFloat64
+	spillString
+	spillBytes
+	spillDecimal
+	spillTime
+	spillDuration
+	spillHex
+	spillBit
+	spillEnum
+	spillSet
+	spillJSON
+)
+
+// encodeSpillValues encodes the values of a row, unlike codec.EncodeKey,
+// the encoded values can be decoded to the same types.
+func encodeSpillValues(b []byte, vals []interface{}) ([]byte, error) {
+	b = codec.EncodeInt(b, int64(len(vals)))
+	for _, val := range vals {
+		switch v := val.(type) {
+		case nil:
+			b = append(b, spillNil)
+		case bool:
+			n := int64(0)
+			if v {
+				n = 1
+			}
+			b = codec.EncodeInt(append(b, spillInt), n)
+		case int:
+			b = codec.EncodeInt(append(b, spillInt), int64(v))
+		case int8:
+			b = codec.EncodeInt(append(b, spillInt), int64(v))
+		case int16:
+			b = codec.EncodeInt(append(b, spillInt), int64(v))
+		case int32:
+			b = codec.EncodeInt(append(b, spillInt), int64(v))
+		case int64:
+			b = codec.EncodeInt(append(b, spillInt), v)
+		case uint:
+			b = codec.EncodeUint(append(b, spillUint), uint64(v))
+		case uint8:
+			b = codec.EncodeUint(append(b, spillUint), uint64(v))
+		case uint16:
+			b = codec.EncodeUint(append(b, spillUint), uint64(v))
+		case uint32:
+			b = codec.EncodeUint(append(b, spillUint), uint64(v))
+		case uint64:
+			b = codec.EncodeUint(append(b, spillUint), v)
+		case float32:
+			b = codec.EncodeFloat(append(b, spillFloat32), float64(v))
+		case float64:
+			b = codec.EncodeFloat(append(b, spillFloat64), v)
+		case string:
+			b = codec.EncodeBytes(append(b, spillString), []byte(v))
+		case []byte:
+			b = codec.EncodeBytes(append(b, spillBytes), v)
+		case mysql.Decimal:
+			b = codec.EncodeDecimal(append(b, spillDecimal), v)
+		case mysql.Time:
+			t, err := v.Time.MarshalBinary()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			b = codec.EncodeBytes(append(b, spillTime), t)
+			b = codec.EncodeInt(b, int64(v.Type))
+			b = codec.EncodeInt(b, int64(v.Fsp))
+		case mysql.Duration:
+			b = codec.EncodeInt(append(b, spillDuration), int64(v.Duration))
+			b = codec.EncodeInt(b, int64(v.Fsp))
+		case mysql.Hex:
+			b = codec.EncodeInt(append(b, spillHex), v.Value)
+		case mysql.Bit:
+			b = codec.EncodeUint(append(b, spillBit), v.Value)
+			b = codec.EncodeInt(b, int64(v.Width))
+		case mysql.Enum:
+			b = codec.EncodeBytes(append(b, spillEnum), []byte(v.Name))
+			b = codec.EncodeUint(b, v.Value)
+		case mysql.Set:
+			b = codec.EncodeBytes(append(b, spillSet), []byte(v.Name))
+			b = codec.EncodeUint(b, v.Value)
+		case mysql.JSON:
+			data, err := v.Marshal()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			b = codec.EncodeBytes(append(b, spillJSON), data)
+		default:
+			return nil, errors.Errorf("unsupported spill value type %T", val)
+		}
+	}
+	return b, nil
+}
+
+// decodeSpillValues decodes the values encoded by encodeSpillValues,
+// it returns the remaining bytes.
+func decodeSpillValues(b []byte) ([]byte, []interface{}, error) {
+	b, n, err := codec.DecodeInt(b)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	vals := make([]interface{}, n)
+	for i := range vals {
+		if len(b) == 0 {
+			return nil, nil, errors.New("insufficient bytes to decode spilled value")
+		}
+		flag := b[0]
+		b = b[1:]
+		switch flag {
+		case spillNil:
+		case spillInt:
+			b, vals[i], err = codec.DecodeInt(b)
+		case spillUint:
+			b, vals[i], err = codec.DecodeUint(b)
+		case spillFloat32:
+			var f float64
+			b, f, err = codec.DecodeFloat(b)
+			vals[i] = float32(f)
+		case spillFloat64:
+			b, vals[i], err = codec.DecodeFloat(b)
+		case spillString:
+			var v []byte
+			b, v, err = codec.DecodeBytes(b)
+			vals[i] = string(v)
+		case spillBytes:
+			b, vals[i], err = codec.DecodeBytes(b)
+		case spillDecimal:
+			b, vals[i], err = codec.DecodeDecimal(b)
+		case spillTime:
+			b, vals[i], err = decodeSpillTime(b)
+		case spillDuration:
+			var d, fsp int64
+			b, d, err = codec.DecodeInt(b)
+			if err == nil {
+				b, fsp, err = codec.DecodeInt(b)
+			}
+			vals[i] = mysql.Duration{Duration: time.Duration(d), Fsp: int(fsp)}
+		case spillHex:
+			var v int64
+			b, v, err = codec.DecodeInt(b)
+			vals[i] = mysql.Hex{Value: v}
+		case spillBit:
+			var v uint64
+			var width int64
+			b, v, err = codec.DecodeUint(b)
+			if err == nil {
+				b, width, err = codec.DecodeInt(b)
+			}
+			vals[i] = mysql.Bit{Value: v, Width: int(width)}
+		case spillEnum, spillSet:
+			var name []byte
+			var v uint64
+			b, name, err = codec.DecodeBytes(b)
+			if err == nil {
+				b, v, err = codec.DecodeUint(b)
+			}
+			if flag == spillEnum {
+				vals[i] = mysql.Enum{Name: string(name), Value: v}
+			} else {
+				vals[i] = mysql.Set{Name: string(name), Value: v}
+			}
+		case spillJSON:
+			var data []byte
+			b, data, err = codec.DecodeBytes(b)
+			if err == nil {
+				var j mysql.JSON
+				err = j.Unmarshal(data)
+				vals[i] = j
+			}
+		default:
+			return nil, nil, errors.Errorf("invalid spilled value flag %d", flag)
+		}
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	return b, vals, nil
+}
+
+func decodeSpillTime(b []byte) ([]byte, mysql.Time, error) {
+	var t mysql.Time
+	b, data, err := codec.DecodeBytes(b)
+	if err != nil {
+		return nil, t, errors.Trace(err)
+	}
+	if err = t.Time.UnmarshalBinary(data); err != nil {
+		return nil, t, errors.Trace(err)
+	}
+	var tp, fsp int64
+	b, tp, err = codec.DecodeInt(b)
+	if err != nil {
+		return nil, t, errors.Trace(err)
+	}
+	b, fsp, err = codec.DecodeInt(b)
+	if err != nil {
+		return nil, t, errors.Trace(err)
+	}
+	t.Type, t.Fsp = uint8(tp), int(fsp)
+	return b, t, nil
+}
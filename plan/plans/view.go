@@ -0,0 +1,72 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/format"
+)
+
+var (
+	_ plan.Plan = (*ViewFieldsPlan)(nil)
+)
+
+// ViewFieldsPlan picks the fields of a view from the rows of the SELECT
+// statement of the view, whose wildcards may get more fields than the ones
+// when the view is created.
+type ViewFieldsPlan struct {
+	Src plan.Plan
+	// Offsets are the offsets of the view fields in the fields of Src.
+	Offsets []int
+	Fields  []*field.ResultField
+}
+
+// Explain implements plan.Plan Explain interface.
+func (r *ViewFieldsPlan) Explain(w format.Formatter) {
+	r.Src.Explain(w)
+	w.Format("┌Pick view fields\n└Output field names %v\n", field.RFQNames(r.Fields))
+}
+
+// GetFields implements plan.Plan GetFields interface.
+func (r *ViewFieldsPlan) GetFields() []*field.ResultField {
+	return r.Fields
+}
+
+// Filter implements plan.Plan Filter interface.
+func (r *ViewFieldsPlan) Filter(ctx context.Context, expr expression.Expression) (plan.Plan, bool, error) {
+	return r, false, nil
+}
+
+// Next implements plan.Plan Next interface.
+func (r *ViewFieldsPlan) Next(ctx context.Context) (*plan.Row, error) {
+	row, err := r.Src.Next(ctx)
+	if row == nil || err != nil {
+		return nil, errors.Trace(err)
+	}
+	data := make([]interface{}, len(r.Offsets))
+	for i, offset := range r.Offsets {
+		data[i] = row.Data[offset]
+	}
+	row.Data = data
+	return row, nil
+}
+
+// Close implements plan.Plan Close interface.
+func (r *ViewFieldsPlan) Close() error {
+	return r.Src.Close()
+}
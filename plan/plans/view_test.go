@@ -0,0 +1,52 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans_test
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/plan/plans"
+)
+
+type testViewSuite struct{}
+
+var _ = Suite(&testViewSuite{})
+
+func (s *testViewSuite) TestViewFields(c *C) {
+	src := &testTablePlan{
+		rows: []*testRowData{
+			{1, []interface{}{1, "a", 10}},
+			{2, []interface{}{2, "b", 20}},
+		},
+		fields: []string{"id", "name", "added"},
+	}
+	p := &plans.ViewFieldsPlan{
+		Src:     src,
+		Offsets: []int{0, 2},
+		Fields:  []*field.ResultField{{Name: "id"}, {Name: "added"}},
+	}
+	c.Assert(p.GetFields(), HasLen, 2)
+
+	var rows [][]interface{}
+	for {
+		row, err := p.Next(nil)
+		c.Assert(err, IsNil)
+		if row == nil {
+			break
+		}
+		rows = append(rows, row.Data)
+	}
+	c.Assert(rows, DeepEquals, [][]interface{}{{1, 10}, {2, 20}})
+	c.Assert(p.Close(), IsNil)
+}
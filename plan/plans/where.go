@@ -88,6 +88,16 @@ func (r *FilterDefaultPlan) meetCondition(ctx context.Context) (bool, error) {
 	return x == 1, nil
 }
 
+// Filter implements plan.Plan Filter interface.
+// The filtered plan is still filtered by the expression.
+func (r *FilterDefaultPlan) Filter(ctx context.Context, expr expression.Expression) (plan.Plan, bool, error) {
+	p, filtered, err := r.Plan.Filter(ctx, expr)
+	if !filtered || err != nil {
+		return r, false, errors.Trace(err)
+	}
+	return &FilterDefaultPlan{Plan: p, Expr: r.Expr}, true, nil
+}
+
 // Close implements plan.Plan Close interface.
 func (r *FilterDefaultPlan) Close() error {
 	return r.Plan.Close()
@@ -0,0 +1,532 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans
+
+import (
+	"math"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/expression/builtin"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/format"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var (
+	_ plan.Plan = (*WindowPlan)(nil)
+)
+
+// WindowPlan computes the window functions on all the rows of Src, then
+// evaluates the select fields with window functions. The arguments, PARTITION BY
+// and ORDER BY expressions of the window functions are the positions of the
+// hidden fields evaluated by Src.
+// The rows are returned in the order of the window of the last function.
+type WindowPlan struct {
+	*SelectList
+	Src plan.Plan
+	// Exprs is the select fields with window functions, indexed by field offset.
+	Exprs map[int]expression.Expression
+	Funcs []*expression.WindowFunc
+
+	rows   []*plan.Row
+	order  []int
+	cursor int
+	done   bool
+}
+
+// Explain implements plan.Plan Explain interface.
+func (r *WindowPlan) Explain(w format.Formatter) {
+	r.Src.Explain(w)
+	w.Format("┌Compute window functions")
+	for _, v := range r.Funcs {
+		w.Format(" %s,", v)
+	}
+	w.Format("\n└Output field names %v\n", field.RFQNames(r.ResultFields))
+}
+
+// Filter implements plan.Plan Filter interface.
+func (r *WindowPlan) Filter(ctx context.Context, expr expression.Expression) (plan.Plan, bool, error) {
+	return r, false, nil
+}
+
+// Next implements plan.Plan Next interface.
+func (r *WindowPlan) Next(ctx context.Context) (row *plan.Row, err error) {
+	if !r.done {
+		r.done = true
+		if err = r.fetchAll(ctx); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if r.cursor == len(r.order) {
+		return nil, nil
+	}
+	row = r.rows[r.order[r.cursor]]
+	r.cursor++
+	updateRowStack(ctx, row.Data, row.FromData)
+	return row, nil
+}
+
+func positionArgs(row *plan.Row) map[interface{}]interface{} {
+	return map[interface{}]interface{}{
+		expression.ExprEvalPositionFunc: func(position int) (interface{}, error) {
+			// position is in [1, len(fields)], so we must decrease 1 to get correct index
+			return row.Data[position-1], nil
+		},
+	}
+}
+
+func (r *WindowPlan) fetchAll(ctx context.Context) error {
+	for {
+		row, err := r.Src.Next(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		r.rows = append(r.rows, row)
+	}
+
+	results := make([][]interface{}, len(r.Funcs))
+	for i, w := range r.Funcs {
+		wr := &windowRows{ctx: ctx, w: w, rows: r.rows}
+		if err := wr.compute(); err != nil {
+			return errors.Trace(err)
+		}
+		results[i] = wr.results
+		r.order = wr.order
+	}
+
+	for i, row := range r.rows {
+		m := positionArgs(row)
+		for j, w := range r.Funcs {
+			m[w] = results[j][i]
+		}
+		for k, e := range r.Exprs {
+			v, err := e.Eval(ctx, m)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			row.Data[k] = v
+		}
+	}
+	return nil
+}
+
+// Close implements plan.Plan Close interface.
+func (r *WindowPlan) Close() error {
+	r.rows, r.order = nil, nil
+	r.cursor = 0
+	r.done = false
+	return r.Src.Close()
+}
+
+// windowRows computes a window function on the rows.
+type windowRows struct {
+	ctx  context.Context
+	w    *expression.WindowFunc
+	rows []*plan.Row
+	// keys is the PARTITION BY values followed by the ORDER BY values of each row.
+	keys  [][]interface{}
+	ascs  []bool
+	order []int
+	// results is the result of each row.
+	results []interface{}
+}
+
+// Len implements sort.Interface Len interface.
+func (r *windowRows) Len() int {
+	return len(r.order)
+}
+
+// Swap implements sort.Interface Swap interface.
+func (r *windowRows) Swap(i, j int) {
+	r.order[i], r.order[j] = r.order[j], r.order[i]
+}
+
+// Less implements sort.Interface Less interface.
+func (r *windowRows) Less(i, j int) bool {
+	return compareSortKeys(r.keys[r.order[i]], r.keys[r.order[j]], r.ascs) < 0
+}
+
+func (r *windowRows) eval(e expression.Expression, i int) (interface{}, error) {
+	v, err := e.Eval(r.ctx, positionArgs(r.rows[r.order[i]]))
+	return v, errors.Trace(err)
+}
+
+// samePartition returns true if the rows at i and j of order are in the same partition.
+func (r *windowRows) samePartition(i, j int) bool {
+	n := len(r.w.PartitionBy)
+	return compareSortKeys(r.keys[r.order[i]][:n], r.keys[r.order[j]][:n], r.ascs[:n]) == 0
+}
+
+// peers returns true if the rows at i and j of order are peers, which are
+// in the same partition and have the same ORDER BY values.
+func (r *windowRows) peers(i, j int) bool {
+	return compareSortKeys(r.keys[r.order[i]], r.keys[r.order[j]], r.ascs) == 0
+}
+
+func (r *windowRows) compute() error {
+	w := r.w
+	r.order = make([]int, len(r.rows))
+	r.keys = make([][]interface{}, len(r.rows))
+	for i := range r.rows {
+		r.order[i] = i
+	}
+	for range w.PartitionBy {
+		r.ascs = append(r.ascs, true)
+	}
+	for _, v := range w.OrderBy {
+		r.ascs = append(r.ascs, v.Asc)
+	}
+	for i := range r.rows {
+		key := make([]interface{}, 0, len(r.ascs))
+		for _, e := range w.PartitionBy {
+			v, err := r.eval(e, i)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			key = append(key, v)
+		}
+		for _, v := range w.OrderBy {
+			v, err := r.eval(v.Expr, i)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			key = append(key, v)
+		}
+		r.keys[i] = key
+	}
+	sort.Stable(r)
+
+	r.results = make([]interface{}, len(r.rows))
+	for lo := 0; lo < len(r.order); {
+		hi := lo + 1
+		for hi < len(r.order) && r.samePartition(lo, hi) {
+			hi++
+		}
+		if err := r.computePartition(lo, hi); err != nil {
+			return errors.Trace(err)
+		}
+		lo = hi
+	}
+	return nil
+}
+
+// computePartition computes the function on the partition [lo, hi) of order.
+func (r *windowRows) computePartition(lo, hi int) error {
+	// peerStart and peerEnd are the first and the next of last peer of each row.
+	peerStart := make([]int, hi-lo)
+	peerEnd := make([]int, hi-lo)
+	for i := lo; i < hi; {
+		j := i + 1
+		for j < hi && r.peers(i, j) {
+			j++
+		}
+		for k := i; k < j; k++ {
+			peerStart[k-lo], peerEnd[k-lo] = i, j
+		}
+		i = j
+	}
+
+	n := hi - lo
+	switch r.w.F {
+	case "row_number":
+		for i := lo; i < hi; i++ {
+			r.set(i, int64(i-lo+1))
+		}
+	case "rank":
+		for i := lo; i < hi; i++ {
+			r.set(i, int64(peerStart[i-lo]-lo+1))
+		}
+	case "dense_rank":
+		var rank int64
+		for i := lo; i < hi; i++ {
+			if peerStart[i-lo] == i {
+				rank++
+			}
+			r.set(i, rank)
+		}
+	case "percent_rank":
+		for i := lo; i < hi; i++ {
+			if n == 1 {
+				r.set(i, float64(0))
+				continue
+			}
+			r.set(i, float64(peerStart[i-lo]-lo)/float64(n-1))
+		}
+	case "cume_dist":
+		for i := lo; i < hi; i++ {
+			r.set(i, float64(peerEnd[i-lo]-lo)/float64(n))
+		}
+	case "ntile":
+		buckets, err := r.positiveInt(r.w.Args[0], lo)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		size, extra := int64(n)/buckets, int64(n)%buckets
+		for i := lo; i < hi; i++ {
+			k := int64(i - lo)
+			if k < extra*(size+1) {
+				r.set(i, k/(size+1)+1)
+			} else {
+				r.set(i, (k-extra*(size+1))/size+extra+1)
+			}
+		}
+	case "lag", "lead":
+		return r.computeLagLead(lo, hi)
+	default:
+		return r.computeFramed(lo, hi, peerStart, peerEnd)
+	}
+	return nil
+}
+
+func (r *windowRows) set(i int, v interface{}) {
+	r.results[r.order[i]] = v
+}
+
+// positiveInt evaluates the argument e of the function on the row at i of
+// order, it must be a positive integer.
+func (r *windowRows) positiveInt(e expression.Expression, i int) (int64, error) {
+	v, err := r.eval(e, i)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	n, err := types.ToInt64(v)
+	if err != nil || n <= 0 {
+		return 0, errors.Errorf("Incorrect arguments to %s", r.w.F)
+	}
+	return n, nil
+}
+
+func (r *windowRows) computeLagLead(lo, hi int) error {
+	args := r.w.Args
+	for i := lo; i < hi; i++ {
+		offset := int64(1)
+		if len(args) > 1 {
+			v, err := r.eval(args[1], i)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if offset, err = types.ToInt64(v); err != nil || offset < 0 {
+				return errors.Errorf("Incorrect arguments to %s", r.w.F)
+			}
+		}
+		j := i - int(offset)
+		if r.w.F == "lead" {
+			j = i + int(offset)
+		}
+		e := args[0]
+		if j < lo || j >= hi {
+			if len(args) < 3 {
+				r.set(i, nil)
+				continue
+			}
+			e, j = args[2], i
+		}
+		v, err := r.eval(e, j)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		r.set(i, v)
+	}
+	return nil
+}
+
+// frame returns the frame [start, end) of order for the row at i in the partition [lo, hi).
+func (r *windowRows) frame(i, lo, hi int, peerStart, peerEnd []int) (int, int, error) {
+	f := r.w.Frame
+	if f == nil {
+		if len(r.w.OrderBy) == 0 {
+			return lo, hi, nil
+		}
+		// RANGE BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW
+		return lo, peerEnd[i-lo], nil
+	}
+
+	start, err := r.bound(f, f.Start, true, i, lo, hi, peerStart, peerEnd)
+	if err != nil {
+		return 0, 0, errors.Trace(err)
+	}
+	end, err := r.bound(f, f.End, false, i, lo, hi, peerStart, peerEnd)
+	if err != nil {
+		return 0, 0, errors.Trace(err)
+	}
+	if start > end {
+		end = start
+	}
+	return start, end, nil
+}
+
+// bound returns the start or the next of the end of the frame at b.
+func (r *windowRows) bound(f *expression.WindowFrame, b expression.FrameBound, start bool, i, lo, hi int, peerStart, peerEnd []int) (int, error) {
+	switch {
+	case b.UnBounded && b.Type == expression.BoundPreceding:
+		return lo, nil
+	case b.UnBounded:
+		return hi, nil
+	case b.Type == expression.BoundCurrentRow:
+		if !f.Range {
+			if start {
+				return i, nil
+			}
+			return i + 1, nil
+		}
+		if start {
+			return peerStart[i-lo], nil
+		}
+		return peerEnd[i-lo], nil
+	}
+
+	if !f.Range {
+		n, err := types.ToInt64(b.N)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		k := i + int(n)
+		if b.Type == expression.BoundPreceding {
+			k = i - int(n)
+		}
+		if !start {
+			k++
+		}
+		if k < lo {
+			return lo, nil
+		} else if k > hi {
+			return hi, nil
+		}
+		return k, nil
+	}
+
+	// The RANGE frame with N PRECEDING or N FOLLOWING is on the distances of the
+	// ORDER BY values from the current row, which increase in the partition.
+	offset, err := types.ToFloat64(b.N)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if b.Type == expression.BoundPreceding {
+		offset = -offset
+	}
+	cur, err := r.rangeKey(i)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if math.IsInf(cur, 0) {
+		// The NULL values are peers of each other.
+		if start {
+			return peerStart[i-lo], nil
+		}
+		return peerEnd[i-lo], nil
+	}
+	asc := r.w.OrderBy[0].Asc
+	var serr error
+	k := sort.Search(hi-lo, func(j int) bool {
+		v, err := r.rangeKey(lo + j)
+		if err != nil {
+			serr = err
+			return true
+		}
+		d := v - cur
+		if !asc {
+			d = cur - v
+		}
+		if start {
+			return d >= offset
+		}
+		return d > offset
+	})
+	return lo + k, errors.Trace(serr)
+}
+
+// rangeKey returns the ORDER BY value of the row at i of order for a RANGE
+// frame. NULL is the smallest value in both directions, so it is the first in
+// ascending order and the last in descending order, and the distances from
+// the current row keep increasing in the partition.
+func (r *windowRows) rangeKey(i int) (float64, error) {
+	v := r.keys[r.order[i]][len(r.w.PartitionBy)]
+	if v == nil {
+		return math.Inf(-1), nil
+	}
+	f, err := types.ToFloat64(v)
+	if err != nil {
+		return 0, errors.New("Window '<unnamed window>' with RANGE N PRECEDING/FOLLOWING frame requires exactly one ORDER BY expression, of numeric or temporal type")
+	}
+	return f, nil
+}
+
+func (r *windowRows) computeFramed(lo, hi int, peerStart, peerEnd []int) error {
+	w := r.w
+	// The aggregate function keeps the state of the last frame, the rows are
+	// added to it if the frame only grows at the end.
+	var (
+		agg              builtin.Func
+		state            map[interface{}]interface{}
+		aggStart, aggEnd = -1, -1
+	)
+	if expression.IsAggregateFunc(w.F) {
+		agg = builtin.Funcs[w.F]
+	}
+	for i := lo; i < hi; i++ {
+		start, end, err := r.frame(i, lo, hi, peerStart, peerEnd)
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		var v interface{}
+		switch w.F {
+		case "first_value":
+			if start < end {
+				v, err = r.eval(w.Args[0], start)
+			}
+		case "last_value":
+			if start < end {
+				v, err = r.eval(w.Args[0], end-1)
+			}
+		case "nth_value":
+			var n int64
+			if n, err = r.positiveInt(w.Args[1], i); err == nil && int64(end-start) >= n {
+				v, err = r.eval(w.Args[0], start+int(n)-1)
+			}
+		default:
+			if start == end {
+				v, err = agg.F(nil, map[interface{}]interface{}{builtin.ExprEvalArgAggEmpty: true})
+				break
+			}
+			if start != aggStart || end < aggEnd {
+				state = map[interface{}]interface{}{builtin.ExprEvalFn: w}
+				aggStart, aggEnd = start, start
+			}
+			for ; aggEnd < end && err == nil; aggEnd++ {
+				var arg interface{}
+				if arg, err = r.eval(w.Args[0], aggEnd); err == nil {
+					_, err = agg.F([]interface{}{arg}, state)
+				}
+			}
+			if err == nil {
+				state[builtin.ExprAggDone] = true
+				v, err = agg.F(nil, state)
+				delete(state, builtin.ExprAggDone)
+			}
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+		r.set(i, v)
+	}
+	return nil
+}
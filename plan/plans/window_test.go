@@ -0,0 +1,267 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plans_test
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/plan/plans"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/types"
+)
+
+type testWindowSuite struct{}
+
+var _ = Suite(&testWindowSuite{})
+
+var (
+	windowDept   = &expression.Position{N: 1, Name: "dept"}
+	windowSalary = &expression.Position{N: 2, Name: "salary"}
+)
+
+// run computes w on the rows of (dept, salary), and returns the salary and the
+// result of each row in output order.
+func (s *testWindowSuite) run(c *C, w *expression.WindowFunc) [][]interface{} {
+	data := [][]interface{}{
+		{"a", int64(100)},
+		{"b", int64(300)},
+		{"a", int64(200)},
+		{"a", int64(200)},
+		{"b", int64(100)},
+		{"a", int64(400)},
+	}
+	return s.runData(c, w, data)
+}
+
+// runData is like run, but computes w on the rows of data.
+func (s *testWindowSuite) runData(c *C, w *expression.WindowFunc, data [][]interface{}) [][]interface{} {
+	var rows []*testRowData
+	for i, v := range data {
+		rows = append(rows, &testRowData{int64(i), append(v, nil)})
+	}
+	selectList := &plans.SelectList{HiddenFieldOffset: 3}
+	for _, name := range []string{"dept", "salary", "w"} {
+		selectList.AddField(&field.Field{Expr: expression.Value{}}, &field.ResultField{Name: name})
+	}
+	p := &plans.WindowPlan{
+		SelectList: selectList,
+		Src:        &testTablePlan{rows, []string{"dept", "salary", "w"}, 0},
+		Exprs:      map[int]expression.Expression{2: w},
+		Funcs:      []*expression.WindowFunc{w},
+	}
+
+	ctx := mock.NewContext()
+	var ret [][]interface{}
+	for {
+		row, err := p.Next(ctx)
+		c.Assert(err, IsNil)
+		if row == nil {
+			break
+		}
+		ret = append(ret, []interface{}{row.Data[1], types.RawData(row.Data[2])})
+	}
+	c.Assert(p.Close(), IsNil)
+	return ret
+}
+
+func (s *testWindowSuite) newWindowFunc(c *C, f string, args []expression.Expression, desc bool, frame *expression.WindowFrame) *expression.WindowFunc {
+	orderBy := []*expression.WindowOrderItem{{Expr: windowSalary, Asc: !desc}}
+	w, err := expression.NewWindowFunc(f, args, false, []expression.Expression{windowDept}, orderBy, frame)
+	c.Assert(err, IsNil)
+	return w
+}
+
+func (s *testWindowSuite) TestRanking(c *C) {
+	tbl := []struct {
+		f      string
+		expect []interface{}
+	}{
+		{"row_number", []interface{}{int64(1), int64(2), int64(3), int64(4), int64(1), int64(2)}},
+		{"rank", []interface{}{int64(1), int64(2), int64(2), int64(4), int64(1), int64(2)}},
+		{"dense_rank", []interface{}{int64(1), int64(2), int64(2), int64(3), int64(1), int64(2)}},
+		{"percent_rank", []interface{}{float64(0), float64(1) / 3, float64(1) / 3, float64(1), float64(0), float64(1)}},
+		{"cume_dist", []interface{}{0.25, 0.75, 0.75, float64(1), 0.5, float64(1)}},
+	}
+	for _, t := range tbl {
+		ret := s.run(c, s.newWindowFunc(c, t.f, nil, true, nil))
+		c.Assert(ret, HasLen, 6)
+		// The rows are sorted by dept, salary desc.
+		salaries := []interface{}{int64(400), int64(200), int64(200), int64(100), int64(300), int64(100)}
+		for i, v := range ret {
+			c.Assert(v[0], Equals, salaries[i])
+			c.Assert(v[1], Equals, t.expect[i], Commentf("%s row %d", t.f, i))
+		}
+	}
+
+	ret := s.run(c, s.newWindowFunc(c, "ntile", []expression.Expression{expression.Value{Val: int64(3)}}, false, nil))
+	expect := []interface{}{int64(1), int64(1), int64(2), int64(3), int64(1), int64(2)}
+	for i, v := range ret {
+		c.Assert(v[1], Equals, expect[i])
+	}
+}
+
+func (s *testWindowSuite) TestLagLead(c *C) {
+	ret := s.run(c, s.newWindowFunc(c, "lag", []expression.Expression{windowSalary}, false, nil))
+	expect := []interface{}{nil, int64(100), int64(200), int64(200), nil, int64(100)}
+	for i, v := range ret {
+		c.Assert(v[1], Equals, expect[i])
+	}
+
+	args := []expression.Expression{windowSalary, expression.Value{Val: int64(2)}, expression.Value{Val: int64(0)}}
+	ret = s.run(c, s.newWindowFunc(c, "lead", args, false, nil))
+	expect = []interface{}{int64(200), int64(400), int64(0), int64(0), int64(0), int64(0)}
+	for i, v := range ret {
+		c.Assert(v[1], Equals, expect[i])
+	}
+}
+
+func (s *testWindowSuite) TestFrame(c *C) {
+	rows := func(start, end expression.FrameBound) *expression.WindowFrame {
+		return &expression.WindowFrame{Start: start, End: end}
+	}
+	ranges := func(start, end expression.FrameBound) *expression.WindowFrame {
+		return &expression.WindowFrame{Range: true, Start: start, End: end}
+	}
+	unboundedPreceding := expression.FrameBound{Type: expression.BoundPreceding, UnBounded: true}
+	unboundedFollowing := expression.FrameBound{Type: expression.BoundFollowing, UnBounded: true}
+	currentRow := expression.FrameBound{Type: expression.BoundCurrentRow}
+	preceding := func(n int64) expression.FrameBound {
+		return expression.FrameBound{Type: expression.BoundPreceding, N: n}
+	}
+	following := func(n int64) expression.FrameBound {
+		return expression.FrameBound{Type: expression.BoundFollowing, N: n}
+	}
+	decimals := func(vs ...int64) []interface{} {
+		var ret []interface{}
+		for _, v := range vs {
+			if v < 0 {
+				ret = append(ret, nil)
+				continue
+			}
+			ret = append(ret, mysql.NewDecimalFromInt(v, 0).String())
+		}
+		return ret
+	}
+
+	// The rows are sorted by dept, salary: a 100, a 200, a 200, a 400, b 100, b 300.
+	tbl := []struct {
+		f      string
+		frame  *expression.WindowFrame
+		expect []interface{}
+	}{
+		// RANGE BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW includes the peers.
+		{"sum", nil, decimals(100, 500, 500, 900, 100, 400)},
+		{"sum", rows(unboundedPreceding, currentRow), decimals(100, 300, 500, 900, 100, 400)},
+		{"sum", rows(preceding(1), following(1)), decimals(300, 500, 800, 600, 400, 400)},
+		{"sum", rows(following(1), unboundedFollowing), decimals(800, 600, 400, -1, 300, -1)},
+		{"sum", ranges(preceding(100), currentRow), decimals(100, 500, 500, 400, 100, 300)},
+		{"sum", ranges(currentRow, following(200)), decimals(500, 800, 800, 400, 400, 300)},
+		{"count", rows(preceding(2), preceding(1)), []interface{}{int64(0), int64(1), int64(2), int64(2), int64(0), int64(1)}},
+		{"first_value", ranges(preceding(100), following(100)), []interface{}{int64(100), int64(100), int64(100), int64(400), int64(100), int64(300)}},
+		{"last_value", nil, []interface{}{int64(100), int64(200), int64(200), int64(400), int64(100), int64(300)}},
+		{"max", rows(unboundedPreceding, unboundedFollowing), []interface{}{int64(400), int64(400), int64(400), int64(400), int64(300), int64(300)}},
+	}
+	for _, t := range tbl {
+		ret := s.run(c, s.newWindowFunc(c, t.f, []expression.Expression{windowSalary}, false, t.frame))
+		for i, v := range ret {
+			if d, ok := v[1].(mysql.Decimal); ok {
+				v[1] = d.String()
+			}
+			c.Assert(v[1], Equals, t.expect[i], Commentf("%s %v row %d", t.f, t.frame, i))
+		}
+	}
+
+	args := []expression.Expression{windowSalary, expression.Value{Val: int64(2)}}
+	ret := s.run(c, s.newWindowFunc(c, "nth_value", args, false, nil))
+	expect := []interface{}{nil, int64(200), int64(200), int64(200), nil, int64(300)}
+	for i, v := range ret {
+		c.Assert(v[1], Equals, expect[i])
+	}
+
+	// NULL is the last in descending order and the first in ascending order,
+	// it is only in the RANGE N PRECEDING/FOLLOWING frames of the NULL rows.
+	data := [][]interface{}{{"a", int64(3)}, {"a", nil}, {"a", int64(5)}, {"a", int64(4)}}
+	frame := ranges(preceding(1), following(1))
+	tblNull := []struct {
+		f      string
+		desc   bool
+		expect [][]interface{}
+	}{
+		{"last_value", true, [][]interface{}{{int64(5), int64(4)}, {int64(4), int64(3)}, {int64(3), int64(3)}, {nil, nil}}},
+		{"first_value", false, [][]interface{}{{nil, nil}, {int64(3), int64(3)}, {int64(4), int64(3)}, {int64(5), int64(4)}}},
+		{"count", true, [][]interface{}{{int64(5), int64(2)}, {int64(4), int64(3)}, {int64(3), int64(2)}, {nil, int64(0)}}},
+	}
+	for _, t := range tblNull {
+		ret = s.runData(c, s.newWindowFunc(c, t.f, []expression.Expression{windowSalary}, t.desc, frame), data)
+		c.Assert(ret, DeepEquals, t.expect, Commentf("%s desc %v", t.f, t.desc))
+	}
+}
+
+func (s *testWindowSuite) TestNewWindowFunc(c *C) {
+	orderBy := []*expression.WindowOrderItem{{Expr: windowSalary, Asc: true}}
+	tbl := []struct {
+		f        string
+		args     []expression.Expression
+		distinct bool
+		orderBy  []*expression.WindowOrderItem
+		frame    *expression.WindowFrame
+		ok       bool
+	}{
+		{"RANK", nil, false, orderBy, nil, true},
+		{"rank", []expression.Expression{windowSalary}, false, orderBy, nil, false},
+		{"lag", nil, false, orderBy, nil, false},
+		{"abs", []expression.Expression{windowSalary}, false, orderBy, nil, false},
+		{"count", []expression.Expression{windowSalary}, true, orderBy, nil, false},
+		{"sum", []expression.Expression{windowSalary}, false, nil, &expression.WindowFrame{
+			Range: true,
+			Start: expression.FrameBound{Type: expression.BoundPreceding, N: int64(1)},
+			End:   expression.FrameBound{Type: expression.BoundCurrentRow},
+		}, false},
+		{"sum", []expression.Expression{windowSalary}, false, orderBy, &expression.WindowFrame{
+			Start: expression.FrameBound{Type: expression.BoundFollowing, UnBounded: true},
+			End:   expression.FrameBound{Type: expression.BoundFollowing, UnBounded: true},
+		}, false},
+		{"sum", []expression.Expression{windowSalary}, false, orderBy, &expression.WindowFrame{
+			Start: expression.FrameBound{Type: expression.BoundPreceding, N: 1.5},
+			End:   expression.FrameBound{Type: expression.BoundCurrentRow},
+		}, false},
+		{"sum", []expression.Expression{windowSalary}, false, orderBy, &expression.WindowFrame{
+			Range: true,
+			Start: expression.FrameBound{Type: expression.BoundPreceding, N: 1.5},
+			End:   expression.FrameBound{Type: expression.BoundCurrentRow},
+		}, true},
+	}
+	for _, t := range tbl {
+		w, err := expression.NewWindowFunc(t.f, t.args, t.distinct, nil, t.orderBy, t.frame)
+		if !t.ok {
+			c.Assert(err, NotNil, Commentf("%s", t.f))
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Assert(w.IsStatic(), IsFalse)
+		c.Assert(w.Clone().String(), Equals, w.String())
+	}
+
+	w := s.newWindowFunc(c, "sum", []expression.Expression{windowSalary}, true, &expression.WindowFrame{
+		Start: expression.FrameBound{Type: expression.BoundPreceding, N: int64(1)},
+		End:   expression.FrameBound{Type: expression.BoundCurrentRow},
+	})
+	c.Assert(w.String(), Equals, "sum(salary) OVER (PARTITION BY dept ORDER BY salary DESC ROWS BETWEEN 1 PRECEDING AND CURRENT ROW)")
+	c.Assert(expression.ContainWindowFunc(expression.NewBinaryOperation(opcode.Plus, w, windowSalary)), IsTrue)
+	_, err := w.Eval(nil, map[interface{}]interface{}{})
+	c.Assert(err, NotNil)
+}
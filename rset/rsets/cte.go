@@ -0,0 +1,128 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rsets
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/plan/plans"
+	"github.com/pingcap/tidb/stmt"
+)
+
+var (
+	_ plan.Planner = (*CTE)(nil)
+)
+
+// CTE is a common table expression of a WITH clause, it is shared by all the
+// table sources referencing it.
+type CTE struct {
+	Name     string
+	ColNames []string
+	// Query is the query of a non-recursive common table expression, it is
+	// planned for each reference like a derived table.
+	Query stmt.Statement
+	// Seed and Recursive are the non-recursive and the recursive query blocks of
+	// a recursive common table expression, Distinct is true if they are combined
+	// by UNION DISTINCT.
+	Seed      stmt.Statement
+	Recursive stmt.Statement
+	Distinct  bool
+}
+
+func (c *CTE) String() string {
+	return c.Name
+}
+
+type cteKeyType int
+
+func (k cteKeyType) String() string {
+	return "cte-key"
+}
+
+// cteKey is the key of the recursive common table expressions whose recursive
+// parts are being planned in the context.
+const cteKey cteKeyType = 0
+
+// cteWorkTable is the rows of the last iteration of a recursive common table
+// expression, which are read by the reference to it in its recursive part.
+type cteWorkTable struct {
+	plan *plans.CTEWorkTablePlan
+	used bool
+}
+
+func planStatement(ctx context.Context, s stmt.Statement) (plan.Plan, error) {
+	planner, ok := s.(plan.Planner)
+	if !ok {
+		return nil, errors.Errorf("invalid statement %T, no Plan interface", s)
+	}
+	p, err := planner.Plan(ctx)
+	return p, errors.Trace(err)
+}
+
+// Plan gets the plan of the common table expression. The reference in the
+// recursive part of a recursive common table expression gets the plan reading
+// the rows of the last iteration.
+func (c *CTE) Plan(ctx context.Context) (plan.Plan, error) {
+	if c.Recursive == nil {
+		p, err := planStatement(ctx, c.Query)
+		return p, errors.Trace(err)
+	}
+
+	works, _ := ctx.Value(cteKey).(map[*CTE]*cteWorkTable)
+	if w, ok := works[c]; ok {
+		if w.used {
+			return nil, errors.Errorf("In recursive query block of Recursive Common Table Expression '%s', the recursive table must be referenced only once, and not in any subquery", c.Name)
+		}
+		w.used = true
+		return w.plan, nil
+	}
+
+	seed, err := planStatement(ctx, c.Seed)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	fields := make([]*field.ResultField, len(seed.GetFields()))
+	for i, f := range seed.GetFields() {
+		nf := f.Clone()
+		nf.OrgTableName = ""
+		nf.TableName = ""
+		fields[i] = nf
+	}
+	w := &cteWorkTable{plan: &plans.CTEWorkTablePlan{Name: c.Name, Fields: fields}}
+	if works == nil {
+		works = map[*CTE]*cteWorkTable{}
+		ctx.SetValue(cteKey, works)
+		defer ctx.ClearValue(cteKey)
+	}
+	works[c] = w
+	defer delete(works, c)
+
+	recursive, err := planStatement(ctx, c.Recursive)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !w.used {
+		return nil, errors.Errorf("Recursive Common Table Expression '%s' should have one or more non-recursive query blocks followed by one or more recursive ones", c.Name)
+	}
+	return &plans.RecursiveCTEPlan{
+		Name:      c.Name,
+		Seed:      seed,
+		Recursive: recursive,
+		WorkTable: w.plan,
+		Distinct:  c.Distinct,
+		Fields:    fields,
+	}, nil
+}
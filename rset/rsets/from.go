@@ -39,13 +39,18 @@ var (
 
 // TableRset is record set to select table, like `select c from t as at`.
 type TableRset struct {
-	Schema string
-	Name   string
+	Schema     string
+	Name       string
+	IndexHints []*plans.IndexHint
 }
 
 // Plan gets InfoSchemaPlan/TableDefaultPlan.
 func (r *TableRset) Plan(ctx context.Context) (plan.Plan, error) {
 	if strings.EqualFold(r.Schema, infoschema.Name) {
+		// The tables of information schema have no index.
+		if err := plans.CheckIndexHints(r.Name, nil, r.IndexHints); err != nil {
+			return nil, errors.Trace(err)
+		}
 		return plans.NewInfoSchemaPlan(r.Name)
 	}
 	is := sessionctx.GetDomain(ctx).InfoSchema()
@@ -53,7 +58,13 @@ func (r *TableRset) Plan(ctx context.Context) (plan.Plan, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	tdp := &plans.TableDefaultPlan{T: t}
+	if err = checkViewPrivilege(ctx, r.Schema, t.Meta()); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err = plans.CheckIndexHints(t.TableName().O, t.Indices(), r.IndexHints); err != nil {
+		return nil, errors.Trace(err)
+	}
+	tdp := &plans.TableDefaultPlan{T: t, IndexHints: r.IndexHints}
 	tdp.Fields = make([]*field.ResultField, 0, len(t.Cols()))
 	for _, col := range t.Cols() {
 		f := field.ColToResultField(col, t.TableName().O)
@@ -82,19 +93,30 @@ func newTableRset(currentSchema, name string) (*TableRset, error) {
 
 // TableSource is table source or sub select.
 type TableSource struct {
-	// Source is table.Ident or stmt.Statement.
+	// Source is table.Ident, *CTE or stmt.Statement.
 	Source interface{}
 	// Table source name.
 	Name string
+	// IndexHints are the index hints of a table.
+	IndexHints []*plans.IndexHint
 }
 
 func (t *TableSource) String() string {
 	switch x := t.Source.(type) {
 	case table.Ident:
+		s := x.String()
+		if len(t.Name) != 0 {
+			s = fmt.Sprintf("%s AS %s", s, t.Name)
+		}
+		for _, h := range t.IndexHints {
+			s = fmt.Sprintf("%s %s", s, h)
+		}
+		return s
+	case *CTE:
 		if len(t.Name) == 0 {
-			return x.String()
+			return x.Name
 		}
-		return fmt.Sprintf("%s AS %s", x, t.Name)
+		return fmt.Sprintf("%s AS %s", x.Name, t.Name)
 	case stmt.Statement:
 		if len(t.Name) == 0 {
 			return fmt.Sprintf("(%s)", x)
@@ -115,6 +115,9 @@ func (s *testTableRsetSuite) TestTableSourceString(c *C) {
 	str := ts.String()
 	c.Assert(len(str), Greater, 0)
 
+	ts.IndexHints = []*plans.IndexHint{{Type: plans.IgnoreIndex, Names: []model.CIStr{model.NewCIStr("i1"), model.NewCIStr("i2")}}}
+	c.Assert(ts.String(), Matches, `.* AS .* IGNORE INDEX \(i1, i2\)`)
+
 	store := newStore(c)
 	se := newSession(c, store, s.dbName)
 	ctx, ok := se.(context.Context)
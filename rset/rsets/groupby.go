@@ -145,7 +145,13 @@ func (r *GroupByRset) Plan(ctx context.Context) (plan.Plan, error) {
 		r.By[i] = by
 	}
 
-	return &plans.GroupByDefaultPlan{By: r.By, Src: r.Src,
+	src := r.Src
+	if len(r.By) == 0 {
+		// MIN and MAX of an indexed column need only the index endpoints.
+		src, _ = plans.UseIndexEndpoints(src, r.SelectList)
+	}
+
+	return &plans.GroupByDefaultPlan{By: r.By, Src: src,
 		SelectList: r.SelectList}, nil
 }
 
@@ -67,6 +67,8 @@ type JoinRset struct {
 	Right interface{}
 	Type  JoinType
 	On    expression.Expression
+	// Modify is true if the rows are updated or deleted, the tables can't be views then.
+	Modify bool
 
 	tableNames map[string]struct{}
 }
@@ -202,9 +204,11 @@ func (r *JoinRset) checkTableDuplicate(t *TableSource, tr *TableRset) error {
 
 func (r *JoinRset) buildSourcePlan(ctx context.Context, t *TableSource) (plan.Plan, []*field.ResultField, error) {
 	var (
-		src interface{}
-		tr  *TableRset
-		err error
+		src  interface{}
+		tr   *TableRset
+		view *ViewRset
+		cte  *CTE
+		err  error
 	)
 	switch s := t.Source.(type) {
 	case table.Ident:
@@ -213,10 +217,34 @@ func (r *JoinRset) buildSourcePlan(ctx context.Context, t *TableSource) (plan.Pl
 		if err != nil {
 			return nil, nil, errors.Trace(err)
 		}
+		tr.IndexHints = t.IndexHints
 		src = tr
 		if err := r.checkTableDuplicate(t, tr); err != nil {
 			return nil, nil, errors.Trace(err)
 		}
+		if view, err = findView(ctx, tr); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if view != nil {
+			if r.Modify {
+				return nil, nil, errors.Errorf("The target table %s of the UPDATE or DELETE is not updatable", tr.Name)
+			}
+			src = view
+		}
+	case *CTE:
+		name := t.Name
+		if len(name) == 0 {
+			name = s.Name
+		}
+		if _, ok := r.tableNames[name]; ok {
+			return nil, nil, errors.Errorf("%s: duplicate name %s", r, name)
+		}
+		r.tableNames[name] = struct{}{}
+		if r.Modify {
+			return nil, nil, errors.Errorf("The target table %s of the UPDATE or DELETE is not updatable", s.Name)
+		}
+		cte = s
+		src = s
 	case stmt.Statement:
 		src = s
 	default:
@@ -235,10 +263,26 @@ func (r *JoinRset) buildSourcePlan(ctx context.Context, t *TableSource) (plan.Pl
 		return nil, nil, errors.Errorf("invalid table source %T, no Plan interface", t.Source)
 	}
 
+	if cte != nil && len(cte.ColNames) > 0 && len(cte.ColNames) != len(p.GetFields()) {
+		return nil, nil, errors.New("In definition of view, derived table or common table expression, SELECT list and column names list have different column counts")
+	}
 	var fields []*field.ResultField
 	dupNames := make(map[string]struct{}, len(p.GetFields()))
-	for _, nf := range p.GetFields() {
+	for i, nf := range p.GetFields() {
 		f := nf.Clone()
+		if view != nil {
+			// The fields of a view are named by the view columns.
+			f.Name = view.View.Columns[i].Name.O
+			f.TableName = view.View.Name.O
+			f.DBName = view.Schema
+		}
+		if cte != nil {
+			if len(cte.ColNames) > 0 {
+				f.Name = cte.ColNames[i]
+			}
+			f.TableName = cte.Name
+			f.DBName = ""
+		}
 		if t.Name != "" {
 			f.TableName = t.Name
 		}
@@ -81,6 +81,16 @@ func (r *OrderByRset) CheckAggregate(selectList *plans.SelectList) error {
 	return nil
 }
 
+// CheckWindow will check whether order by has window function or not,
+// if has, we will add it to select list hidden field.
+func (r *OrderByRset) CheckWindow(selectList *plans.SelectList) {
+	for i, v := range r.By {
+		if expression.ContainWindowFunc(v.Expr) {
+			r.By[i].Expr = selectList.UpdateWindowFields(v.Expr)
+		}
+	}
+}
+
 type orderByVisitor struct {
 	expression.BaseVisitor
 	selectList *plans.SelectList
@@ -173,6 +183,7 @@ func (v *orderByVisitor) VisitPosition(p *expression.Position) (expression.Expre
 
 // Plan get SrcPlan/OrderByDefaultPlan.
 // If src is NullPlan or order by fields are empty, then gets SrcPlan.
+// If src can read the rows in order from an index, then gets SrcPlan too.
 // Default gets OrderByDefaultPlan.
 func (r *OrderByRset) Plan(ctx context.Context) (plan.Plan, error) {
 	if _, ok := r.Src.(*plans.NullPlan); ok {
@@ -217,6 +228,11 @@ func (r *OrderByRset) Plan(ctx context.Context) (plan.Plan, error) {
 		return r.Src, nil
 	}
 
+	// if the rows can be read in order from an index, no need to sort them.
+	if plans.UseIndexOrder(r.Src, by, ascs, r.SelectList) {
+		return r.Src, nil
+	}
+
 	return &plans.OrderByDefaultPlan{By: by, Ascs: ascs, Src: r.Src,
 		SelectList: r.SelectList}, nil
 }
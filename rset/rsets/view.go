@@ -0,0 +1,221 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rsets
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/plan/plans"
+	"github.com/pingcap/tidb/privilege/privileges"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/stmt"
+)
+
+var (
+	_ plan.Planner = (*ViewRset)(nil)
+)
+
+// CompileView compiles the SELECT statement src of a view in schema, the tables
+// without schema in it are the ones in schema. It is set by package tidb, which
+// has the parser and the compiler.
+var CompileView func(ctx context.Context, schema, src string) (stmt.Statement, error)
+
+// ViewRset is record set to select a view, the SELECT statement of the view is
+// planned as a derived table.
+type ViewRset struct {
+	Schema string
+	View   *model.TableInfo
+}
+
+type viewKeyType int
+
+func (k viewKeyType) String() string {
+	return "view-key"
+}
+
+// viewKey is the key of the innermost view being planned in the context.
+const viewKey viewKeyType = 0
+
+// expandingView is a view being planned, the views are linked from the innermost
+// one to the outermost one.
+type expandingView struct {
+	name    string
+	definer string
+	outer   *expandingView
+}
+
+type viewCacheKeyType int
+
+func (k viewCacheKeyType) String() string {
+	return "view-cache-key"
+}
+
+// viewCacheKey is the key of the compiled views of the session in the context.
+const viewCacheKey viewCacheKeyType = 0
+
+// compiledView is the SELECT statement of a view compiled in a schema version.
+type compiledView struct {
+	version int64
+	planner plan.Planner
+}
+
+func getExpandingView(ctx context.Context) *expandingView {
+	v, _ := ctx.Value(viewKey).(*expandingView)
+	return v
+}
+
+// findView returns the view of the table source tr, it returns nil if tr is not a view.
+func findView(ctx context.Context, tr *TableRset) (*ViewRset, error) {
+	if strings.EqualFold(tr.Schema, infoschema.Name) {
+		return nil, nil
+	}
+	is := sessionctx.GetDomain(ctx).InfoSchema()
+	t, err := is.TableByName(model.NewCIStr(tr.Schema), model.NewCIStr(tr.Name))
+	if err != nil || !t.Meta().IsView() {
+		// The error is returned when the table is planned.
+		return nil, nil
+	}
+	if len(tr.IndexHints) > 0 {
+		return nil, errors.Errorf("Incorrect usage of index hints and view '%s'", t.Meta().Name.O)
+	}
+	return &ViewRset{Schema: tr.Schema, View: t.Meta()}, nil
+}
+
+// Plan gets the plan of the SELECT statement of the view. The tables in it are
+// read with the privileges of the view definer.
+func (r *ViewRset) Plan(ctx context.Context) (plan.Plan, error) {
+	name := strings.ToLower(r.Schema + "." + r.View.Name.O)
+	outer := getExpandingView(ctx)
+	for v := outer; v != nil; v = v.outer {
+		if v.name == name {
+			return nil, errors.Errorf("View '%s.%s' contains view recursion", r.Schema, r.View.Name.O)
+		}
+	}
+	planner, err := r.compile(ctx, name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	ctx.SetValue(viewKey, &expandingView{name: name, definer: r.View.View.Definer, outer: outer})
+	defer func() {
+		if outer == nil {
+			ctx.ClearValue(viewKey)
+		} else {
+			ctx.SetValue(viewKey, outer)
+		}
+	}()
+	p, err := planner.Plan(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return r.pickFields(p)
+}
+
+// compile compiles the SELECT statement of the view, the compiled statement
+// is cached in the session until the schema changes.
+func (r *ViewRset) compile(ctx context.Context, name string) (plan.Planner, error) {
+	if CompileView == nil {
+		return nil, errors.New("view is not supported")
+	}
+	version := sessionctx.GetDomain(ctx).InfoSchema().SchemaMetaVersion()
+	cache, _ := ctx.Value(viewCacheKey).(map[string]*compiledView)
+	if cv, ok := cache[name]; ok && cv.version == version {
+		return cv.planner, nil
+	}
+
+	s, err := CompileView(ctx, r.Schema, r.View.View.Select)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	planner, ok := s.(plan.Planner)
+	if !ok {
+		return nil, errors.Errorf("invalid view %s.%s", r.Schema, r.View.Name.O)
+	}
+	if cache == nil {
+		cache = make(map[string]*compiledView)
+		ctx.SetValue(viewCacheKey, cache)
+	}
+	cache[name] = &compiledView{version: version, planner: planner}
+	return planner, nil
+}
+
+// pickFields picks the fields of the view from the fields of plan p, the
+// fields that a wildcard gets from the columns added to the tables after
+// the view is created are skipped.
+func (r *ViewRset) pickFields(p plan.Plan) (plan.Plan, error) {
+	fields := p.GetFields()
+	viewFields := r.View.View.Fields
+	if len(viewFields) == 0 {
+		// The fields are not stored in the views created by the old versions.
+		if len(fields) != len(r.View.Columns) {
+			return nil, errors.Errorf("View '%s.%s' references invalid table(s) or column(s)", r.Schema, r.View.Name.O)
+		}
+		return p, nil
+	}
+
+	offsets := make([]int, 0, len(viewFields))
+	for i, f := range fields {
+		if len(offsets) == len(viewFields) {
+			break
+		}
+		vf := viewFields[len(offsets)]
+		if strings.EqualFold(f.TableName, vf.Table.O) && strings.EqualFold(f.Name, vf.Name.O) {
+			offsets = append(offsets, i)
+		}
+	}
+	if len(offsets) != len(viewFields) || len(offsets) != len(r.View.Columns) {
+		return nil, errors.Errorf("View '%s.%s' references invalid table(s) or column(s)", r.Schema, r.View.Name.O)
+	}
+	if len(offsets) == len(fields) {
+		return p, nil
+	}
+	picked := make([]*field.ResultField, len(offsets))
+	for i, offset := range offsets {
+		picked[i] = fields[offset]
+	}
+	return &plans.ViewFieldsPlan{Src: p, Offsets: offsets, Fields: picked}, nil
+}
+
+// checkViewPrivilege checks whether the definer of the view being planned has
+// the SELECT privilege on the table t in schema.
+func checkViewPrivilege(ctx context.Context, schema string, t *model.TableInfo) error {
+	v := getExpandingView(ctx)
+	if v == nil || len(v.definer) == 0 {
+		return nil
+	}
+	is := sessionctx.GetDomain(ctx).InfoSchema()
+	db, ok := is.SchemaByName(model.NewCIStr(schema))
+	if !ok {
+		return errors.Errorf("Unknown database '%s'", schema)
+	}
+	// The privileges are loaded with SQL, whose tables are not in the view.
+	ctx.ClearValue(viewKey)
+	defer ctx.SetValue(viewKey, v)
+	checker := &privileges.UserPrivileges{User: v.definer}
+	hasPriv, err := checker.Check(ctx, db, t, mysql.SelectPriv)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !hasPriv {
+		return errors.Errorf("SELECT command denied to user '%s' for table '%s'", v.definer, t.Name.O)
+	}
+	return nil
+}
@@ -66,30 +66,18 @@ func (r *WhereRset) planBinOp(ctx context.Context, x *expression.BinaryOperation
 			f(b.R)
 		}
 		f(x)
-		out := []expression.Expression{}
-		p := r.Src
-		isNewPlan := false
-		for _, e := range in {
-			p2, filtered, err = p.Filter(ctx, e)
-			if err != nil {
-				return nil, err
-			}
-
-			if !filtered {
-				out = append(out, e)
-				continue
-			}
-
-			p = p2
-			isNewPlan = true
+		var out []expression.Expression
+		p2, out, err = plans.ChooseAccessPath(ctx, p, in)
+		if err != nil {
+			return nil, err
 		}
 
-		if !isNewPlan {
+		if p2 == p {
 			break
 		}
 
 		if len(out) == 0 {
-			return p, nil
+			return p2, nil
 		}
 
 		for len(out) > 1 {
@@ -100,7 +88,7 @@ func (r *WhereRset) planBinOp(ctx context.Context, x *expression.BinaryOperation
 			out[n-2] = e
 		}
 
-		return &plans.FilterDefaultPlan{Plan: p, Expr: out[0]}, nil
+		return &plans.FilterDefaultPlan{Plan: p2, Expr: out[0]}, nil
 	default:
 		// TODO: better plan for `OR`.
 		log.Warn("TODO: better plan for", x.Op)
@@ -143,6 +131,20 @@ func (r *WhereRset) planIsNull(ctx context.Context, x *expression.IsNull) (plan.
 	return &plans.FilterDefaultPlan{Plan: p, Expr: x}, nil
 }
 
+func (r *WhereRset) planPatternIn(ctx context.Context, x *expression.PatternIn) (plan.Plan, error) {
+	p := r.Src
+	p2, filtered, err := p.Filter(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+
+	if filtered {
+		return p2, nil
+	}
+
+	return &plans.FilterDefaultPlan{Plan: p, Expr: x}, nil
+}
+
 func (r *WhereRset) planUnaryOp(ctx context.Context, x *expression.UnaryOperation) (plan.Plan, error) {
 	p := r.Src
 	p2, filtered, err := p.Filter(ctx, x)
@@ -204,6 +206,12 @@ func (r *WhereRset) Plan(ctx context.Context) (plan.Plan, error) {
 		return r.planStatic(ctx, expr)
 	}
 
+	// Push the conditions down into the joins, they are planned along with the
+	// ON conditions of the joins.
+	if p, ok, err := plans.OptimizeJoin(ctx, r.Src, expr); err != nil || ok {
+		return p, errors.Trace(err)
+	}
+
 	var (
 		src = r.Src
 		err error
@@ -217,8 +225,7 @@ func (r *WhereRset) Plan(ctx context.Context) (plan.Plan, error) {
 	case *expression.IsNull:
 		src, err = r.planIsNull(ctx, x)
 	case *expression.PatternIn:
-		// TODO: optimize
-		// TODO: show plan
+		src, err = r.planPatternIn(ctx, x)
 	case *expression.PatternLike:
 		// TODO: optimize
 	case *expression.PatternRegexp:
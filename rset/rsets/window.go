@@ -0,0 +1,154 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rsets
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/field"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/plan/plans"
+)
+
+var (
+	_ plan.Planner = (*WindowRset)(nil)
+)
+
+// WindowRset is record set for the select fields with window functions.
+type WindowRset struct {
+	Src        plan.Plan
+	SelectList *plans.SelectList
+	// Exprs is the select fields with window functions, indexed by field offset.
+	Exprs map[int]expression.Expression
+	Funcs []*expression.WindowFunc
+}
+
+// NewWindowRset moves the select fields with window functions out of select list,
+// the values they depend on are added to select list hidden fields, so that they
+// are evaluated before the window functions. It returns nil if there is no
+// window function in select list.
+func NewWindowRset(selectList *plans.SelectList) (*WindowRset, error) {
+	visitor := &windowFieldsVisitor{selectList: selectList}
+	visitor.BaseVisitor.V = visitor
+	r := &WindowRset{SelectList: selectList, Exprs: map[int]expression.Expression{}}
+	for i, n := 0, len(selectList.Fields); i < n; i++ {
+		f := selectList.Fields[i]
+		if !expression.ContainWindowFunc(f.Expr) {
+			continue
+		}
+		e, err := f.Expr.Clone().Accept(visitor)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		r.Exprs[i] = e
+		// The field value is set by WindowPlan.
+		selectList.Fields[i] = &field.Field{Expr: expression.Value{}, AsName: f.AsName}
+		delete(selectList.AggFields, i)
+	}
+	if len(r.Exprs) == 0 {
+		return nil, nil
+	}
+	r.Funcs = visitor.funcs
+	return r, nil
+}
+
+// Plan gets WindowPlan.
+func (r *WindowRset) Plan(ctx context.Context) (plan.Plan, error) {
+	return &plans.WindowPlan{
+		SelectList: r.SelectList,
+		Src:        r.Src,
+		Exprs:      r.Exprs,
+		Funcs:      r.Funcs,
+	}, nil
+}
+
+// windowFieldsVisitor replaces the identifiers, aggregate functions and subqueries
+// in the select fields with window functions and the arguments of the window
+// functions with the positions of the hidden fields.
+type windowFieldsVisitor struct {
+	expression.BaseVisitor
+	selectList *plans.SelectList
+	funcs      []*expression.WindowFunc
+}
+
+func (v *windowFieldsVisitor) hide(e expression.Expression) (expression.Expression, error) {
+	if e.IsStatic() {
+		return e, nil
+	}
+	var pos *expression.Position
+	if expression.ContainAggregateFunc(e) {
+		expr, err := v.selectList.UpdateAggFields(e)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pos = expr.(*expression.Position)
+	} else {
+		v.selectList.AddField(&field.Field{Expr: e}, nil)
+		pos = &expression.Position{N: len(v.selectList.Fields), Name: e.String()}
+	}
+	if pos.N <= v.selectList.HiddenFieldOffset {
+		return pos, nil
+	}
+
+	// The hidden fields are not resolved with the select fields, resolve the
+	// identifiers here.
+	f := v.selectList.Fields[pos.N-1]
+	expr, err := f.Expr.Accept(NewFromIdentVisitor(v.selectList.FromFields, FieldListClause))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	f.Expr = expr
+	return pos, nil
+}
+
+func (v *windowFieldsVisitor) VisitIdent(i *expression.Ident) (expression.Expression, error) {
+	return v.hide(i)
+}
+
+func (v *windowFieldsVisitor) VisitSubQuery(sq expression.SubQuery) (expression.Expression, error) {
+	return v.hide(sq)
+}
+
+func (v *windowFieldsVisitor) VisitCall(c *expression.Call) (expression.Expression, error) {
+	if expression.IsAggregateFunc(c.F) {
+		return v.hide(c)
+	}
+	return v.BaseVisitor.VisitCall(c)
+}
+
+func (v *windowFieldsVisitor) VisitWindowFunc(w *expression.WindowFunc) (expression.Expression, error) {
+	var exprs []*expression.Expression
+	for i := range w.Args {
+		exprs = append(exprs, &w.Args[i])
+	}
+	for i := range w.PartitionBy {
+		exprs = append(exprs, &w.PartitionBy[i])
+	}
+	for _, item := range w.OrderBy {
+		exprs = append(exprs, &item.Expr)
+	}
+	for _, e := range exprs {
+		if expression.ContainWindowFunc(*e) {
+			return nil, errors.Errorf("You cannot use the window function '%s' in this context.", w.F)
+		}
+		expr, err := v.hide(*e)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		*e = expr
+	}
+	v.funcs = append(v.funcs, w)
+	return w, nil
+}
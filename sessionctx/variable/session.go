@@ -14,7 +14,10 @@
 package variable
 
 import (
+	"time"
+
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
 )
 
 // SessionVars is to handle user-defined or global variables in current session.
@@ -43,6 +46,11 @@ type SessionVars struct {
 
 	// Current user
 	User string
+
+	// The location of the time_zone system variable, it is parsed again
+	// only if the variable is changed.
+	timeZoneName string
+	timeZone     *time.Location
 }
 
 // sessionVarsKeyType is a dummy type to avoid naming collision in context.
@@ -118,3 +126,29 @@ func (s *SessionVars) GetNextPreparedStmtID() uint32 {
 func (s *SessionVars) SetCurrentUser(user string) {
 	s.User = user
 }
+
+// GetTimeZone gets the location of the time_zone system variable of the session,
+// it is time.Local if there is no session vars in the context.
+func GetTimeZone(ctx context.Context) *time.Location {
+	if ctx == nil {
+		return time.Local
+	}
+	s := GetSessionVars(ctx)
+	if s == nil {
+		return time.Local
+	}
+
+	name := s.Systems[TimeZone]
+	if name == "" {
+		name = GetSysVar(TimeZone).Value
+	}
+	if s.timeZone == nil || s.timeZoneName != name {
+		loc, err := mysql.ParseTimeZone(name)
+		if err != nil {
+			// The value is checked when it is set, so this should not happen.
+			loc = time.Local
+		}
+		s.timeZoneName, s.timeZone = name, loc
+	}
+	return s.timeZone
+}
@@ -14,6 +14,8 @@
 package variable_test
 
 import (
+	"time"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/mock"
@@ -48,3 +50,24 @@ func (*testSessionSuite) TestSession(c *C) {
 	v.SetLastInsertID(uint64(1))
 	c.Assert(v.LastInsertID, Equals, uint64(1))
 }
+
+func (*testSessionSuite) TestTimeZone(c *C) {
+	c.Assert(variable.GetTimeZone(nil), Equals, time.Local)
+
+	ctx := mock.NewContext()
+	c.Assert(variable.GetTimeZone(ctx), Equals, time.Local)
+
+	variable.BindSessionVars(ctx)
+	v := variable.GetSessionVars(ctx)
+	c.Assert(variable.GetTimeZone(ctx), Equals, time.Local)
+
+	v.Systems[variable.TimeZone] = "+08:00"
+	loc := variable.GetTimeZone(ctx)
+	_, offset := time.Date(2015, 1, 1, 0, 0, 0, 0, loc).Zone()
+	c.Assert(offset, Equals, 8*60*60)
+	// The location is cached until the variable is changed.
+	c.Assert(variable.GetTimeZone(ctx), Equals, loc)
+
+	v.Systems[variable.TimeZone] = "UTC"
+	c.Assert(variable.GetTimeZone(ctx).String(), Equals, "UTC")
+}
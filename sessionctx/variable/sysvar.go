@@ -152,7 +152,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal, "rpl_semi_sync_master_trace_level", ""},
 	{ScopeGlobal | ScopeSession, "max_insert_delayed_threads", "20"},
 	{ScopeNone, "performance_schema_session_connect_attrs_size", "512"},
-	{ScopeGlobal | ScopeSession, "time_zone", "SYSTEM"},
+	{ScopeGlobal | ScopeSession, TimeZone, "SYSTEM"},
 	{ScopeGlobal, "innodb_max_dirty_pages_pct", "75"},
 	{ScopeGlobal, "innodb_file_per_table", "ON"},
 	{ScopeGlobal, "innodb_log_compressed_pages", "ON"},
@@ -560,6 +560,9 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, "min_examined_row_limit", "0"},
 	{ScopeGlobal, "sync_frm", "ON"},
 	{ScopeGlobal, "innodb_online_alter_log_max_size", "134217728"},
+	{ScopeGlobal | ScopeSession, TiDBMemQuota, "67108864"},
+	{ScopeGlobal | ScopeSession, TiDBPlanCacheSize, "100"},
+	{ScopeGlobal | ScopeSession, CTEMaxRecursionDepth, "1000"},
 }
 
 // SetNamesVariables is the system variable names related to set names statements.
@@ -572,6 +575,19 @@ var SetNamesVariables = []string{
 const (
 	// CollationConnection is the name for collation_connection system variable.
 	CollationConnection = "collation_connection"
+	// TiDBMemQuota is the name for tidb_mem_quota system variable, it is the max memory in bytes
+	// used by the sorting, grouping, distinct and join operators of a session, an operator
+	// spills its rows to disk when it is exceeded.
+	TiDBMemQuota = "tidb_mem_quota"
+	// TiDBPlanCacheSize is the name for tidb_plan_cache_size system variable, it is the max
+	// number of plans of prepared statements cached in a session, 0 disables the cache.
+	TiDBPlanCacheSize = "tidb_plan_cache_size"
+	// CTEMaxRecursionDepth is the name for cte_max_recursion_depth system variable, it is the
+	// max number of iterations of a recursive common table expression.
+	CTEMaxRecursionDepth = "cte_max_recursion_depth"
+	// TimeZone is the name for time_zone system variable, it is the time zone of the
+	// session used by TIMESTAMP columns and the current time functions.
+	TimeZone = "time_zone"
 )
 
 // GlobalVarAccessor is the interface for accessing global scope system and status variables.
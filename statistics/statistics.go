@@ -0,0 +1,398 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package statistics builds, stores and uses the statistics of table data
+// for cost estimation.
+package statistics
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/column"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/codec"
+)
+
+const (
+	// DefaultBucketCount is the max number of buckets in a histogram.
+	DefaultBucketCount = 64
+	// DefaultSampleSize is the max number of rows sampled to build the histograms.
+	DefaultSampleSize = 10000
+)
+
+// Bucket is a bucket of an equal depth histogram.
+type Bucket struct {
+	// Count is the number of values less than or equal to Value,
+	// it is accumulated from the first bucket.
+	Count int64 `json:"count"`
+	// Value is the upper bound of the bucket, encoded by codec.EncodeKey.
+	Value []byte `json:"value"`
+	// Repeats is the number of values equal to Value.
+	Repeats int64 `json:"repeats"`
+}
+
+// Column is the statistics of a column or an index.
+type Column struct {
+	// ID is the column ID, it is always 0 for an index.
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	NDV       int64    `json:"ndv"`
+	NullCount int64    `json:"null_count"`
+	Buckets   []Bucket `json:"buckets"`
+}
+
+// Table is the statistics of a table.
+type Table struct {
+	TableID int64     `json:"table_id"`
+	Count   int64     `json:"count"`
+	Columns []*Column `json:"columns"`
+	Indices []*Column `json:"indices"`
+	// Version is the statistics version of the table when it is loaded.
+	Version int64 `json:"-"`
+}
+
+// Column returns the statistics of the column with name, or nil if not found.
+func (t *Table) Column(name string) *Column {
+	return findColumn(t.Columns, name)
+}
+
+// Index returns the statistics of the index with name, or nil if not found.
+func (t *Table) Index(name string) *Column {
+	return findColumn(t.Indices, name)
+}
+
+func findColumn(cols []*Column, name string) *Column {
+	for _, c := range cols {
+		if strings.EqualFold(c.Name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// NotNullCount returns the number of not null values in the histogram.
+func (c *Column) NotNullCount() float64 {
+	if len(c.Buckets) == 0 {
+		return 0
+	}
+	return float64(c.Buckets[len(c.Buckets)-1].Count)
+}
+
+// lowerBound returns the index of the first bucket whose upper bound is
+// greater than or equal to value, and whether the upper bound equals to value.
+func (c *Column) lowerBound(value []byte) (int, bool) {
+	idx := sort.Search(len(c.Buckets), func(i int) bool {
+		return bytes.Compare(c.Buckets[i].Value, value) >= 0
+	})
+	return idx, idx < len(c.Buckets) && bytes.Equal(c.Buckets[idx].Value, value)
+}
+
+// EqualRowCount estimates the number of rows equal to value.
+func (c *Column) EqualRowCount(value []byte) float64 {
+	idx, match := c.lowerBound(value)
+	if idx == len(c.Buckets) {
+		return 0
+	}
+	if match {
+		return float64(c.Buckets[idx].Repeats)
+	}
+	if c.NDV == 0 {
+		return 0
+	}
+	return c.NotNullCount() / float64(c.NDV)
+}
+
+// LessRowCount estimates the number of rows less than value.
+func (c *Column) LessRowCount(value []byte) float64 {
+	idx, match := c.lowerBound(value)
+	if idx == len(c.Buckets) {
+		return c.NotNullCount()
+	}
+	var preCount float64
+	if idx > 0 {
+		preCount = float64(c.Buckets[idx-1].Count)
+	}
+	b := c.Buckets[idx]
+	if match {
+		return float64(b.Count - b.Repeats)
+	}
+	// The value falls inside the bucket, assume half of the bucket is less than it.
+	return preCount + (float64(b.Count-b.Repeats)-preCount)/2
+}
+
+// GreaterRowCount estimates the number of rows greater than value.
+func (c *Column) GreaterRowCount(value []byte) float64 {
+	n := c.NotNullCount() - c.LessRowCount(value) - c.EqualRowCount(value)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// BetweenRowCount estimates the number of rows in range [a, b).
+func (c *Column) BetweenRowCount(a, b []byte) float64 {
+	n := c.LessRowCount(b) - c.LessRowCount(a)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// builder collects the values of a column or an index.
+type builder struct {
+	col      *Column
+	hashes   map[uint64]struct{}
+	samples  [][]byte
+	notNulls int64
+}
+
+func newBuilder(id int64, name string) *builder {
+	return &builder{
+		col:    &Column{ID: id, Name: name},
+		hashes: make(map[uint64]struct{}),
+	}
+}
+
+// collect counts the null and distinct values.
+func (b *builder) collect(isNull bool, value []byte) {
+	if isNull {
+		b.col.NullCount++
+		return
+	}
+	b.notNulls++
+	h := fnv.New64a()
+	h.Write(value)
+	b.hashes[h.Sum64()] = struct{}{}
+}
+
+// build builds the histogram of the column from sampled values, the bucket
+// counts are scaled up from the sample to all the not null values.
+func (b *builder) build(bucketCount int) *Column {
+	col := b.col
+	col.NDV = int64(len(b.hashes))
+	var values [][]byte
+	for _, v := range b.samples {
+		if v != nil {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return col
+	}
+	sort.Sort(byteSlices(values))
+
+	depth := (len(values) + bucketCount - 1) / bucketCount
+	var preCount int64
+	for i, v := range values {
+		n := int64(i + 1)
+		if len(col.Buckets) > 0 {
+			last := &col.Buckets[len(col.Buckets)-1]
+			if bytes.Equal(last.Value, v) {
+				last.Count = n
+				last.Repeats++
+				continue
+			}
+			if last.Count-preCount < int64(depth) {
+				last.Count = n
+				last.Value = v
+				last.Repeats = 1
+				continue
+			}
+			preCount = last.Count
+		}
+		col.Buckets = append(col.Buckets, Bucket{Count: n, Value: v, Repeats: 1})
+	}
+
+	scale := float64(b.notNulls) / float64(len(values))
+	for i := range col.Buckets {
+		bkt := &col.Buckets[i]
+		bkt.Count = int64(float64(bkt.Count)*scale + 0.5)
+		bkt.Repeats = int64(float64(bkt.Repeats)*scale + 0.5)
+	}
+	return col
+}
+
+type byteSlices [][]byte
+
+func (s byteSlices) Len() int           { return len(s) }
+func (s byteSlices) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byteSlices) Less(i, j int) bool { return bytes.Compare(s[i], s[j]) < 0 }
+
+// Build scans all rows of table t, counts the distinct and null values of each
+// column and index, and builds their histograms from a random sample of rows.
+func Build(ctx context.Context, t table.Table) (*Table, error) {
+	return build(ctx, t, DefaultSampleSize, DefaultBucketCount)
+}
+
+func build(ctx context.Context, t table.Table, sampleSize int, bucketCount int) (*Table, error) {
+	cols := t.Cols()
+	indices := t.Indices()
+	colBuilders := make([]*builder, len(cols))
+	for i, col := range cols {
+		colBuilders[i] = newBuilder(col.ID, col.Name.O)
+	}
+	idxBuilders := make([]*builder, len(indices))
+	for i, idx := range indices {
+		idxBuilders[i] = newBuilder(0, idx.Name.O)
+	}
+
+	// Reservoir sampling, keeps each row with the same probability.
+	rnd := rand.New(rand.NewSource(t.TableID()))
+	var count int64
+	err := t.IterRecords(ctx, t.FirstKey(), cols, func(h int64, data []interface{}, cols []*column.Col) (bool, error) {
+		count++
+		slot := -1
+		if count <= int64(sampleSize) {
+			slot = int(count - 1)
+		} else if r := rnd.Int63n(count); r < int64(sampleSize) {
+			slot = int(r)
+		}
+
+		for i, v := range data {
+			value, err := codec.EncodeKey(v)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			colBuilders[i].collect(v == nil, value)
+			colBuilders[i].sample(slot, v == nil, value)
+		}
+		for i, idx := range indices {
+			vals, err := idx.FetchValues(data)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			value, err := codec.EncodeKey(vals...)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			isNull := hasNull(vals)
+			idxBuilders[i].collect(isNull, value)
+			idxBuilders[i].sample(slot, isNull, value)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	tbl := &Table{TableID: t.TableID(), Count: count}
+	for _, b := range colBuilders {
+		tbl.Columns = append(tbl.Columns, b.build(bucketCount))
+	}
+	for _, b := range idxBuilders {
+		tbl.Indices = append(tbl.Indices, b.build(bucketCount))
+	}
+	return tbl, nil
+}
+
+// sample keeps the value at the sample slot of the row, slot is -1 if the
+// row is not sampled. A null value is kept as nil.
+func (b *builder) sample(slot int, isNull bool, value []byte) {
+	if slot < 0 {
+		return
+	}
+	if isNull {
+		value = nil
+	}
+	if slot >= len(b.samples) {
+		b.samples = append(b.samples, value)
+		return
+	}
+	b.samples[slot] = value
+}
+
+func hasNull(vals []interface{}) bool {
+	for _, v := range vals {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Save stores the statistics of the table in the transaction.
+func Save(txn kv.Transaction, t *Table) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = meta.NewMeta(txn).SetTableStats(t.TableID, data)
+	return errors.Trace(err)
+}
+
+// Load loads the statistics of the table from the transaction,
+// returns nil if the table has not been analyzed.
+func Load(txn kv.Transaction, tableID int64) (*Table, error) {
+	data, err := meta.NewMeta(txn).GetTableStats(tableID)
+	if err != nil || data == nil {
+		return nil, errors.Trace(err)
+	}
+	t := &Table{}
+	err = json.Unmarshal(data, t)
+	return t, errors.Trace(err)
+}
+
+// Cache caches the decoded statistics of the tables by their statistics versions.
+type Cache struct {
+	mu     sync.Mutex
+	tables map[int64]*Table
+}
+
+// NewCache creates a Cache.
+func NewCache() *Cache {
+	return &Cache{tables: make(map[int64]*Table)}
+}
+
+// Load loads the statistics of the table from the transaction like Load, the
+// cached statistics are returned if the statistics version is not changed.
+func (c *Cache) Load(txn kv.Transaction, tableID int64) (*Table, error) {
+	version, err := meta.NewMeta(txn).GetTableStatsVersion(tableID)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if version == 0 {
+		// The statistics saved by the old versions have no version.
+		t, err := Load(txn, tableID)
+		return t, errors.Trace(err)
+	}
+
+	c.mu.Lock()
+	t, ok := c.tables[tableID]
+	c.mu.Unlock()
+	if ok && t.Version == version {
+		return t, nil
+	}
+	t, err = Load(txn, tableID)
+	if err != nil || t == nil {
+		return nil, errors.Trace(err)
+	}
+	t.Version = version
+
+	c.mu.Lock()
+	// Keep the newer statistics loaded by the transactions started later.
+	if old, ok := c.tables[tableID]; !ok || old.Version < version {
+		c.tables[tableID] = t
+	}
+	c.mu.Unlock()
+	return t, nil
+}
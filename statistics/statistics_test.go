@@ -0,0 +1,194 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/column"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/store/localstore"
+	"github.com/pingcap/tidb/store/localstore/goleveldb"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testStatisticsSuite{})
+
+type testStatisticsSuite struct {
+	store kv.Storage
+	txn   kv.Transaction
+	tbl   table.Table
+	vars  map[string]interface{}
+}
+
+type simpleAllocator struct {
+	id int64
+}
+
+func (s *simpleAllocator) Alloc(tableID int64) (int64, error) {
+	s.id++
+	return s.id, nil
+}
+
+// implement Context interface
+func (s *testStatisticsSuite) GetTxn(forceNew bool) (kv.Transaction, error) { return s.txn, nil }
+
+func (s *testStatisticsSuite) FinishTxn(rollback bool) error { return nil }
+
+func (s *testStatisticsSuite) SetValue(key fmt.Stringer, value interface{}) {
+	s.vars[key.String()] = value
+}
+
+func (s *testStatisticsSuite) Value(key fmt.Stringer) interface{} {
+	return s.vars[key.String()]
+}
+
+func (s *testStatisticsSuite) ClearValue(key fmt.Stringer) {}
+
+func (s *testStatisticsSuite) SetUpSuite(c *C) {
+	driver := localstore.Driver{Driver: goleveldb.MemoryDriver{}}
+	store, err := driver.Open("memory")
+	c.Assert(err, IsNil)
+	s.store = store
+	s.vars = map[string]interface{}{}
+	variable.BindSessionVars(s)
+	s.txn, err = store.Begin()
+	c.Assert(err, IsNil)
+
+	var cols []*column.Col
+	for i, name := range []string{"c1", "c2", "c3"} {
+		cols = append(cols, &column.Col{
+			ColumnInfo: model.ColumnInfo{
+				ID:        int64(i + 1),
+				Name:      model.NewCIStr(name),
+				Offset:    i,
+				FieldType: *types.NewFieldType(mysql.TypeLonglong),
+			},
+		})
+	}
+	s.tbl = tables.NewTable(1, "t", cols, &simpleAllocator{})
+	s.tbl.AddIndex(&column.IndexedCol{
+		IndexInfo: model.IndexInfo{
+			Name:    model.NewCIStr("c2"),
+			Table:   model.NewCIStr("t"),
+			Columns: []*model.IndexColumn{{Name: model.NewCIStr("c2"), Offset: 1}},
+		},
+		X: kv.NewKVIndex("i", "c2", false),
+	})
+
+	// c1 is unique, c2 has 10 distinct values, a quarter of c3 is null.
+	for i := int64(0); i < 100; i++ {
+		var c3 interface{}
+		if i%4 != 0 {
+			c3 = i
+		}
+		_, err = s.tbl.AddRecord(s, []interface{}{i, i % 10, c3})
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *testStatisticsSuite) TearDownSuite(c *C) {
+	s.txn.Rollback()
+	s.store.Close()
+}
+
+func encode(c *C, v interface{}) []byte {
+	b, err := codec.EncodeKey(v)
+	c.Assert(err, IsNil)
+	return b
+}
+
+func (s *testStatisticsSuite) TestBuild(c *C) {
+	tbl, err := statistics.Build(s, s.tbl)
+	c.Assert(err, IsNil)
+	c.Assert(tbl.TableID, Equals, int64(1))
+	c.Assert(tbl.Count, Equals, int64(100))
+	c.Assert(tbl.Columns, HasLen, 3)
+	c.Assert(tbl.Indices, HasLen, 1)
+
+	c1 := tbl.Column("C1")
+	c.Assert(c1.ID, Equals, int64(1))
+	c.Assert(c1.NDV, Equals, int64(100))
+	c.Assert(c1.NullCount, Equals, int64(0))
+	c.Assert(len(c1.Buckets) <= statistics.DefaultBucketCount, IsTrue)
+	c.Assert(c1.EqualRowCount(encode(c, int64(31))), Equals, float64(1))
+	c.Assert(c1.LessRowCount(encode(c, int64(31))), Equals, float64(31))
+	c.Assert(c1.GreaterRowCount(encode(c, int64(31))), Equals, float64(68))
+	c.Assert(c1.BetweenRowCount(encode(c, int64(11)), encode(c, int64(21))), Equals, float64(10))
+	// 30 falls inside the bucket [30, 31].
+	c.Assert(c1.LessRowCount(encode(c, int64(30))), Equals, float64(30.5))
+	c.Assert(c1.LessRowCount(encode(c, int64(1000))), Equals, float64(100))
+	c.Assert(c1.EqualRowCount(encode(c, int64(1000))), Equals, float64(0))
+
+	c2 := tbl.Column("c2")
+	c.Assert(c2.NDV, Equals, int64(10))
+	c.Assert(c2.EqualRowCount(encode(c, int64(3))), Equals, float64(10))
+	c.Assert(c2.LessRowCount(encode(c, int64(3))), Equals, float64(30))
+
+	c3 := tbl.Column("c3")
+	c.Assert(c3.NDV, Equals, int64(75))
+	c.Assert(c3.NullCount, Equals, int64(25))
+
+	idx := tbl.Index("c2")
+	c.Assert(idx.ID, Equals, int64(0))
+	c.Assert(idx.NDV, Equals, int64(10))
+	c.Assert(idx.EqualRowCount(encode(c, int64(3))), Equals, float64(10))
+
+	c.Assert(tbl.Column("c4"), IsNil)
+	c.Assert(tbl.Index("c1"), IsNil)
+}
+
+func (s *testStatisticsSuite) TestSaveAndLoad(c *C) {
+	tbl, err := statistics.Load(s.txn, s.tbl.TableID())
+	c.Assert(err, IsNil)
+	c.Assert(tbl, IsNil)
+
+	tbl, err = statistics.Build(s, s.tbl)
+	c.Assert(err, IsNil)
+	err = statistics.Save(s.txn, tbl)
+	c.Assert(err, IsNil)
+
+	loaded, err := statistics.Load(s.txn, s.tbl.TableID())
+	c.Assert(err, IsNil)
+	c.Assert(loaded, DeepEquals, tbl)
+
+	// The cache decodes the statistics again only if they are saved again.
+	cache := statistics.NewCache()
+	cached, err := cache.Load(s.txn, s.tbl.TableID())
+	c.Assert(err, IsNil)
+	c.Assert(cached.Version, Equals, int64(1))
+	again, err := cache.Load(s.txn, s.tbl.TableID())
+	c.Assert(err, IsNil)
+	c.Assert(again == cached, IsTrue)
+
+	err = statistics.Save(s.txn, tbl)
+	c.Assert(err, IsNil)
+	again, err = cache.Load(s.txn, s.tbl.TableID())
+	c.Assert(err, IsNil)
+	c.Assert(again == cached, IsFalse)
+	c.Assert(again.Version, Equals, int64(2))
+}
@@ -61,6 +61,8 @@ const (
 	ShowCreateTable
 	ShowGrants
 	ShowTriggers
+	ShowStats
+	ShowCreateView
 )
 
 const (
@@ -0,0 +1,77 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmts
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/rset"
+	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/stmt"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/format"
+)
+
+var _ stmt.Statement = (*AnalyzeTableStmt)(nil)
+
+// AnalyzeTableStmt is a statement to collect the statistics of tables,
+// the statistics are stored with the table meta and used for cost estimation.
+// See: https://dev.mysql.com/doc/refman/5.7/en/analyze-table.html
+type AnalyzeTableStmt struct {
+	TableIdents []table.Ident
+
+	Text string
+}
+
+// Explain implements the stmt.Statement Explain interface.
+func (s *AnalyzeTableStmt) Explain(ctx context.Context, w format.Formatter) {
+	w.Format("%s\n", s.Text)
+}
+
+// IsDDL implements the stmt.Statement IsDDL interface.
+func (s *AnalyzeTableStmt) IsDDL() bool {
+	return false
+}
+
+// OriginText implements the stmt.Statement OriginText interface.
+func (s *AnalyzeTableStmt) OriginText() string {
+	return s.Text
+}
+
+// SetText implements the stmt.Statement SetText interface.
+func (s *AnalyzeTableStmt) SetText(text string) {
+	s.Text = text
+}
+
+// Exec implements the stmt.Statement Exec interface.
+func (s *AnalyzeTableStmt) Exec(ctx context.Context) (rset.Recordset, error) {
+	for _, ti := range s.TableIdents {
+		t, err := getTable(ctx, ti)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		st, err := statistics.Build(ctx, t)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		txn, err := ctx.GetTxn(false)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err = statistics.Save(txn, st); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return nil, nil
+}
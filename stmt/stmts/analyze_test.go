@@ -0,0 +1,50 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmts_test
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/stmt/stmts"
+)
+
+func (s *testStmtSuite) TestAnalyzeTable(c *C) {
+	testSQL := `drop table if exists analyze_test; create table analyze_test(id int, c int, index idx_c(c));
+	insert into analyze_test values (1, 1), (2, 2), (3, null);`
+	mustExec(c, s.testDB, testSQL)
+
+	testSQL = "analyze table analyze_test;"
+	stmtList, err := tidb.Compile(s.ctx, testSQL)
+	c.Assert(err, IsNil)
+	c.Assert(stmtList, HasLen, 1)
+
+	testStmt, ok := stmtList[0].(*stmts.AnalyzeTableStmt)
+	c.Assert(ok, IsTrue)
+	c.Assert(testStmt.TableIdents, HasLen, 1)
+
+	c.Assert(testStmt.IsDDL(), IsFalse)
+	c.Assert(len(testStmt.OriginText()), Greater, 0)
+
+	mf := newMockFormatter()
+	testStmt.Explain(nil, mf)
+	c.Assert(mf.Len(), Greater, 0)
+
+	mustExec(c, s.testDB, testSQL)
+
+	// Analyzing a table that doesn't exist fails.
+	tx := mustBegin(c, s.testDB)
+	_, err = tx.Exec("analyze table analyze_test, analyze_not_exist;")
+	c.Assert(err, NotNil)
+	tx.Rollback()
+}
@@ -23,9 +23,12 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser/coldef"
+	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/rset"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/stmt"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
@@ -36,6 +39,7 @@ var (
 	_ stmt.Statement = (*CreateDatabaseStmt)(nil)
 	_ stmt.Statement = (*CreateTableStmt)(nil)
 	_ stmt.Statement = (*CreateIndexStmt)(nil)
+	_ stmt.Statement = (*CreateViewStmt)(nil)
 )
 
 // CreateDatabaseStmt is a statement to create a database.
@@ -163,3 +167,98 @@ func (s *CreateIndexStmt) Exec(ctx context.Context) (rset.Recordset, error) {
 	}
 	return nil, nil
 }
+
+// CreateViewStmt is a statement to create a view.
+// See: https://dev.mysql.com/doc/refman/5.7/en/create-view.html
+type CreateViewStmt struct {
+	OrReplace bool
+	Ident     table.Ident
+	// Cols are the column names of the view, the field names of Select are used if it is empty.
+	Cols   []model.CIStr
+	Select *SelectStmt
+
+	Text string
+}
+
+// Explain implements the stmt.Statement Explain interface.
+func (s *CreateViewStmt) Explain(ctx context.Context, w format.Formatter) {
+	w.Format("%s\n", s.Text)
+}
+
+// IsDDL implements the stmt.Statement IsDDL interface.
+func (s *CreateViewStmt) IsDDL() bool {
+	return true
+}
+
+// OriginText implements the stmt.Statement OriginText interface.
+func (s *CreateViewStmt) OriginText() string {
+	return s.Text
+}
+
+// SetText implements the stmt.Statement SetText interface.
+func (s *CreateViewStmt) SetText(text string) {
+	s.Text = text
+}
+
+// Exec implements the stmt.Statement Exec interface.
+// The SELECT statement is planned to check it and get the view columns and
+// fields, the current user is the definer of the view.
+func (s *CreateViewStmt) Exec(ctx context.Context) (_ rset.Recordset, err error) {
+	ident := s.Ident.Full(ctx)
+	is := sessionctx.GetDomain(ctx).InfoSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return nil, terror.DatabaseNotExists.Gen("database %s not exists", ident.Schema)
+	}
+	// Check Privilege
+	privChecker := privilege.GetPrivilegeChecker(ctx)
+	hasPriv, err := privChecker.Check(ctx, schema, nil, mysql.CreatePriv)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !hasPriv {
+		return nil, errors.Errorf("You do not have the privilege to create view %s.%s.", ident.Schema, ident.Name)
+	}
+
+	p, err := s.Select.Plan(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	fields := p.GetFields()
+	if len(s.Cols) > 0 && len(s.Cols) != len(fields) {
+		return nil, errors.New("View's SELECT and view's field list have different column counts")
+	}
+	cols := make([]*model.ColumnInfo, 0, len(fields))
+	viewFields := make([]*model.ViewField, 0, len(fields))
+	names := make(map[string]struct{}, len(fields))
+	for i, f := range fields {
+		viewFields = append(viewFields, &model.ViewField{
+			Table: model.NewCIStr(f.TableName),
+			Name:  model.NewCIStr(f.Name),
+		})
+		name := model.NewCIStr(f.Name)
+		if len(s.Cols) > 0 {
+			name = s.Cols[i]
+		}
+		if _, ok := names[name.L]; ok {
+			return nil, errors.Errorf("Duplicate column name '%s'", name.O)
+		}
+		names[name.L] = struct{}{}
+		cols = append(cols, &model.ColumnInfo{
+			Name:      name,
+			Offset:    i,
+			FieldType: f.Col.FieldType,
+		})
+	}
+
+	view := &model.ViewInfo{
+		Definer: variable.GetSessionVars(ctx).User,
+		Select:  s.Select.Text,
+		Fields:  viewFields,
+	}
+	err = sessionctx.GetDomain(ctx).DDL().CreateView(ctx, ident, cols, view, s.OrReplace)
+	if terror.ErrorEqual(err, ddl.ErrExists) {
+		return nil, errors.Errorf("CREATE VIEW: table exists %s", s.Ident)
+	}
+	return nil, errors.Trace(err)
+}
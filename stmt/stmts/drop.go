@@ -39,6 +39,7 @@ var (
 	_ stmt.Statement = (*DropDatabaseStmt)(nil)
 	_ stmt.Statement = (*DropTableStmt)(nil)
 	_ stmt.Statement = (*DropIndexStmt)(nil)
+	_ stmt.Statement = (*DropViewStmt)(nil)
 )
 
 // DropDatabaseStmt is a statement to drop a database and all tables in the database.
@@ -128,6 +129,11 @@ func (s *DropTableStmt) Exec(ctx context.Context) (rset.Recordset, error) {
 		} else if err != nil {
 			return nil, errors.Trace(err)
 		}
+		if tb.Meta().IsView() {
+			// A view is dropped by DROP VIEW.
+			notExistTables = append(notExistTables, ti.String())
+			continue
+		}
 		// Check Privilege
 		privChecker := privilege.GetPrivilegeChecker(ctx)
 		hasPriv, err := privChecker.Check(ctx, schema, tb.Meta(), mysql.DropPriv)
@@ -149,6 +155,77 @@ func (s *DropTableStmt) Exec(ctx context.Context) (rset.Recordset, error) {
 	return nil, nil
 }
 
+// DropViewStmt is a statement to drop one or more views.
+// See: https://dev.mysql.com/doc/refman/5.7/en/drop-view.html
+type DropViewStmt struct {
+	IfExists   bool
+	ViewIdents []table.Ident
+
+	Text string
+}
+
+// Explain implements the stmt.Statement Explain interface.
+func (s *DropViewStmt) Explain(ctx context.Context, w format.Formatter) {
+	w.Format("%s\n", s.Text)
+}
+
+// IsDDL implements the stmt.Statement IsDDL interface.
+func (s *DropViewStmt) IsDDL() bool {
+	return true
+}
+
+// OriginText implements the stmt.Statement OriginText interface.
+func (s *DropViewStmt) OriginText() string {
+	return s.Text
+}
+
+// SetText implements the stmt.Statement SetText interface.
+func (s *DropViewStmt) SetText(text string) {
+	s.Text = text
+}
+
+// Exec implements the stmt.Statement Exec interface.
+func (s *DropViewStmt) Exec(ctx context.Context) (rset.Recordset, error) {
+	var notExistViews []string
+	is := sessionctx.GetDomain(ctx).InfoSchema()
+	for _, vi := range s.ViewIdents {
+		fullvi := vi.Full(ctx)
+		schema, ok := is.SchemaByName(fullvi.Schema)
+		if !ok {
+			notExistViews = append(notExistViews, vi.String())
+			continue
+		}
+		tb, err := is.TableByName(fullvi.Schema, fullvi.Name)
+		if err != nil && strings.HasSuffix(err.Error(), "not exist") {
+			notExistViews = append(notExistViews, vi.String())
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !tb.Meta().IsView() {
+			return nil, errors.Errorf("'%s.%s' is not VIEW", fullvi.Schema, fullvi.Name)
+		}
+		// Check Privilege
+		privChecker := privilege.GetPrivilegeChecker(ctx)
+		hasPriv, err := privChecker.Check(ctx, schema, tb.Meta(), mysql.DropPriv)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !hasPriv {
+			return nil, errors.Errorf("You do not have the privilege to drop view %s.%s.", fullvi.Schema, fullvi.Name)
+		}
+
+		err = sessionctx.GetDomain(ctx).DDL().DropView(ctx, fullvi)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if len(notExistViews) > 0 && !s.IfExists {
+		return nil, errors.Errorf("DROP VIEW: view %s does not exist", strings.Join(notExistViews, ","))
+	}
+	return nil, nil
+}
+
 // DropIndexStmt is a statement to drop the index.
 // See: https://dev.mysql.com/doc/refman/5.7/en/drop-index.html
 type DropIndexStmt struct {
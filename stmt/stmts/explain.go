@@ -18,7 +18,9 @@
 package stmts
 
 import (
+	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/plan/plans"
 	"github.com/pingcap/tidb/rset"
 	"github.com/pingcap/tidb/rset/rsets"
@@ -33,6 +35,12 @@ var _ stmt.Statement = (*ExplainStmt)(nil)
 // See: https://dev.mysql.com/doc/refman/5.7/en/explain.html
 type ExplainStmt struct {
 	S stmt.Statement
+	// Analyze is true for EXPLAIN ANALYZE, which executes the select statement
+	// and explains it with the runtime statistics.
+	Analyze bool
+	// Format is the output format, "json" explains the plan as a JSON tree,
+	// "traditional" or empty explains it as indented text.
+	Format string
 
 	Text string
 }
@@ -76,5 +84,56 @@ func (s *ExplainStmt) Exec(ctx context.Context) (_ rset.Recordset, err error) {
 		return v.Exec(ctx)
 	}
 
+	if s.Analyze {
+		x, ok := s.S.(*SelectStmt)
+		if !ok {
+			return nil, errors.Errorf("EXPLAIN ANALYZE is not supported for %s", s.S.OriginText())
+		}
+		p, err := x.Plan(ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return rsets.Recordset{Ctx: ctx, Plan: &plans.ExplainAnalyzePlan{Src: p}}, nil
+	}
+
+	switch s.Format {
+	case "", "traditional":
+	case "json":
+		p, err := s.explainJSON(ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return rsets.Recordset{Ctx: ctx, Plan: p}, nil
+	default:
+		return nil, errors.Errorf("unknown EXPLAIN format %s", s.Format)
+	}
+
 	return rsets.Recordset{Ctx: ctx, Plan: &plans.ExplainDefaultPlan{S: s.S}}, nil
 }
+
+// explainJSON returns the plan which explains the statement as a JSON tree.
+func (s *ExplainStmt) explainJSON(ctx context.Context) (*plans.ExplainJSONPlan, error) {
+	var (
+		p   plan.Plan
+		op  string
+		err error
+	)
+	switch x := s.S.(type) {
+	case *SelectStmt:
+		p, err = x.Plan(ctx)
+	case *UnionStmt:
+		p, err = x.Plan(ctx)
+	case *UpdateStmt:
+		p, err = x.plan(ctx)
+		op = "Update"
+	case *DeleteStmt:
+		p, err = x.plan(ctx)
+		op = "Delete"
+	default:
+		return nil, errors.Errorf("EXPLAIN FORMAT=JSON is not supported for %s", s.S.OriginText())
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &plans.ExplainJSONPlan{Src: p, Stmt: op}, nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stmts
+
+import (
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/parser/coldef"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/kvcache"
+)
+
+// A dummy type to avoid naming collision in context.
+type planCacheKeyType int
+
+// String defines a Stringer function for debugging and pretty printing.
+func (k planCacheKeyType) String() string {
+	return "plan cache"
+}
+
+// planCacheKey holds the plan cache of the prepared statements in a session.
+// The plans can't be shared by sessions, as a plan holds the state of its execution
+// and the parameter markers of the statement it is built for.
+const planCacheKey planCacheKeyType = 0
+
+// cachedPlan is a plan cached for a prepared SelectStmt, it is valid only for the schema
+// version and the lock type it is built with.
+// A plan holds the state of its execution, so it is checked out by one execution at a time,
+// inUse is true until the recordset of that execution is closed.
+type cachedPlan struct {
+	schemaVersion int64
+	lock          coldef.LockType
+	plan          plan.Plan
+	inUse         bool
+}
+
+// checkout marks the cached plan in use and returns the plan for an execution.
+func (cp *cachedPlan) checkout() plan.Plan {
+	cp.inUse = true
+	return &checkedOutPlan{Plan: cp.plan, cp: cp}
+}
+
+// checkedOutPlan is the cached plan used by an execution, closing it returns the plan to the cache.
+type checkedOutPlan struct {
+	plan.Plan
+	cp       *cachedPlan
+	released bool
+}
+
+// Close implements plan.Plan Close interface.
+// Only the first Close resets the shared plan, as the plan may be checked out by another
+// execution after that.
+func (p *checkedOutPlan) Close() error {
+	if p.released {
+		return nil
+	}
+	p.released = true
+	err := p.Plan.Close()
+	p.cp.inUse = false
+	return errors.Trace(err)
+}
+
+// planCacheSize returns the max number of cached plans in the session,
+// it is tidb_plan_cache_size system variable.
+func planCacheSize(ctx context.Context) int {
+	var value string
+	if vars := variable.GetSessionVars(ctx); vars != nil {
+		value = vars.Systems[variable.TiDBPlanCacheSize]
+	}
+	if value == "" {
+		value = variable.GetSysVar(variable.TiDBPlanCacheSize).Value
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil || size < 0 {
+		return 0
+	}
+	return size
+}
+
+// getPlanCache returns the plan cache of the session, it returns nil if the cache is disabled.
+func getPlanCache(ctx context.Context) *kvcache.SimpleLRUCache {
+	size := planCacheSize(ctx)
+	if size == 0 {
+		ctx.ClearValue(planCacheKey)
+		return nil
+	}
+	cache, ok := ctx.Value(planCacheKey).(*kvcache.SimpleLRUCache)
+	if !ok || cache.Capacity() != size {
+		cache = kvcache.NewSimpleLRUCache(size)
+		ctx.SetValue(planCacheKey, cache)
+	}
+	return cache
+}
+
+// removeCachedPlan removes the cached plan of the prepared statement.
+func removeCachedPlan(ctx context.Context, s *PreparedStmt) {
+	if cache, ok := ctx.Value(planCacheKey).(*kvcache.SimpleLRUCache); ok {
+		cache.Delete(s.SQLStmt)
+	}
+}
+
+// cachedPlan returns the plan of the prepared SelectStmt. The plan is built once and reused
+// until the schema is changed, the parameter markers in it are evaluated to the values bound
+// by each ExecuteStmt. If the cached plan is still used by the open recordset of an earlier
+// execution, a new plan is built for this execution and the cached one is kept.
+func (s *SelectStmt) cachedPlan(ctx context.Context) (plan.Plan, error) {
+	cache := getPlanCache(ctx)
+	if cache == nil {
+		return s.Plan(ctx)
+	}
+	schemaVersion := sessionctx.GetDomain(ctx).InfoSchema().SchemaMetaVersion()
+	lock := s.lockType(ctx)
+	if v, ok := cache.Get(s); ok {
+		cp := v.(*cachedPlan)
+		if cp.schemaVersion == schemaVersion && cp.lock == lock {
+			if cp.inUse {
+				return s.Plan(ctx)
+			}
+			return cp.checkout(), nil
+		}
+	}
+	p, err := s.Plan(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cp := &cachedPlan{schemaVersion: schemaVersion, lock: lock, plan: p}
+	cache.Put(s, cp)
+	return cp.checkout(), nil
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/field"
 	"github.com/pingcap/tidb/rset"
+	"github.com/pingcap/tidb/rset/rsets"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/stmt"
 	"github.com/pingcap/tidb/util/format"
@@ -91,6 +92,9 @@ func (s *PreparedStmt) Exec(ctx context.Context) (_ rset.Recordset, err error) {
 		s.ID = vars.GetNextPreparedStmtID()
 		s.Name = getPreparedStmtIDKey(s.ID)
 	}
+	if old, ok := vars.PreparedStmts[s.Name].(*PreparedStmt); ok {
+		removeCachedPlan(ctx, old)
+	}
 	vars.PreparedStmts[s.Name] = s
 	return nil, nil
 }
@@ -167,10 +171,13 @@ func (s *DeallocateStmt) Exec(ctx context.Context) (_ rset.Recordset, err error)
 	if len(s.Name) == 0 {
 		s.Name = getPreparedStmtIDKey(s.ID)
 	}
-	_, ok := vars.PreparedStmts[s.Name]
+	vs, ok := vars.PreparedStmts[s.Name]
 	if !ok {
 		return nil, errors.Errorf("Can not find prepared statement with name %s", s.Name)
 	}
+	if ps, ok := vs.(*PreparedStmt); ok {
+		removeCachedPlan(ctx, ps)
+	}
 	delete(vars.PreparedStmts, s.Name)
 	return nil, nil
 }
@@ -231,5 +238,13 @@ func (s *ExecuteStmt) Exec(ctx context.Context) (_ rset.Recordset, err error) {
 	ps.InPrepare = false
 
 	// Run statement.
+	if ss, ok := ps.SQLStmt.(*SelectStmt); ok {
+		// The plan of SelectStmt is cached, as it is the same for any parameter values.
+		r, err := ss.cachedPlan(ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return rsets.Recordset{Ctx: ctx, Plan: r}, nil
+	}
 	return ps.SQLStmt.Exec(ctx)
 }
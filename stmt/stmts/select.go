@@ -22,8 +22,10 @@ import (
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/expression/subquery"
 	"github.com/pingcap/tidb/field"
 	"github.com/pingcap/tidb/parser/coldef"
+	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/plan/plans"
 	"github.com/pingcap/tidb/rset"
@@ -51,6 +53,7 @@ type SelectStmt struct {
 	Lock coldef.LockType
 
 	selectList *plans.SelectList
+	windows    *rsets.WindowRset
 
 	Text string
 }
@@ -117,15 +120,43 @@ func (s *SelectStmt) checkOneColumn(ctx context.Context) error {
 	return nil
 }
 
+// checkWindowFuncs checks that window functions are only used in select fields and order by.
+func (s *SelectStmt) checkWindowFuncs() error {
+	var exprs []expression.Expression
+	if s.Where != nil {
+		exprs = append(exprs, s.Where.Expr)
+	}
+	if s.GroupBy != nil {
+		exprs = append(exprs, s.GroupBy.By...)
+	}
+	if s.Having != nil {
+		exprs = append(exprs, s.Having.Expr)
+	}
+	for _, e := range exprs {
+		funcs, err := expression.MentionedWindowFuncs(e)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if len(funcs) > 0 {
+			return errors.Errorf("You cannot use the window function '%s' in this context.", funcs[0].F)
+		}
+	}
+	return nil
+}
+
 // Plan implements the plan.Planner interface.
 // The whole phase for select is
-// `from -> where -> lock -> group by -> having -> select fields -> distinct -> order by -> limit -> final`
+// `from -> where -> lock -> group by -> having -> select fields -> window -> distinct -> order by -> limit -> final`
 func (s *SelectStmt) Plan(ctx context.Context) (plan.Plan, error) {
 	var (
 		r   plan.Plan
 		err error
 	)
 
+	if err = s.checkWindowFuncs(); err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	if s.From != nil {
 		r, err = s.From.Plan(ctx)
 		if err != nil {
@@ -144,21 +175,31 @@ func (s *SelectStmt) Plan(ctx context.Context) (plan.Plan, error) {
 	// Put RowStackFromPlan here so that we can catch the origin from data after above FROM phase.
 	r = &plans.RowStackFromPlan{Src: r}
 
-	if w := s.Where; w != nil {
-		r, err = (&rsets.WhereRset{Expr: w.Expr, Src: r}).Plan(ctx)
+	var (
+		where expression.Expression
+		joins []*subqueryJoin
+	)
+	if s.Where != nil {
+		// The subquery predicates which can be rewritten into semi joins are
+		// planned after the other conditions.
+		joins, where, err = splitSubqueryJoins(ctx, r.GetFields(), s.Where.Expr)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if where != nil {
+		r, err = (&rsets.WhereRset{Expr: where, Src: r}).Plan(ctx)
 		if err != nil {
 			return nil, err
 		}
+	} else if r, _, err = plans.OptimizeJoin(ctx, r, nil); err != nil {
+		return nil, errors.Trace(err)
 	}
-	lock := s.Lock
-	if lock != coldef.SelectLockNone && autocommit.ShouldAutocommit(ctx) {
-		// Locking of rows for update using SELECT FOR UPDATE only applies when autocommit
-		// is disabled (either by beginning transaction with START TRANSACTION or by setting
-		// autocommit to 0. If autocommit is enabled, the rows matching the specification are not locked.
-		// See: https://dev.mysql.com/doc/refman/5.7/en/innodb-locking-reads.html
-		lock = coldef.SelectLockNone
+	for _, j := range joins {
+		r = plans.NewSemiJoinPlan(ctx, r, j.inner, j.outerKeys, j.innerKeys, j.anti, j.nullAware, j.expr)
 	}
-	r = &plans.SelectLockPlan{Src: r, Lock: lock}
+	r = &plans.SelectLockPlan{Src: r, Lock: s.lockType(ctx)}
+	src := r
 
 	if err := s.checkOneColumn(ctx); err != nil {
 		return nil, errors.Trace(err)
@@ -192,9 +233,15 @@ func (s *SelectStmt) Plan(ctx context.Context) (plan.Plan, error) {
 		if err = s.OrderBy.CheckAggregate(selectList); err != nil {
 			return nil, errors.Trace(err)
 		}
+		// `order by` may contain window functions, and we will add this to hidden fields too.
+		s.OrderBy.CheckWindow(selectList)
 	}
 
 	if s.selectList == nil {
+		// The select fields with window functions are evaluated after having.
+		if s.windows, err = rsets.NewWindowRset(selectList); err != nil {
+			return nil, errors.Trace(err)
+		}
 		s.selectList = selectList
 	}
 
@@ -226,6 +273,14 @@ func (s *SelectStmt) Plan(ctx context.Context) (plan.Plan, error) {
 		}
 	}
 
+	if s.windows != nil {
+		w := *s.windows
+		w.Src = r
+		if r, err = w.Plan(ctx); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
 	if s.Distinct {
 		r = &plans.DistinctDefaultPlan{Src: r, SelectList: selectList}
 	}
@@ -239,6 +294,10 @@ func (s *SelectStmt) Plan(ctx context.Context) (plan.Plan, error) {
 		}
 	}
 
+	// Read the rows from the index only, if it has all the columns we need.
+	// It is done after ORDER BY which may read the rows from an index.
+	plans.UseCoveringIndex(src, s.mentionedExprs(selectList))
+
 	if s := s.Offset; s != nil {
 		r = &plans.OffsetDefaultPlan{Count: s.Count, Src: r, Fields: r.GetFields()}
 	}
@@ -249,6 +308,26 @@ func (s *SelectStmt) Plan(ctx context.Context) (plan.Plan, error) {
 	return r, nil
 }
 
+// mentionedExprs returns the expressions evaluated on the rows from FROM and WHERE clause.
+func (s *SelectStmt) mentionedExprs(selectList *plans.SelectList) []expression.Expression {
+	var exprs []expression.Expression
+	for _, f := range selectList.Fields {
+		exprs = append(exprs, f.Expr)
+	}
+	if s.GroupBy != nil {
+		exprs = append(exprs, s.GroupBy.By...)
+	}
+	if s.Having != nil {
+		exprs = append(exprs, s.Having.Expr)
+	}
+	if s.OrderBy != nil {
+		for _, by := range s.OrderBy.By {
+			exprs = append(exprs, by.Expr)
+		}
+	}
+	return exprs
+}
+
 // Exec implements the stmt.Statement Exec interface.
 func (s *SelectStmt) Exec(ctx context.Context) (rs rset.Recordset, err error) {
 	log.Info("Exec :", s.OriginText())
@@ -259,3 +338,235 @@ func (s *SelectStmt) Exec(ctx context.Context) (rs rset.Recordset, err error) {
 
 	return rsets.Recordset{Ctx: ctx, Plan: r}, nil
 }
+
+// lockType returns the lock type of the rows read in the context.
+func (s *SelectStmt) lockType(ctx context.Context) coldef.LockType {
+	if s.Lock != coldef.SelectLockNone && autocommit.ShouldAutocommit(ctx) {
+		// Locking of rows for update using SELECT FOR UPDATE only applies when autocommit
+		// is disabled (either by beginning transaction with START TRANSACTION or by setting
+		// autocommit to 0. If autocommit is enabled, the rows matching the specification are not locked.
+		// See: https://dev.mysql.com/doc/refman/5.7/en/innodb-locking-reads.html
+		return coldef.SelectLockNone
+	}
+	return s.Lock
+}
+
+// subqueryJoin is an `IN`, `NOT IN`, `EXISTS` or `NOT EXISTS` subquery predicate
+// in WHERE clause, which is planned as a semi join or an anti join. The subquery
+// is planned as inner without its correlated equal conditions, which become the
+// join keys along with the compared expression and the field of `IN` subquery.
+type subqueryJoin struct {
+	expr      expression.Expression
+	inner     plan.Plan
+	outerKeys []expression.Expression
+	innerKeys []expression.Expression
+	anti      bool
+	nullAware bool
+}
+
+// splitSubqueryJoins splits the WHERE condition into the subquery predicates which
+// can be planned as semi joins, and the rest conditions.
+func splitSubqueryJoins(ctx context.Context, fromFields []*field.ResultField, where expression.Expression) ([]*subqueryJoin, expression.Expression, error) {
+	var (
+		joins []*subqueryJoin
+		rest  expression.Expression
+	)
+	for _, cond := range plans.SplitConjuncts(where) {
+		j, err := newSubqueryJoin(ctx, fromFields, cond)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if j != nil {
+			joins = append(joins, j)
+		} else if rest == nil {
+			rest = cond
+		} else {
+			rest = expression.NewBinaryOperation(opcode.AndAnd, rest, cond)
+		}
+	}
+	return joins, rest, nil
+}
+
+// newSubqueryJoin returns the subqueryJoin for the condition, or nil if it is not
+// a subquery predicate which can be planned as a semi join. The subquery must be a
+// simple select without aggregation or limit, whose conditions referencing the outer
+// query are equal conditions between the inner and the outer columns.
+func newSubqueryJoin(ctx context.Context, fromFields []*field.ResultField, cond expression.Expression) (*subqueryJoin, error) {
+	j := &subqueryJoin{expr: cond}
+	var (
+		sq    expression.SubQuery
+		outer expression.Expression
+	)
+	switch x := plans.UnwrapPExpr(cond).(type) {
+	case *expression.PatternIn:
+		if x.Sel == nil {
+			return nil, nil
+		}
+		if _, ok := x.Expr.(*expression.Row); ok {
+			return nil, nil
+		}
+		sq, outer = x.Sel, x.Expr
+		j.anti, j.nullAware = x.Not, x.Not
+	case *expression.ExistsSubQuery:
+		sq = x.Sel
+	case *expression.UnaryOperation:
+		es, ok := plans.UnwrapPExpr(x.V).(*expression.ExistsSubQuery)
+		if x.Op != opcode.Not || !ok {
+			return nil, nil
+		}
+		sq, j.anti = es.Sel, true
+	default:
+		return nil, nil
+	}
+	x, ok := sq.(*subquery.SubQuery)
+	if !ok {
+		return nil, nil
+	}
+	sel, ok := x.Stmt.(*SelectStmt)
+	if !ok || !sel.canSemiJoin(outer != nil) {
+		return nil, nil
+	}
+
+	src, err := sel.From.Plan(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	v := newIdentScopeVisitor(src.GetFields(), fromFields)
+	var innerConds []expression.Expression
+	if sel.Where != nil {
+		for _, c := range plans.SplitConjuncts(sel.Where.Expr.Clone()) {
+			switch v.scope(c) {
+			case 0, scopeInner:
+				innerConds = append(innerConds, c)
+				continue
+			case scopeInner | scopeOuter:
+				b, ok := plans.UnwrapPExpr(c).(*expression.BinaryOperation)
+				if !ok || b.Op != opcode.EQ {
+					return nil, nil
+				}
+				l, r := v.scope(b.L), v.scope(b.R)
+				if l == scopeInner && r == scopeOuter {
+					j.outerKeys = append(j.outerKeys, b.R)
+					j.innerKeys = append(j.innerKeys, b.L)
+					continue
+				}
+				if l == scopeOuter && r == scopeInner {
+					j.outerKeys = append(j.outerKeys, b.L)
+					j.innerKeys = append(j.innerKeys, b.R)
+					continue
+				}
+			}
+			return nil, nil
+		}
+	}
+	if outer != nil {
+		// The compared expression and the field are the last keys, see plans.SemiJoinPlan NullAware.
+		l, f := outer.Clone(), sel.Fields[0].Expr.Clone()
+		if v.scope(l)&^scopeOuter != 0 || v.scope(f)&^scopeInner != 0 {
+			return nil, nil
+		}
+		j.outerKeys = append(j.outerKeys, l)
+		j.innerKeys = append(j.innerKeys, f)
+	}
+
+	j.inner = src
+	if len(innerConds) > 0 {
+		e := innerConds[0]
+		for _, c := range innerConds[1:] {
+			e = expression.NewBinaryOperation(opcode.AndAnd, e, c)
+		}
+		if j.inner, err = (&rsets.WhereRset{Expr: e, Src: src}).Plan(ctx); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if err = resolveIdents(j.outerKeys, fromFields); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err = resolveIdents(j.innerKeys, j.inner.GetFields()); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return j, nil
+}
+
+// canSemiJoin returns whether the subquery can be planned as the inner side of
+// a semi join. The subquery of `IN` must have only one field.
+func (s *SelectStmt) canSemiJoin(in bool) bool {
+	if s.From == nil || s.GroupBy != nil || s.Having != nil || s.Limit != nil || s.Offset != nil ||
+		s.Lock != coldef.SelectLockNone || rsets.HasAggFields(s.Fields) {
+		return false
+	}
+	if !in {
+		return true
+	}
+	if len(s.Fields) != 1 {
+		return false
+	}
+	if ident, ok := s.Fields[0].Expr.(*expression.Ident); ok {
+		if _, wildcard, _ := field.CheckWildcardField(ident.L); wildcard {
+			return false
+		}
+	}
+	return true
+}
+
+func resolveIdents(exprs []expression.Expression, fields []*field.ResultField) error {
+	visitor := rsets.NewFromIdentVisitor(fields, rsets.WhereClause)
+	for i, e := range exprs {
+		var err error
+		if exprs[i], err = e.Accept(visitor); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// The scopes of identifiers in a subquery.
+const (
+	scopeInner = 1 << iota
+	scopeOuter
+	scopeUnknown
+)
+
+// identScopeVisitor finds the scopes of the identifiers in an expression, that is
+// whether they are the columns of the subquery FROM clause or of the outer query.
+// The identifiers in a nested subquery are in unknown scope.
+type identScopeVisitor struct {
+	expression.BaseVisitor
+	inner  []*field.ResultField
+	outer  []*field.ResultField
+	scopes int
+}
+
+func newIdentScopeVisitor(inner, outer []*field.ResultField) *identScopeVisitor {
+	v := &identScopeVisitor{inner: inner, outer: outer}
+	v.BaseVisitor.V = v
+	return v
+}
+
+// scope returns the bit set of the scopes of identifiers in e.
+func (v *identScopeVisitor) scope(e expression.Expression) int {
+	v.scopes = 0
+	if _, err := e.Accept(v); err != nil {
+		return scopeUnknown
+	}
+	return v.scopes
+}
+
+// VisitIdent implements Visitor interface.
+func (v *identScopeVisitor) VisitIdent(i *expression.Ident) (expression.Expression, error) {
+	switch n := len(field.GetResultFieldIndex(i.L, v.inner)); {
+	case n == 1:
+		v.scopes |= scopeInner
+	case n == 0 && len(field.GetResultFieldIndex(i.L, v.outer)) == 1:
+		v.scopes |= scopeOuter
+	default:
+		v.scopes |= scopeUnknown
+	}
+	return i, nil
+}
+
+// VisitSubQuery implements Visitor interface.
+func (v *identScopeVisitor) VisitSubQuery(sq expression.SubQuery) (expression.Expression, error) {
+	v.scopes |= scopeUnknown
+	return sq, nil
+}
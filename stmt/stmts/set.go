@@ -21,6 +21,7 @@ import (
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/rset"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/stmt"
@@ -138,19 +139,27 @@ func (s *SetStmt) Exec(ctx context.Context) (_ rset.Recordset, err error) {
 				if err != nil {
 					return nil, errors.Trace(err)
 				}
+				if err = checkSysVarValue(name, svalue); err != nil {
+					return nil, errors.Trace(err)
+				}
 				err = globalVars.SetGlobalSysVar(ctx, name, svalue)
 				return nil, errors.Trace(err)
 			}
 			return nil, errors.Errorf("Variable '%s' is a SESSION variable and can't be used with SET GLOBAL", name)
 		}
 		if sysVar.Scope&variable.ScopeSession > 0 {
-			if value, err := v.getValue(ctx); err != nil {
+			value, err := v.getValue(ctx)
+			if err != nil {
 				return nil, errors.Trace(err)
-			} else if value == nil {
-				sessionVars.Systems[name] = ""
-			} else {
-				sessionVars.Systems[name] = fmt.Sprintf("%v", value)
 			}
+			svalue := ""
+			if value != nil {
+				svalue = fmt.Sprintf("%v", value)
+			}
+			if err = checkSysVarValue(name, svalue); err != nil {
+				return nil, errors.Trace(err)
+			}
+			sessionVars.Systems[name] = svalue
 			return nil, nil
 		}
 		return nil, errors.Errorf("Variable '%s' is a GLOBAL variable and should be set with SET GLOBAL", name)
@@ -159,6 +168,15 @@ func (s *SetStmt) Exec(ctx context.Context) (_ rset.Recordset, err error) {
 	return nil, nil
 }
 
+// checkSysVarValue checks whether value is valid for the system variable name.
+func checkSysVarValue(name string, value string) error {
+	if name == variable.TimeZone {
+		_, err := mysql.ParseTimeZone(value)
+		return errors.Trace(err)
+	}
+	return nil
+}
+
 // SetCharsetStmt is a statement to assign values to character and collation variables.
 // See: https://dev.mysql.com/doc/refman/5.7/en/set-statement.html
 type SetCharsetStmt struct {
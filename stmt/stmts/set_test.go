@@ -117,6 +117,17 @@ func (s *testStmtSuite) TestSet(c *C) {
 	_, err = tx.Exec(errTestSql)
 	c.Assert(err, NotNil)
 	tx.Rollback()
+
+	mustExec(c, s.testDB, "SET @@time_zone = '+08:00';")
+	mustExec(c, s.testDB, "SET @@time_zone = 'Europe/Helsinki';")
+	mustExec(c, s.testDB, "SET @@global.time_zone = 'SYSTEM';")
+
+	for _, errTestSql = range []string{"SET @@time_zone = 'foo';", "SET @@global.time_zone = '+14:00';"} {
+		tx = mustBegin(c, s.testDB)
+		_, err = tx.Exec(errTestSql)
+		c.Assert(err, NotNil)
+		tx.Rollback()
+	}
 }
 
 func (s *testStmtSuite) TestSetCharsetStmt(c *C) {
@@ -54,5 +54,12 @@ func getDefaultValue(ctx context.Context, c *column.Col) (interface{}, bool, err
 
 func getTable(ctx context.Context, tableIdent table.Ident) (table.Table, error) {
 	full := tableIdent.Full(ctx)
-	return sessionctx.GetDomain(ctx).InfoSchema().TableByName(full.Schema, full.Name)
+	t, err := sessionctx.GetDomain(ctx).InfoSchema().TableByName(full.Schema, full.Name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if t.Meta().IsView() {
+		return nil, errors.Errorf("'%s.%s' is not BASE TABLE", full.Schema, full.Name)
+	}
+	return t, nil
 }
@@ -14,6 +14,9 @@
 package hbasekv
 
 import (
+	"bytes"
+	"sort"
+
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/go-hbase"
@@ -25,6 +28,7 @@ var (
 	_ kv.Snapshot     = (*hbaseSnapshot)(nil)
 	_ kv.MvccSnapshot = (*hbaseSnapshot)(nil)
 	_ kv.Iterator     = (*hbaseIter)(nil)
+	_ kv.Iterator     = (*hbaseReverseIter)(nil)
 )
 
 // hbaseBatchSize is used for go-themis Scanner.
@@ -134,6 +138,24 @@ func (s *hbaseSnapshot) NewIterator(param interface{}) kv.Iterator {
 	return newInnerScanner(scanner)
 }
 
+// NewReverseIterator creates an iterator of the keys less than param in
+// descending order, or all the keys if param is nil.
+func (s *hbaseSnapshot) NewReverseIterator(param interface{}) kv.Iterator {
+	var end []byte
+	if param != nil {
+		k, ok := param.([]byte)
+		if !ok {
+			log.Errorf("hbase iterator parameter error, %+v", param)
+			return nil
+		}
+		end = k
+	}
+
+	it := &hbaseReverseIter{s: s, hi: end}
+	it.readBatch()
+	return it
+}
+
 // MvccIterator seeks to the key in the specific version's snapshot, if the
 // version doesn't exist, returns the nearest(lower) version's snaphot.
 func (s *hbaseSnapshot) NewMvccIterator(k kv.Key, ver kv.Version) kv.Iterator {
@@ -197,3 +219,104 @@ func (it *hbaseIter) Close() {
 	}
 	it.rs = nil
 }
+
+// hbaseReverseBatchSize is the max number of keys read in a batch by hbaseReverseIter.
+const hbaseReverseBatchSize = 128
+
+// hbaseReverseIter iterates the keys less than hi in descending order. HBase
+// scanners can only scan forward, so the keys are read in batches from the
+// end. A batch is the keys in a range [lo, hi) just below the keys read, which
+// is narrowed by one byte of lo at a time with one-row probe scans until it has
+// at most hbaseReverseBatchSize keys, then it is scanned forward. The keys
+// far below hi are never scanned.
+type hbaseReverseIter struct {
+	s *hbaseSnapshot
+	// hi is the exclusive upper bound of the keys not read yet, nil means no bound.
+	hi   []byte
+	done bool
+
+	keys   []string
+	values [][]byte
+	cursor int
+}
+
+// scan returns at most limit rows in [start, hi) in ascending order.
+func (it *hbaseReverseIter) scan(start []byte, limit int) []*hbase.ResultRow {
+	if it.hi != nil && bytes.Compare(start, it.hi) >= 0 {
+		return nil
+	}
+	scanner := it.s.txn.GetScanner([]byte(it.s.storeName), start, it.hi, limit)
+	defer scanner.Close()
+	var rows []*hbase.ResultRow
+	for len(rows) < limit {
+		r := scanner.Next()
+		if r == nil || len(r.Columns) == 0 {
+			break
+		}
+		rows = append(rows, r)
+	}
+	return rows
+}
+
+// readBatch reads the greatest keys less than hi, and moves hi to the least of them.
+func (it *hbaseReverseIter) readBatch() {
+	it.keys, it.values = nil, nil
+	it.cursor = -1
+	if it.done {
+		return
+	}
+	var lo []byte
+	for {
+		rows := it.scan(lo, hbaseReverseBatchSize+1)
+		if len(rows) <= hbaseReverseBatchSize {
+			for _, r := range rows {
+				it.keys = append(it.keys, string(r.Row))
+				it.values = append(it.values, r.Columns[hbaseFmlAndQual].Value)
+			}
+			it.cursor = len(it.keys) - 1
+			it.hi = lo
+			it.done = lo == nil
+			return
+		}
+		// There are keys greater than lo in [lo, hi), narrow the range to the keys
+		// with the greatest next byte after lo.
+		next := sort.Search(256, func(b int) bool {
+			return len(it.scan(appendByte(lo, byte(b)), 1)) == 0
+		}) - 1
+		lo = appendByte(lo, byte(next))
+	}
+}
+
+func appendByte(b []byte, c byte) []byte {
+	ret := make([]byte, len(b)+1)
+	copy(ret, b)
+	ret[len(b)] = c
+	return ret
+}
+
+func (it *hbaseReverseIter) Next() error {
+	it.cursor--
+	if it.cursor < 0 {
+		it.readBatch()
+	}
+	return nil
+}
+
+func (it *hbaseReverseIter) Valid() bool {
+	return it.cursor >= 0
+}
+
+func (it *hbaseReverseIter) Key() string {
+	return it.keys[it.cursor]
+}
+
+func (it *hbaseReverseIter) Value() []byte {
+	return it.values[it.cursor]
+}
+
+func (it *hbaseReverseIter) Close() {
+	it.keys = nil
+	it.values = nil
+	it.cursor = -1
+	it.done = true
+}
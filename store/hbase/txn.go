@@ -155,6 +155,19 @@ func (txn *hbaseTxn) Seek(k kv.Key) (kv.Iterator, error) {
 	return kv.NewDecodeKeyIter(iter), nil
 }
 
+func (txn *hbaseTxn) SeekReverse(k kv.Key) (kv.Iterator, error) {
+	log.Debugf("seek reverse %q txn:%d", k, txn.tid)
+	encKey := kv.EncodeSeekReverseKey(k)
+	iter, err := txn.UnionStore.SeekReverse(encKey, txn)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !iter.Valid() {
+		return &kv.UnionIter{}, nil
+	}
+	return kv.NewDecodeKeyIter(iter), nil
+}
+
 func (txn *hbaseTxn) Delete(k kv.Key) error {
 	log.Debugf("delete %q txn:%d", k, txn.tid)
 	k = kv.EncodeKey(k)
@@ -126,6 +126,36 @@ func (i *iterator) Next() bool {
 	return i.key != nil
 }
 
+func (i *iterator) Prev() bool {
+	// The previous pair is the last one whose key is less than the current key.
+	return i.SeekReverse(i.key)
+}
+
+func (i *iterator) SeekReverse(endKey []byte) bool {
+	i.seeked = true
+	i.d.DB.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		c := b.Cursor()
+		var key []byte
+		var value []byte
+
+		if endKey == nil {
+			key, value = c.Last()
+		} else if k, _ := c.Seek(endKey); k == nil {
+			key, value = c.Last()
+		} else {
+			key, value = c.Prev()
+		}
+
+		i.key = bytes.CloneBytes(key)
+		i.value = bytes.CloneBytes(value)
+
+		return nil
+	})
+
+	return i.key != nil
+}
+
 func (i *iterator) Seek(startKey []byte) bool {
 	i.d.DB.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket(bucketName)
@@ -47,10 +47,17 @@ type Iterator interface {
 	// Value returns the current value of the key/value pair or nil
 	// if the iterator is done.
 	Value() []byte
+	// Prev moves the iterator to the previous key/value pair,
+	// returns true/false if the iterator is exhausted.
+	Prev() bool
 	// Seek moves the iterator to the first key/value pair whose key is greater
 	// or equal to the given key.
 	// It returns whether such pair exists or not.
 	Seek(startKey []byte) bool
+	// SeekReverse moves the iterator to the last key/value pair whose key is
+	// less than the given key, or the last key/value pair if the key is nil.
+	// It returns whether such pair exists or not.
+	SeekReverse(endKey []byte) bool
 	// Release releases current iterator.
 	Release()
 }
@@ -19,14 +19,16 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/store/localstore/engine"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/syndtr/goleveldb/leveldb/storage"
 	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 var (
-	_ engine.DB    = (*db)(nil)
-	_ engine.Batch = (*leveldb.Batch)(nil)
+	_ engine.DB       = (*db)(nil)
+	_ engine.Batch    = (*leveldb.Batch)(nil)
+	_ engine.Iterator = (*dbIter)(nil)
 )
 
 var (
@@ -56,7 +58,7 @@ func (d *db) NewBatch() engine.Batch {
 }
 
 func (d *db) Seek(startKey []byte) (engine.Iterator, error) {
-	return d.DB.NewIterator(&util.Range{Start: startKey}, nil), nil
+	return &dbIter{d.DB.NewIterator(&util.Range{Start: startKey}, nil)}, nil
 }
 
 func (d *db) Commit(b engine.Batch) error {
@@ -74,6 +76,17 @@ func (d *db) Close() error {
 	return d.DB.Close()
 }
 
+type dbIter struct {
+	iterator.Iterator
+}
+
+func (it *dbIter) SeekReverse(endKey []byte) bool {
+	if endKey == nil || !it.Iterator.Seek(endKey) {
+		return it.Iterator.Last()
+	}
+	return it.Iterator.Prev()
+}
+
 // Driver implements engine Driver.
 type Driver struct {
 }
@@ -82,3 +82,36 @@ func (s *testSuite) TestDB(c *C) {
 
 	iter.Release()
 }
+
+func (s *testSuite) TestReverseIterator(c *C) {
+	db := s.db
+
+	b := db.NewBatch()
+	b.Put([]byte("r1"), []byte("1"))
+	b.Put([]byte("r2"), []byte("2"))
+	b.Put([]byte("r3"), []byte("3"))
+	err := db.Commit(b)
+	c.Assert(err, IsNil)
+
+	iter, err := db.Seek(nil)
+	c.Assert(err, IsNil)
+	c.Assert(iter.SeekReverse([]byte("r3")), Equals, true)
+	c.Assert(iter.Key(), DeepEquals, []byte("r2"))
+	c.Assert(iter.Value(), DeepEquals, []byte("2"))
+	c.Assert(iter.Prev(), Equals, true)
+	c.Assert(iter.Key(), DeepEquals, []byte("r1"))
+	c.Assert(iter.Prev(), Equals, true)
+	c.Assert(string(iter.Key()) < "r1", IsTrue)
+
+	c.Assert(iter.SeekReverse([]byte("r25")), Equals, true)
+	c.Assert(iter.Key(), DeepEquals, []byte("r2"))
+
+	c.Assert(iter.SeekReverse([]byte("s")), Equals, true)
+	c.Assert(iter.Key(), DeepEquals, []byte("r3"))
+
+	c.Assert(iter.SeekReverse(nil), Equals, true)
+	c.Assert(iter.Key(), DeepEquals, []byte("r3"))
+
+	c.Assert(iter.SeekReverse([]byte("")), Equals, false)
+	iter.Release()
+}
@@ -16,6 +16,7 @@ package localstore
 import (
 	"bytes"
 	"fmt"
+	"testing"
 	"time"
 
 	. "github.com/pingcap/check"
@@ -23,6 +24,10 @@ import (
 	"github.com/pingcap/tidb/store/localstore/goleveldb"
 )
 
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
 var _ = Suite(&testMvccSuite{})
 
 type testMvccSuite struct {
@@ -148,6 +153,39 @@ func (t *testMvccSuite) TestMvccNext(c *C) {
 	txn.Commit()
 }
 
+func (t *testMvccSuite) TestMvccSeekReverse(c *C) {
+	txn, err := t.s.Begin()
+	c.Assert(err, IsNil)
+	c.Assert(txn.Delete(encodeInt(2)), IsNil)
+	c.Assert(txn.Set(encodeInt(3), []byte("new")), IsNil)
+	c.Assert(txn.Commit(), IsNil)
+
+	txn, err = t.s.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Commit()
+	// The uncommitted key in the transaction is iterated too.
+	c.Assert(txn.Set(encodeInt(1), []byte("dirty")), IsNil)
+
+	checkKeys := func(it kv.Iterator, keys []int, values []string) {
+		defer it.Close()
+		for i, k := range keys {
+			c.Assert(it.Valid(), IsTrue)
+			c.Assert(it.Key(), Equals, string(encodeInt(k)))
+			c.Assert(string(it.Value()), Equals, values[i])
+			it.Next()
+		}
+		c.Assert(it.Valid(), IsFalse)
+	}
+
+	it, err := txn.SeekReverse(nil)
+	c.Assert(err, IsNil)
+	checkKeys(it, []int{4, 3, 1, 0}, []string{string(encodeInt(4)), "new", "dirty", string(encodeInt(0))})
+
+	it, err = txn.SeekReverse(encodeInt(3))
+	c.Assert(err, IsNil)
+	checkKeys(it, []int{1, 0}, []string{"dirty", string(encodeInt(0))})
+}
+
 func encodeTestDataKey(i int) []byte {
 	return kv.EncodeKey(encodeInt(i))
 }
@@ -27,6 +27,7 @@ var (
 	_ kv.Snapshot     = (*dbSnapshot)(nil)
 	_ kv.MvccSnapshot = (*dbSnapshot)(nil)
 	_ kv.Iterator     = (*dbIter)(nil)
+	_ kv.Iterator     = (*dbReverseIter)(nil)
 )
 
 // dbSnapshot implements MvccSnapshot interface.
@@ -61,6 +62,32 @@ func (s *dbSnapshot) internalSeek(startKey []byte) (engine.Iterator, error) {
 	return s.rawIt, nil
 }
 
+// internalSeekReverse moves the raw iterator to the last key less than endKey,
+// or the last key if endKey is nil.
+func (s *dbSnapshot) internalSeekReverse(endKey []byte) (engine.Iterator, error) {
+	s.store.snapLock.RLock()
+	defer s.store.snapLock.RUnlock()
+
+	if s.store.closed {
+		return nil, errors.Trace(ErrDBClosed)
+	}
+
+	if s.rawIt == nil {
+		var err error
+		s.rawIt, err = s.db.Seek([]byte{0})
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	ok := s.rawIt.SeekReverse(endKey)
+	if !ok {
+		s.rawIt.Release()
+		s.rawIt = nil
+		return nil, kv.ErrNotExist
+	}
+	return s.rawIt, nil
+}
+
 func (s *dbSnapshot) MvccGet(k kv.Key, ver kv.Version) ([]byte, error) {
 	// engine Snapshot return nil, nil for value not found,
 	// so here we will check nil and return kv.ErrNotExist.
@@ -152,6 +179,19 @@ func (s *dbSnapshot) NewIterator(param interface{}) kv.Iterator {
 	return newDBIter(s, k, s.version)
 }
 
+func (s *dbSnapshot) NewReverseIterator(param interface{}) kv.Iterator {
+	var k []byte
+	if param != nil {
+		var ok bool
+		k, ok = param.([]byte)
+		if !ok {
+			log.Errorf("leveldb iterator parameter error, %+v", param)
+			return nil
+		}
+	}
+	return newDBReverseIter(s, k, s.version)
+}
+
 func (s *dbSnapshot) MvccRelease() {
 	s.Release()
 }
@@ -240,3 +280,76 @@ func (it *dbIter) Value() []byte {
 }
 
 func (it *dbIter) Close() {}
+
+// dbReverseIter iterates the keys less than endKey in descending order.
+type dbReverseIter struct {
+	s               *dbSnapshot
+	endKey          []byte // encoded end key, nil for the end of all keys.
+	valid           bool
+	exceptedVersion kv.Version
+	k               kv.Key
+	v               []byte
+}
+
+func newDBReverseIter(s *dbSnapshot, endKey kv.Key, exceptedVer kv.Version) *dbReverseIter {
+	it := &dbReverseIter{
+		s:               s,
+		valid:           true,
+		exceptedVersion: exceptedVer,
+	}
+	if endKey != nil {
+		it.endKey = codec.EncodeBytes(nil, endKey)
+	}
+	it.Next()
+	return it
+}
+
+func (it *dbReverseIter) Next() error {
+	var retErr error
+	for {
+		engineIter, err := it.s.internalSeekReverse(it.endKey)
+		if err != nil {
+			it.valid = false
+			retErr = err
+			break
+		}
+
+		// The last raw key before endKey is a version key or the meta key of
+		// the previous key, get the real key from it.
+		key, _, err := MvccDecode(engineIter.Key())
+		if err != nil {
+			// It's not a valid metaKey, maybe other data.
+			it.valid = false
+			break
+		}
+		// All the raw keys of the key are not less than its encoded meta key.
+		it.endKey = codec.EncodeBytes(nil, key)
+		val, err := it.s.MvccGet(key, it.exceptedVersion)
+		if err != nil && !terror.ErrorEqual(err, kv.ErrNotExist) {
+			it.valid = false
+			retErr = err
+			break
+		}
+		if val != nil {
+			it.k = bytes.CloneBytes(key)
+			it.v = bytes.CloneBytes(val)
+			break
+		}
+		// Current key's all versions are deleted, just go to the previous key.
+	}
+	return errors.Trace(retErr)
+}
+
+func (it *dbReverseIter) Valid() bool {
+	return it.valid
+}
+
+func (it *dbReverseIter) Key() string {
+	return string(it.k)
+}
+
+func (it *dbReverseIter) Value() []byte {
+	return it.v
+}
+
+func (it *dbReverseIter) Close() {}
@@ -168,6 +168,21 @@ func (txn *dbTxn) Seek(k kv.Key) (kv.Iterator, error) {
 	return kv.NewDecodeKeyIter(iter), nil
 }
 
+func (txn *dbTxn) SeekReverse(k kv.Key) (kv.Iterator, error) {
+	log.Debugf("seek reverse key:%q, txn:%d", k, txn.tid)
+	encKey := kv.EncodeSeekReverseKey(k)
+
+	iter, err := txn.UnionStore.SeekReverse(encKey, txn)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !iter.Valid() {
+		return &kv.UnionIter{}, nil
+	}
+
+	return kv.NewDecodeKeyIter(iter), nil
+}
+
 func (txn *dbTxn) Delete(k kv.Key) error {
 	log.Debugf("delete key:%q, txn:%d", k, txn.tid)
 	k = kv.EncodeKey(k)
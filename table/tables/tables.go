@@ -48,11 +48,14 @@ type Table struct {
 	recordPrefix string
 	indexPrefix  string
 	alloc        autoid.Allocator
+	// version is the version of the stored format, see model.TableInfo Version.
+	version uint16
 }
 
 // TableFromMeta creates a Table instance from model.TableInfo.
 func TableFromMeta(alloc autoid.Allocator, tblInfo *model.TableInfo) table.Table {
 	t := NewTable(tblInfo.ID, tblInfo.Name.O, nil, alloc)
+	t.version = tblInfo.Version
 
 	for _, colInfo := range tblInfo.Columns {
 		c := column.Col{ColumnInfo: *colInfo}
@@ -80,6 +83,7 @@ func NewTable(tableID int64, tableName string, cols []*column.Col, alloc autoid.
 		indexPrefix:  fmt.Sprintf("%d_i", tableID),
 		alloc:        alloc,
 		Columns:      cols,
+		version:      model.CurrLatestTableInfoVersion,
 	}
 	return t
 }
@@ -107,8 +111,9 @@ func (t *Table) TableName() model.CIStr {
 // Meta implements table.Table Meta interface.
 func (t *Table) Meta() *model.TableInfo {
 	ti := &model.TableInfo{
-		Name: t.Name,
-		ID:   t.ID,
+		Name:    t.Name,
+		ID:      t.ID,
+		Version: t.version,
 	}
 	// load table meta
 	for _, col := range t.Columns {
@@ -158,6 +163,13 @@ func (t *Table) unflatten(rec interface{}, col *column.Col) (interface{}, error)
 		return mysql.ParseSetValue(col.Elems, rec.(uint64))
 	case mysql.TypeBit:
 		return mysql.Bit{Value: rec.(uint64), Width: col.Flen}, nil
+	case mysql.TypeJSON:
+		var j mysql.JSON
+		err := j.Unmarshal(rec.([]byte))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return j, nil
 	}
 	log.Error(col.Tp, rec, reflect.TypeOf(rec))
 	return nil, nil
@@ -179,6 +191,9 @@ func (t *Table) flatten(data interface{}) (interface{}, error) {
 		return x.Value, nil
 	case mysql.Bit:
 		return x.Value, nil
+	case mysql.JSON:
+		// for mysql json type
+		return x.Marshal()
 	default:
 		return data, nil
 	}
@@ -237,7 +252,8 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, currData []interface{
 	}
 
 	// set new value
-	if err := t.setNewData(ctx, h, newData); err != nil {
+	loc := variable.GetTimeZone(ctx)
+	if err := t.setNewData(ctx, h, convertTimestamps(newData, loc, time.UTC)); err != nil {
 		return errors.Trace(err)
 	}
 
@@ -339,11 +355,13 @@ func (t *Table) AddRecord(ctx context.Context, r []interface{}) (recordID int64,
 	if err != nil {
 		return 0, errors.Trace(err)
 	}
+	loc := variable.GetTimeZone(ctx)
+	idxRow := convertTimestamps(r, loc, t.indexTimestampLocation())
 	for _, v := range t.indices {
 		if v == nil {
 			continue
 		}
-		colVals, _ := v.FetchValues(r)
+		colVals, _ := v.FetchValues(idxRow)
 		if err = v.X.Create(txn, colVals, recordID); err != nil {
 			if terror.ErrorEqual(err, kv.ErrKeyExists) {
 				// Get the duplicate row handle
@@ -367,6 +385,7 @@ func (t *Table) AddRecord(ctx context.Context, r []interface{}) (recordID int64,
 	}
 
 	// column key -> column value
+	r = convertTimestamps(r, loc, time.UTC)
 	for _, c := range t.Cols() {
 		k := t.RecordKey(recordID, c)
 		if err := t.SetColValue(txn, k, r[c.Offset]); err != nil {
@@ -417,7 +436,7 @@ func (t *Table) RowWithCols(ctx context.Context, h int64, cols []*column.Col) ([
 		}
 		v[c.Offset] = val
 	}
-	return v, nil
+	return convertTimestamps(v, time.UTC, variable.GetTimeZone(ctx)), nil
 }
 
 // Row implements table.Table Row interface.
@@ -451,7 +470,7 @@ func (t *Table) RemoveRecord(ctx context.Context, h int64, r []interface{}) erro
 		return errors.Trace(err)
 	}
 
-	err = t.removeRowIndices(ctx, h, r)
+	err = t.removeRowIndices(ctx, h, convertTimestamps(r, variable.GetTimeZone(ctx), t.indexTimestampLocation()))
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -508,6 +527,7 @@ func (t *Table) RemoveRowIndex(ctx context.Context, h int64, vals []interface{},
 	if err != nil {
 		return errors.Trace(err)
 	}
+	vals = convertTimestamps(vals, variable.GetTimeZone(ctx), t.indexTimestampLocation())
 	if err = idx.X.Delete(txn, vals, h); err != nil {
 		return errors.Trace(err)
 	}
@@ -520,6 +540,7 @@ func (t *Table) BuildIndexForRow(ctx context.Context, h int64, vals []interface{
 	if err != nil {
 		return errors.Trace(err)
 	}
+	vals = convertTimestamps(vals, variable.GetTimeZone(ctx), t.indexTimestampLocation())
 	if err = idx.X.Create(txn, vals, h); err != nil {
 		return errors.Trace(err)
 	}
@@ -574,6 +595,40 @@ func (t *Table) IterRecords(ctx context.Context, startKey string, cols []*column
 	return nil
 }
 
+// indexTimestampLocation returns the time zone of the wall clocks of the
+// TIMESTAMP values in the index keys.
+func (t *Table) indexTimestampLocation() *time.Location {
+	info := model.TableInfo{Version: t.version}
+	return info.TimestampIndexLocation()
+}
+
+// convertTimestamps returns vals with the TIMESTAMP values converted from the
+// time zone from to the time zone to, vals is copied if any value is converted.
+// TIMESTAMP values are stored in UTC in the rows and in the time zone of the
+// table version in the index keys, but they are in the time zone of the
+// session out of the table.
+func convertTimestamps(vals []interface{}, from, to *time.Location) []interface{} {
+	if from == to {
+		return vals
+	}
+
+	var converted []interface{}
+	for i, v := range vals {
+		x, ok := v.(mysql.Time)
+		if !ok || x.Type != mysql.TypeTimestamp {
+			continue
+		}
+		if converted == nil {
+			converted = append([]interface{}(nil), vals...)
+		}
+		converted[i] = x.ConvertTimeZone(from, to)
+	}
+	if converted == nil {
+		return vals
+	}
+	return converted
+}
+
 // AllocAutoID implements table.Table AllocAutoID interface.
 func (t *Table) AllocAutoID() (int64, error) {
 	return t.alloc.Alloc(t.ID)
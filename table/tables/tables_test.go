@@ -159,6 +159,21 @@ func (ts *testSuite) TestTypes(c *C) {
 	c.Assert(row[0], DeepEquals, float64(2))
 	_, err = ts.se.Execute("drop table test.t")
 	c.Assert(err, IsNil)
+
+	_, err = ts.se.Execute("CREATE TABLE test.t (c1 int, c2 json)")
+	c.Assert(err, IsNil)
+	_, err = ts.se.Execute(`insert test.t values (1, '{"a": [1, 2.5, "b"]}')`)
+	c.Assert(err, IsNil)
+	rs, err = ts.se.Execute("select c2 from test.t where c1 = 1")
+	c.Assert(err, IsNil)
+	row, err = rs[0].FirstRow()
+	c.Assert(err, IsNil)
+	c.Assert(row, NotNil)
+	c.Assert(row[0].(mysql.JSON).String(), Equals, `{"a": [1, 2.5, "b"]}`)
+	_, err = ts.se.Execute(`insert test.t values (2, '{"a"')`)
+	c.Assert(err, NotNil)
+	_, err = ts.se.Execute("drop table test.t")
+	c.Assert(err, IsNil)
 }
 
 func (ts *testSuite) TestUniqueIndexMultipleNullEntries(c *C) {
@@ -275,7 +275,8 @@ func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTy
 			mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeBlob,
 			mysql.TypeVarString, mysql.TypeString, mysql.TypeGeometry,
 			mysql.TypeDate, mysql.TypeNewDate,
-			mysql.TypeTimestamp, mysql.TypeDatetime, mysql.TypeDuration:
+			mysql.TypeTimestamp, mysql.TypeDatetime, mysql.TypeDuration,
+			mysql.TypeJSON:
 			if len(paramValues) < (pos + 1) {
 				err = mysql.ErrMalformPacket
 				return
@@ -307,6 +307,8 @@ func dumpRowValuesBinary(alloc arena.Allocator, columns []*ColumnInfo, row []int
 			data = append(data, dumpLengthEncodedString(hack.Slice(v.String()), alloc)...)
 		case mysql.Bit:
 			data = append(data, dumpLengthEncodedString(hack.Slice(v.ToString()), alloc)...)
+		case mysql.JSON:
+			data = append(data, dumpLengthEncodedString(hack.Slice(v.String()), alloc)...)
 		}
 	}
 	return
@@ -354,6 +356,8 @@ func dumpTextValue(mysqlType uint8, value interface{}) ([]byte, error) {
 		return hack.Slice(v.String()), nil
 	case mysql.Bit:
 		return hack.Slice(v.ToString()), nil
+	case mysql.JSON:
+		return hack.Slice(v.String()), nil
 	default:
 		return nil, errors.Errorf("invalid type %T", value)
 	}
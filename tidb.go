@@ -26,14 +26,17 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/field"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/optimizer"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/rset"
+	"github.com/pingcap/tidb/rset/rsets"
 	"github.com/pingcap/tidb/sessionctx/autocommit"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/stmt"
@@ -142,6 +145,85 @@ func Compile(ctx context.Context, src string) ([]stmt.Statement, error) {
 	return stmts, nil
 }
 
+// compileView compiles the SELECT statement src of a view in schema.
+func compileView(ctx context.Context, schema, src string) (stmt.Statement, error) {
+	log.Debug("compiling view", src)
+	l := parser.NewLexer(src)
+	l.SetCharsetInfo(getCtxCharsetInfo(ctx))
+	if parser.YYParse(l) != 0 {
+		return nil, errors.Trace(l.Errors()[0])
+	}
+	rawStmts := l.Stmts()
+	if len(rawStmts) != 1 {
+		return nil, errors.Errorf("invalid view definition %s", src)
+	}
+	sel, ok := rawStmts[0].(*ast.SelectStmt)
+	if !ok {
+		return nil, errors.Errorf("invalid view definition %s", src)
+	}
+	// The tables in the view are in the schema of the view, not the current one.
+	sel.Accept(&viewSchemaFiller{schema: model.NewCIStr(schema)})
+	compiler := &optimizer.Compiler{}
+	return compiler.Compile(sel)
+}
+
+// viewSchemaFiller fills the schema of the table names without schema, except
+// the names of the common table expressions in scope.
+type viewSchemaFiller struct {
+	schema model.CIStr
+	ctes   []*viewCTEScope
+}
+
+// viewCTEScope is the common table expressions of a WITH clause in scope.
+type viewCTEScope struct {
+	recursive bool
+	names     map[string]struct{}
+}
+
+func (v *viewSchemaFiller) isCTE(name model.CIStr) bool {
+	for _, scope := range v.ctes {
+		if _, ok := scope.names[name.L]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Enter implements ast.Visitor Enter interface.
+func (v *viewSchemaFiller) Enter(n ast.Node) (ast.Node, bool) {
+	switch x := n.(type) {
+	case *ast.WithClause:
+		v.ctes = append(v.ctes, &viewCTEScope{recursive: x.IsRecursive, names: map[string]struct{}{}})
+	case *ast.CommonTableExpression:
+		// A recursive common table expression can refer to itself.
+		if scope := v.ctes[len(v.ctes)-1]; scope.recursive {
+			scope.names[x.Name.L] = struct{}{}
+		}
+	case *ast.TableName:
+		if len(x.Schema.O) == 0 && !v.isCTE(x.Name) {
+			x.Schema = v.schema
+		}
+	}
+	return n, false
+}
+
+// Leave implements ast.Visitor Leave interface.
+func (v *viewSchemaFiller) Leave(n ast.Node) (ast.Node, bool) {
+	switch x := n.(type) {
+	case *ast.CommonTableExpression:
+		v.ctes[len(v.ctes)-1].names[x.Name.L] = struct{}{}
+	case *ast.SelectStmt:
+		if x.With != nil {
+			v.ctes = v.ctes[:len(v.ctes)-1]
+		}
+	case *ast.UnionStmt:
+		if x.With != nil {
+			v.ctes = v.ctes[:len(v.ctes)-1]
+		}
+	}
+	return n, true
+}
+
 // CompilePrepare compiles prepared statement, allows placeholder as expr.
 // The return values are compiled statement, parameter list and error.
 func CompilePrepare(ctx context.Context, src string) (stmt.Statement, []*expression.ParamMarker, error) {
@@ -324,6 +406,8 @@ func IsQuery(sql string) bool {
 }
 
 func init() {
+	rsets.CompileView = compileView
+
 	// Register default memory and goleveldb storage
 	RegisterLocalStore("memory", goleveldb.MemoryDriver{})
 	RegisterLocalStore("goleveldb", goleveldb.Driver{})
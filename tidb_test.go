@@ -925,6 +925,261 @@ func (s *testSessionSuite) TestSubQuery(c *C) {
 	mustExecMatch(c, se, "select a.c1, a.c2 from (select c1 as c1, c1 as c2 from t1) as a", [][]interface{}{{1, 1}, {2, 2}})
 }
 
+func (s *testSessionSuite) TestSemiJoin(c *C) {
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+
+	mustExecSQL(c, se, "drop table if exists t1, t2")
+	mustExecSQL(c, se, "create table t1 (c1 int, c2 int)")
+	mustExecSQL(c, se, "create table t2 (c1 int, c2 int, index c1 (c1))")
+	mustExecSQL(c, se, "insert into t1 values (1, 1), (2, 2), (null, 3)")
+	mustExecSQL(c, se, "insert into t2 values (1, 1), (1, 2)")
+
+	mustExecMatch(c, se, "select c1 from t1 where c1 in (select c1 from t2)", [][]interface{}{{1}})
+	mustExecMatch(c, se, "select c1 from t1 where c1 not in (select c1 from t2)", [][]interface{}{{2}})
+	mustExecMatch(c, se, "select c2 from t1 where exists (select * from t2 where t2.c2 = t1.c2)", [][]interface{}{{1}, {2}})
+	mustExecMatch(c, se, "select c2 from t1 where not exists (select * from t2 where t2.c1 = t1.c1)", [][]interface{}{{2}, {3}})
+	// The subquery for (null, 3) is empty.
+	mustExecMatch(c, se, "select c2 from t1 where c1 not in (select c1 from t2 where t2.c2 = t1.c2)", [][]interface{}{{2}, {3}})
+	mustExecMatch(c, se, "select c2 from t1 where c2 > 1 and c2 in (select c2 from t2 where t2.c1 = 1)", [][]interface{}{{2}})
+
+	// x NOT IN (...) is null if there is a null value in the subquery.
+	mustExecSQL(c, se, "insert into t2 values (null, 3)")
+	mustExecMatch(c, se, "select c1 from t1 where c1 not in (select c1 from t2)", nil)
+	mustExecMatch(c, se, "select c2 from t1 where c1 not in (select c1 from t2 where t2.c2 = t1.c2)", [][]interface{}{{2}})
+	mustExecMatch(c, se, "select c2 from t1 where c2 not in (select c2 from t2 where t2.c1 = t1.c1)", [][]interface{}{{2}, {3}})
+
+	r := mustExecSQL(c, se, "explain select c1 from t1 where c1 in (select c1 from t2)")
+	rows, err := r.Rows(-1, 0)
+	c.Assert(err, IsNil)
+	c.Assert(fmt.Sprint(rows[0]), Matches, ".*Compute SEMI .* join on c1 IN .*")
+	mustExecSQL(c, se, "drop table t1, t2")
+}
+
+func (s *testSessionSuite) TestPreparedPlanCache(c *C) {
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+
+	mustExecSQL(c, se, "drop table if exists t")
+	mustExecSQL(c, se, "create table t (c1 int, c2 int)")
+	mustExecSQL(c, se, "insert into t values (1, 1), (2, 2), (3, 3)")
+
+	id, _, _, err := se.PrepareStmt("select c1 from t where c2 > ? order by c1")
+	c.Assert(err, IsNil)
+	execMatch := func(arg interface{}, expected [][]interface{}) {
+		r, err := se.ExecutePreparedStmt(id, arg)
+		c.Assert(err, IsNil)
+		rows, err := r.Rows(-1, 0)
+		c.Assert(err, IsNil)
+		matches(c, rows, expected)
+	}
+	// The cached plan is reused with the parameters rebound.
+	execMatch(1, [][]interface{}{{2}, {3}})
+	execMatch(2, [][]interface{}{{3}})
+	execMatch(0, [][]interface{}{{1}, {2}, {3}})
+
+	// Overlapping recordsets of the same statement don't share the execution state.
+	r1, err := se.ExecutePreparedStmt(id, 0)
+	c.Assert(err, IsNil)
+	row, err := r1.Next()
+	c.Assert(err, IsNil)
+	c.Assert(row.Data, DeepEquals, []interface{}{int64(1)})
+	r2, err := se.ExecutePreparedStmt(id, 0)
+	c.Assert(err, IsNil)
+	row, err = r2.Next()
+	c.Assert(err, IsNil)
+	c.Assert(row.Data, DeepEquals, []interface{}{int64(1)})
+	rows, err := r1.Rows(-1, 0)
+	c.Assert(err, IsNil)
+	matches(c, rows, [][]interface{}{{2}, {3}})
+	rows, err = r2.Rows(-1, 0)
+	c.Assert(err, IsNil)
+	matches(c, rows, [][]interface{}{{2}, {3}})
+	c.Assert(r1.Close(), IsNil)
+	execMatch(1, [][]interface{}{{2}, {3}})
+
+	// The plan is rebuilt after the schema is changed.
+	mustExecSQL(c, se, "drop table t")
+	mustExecSQL(c, se, "create table t (c1 int, c2 int)")
+	mustExecSQL(c, se, "insert into t values (4, 4)")
+	execMatch(1, [][]interface{}{{4}})
+
+	mustExecSQL(c, se, "set @@session.tidb_plan_cache_size = 0")
+	execMatch(3, [][]interface{}{{4}})
+	execMatch(4, nil)
+	mustExecSQL(c, se, "set @@session.tidb_plan_cache_size = 100")
+
+	c.Assert(se.DropPreparedStmt(id), IsNil)
+	_, err = se.ExecutePreparedStmt(id, 1)
+	c.Assert(err, NotNil)
+	mustExecSQL(c, se, "drop table t")
+}
+
+func (s *testSessionSuite) TestCoveringIndex(c *C) {
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+
+	mustExecSQL(c, se, "drop table if exists t")
+	mustExecSQL(c, se, "create table t (id int primary key, email varchar(64), v int, index e (email, id))")
+	mustExecSQL(c, se, "insert into t values (-10, 'a', 1), (30, 'c', 2), (20, 'b', 3)")
+	mustExecMatch(c, se, "select id, email from t where email > 'a'", [][]interface{}{{20, "b"}, {30, "c"}})
+
+	// The rows with negative or updated primary keys are still found by the scans.
+	mustExecSQL(c, se, "update t set id = -40 where id = 20")
+	mustExecMatch(c, se, "select id, email from t where email > 'a'", [][]interface{}{{-40, "b"}, {30, "c"}})
+	mustExecMatch(c, se, "select id, v from t", [][]interface{}{{-10, 1}, {30, 2}, {-40, 3}})
+	mustExecMatch(c, se, "select count(*) from t where id < 0", [][]interface{}{{2}})
+	mustExecSQL(c, se, "drop table t")
+}
+
+func (s *testSessionSuite) TestView(c *C) {
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+
+	mustExecSQL(c, se, "drop table if exists t")
+	mustExecSQL(c, se, "create table t (c1 int, c2 int)")
+	mustExecSQL(c, se, "insert into t values (1, 1), (2, 2), (3, 3)")
+	mustExecSQL(c, se, "create view v (a, b) as select c1, c2 * 10 from t where c1 > 1")
+	mustExecMatch(c, se, "select * from v order by a", [][]interface{}{{2, 20}, {3, 30}})
+	mustExecMatch(c, se, "select x.b from v as x join t on x.a = t.c1 where t.c2 = 3", [][]interface{}{{30}})
+	mustExecMatch(c, se, "show create view v", [][]interface{}{{"v", "CREATE VIEW `v` (`a`, `b`) AS select c1, c2 * 10 from t where c1 > 1"}})
+	mustExecMatch(c, se, "show full tables like 'v'", [][]interface{}{{"v", "VIEW"}})
+
+	// The view is expanded when it is planned, so it sees the new rows.
+	mustExecSQL(c, se, "insert into t values (4, 4)")
+	mustExecMatch(c, se, "select count(*) from v", [][]interface{}{{3}})
+
+	mustExecFailed(c, se, "create view v as select 1")
+	mustExecSQL(c, se, "create or replace view v as select c1 from t where c1 = 4")
+	mustExecMatch(c, se, "select * from v", [][]interface{}{{4}})
+	mustExecFailed(c, se, "create or replace view t as select 1")
+	mustExecFailed(c, se, "create view w (a) as select c1, c2 from t")
+	mustExecFailed(c, se, "create view w as select c1, c1 from t")
+
+	// A view on a view.
+	mustExecSQL(c, se, "create view w as select c1 + 1 as d from v")
+	mustExecMatch(c, se, "select d from w", [][]interface{}{{5}})
+
+	// Views are not base tables.
+	mustExecFailed(c, se, "insert into v values (5)")
+	mustExecFailed(c, se, "update v set c1 = 5")
+	mustExecFailed(c, se, "delete from v")
+	mustExecFailed(c, se, "truncate table v")
+	mustExecFailed(c, se, "drop table v")
+	mustExecFailed(c, se, "drop view t")
+
+	// The wildcard of a view gets the columns when the view is created.
+	mustExecSQL(c, se, "create view s as select * from t where c1 = 4")
+	mustExecSQL(c, se, "create view s2 as select *, c1 * 2 as d from t as x where c1 = 4")
+	mustExecSQL(c, se, "alter table t add column c3 int")
+	mustExecMatch(c, se, "select * from s", [][]interface{}{{4, 4}})
+	mustExecMatch(c, se, "select * from s2", [][]interface{}{{4, 4, 8}})
+	mustExecSQL(c, se, "drop view s, s2")
+
+	mustExecSQL(c, se, "drop view w, v")
+	mustExecFailed(c, se, "select * from v")
+	mustExecFailed(c, se, "drop view v")
+	mustExecSQL(c, se, "drop view if exists v")
+	mustExecSQL(c, se, "drop table t")
+}
+
+func (s *testSessionSuite) TestCTE(c *C) {
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+
+	mustExecSQL(c, se, "drop table if exists t")
+	mustExecSQL(c, se, "create table t (id int, parent int)")
+	mustExecSQL(c, se, "insert into t values (1, null), (2, 1), (3, 1), (4, 2), (5, 4)")
+
+	// A common table expression can be referred to several times.
+	mustExecMatch(c, se, "with c (a) as (select id from t where id < 3) select x.a, y.a from c as x join c as y on x.a < y.a", [][]interface{}{{1, 2}})
+	mustExecMatch(c, se, "with c as (select id from t where parent = 1), d as (select id + 10 as id from c) select * from d order by id", [][]interface{}{{12}, {13}})
+	mustExecMatch(c, se, "select count(*) from t where id in (with c as (select 1 union select 2) select * from c)", [][]interface{}{{2}})
+	mustExecFailed(c, se, "with c as (select 1), c as (select 2) select * from c")
+	mustExecFailed(c, se, "with c (a, b) as (select 1) select * from c")
+
+	// The names of common table expressions hide the tables.
+	mustExecMatch(c, se, "with t as (select 1 as id) select id from t", [][]interface{}{{1}})
+
+	mustExecMatch(c, se, "with recursive c (n) as (select 1 union all select n + 1 from c where n < 5) select sum(n) from c", [][]interface{}{{5 * 6 / 2}})
+	mustExecMatch(c, se, `with recursive c as (select id, 0 as depth from t where parent is null
+		union all select t.id, c.depth + 1 from t join c on t.parent = c.id) select id, depth from c order by id`,
+		[][]interface{}{{1, 0}, {2, 1}, {3, 1}, {4, 2}, {5, 3}})
+	// UNION DISTINCT ends the cycles.
+	mustExecMatch(c, se, "with recursive c (n) as (select 1 union select (n + 1) % 3 from c) select n from c order by n", [][]interface{}{{0}, {1}, {2}})
+
+	mustExecFailed(c, se, "with recursive c (n) as (select n from c union all select 1) select * from c")
+	mustExecFailed(c, se, "with recursive c (n) as (select 1 union all select 1 from t where id in (select n from c)) select * from c")
+	mustExecFailed(c, se, "with recursive c (n) as (select 1 union all select n + 1 from c) select * from c")
+	mustExecSQL(c, se, "set @@session.cte_max_recursion_depth = 10")
+	mustExecMatch(c, se, "with recursive c (n) as (select 1 union all select n + 1 from c where n < 11) select count(*) from c", [][]interface{}{{11}})
+	mustExecFailed(c, se, "with recursive c (n) as (select 1 union all select n + 1 from c where n < 12) select count(*) from c")
+	mustExecSQL(c, se, "drop table t")
+}
+
+func (s *testSessionSuite) TestWindowFunction(c *C) {
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+
+	mustExecSQL(c, se, "drop table if exists t")
+	mustExecSQL(c, se, "create table t (id int, dept char(1), salary int)")
+	mustExecSQL(c, se, `insert into t values (1, 'a', 100), (2, 'b', 300), (3, 'a', 200), (4, 'a', 200), (5, 'b', 100), (6, 'a', 400)`)
+
+	mustExecMatch(c, se, "select id, row_number() over (partition by dept order by salary desc, id) from t order by id",
+		[][]interface{}{{1, 4}, {2, 1}, {3, 2}, {4, 3}, {5, 2}, {6, 1}})
+	mustExecMatch(c, se, "select id, rank() over (order by salary), dense_rank() over (order by salary) from t order by id",
+		[][]interface{}{{1, 1, 1}, {2, 5, 3}, {3, 3, 2}, {4, 3, 2}, {5, 1, 1}, {6, 6, 4}})
+	mustExecMatch(c, se, "select id, lag(salary) over (partition by dept order by id), lead(salary, 1, 0) over (partition by dept order by id) from t order by id",
+		[][]interface{}{{1, nil, 200}, {2, nil, 100}, {3, 100, 200}, {4, 200, 400}, {5, 300, 0}, {6, 200, 0}})
+
+	// The running total includes the peers of the current row by default.
+	mustExecMatch(c, se, "select id, sum(salary) over (partition by dept order by salary) from t order by id",
+		[][]interface{}{{1, 100}, {2, 400}, {3, 500}, {4, 500}, {5, 100}, {6, 900}})
+	mustExecMatch(c, se, "select id, sum(salary) over (partition by dept order by id rows between 1 preceding and 1 following) from t order by id",
+		[][]interface{}{{1, 300}, {2, 400}, {3, 500}, {4, 800}, {5, 400}, {6, 600}})
+	mustExecMatch(c, se, "select id, count(*) over (order by salary range between 100 preceding and current row) from t order by id",
+		[][]interface{}{{1, 2}, {2, 3}, {3, 4}, {4, 4}, {5, 2}, {6, 2}})
+	mustExecMatch(c, se, "select id, first_value(id) over (partition by dept order by id), last_value(id) over (partition by dept) from t order by id",
+		[][]interface{}{{1, 1, 6}, {2, 2, 5}, {3, 1, 6}, {4, 1, 6}, {5, 2, 5}, {6, 1, 6}})
+
+	// The window functions are computed after GROUP BY.
+	mustExecMatch(c, se, "select dept, sum(salary), rank() over (order by sum(salary) desc) from t group by dept order by dept",
+		[][]interface{}{{"a", 900, 1}, {"b", 400, 2}})
+	// Top N per group.
+	mustExecMatch(c, se, "select id from (select id, row_number() over (partition by dept order by salary desc, id) as rn from t) as x where rn <= 2 order by id",
+		[][]interface{}{{2}, {3}, {5}, {6}})
+	mustExecMatch(c, se, "select id from t order by row_number() over (order by salary desc, id) limit 2",
+		[][]interface{}{{6}, {2}})
+
+	mustExecFailed(c, se, "select id from t where rank() over (order by id) > 1")
+	mustExecFailed(c, se, "select dept from t group by rank() over (order by id)")
+	mustExecFailed(c, se, "select sum(rank() over (order by id)) over () from t")
+	mustExecFailed(c, se, "select sum(salary) over (range 1 preceding) from t")
+	mustExecFailed(c, se, "select ntile(0) over () from t")
+	mustExecSQL(c, se, "drop table t")
+}
+
+func (s *testSessionSuite) TestIndexInList(c *C) {
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+
+	mustExecSQL(c, se, "drop table if exists t")
+	mustExecSQL(c, se, "create table t (c1 int primary key, c2 int, index c2 (c2))")
+	mustExecSQL(c, se, "insert into t values (1, 10), (2, 20), (3, 20), (4, null)")
+
+	mustExecMatch(c, se, "select c1 from t where c1 in (3, 1, null, 3, 5)", [][]interface{}{{1}, {3}})
+	mustExecMatch(c, se, "select c1 from t where c2 in (20, 10)", [][]interface{}{{1}, {2}, {3}})
+	mustExecMatch(c, se, "select c1 from t where c1 > 1 and c1 in (1, 2, 4)", [][]interface{}{{2}, {4}})
+	mustExecMatch(c, se, "select c1 from t where c1 in (null)", nil)
+	mustExecMatch(c, se, "select c1 from t where c1 not in (1, 2)", [][]interface{}{{3}, {4}})
+
+	r := mustExecSQL(c, se, "explain select c1 from t where c1 in (3, 1)")
+	rows, err := r.Rows(-1, 0)
+	c.Assert(err, IsNil)
+	c.Assert(fmt.Sprint(rows[0]), Matches, `.*using index "PRIMARY" where c1 in \[1,1\] \[3,3\].*`)
+	mustExecSQL(c, se, "drop table t")
+}
+
 func (s *testSessionSuite) TestShow(c *C) {
 	store := newStore(c, s.dbName)
 	se := newSession(c, store, s.dbName)
@@ -1524,3 +1779,126 @@ func mustExecFailed(c *C, se Session, sql string, args ...interface{}) {
 	}
 	c.Assert(err, NotNil)
 }
+
+func (s *testSessionSuite) TestStringFunctions(c *C) {
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+
+	mustExecMatch(c, se, "select lpad('hi', 5, '?'), rpad('hi', 4, 'ab'), right('foobar', 3), reverse('abc')",
+		[][]interface{}{{"???hi", "hiab", "bar", "cba"}})
+	mustExecMatch(c, se, "select ltrim('  a '), rtrim(' a  '), space(2), char_length('中文'), character_length('abc')",
+		[][]interface{}{{"a ", " a", "  ", 2, 3}})
+	mustExecMatch(c, se, "select instr('foobar', 'bar'), strcmp('a', 'b'), ascii('a'), char(77, 121, 83, 81, 76)",
+		[][]interface{}{{4, -1, 97, "MySQL"}})
+	mustExecMatch(c, se, "select hex('abc'), unhex('616263'), format(1234567.891, 2), substring_index('a.b.c', '.', -2)",
+		[][]interface{}{{"616263", "abc", "1,234,567.89", "b.c"}})
+	mustExecMatch(c, se, "select field('b', 'a', 'b'), find_in_set('c', 'a,b,c'), elt(2, 'a', 'b'), elt(3, 'a', 'b')",
+		[][]interface{}{{2, 3, "b", nil}})
+
+	mustExecSQL(c, se, "drop table if exists t")
+	mustExecSQL(c, se, "create table t (hex varchar(10), space int)")
+	mustExecSQL(c, se, "insert into t values ('ab', 1)")
+	mustExecMatch(c, se, "select hex(hex), space from t", [][]interface{}{{"6162", 1}})
+}
+
+func (s *testSessionSuite) TestMathFunctions(c *C) {
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+
+	mustExecSQL(c, se, "drop table if exists t")
+	mustExecSQL(c, se, "create table t (price decimal(10, 4), rate decimal(10, 2))")
+	mustExecSQL(c, se, "insert into t values (10.0050, 3.10), (-2.5000, 0.00)")
+	// The decimal values are rounded exactly, half away from zero.
+	mustExecMatch(c, se, "select round(price, 2), round(price), truncate(price, 1), floor(price), ceil(price), mod(price, rate) from t",
+		[][]interface{}{{"10.01", "10", "10.0", "10", "11", "0.7050"}, {"-2.50", "-3", "-2.5", "-3", "-2", nil}})
+	mustExecMatch(c, se, "select abs(price), sign(price), greatest(price, 0), least(price, 0) from t",
+		[][]interface{}{{"10.0050", 1, "10.0050", 0}, {"2.5000", -1, 0, "-2.5000"}})
+
+	mustExecMatch(c, se, "select round(2.5e0), round(125, -1), truncate(129, -1), mod(-34, 10), pow(2, 10), sqrt(-1)",
+		[][]interface{}{{2, 130, 120, -4, 1024, nil}})
+	mustExecMatch(c, se, "select conv('a', 16, 2), conv(-17, 10, -18), crc32('MySQL'), log(2, 65536), log10(100), ln(0)",
+		[][]interface{}{{"1010", "-H", 3259397556, 16, 2, nil}})
+	mustExecMatch(c, se, "select degrees(pi()), radians(180) = pi(), atan2(0, 1), acos(2)",
+		[][]interface{}{{180, 1, 0, nil}})
+}
+
+func (s *testSessionSuite) TestTimeZone(c *C) {
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+	se2 := newSession(c, store, s.dbName)
+
+	mustExecSQL(c, se, "drop table if exists t")
+	mustExecSQL(c, se, "create table t (id int, ts timestamp, dt datetime, index idx_ts (ts))")
+	mustExecSQL(c, se, "set time_zone = '+08:00'")
+	mustExecSQL(c, se, "insert into t values (1, '2015-11-13 10:00:00', '2015-11-13 10:00:00')")
+	mustExecMatch(c, se, "select ts, dt, unix_timestamp(ts) from t", [][]interface{}{{"2015-11-13 10:00:00", "2015-11-13 10:00:00", 1447380000}})
+
+	// TIMESTAMP is stored in UTC and read in the time zone of the session, DATETIME is not converted.
+	mustExecSQL(c, se2, "set time_zone = '+00:00'")
+	mustExecMatch(c, se2, "select ts, dt from t", [][]interface{}{{"2015-11-13 02:00:00", "2015-11-13 10:00:00"}})
+	mustExecMatch(c, se2, "select id from t where ts = '2015-11-13 02:00:00'", [][]interface{}{{1}})
+	mustExecMatch(c, se2, "select id from t where ts > '2015-11-13 01:59:59' and ts < '2015-11-13 02:00:01'", [][]interface{}{{1}})
+	mustExecSQL(c, se2, "set time_zone = 'Europe/Helsinki'")
+	mustExecMatch(c, se2, "select ts from t where ts = '2015-11-13 04:00:00'", [][]interface{}{{"2015-11-13 04:00:00"}})
+	mustExecSQL(c, se2, "update t set ts = '2015-07-01 12:00:00' where id = 1")
+	mustExecMatch(c, se, "select ts from t where ts = '2015-07-01 17:00:00'", [][]interface{}{{"2015-07-01 17:00:00"}})
+	mustExecSQL(c, se, "delete from t where ts = '2015-07-01 17:00:00'")
+	mustExecMatch(c, se2, "select count(*) from t", [][]interface{}{{0}})
+
+	mustExecMatch(c, se, "select convert_tz('2004-01-01 12:00:00', '+00:00', '+10:00'), convert_tz('2004-01-01 12:00:00', 'UTC', 'foo')",
+		[][]interface{}{{"2004-01-01 22:00:00", nil}})
+	mustExecMatch(c, se, "select @@time_zone", [][]interface{}{{"+08:00"}})
+	mustExecFailed(c, se, "set time_zone = 'foo'")
+	mustExecFailed(c, se, "set time_zone = '+14:00'")
+}
+
+func (s *testSessionSuite) TestDateFunctions(c *C) {
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+
+	mustExecSQL(c, se, "drop table if exists t")
+	mustExecSQL(c, se, "create table t (d datetime, s varchar(32))")
+	mustExecSQL(c, se, "insert into t values ('2009-10-04 22:23:00', '04/10/2009'), ('1999-01-01 00:00:00', 'x')")
+	mustExecMatch(c, se, "select date_format(d, '%W %D %M %Y'), date_format(d, '%X %V'), str_to_date(s, '%d/%m/%Y') from t",
+		[][]interface{}{{"Sunday 4th October 2009", "2009 40", "2009-10-04"}, {"Friday 1st January 1999", "1998 52", nil}})
+	mustExecMatch(c, se, "select dayname(d), monthname(d), last_day(d), datediff(d, '1999-01-01') from t",
+		[][]interface{}{{"Sunday", "October", "2009-10-31", 3929}, {"Friday", "January", "1999-01-31", 0}})
+
+	mustExecMatch(c, se, "select timestampdiff(month, '2003-02-01', '2003-05-01'), timestampdiff(minute, '2003-02-01', '2003-05-01 12:05:55')",
+		[][]interface{}{{3, 128885}})
+	mustExecMatch(c, se, "select timestampadd(minute, 1, '2003-01-02'), timestampadd(month, 1, '2003-01-31'), makedate(2011, 32)",
+		[][]interface{}{{"2003-01-02 00:01:00", "2003-02-28 00:00:00", "2011-02-01"}})
+	mustExecMatch(c, se, "select time_format('100:00:00', '%H %k %h %I %l'), from_unixtime(unix_timestamp('2015-11-13 10:20:19'))",
+		[][]interface{}{{"100 100 04 04 4", "2015-11-13 10:20:19"}})
+}
+
+func (s *testSessionSuite) TestJSON(c *C) {
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+
+	mustExecSQL(c, se, "drop table if exists t")
+	mustExecSQL(c, se, "create table t (id int, j json)")
+	mustExecSQL(c, se, `insert into t values (1, '{"name": "Joe", "tags": [1, 2], "age": 30}'), (2, '[1, "a"]'), (3, null)`)
+	mustExecFailed(c, se, `insert into t values (4, '{"name": }')`)
+	mustExecFailed(c, se, "create index idx_j on t (j)")
+	mustExecFailed(c, se, "create table t1 (j json primary key)")
+
+	mustExecMatch(c, se, "select j, json_type(j) from t where id = 1",
+		[][]interface{}{{`{"age": 30, "name": "Joe", "tags": [1, 2]}`, "OBJECT"}})
+	mustExecMatch(c, se, "select j->'$.name', j->>'$.name', j->'$.tags[1]' from t where id = 1",
+		[][]interface{}{{`"Joe"`, "Joe", "2"}})
+	mustExecMatch(c, se, "select id from t where j->'$.name' = 'Joe' or j->'$[1]' = 'a' order by id",
+		[][]interface{}{{1}, {2}})
+	mustExecMatch(c, se, "select id from t where j->'$.age' > 20 and json_contains(j, '2', '$.tags')",
+		[][]interface{}{{1}})
+	mustExecMatch(c, se, "select json_extract(j, '$.name', '$.age'), j->'$.x' from t where id = 1",
+		[][]interface{}{{`["Joe", 30]`, nil}})
+
+	mustExecSQL(c, se, `update t set j = json_set(j, '$.age', 31, '$.city', 'Oslo') where id = 1`)
+	mustExecSQL(c, se, `update t set j = json_remove(j, '$[0]') where id = 2`)
+	mustExecMatch(c, se, "select j from t where id <= 2 order by id",
+		[][]interface{}{{`{"age": 31, "city": "Oslo", "name": "Joe", "tags": [1, 2]}`}, {`["a"]`}})
+
+	mustExecMatch(c, se, "select json_array(1, 'a', null), json_object('a', 1, 'b', json_array()), json_type('1.5')",
+		[][]interface{}{{`[1, "a", null]`, `{"a": 1, "b": []}`, "DOUBLE"}})
+}
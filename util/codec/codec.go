@@ -48,6 +48,28 @@ var sepKey = []byte{0x00, 0x00}
 // EncodeKey guarantees the encoded slice is in ascending order for comparison.
 // TODO: we may add more test to check its valiadation, especially for null type and multi indices.
 func EncodeKey(args ...interface{}) ([]byte, error) {
+	b, format, err := encodeValues(args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	// The comma is the seperator,
+	// e.g:		0x00, 0x00
+	// We need more tests to check its validation.
+	b = append(b, sepKey...)
+	b = append(b, format...)
+	return b, nil
+}
+
+// EncodeKeyPrefix encodes args like EncodeKey but without the seperator and the format,
+// so it is the prefix of the keys encoded by EncodeKey with args and any values following them.
+func EncodeKeyPrefix(args ...interface{}) ([]byte, error) {
+	b, _, err := encodeValues(args)
+	return b, errors.Trace(err)
+}
+
+// encodeValues encodes args and returns the encoded values and their format flags.
+func encodeValues(args []interface{}) ([]byte, []byte, error) {
 	var b []byte
 	format := make([]byte, 0, len(args))
 	for _, arg := range args {
@@ -123,6 +145,9 @@ func EncodeKey(args ...interface{}) ([]byte, error) {
 		case mysql.Set:
 			b = EncodeUint(b, uint64(v.ToNumber()))
 			format = append(format, formatUintFlag)
+		case mysql.JSON:
+			b = EncodeBytes(b, []byte(v.String()))
+			format = append(format, formatStringFlag)
 		case nil:
 			// We will 0x00, 0x00 for nil.
 			// The []byte{} will be encoded as 0x00, 0x01.
@@ -132,16 +157,10 @@ func EncodeKey(args ...interface{}) ([]byte, error) {
 			b = append(b, sepKey...)
 			format = append(format, formatNilFlag)
 		default:
-			return nil, errors.Errorf("unsupport encode type %T", arg)
+			return nil, nil, errors.Errorf("unsupport encode type %T", arg)
 		}
 	}
-
-	// The comma is the seperator,
-	// e.g:		0x00, 0x00
-	// We need more tests to check its validation.
-	b = append(b, sepKey...)
-	b = append(b, format...)
-	return b, nil
+	return b, format, nil
 }
 
 // StripEnd splits a slice b into two substrings separated by sepKey
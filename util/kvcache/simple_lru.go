@@ -0,0 +1,90 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvcache
+
+import (
+	"container/list"
+)
+
+// cacheEntry is the element of the list in SimpleLRUCache.
+type cacheEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+// SimpleLRUCache is a simple least recently used cache, it is not thread-safe.
+// The keys must be comparable.
+type SimpleLRUCache struct {
+	capacity int
+	elements map[interface{}]*list.Element
+	cache    *list.List
+}
+
+// NewSimpleLRUCache creates a SimpleLRUCache which holds at most capacity entries.
+func NewSimpleLRUCache(capacity int) *SimpleLRUCache {
+	return &SimpleLRUCache{
+		capacity: capacity,
+		elements: make(map[interface{}]*list.Element),
+		cache:    list.New(),
+	}
+}
+
+// Get returns the value of the key, and marks the entry as the most recently used one.
+func (l *SimpleLRUCache) Get(key interface{}) (interface{}, bool) {
+	element, ok := l.elements[key]
+	if !ok {
+		return nil, false
+	}
+	l.cache.MoveToFront(element)
+	return element.Value.(*cacheEntry).value, true
+}
+
+// Put puts the key and value into the cache, the least recently used entry is
+// evicted if the cache is full.
+func (l *SimpleLRUCache) Put(key interface{}, value interface{}) {
+	if element, ok := l.elements[key]; ok {
+		element.Value.(*cacheEntry).value = value
+		l.cache.MoveToFront(element)
+		return
+	}
+	if l.capacity <= 0 {
+		return
+	}
+	if l.cache.Len() >= l.capacity {
+		back := l.cache.Back()
+		delete(l.elements, back.Value.(*cacheEntry).key)
+		l.cache.Remove(back)
+	}
+	l.elements[key] = l.cache.PushFront(&cacheEntry{key: key, value: value})
+}
+
+// Delete deletes the key from the cache.
+func (l *SimpleLRUCache) Delete(key interface{}) {
+	element, ok := l.elements[key]
+	if !ok {
+		return
+	}
+	delete(l.elements, key)
+	l.cache.Remove(element)
+}
+
+// Size returns the number of entries in the cache.
+func (l *SimpleLRUCache) Size() int {
+	return l.cache.Len()
+}
+
+// Capacity returns the max number of entries in the cache.
+func (l *SimpleLRUCache) Capacity() int {
+	return l.capacity
+}
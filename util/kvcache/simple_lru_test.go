@@ -0,0 +1,83 @@
+// Copyright 2015 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvcache
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testLRUCacheSuite{})
+
+type testLRUCacheSuite struct {
+}
+
+func (s *testLRUCacheSuite) TestPutGet(c *C) {
+	lru := NewSimpleLRUCache(3)
+	c.Assert(lru.Capacity(), Equals, 3)
+	for i := 0; i < 3; i++ {
+		lru.Put(i, i*10)
+	}
+	c.Assert(lru.Size(), Equals, 3)
+
+	// 0 is used, so 1 is the least recently used one.
+	v, ok := lru.Get(0)
+	c.Assert(ok, IsTrue)
+	c.Assert(v, Equals, 0)
+	lru.Put(3, 30)
+	c.Assert(lru.Size(), Equals, 3)
+	_, ok = lru.Get(1)
+	c.Assert(ok, IsFalse)
+
+	// Put an existing key updates its value without eviction.
+	lru.Put(2, 21)
+	c.Assert(lru.Size(), Equals, 3)
+	v, ok = lru.Get(2)
+	c.Assert(ok, IsTrue)
+	c.Assert(v, Equals, 21)
+	for _, k := range []int{0, 3} {
+		_, ok = lru.Get(k)
+		c.Assert(ok, IsTrue)
+	}
+
+	lru.Delete(3)
+	lru.Delete(4)
+	c.Assert(lru.Size(), Equals, 2)
+	_, ok = lru.Get(3)
+	c.Assert(ok, IsFalse)
+
+	type key struct {
+		name    string
+		version int64
+	}
+	lru.Put(key{"a", 1}, "a1")
+	v, ok = lru.Get(key{"a", 1})
+	c.Assert(ok, IsTrue)
+	c.Assert(v, Equals, "a1")
+	_, ok = lru.Get(key{"a", 2})
+	c.Assert(ok, IsFalse)
+}
+
+func (s *testLRUCacheSuite) TestZeroCapacity(c *C) {
+	lru := NewSimpleLRUCache(0)
+	lru.Put(1, 1)
+	c.Assert(lru.Size(), Equals, 0)
+	_, ok := lru.Get(1)
+	c.Assert(ok, IsFalse)
+}
@@ -94,6 +94,16 @@ func compareStringFloat(s string, a float64) (int, error) {
 	return -n, errors.Trace(err)
 }
 
+// compareJSON compares the JSON x with a value y of other type, y is
+// converted to a JSON scalar first, so a string is compared as a JSON string.
+func compareJSON(x mysql.JSON, y interface{}) (int, error) {
+	j, err := mysql.ConvertToJSON(y)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return x.Compare(j), nil
+}
+
 func coerceCompare(a, b interface{}) (x interface{}, y interface{}, err error) {
 	rowTypeNum := 0
 	x, y = Coerce(a, b)
@@ -177,6 +187,9 @@ func Compare(a, b interface{}) (int, error) {
 			return CompareFloat64(x, y), nil
 		case string:
 			return compareFloatString(x, y)
+		case mysql.JSON:
+			n, err := compareJSON(y, x)
+			return -n, errors.Trace(err)
 		}
 	case int64:
 		switch y := b.(type) {
@@ -194,6 +207,9 @@ func Compare(a, b interface{}) (int, error) {
 			return CompareFloat64(float64(x), y.ToNumber()), nil
 		case mysql.Set:
 			return CompareFloat64(float64(x), y.ToNumber()), nil
+		case mysql.JSON:
+			n, err := compareJSON(y, x)
+			return -n, errors.Trace(err)
 		}
 	case uint64:
 		switch y := b.(type) {
@@ -211,6 +227,9 @@ func Compare(a, b interface{}) (int, error) {
 			return CompareFloat64(float64(x), y.ToNumber()), nil
 		case mysql.Set:
 			return CompareFloat64(float64(x), y.ToNumber()), nil
+		case mysql.JSON:
+			n, err := compareJSON(y, x)
+			return -n, errors.Trace(err)
 		}
 	case mysql.Decimal:
 		switch y := b.(type) {
@@ -222,6 +241,9 @@ func Compare(a, b interface{}) (int, error) {
 				return 0, errors.Trace(err)
 			}
 			return x.Cmp(f), nil
+		case mysql.JSON:
+			n, err := compareJSON(y, x)
+			return -n, errors.Trace(err)
 		}
 	case string:
 		switch y := b.(type) {
@@ -253,6 +275,9 @@ func Compare(a, b interface{}) (int, error) {
 			return CompareString(x, y.String()), nil
 		case mysql.Set:
 			return CompareString(x, y.String()), nil
+		case mysql.JSON:
+			n, err := compareJSON(y, x)
+			return -n, errors.Trace(err)
 		}
 	case mysql.Time:
 		switch y := b.(type) {
@@ -336,6 +361,13 @@ func Compare(a, b interface{}) (int, error) {
 		case mysql.Set:
 			return CompareFloat64(x.ToNumber(), y.ToNumber()), nil
 		}
+	case mysql.JSON:
+		switch y := b.(type) {
+		case mysql.JSON:
+			return x.Compare(y), nil
+		case float64, int64, uint64, mysql.Decimal, string:
+			return compareJSON(x, y)
+		}
 	}
 
 	return 0, errors.Errorf("invalid comapre type %T cmp %T", a, b)
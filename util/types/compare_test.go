@@ -163,6 +163,16 @@ func (s *testCompareSuite) TestCompare(c *check.C) {
 		{mysql.Set{Name: "a", Value: 1}, mysql.Hex{Value: 1}, 0},
 		{mysql.Set{Name: "a", Value: 1}, mysql.Enum{Name: "a", Value: 1}, 0},
 		{mysql.Set{Name: "a", Value: 1}, mysql.Set{Name: "a", Value: 1}, 0},
+
+		{mysql.JSON{Value: int64(1)}, 1, 0},
+		{mysql.JSON{Value: 1.5}, int64(1), 1},
+		{mysql.JSON{Value: uint64(3)}, float64(2.5), 1},
+		{mysql.JSON{Value: int64(2)}, mysql.NewDecimalFromInt(2, 0), 0},
+		{mysql.JSON{Value: "abc"}, "abc", 0},
+		{mysql.JSON{Value: "1"}, int64(1), 1},
+		{mysql.JSON{Value: nil}, nil, 1},
+		{mysql.JSON{Value: []interface{}{int64(1)}}, mysql.JSON{Value: []interface{}{int64(2)}}, -1},
+		{mysql.JSON{Value: map[string]interface{}{}}, "{}", 1},
 	}
 
 	for _, t := range cmpTbl {
@@ -151,6 +151,8 @@ func convertToInt(val interface{}, target *FieldType) (int64, error) {
 		return convertFloatToInt(v.ToNumber(), lowerBound, upperBound, tp)
 	case mysql.Set:
 		return convertFloatToInt(v.ToNumber(), lowerBound, upperBound, tp)
+	case mysql.JSON:
+		return convertToInt(v.Value, target)
 	case *DataItem:
 		return convertToInt(v.Data, target)
 	}
@@ -240,6 +242,8 @@ func convertToUint(val interface{}, target *FieldType) (uint64, error) {
 		return convertFloatToUint(v.ToNumber(), upperBound, tp)
 	case mysql.Set:
 		return convertFloatToUint(v.ToNumber(), upperBound, tp)
+	case mysql.JSON:
+		return convertToUint(v.Value, target)
 	case *DataItem:
 		return convertToUint(v.Data, target)
 	}
@@ -389,6 +393,18 @@ func Convert(val interface{}, target *FieldType) (v interface{}, err error) {
 			return maxValue, overflow(val, tp)
 		}
 		return mysql.Bit{Value: x, Width: width}, nil
+	case mysql.TypeJSON:
+		// Only the JSON values and the valid JSON texts can be stored.
+		switch x := val.(type) {
+		case mysql.JSON:
+			return x, nil
+		case string:
+			return mysql.ParseJSON(x)
+		case []byte:
+			return mysql.ParseJSON(string(x))
+		default:
+			return invConv(val, tp)
+		}
 	case mysql.TypeDecimal, mysql.TypeNewDecimal:
 		x, err := ToDecimal(val)
 		if err != nil {
@@ -595,6 +611,8 @@ func ToFloat64(value interface{}) (float64, error) {
 		return v.ToNumber(), nil
 	case mysql.Set:
 		return v.ToNumber(), nil
+	case mysql.JSON:
+		return ToFloat64(v.Value)
 	case *DataItem:
 		return ToFloat64(v.Data)
 	default:
@@ -659,6 +677,8 @@ func ToString(value interface{}) (string, error) {
 		return v.String(), nil
 	case mysql.Set:
 		return v.String(), nil
+	case mysql.JSON:
+		return v.String(), nil
 	case *DataItem:
 		return ToString(v.Data)
 	default:
@@ -718,6 +738,8 @@ func ToBool(value interface{}) (int64, error) {
 		isZero = (v.ToNumber() == 0)
 	case mysql.Set:
 		isZero = (v.ToNumber() == 0)
+	case mysql.JSON:
+		return ToBool(v.Value)
 	case *DataItem:
 		return ToBool(v.Data)
 	default:
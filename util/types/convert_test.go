@@ -193,6 +193,22 @@ func (s *testTypeConvertSuite) TestConvertType(c *check.C) {
 	_, err = Convert(2, ft)
 	c.Assert(err, check.NotNil)
 
+	// For TypeJSON
+	ft = NewFieldType(mysql.TypeJSON)
+	v, err = Convert(`{"a": [1, 2.5]}`, ft)
+	c.Assert(err, check.IsNil)
+	c.Assert(v.(mysql.JSON).String(), check.Equals, `{"a": [1, 2.5]}`)
+
+	v, err = Convert(mysql.JSON{Value: "a"}, ft)
+	c.Assert(err, check.IsNil)
+	c.Assert(v, check.Equals, mysql.JSON{Value: "a"})
+
+	_, err = Convert(`{"a"}`, ft)
+	c.Assert(err, check.NotNil)
+
+	_, err = Convert(int64(1), ft)
+	c.Assert(err, check.NotNil)
+
 	// For TypeNewDecimal
 	ft = NewFieldType(mysql.TypeNewDecimal)
 	ft.Decimal = 5
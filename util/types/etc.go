@@ -62,6 +62,7 @@ var type2Str = map[byte]string{
 	mysql.TypeFloat:      "float",
 	mysql.TypeGeometry:   "geometry",
 	mysql.TypeInt24:      "mediumint",
+	mysql.TypeJSON:       "json",
 	mysql.TypeLong:       "int",
 	mysql.TypeLonglong:   "bigint",
 	mysql.TypeLongBlob:   "longtext",
@@ -179,7 +180,7 @@ func IsOrderedType(v interface{}) (r bool) {
 		uint, uint8, uint16, uint32, uint64,
 		float32, float64, string, []byte,
 		mysql.Decimal, mysql.Time, mysql.Duration,
-		mysql.Hex, mysql.Bit, mysql.Enum, mysql.Set:
+		mysql.Hex, mysql.Bit, mysql.Enum, mysql.Set, mysql.JSON:
 		return true
 	}
 	return false
@@ -195,7 +196,7 @@ func Clone(from interface{}) (interface{}, error) {
 	case uint8, uint16, uint32, uint64, float32, float64,
 		int16, int8, bool, string, int, int64, int32,
 		mysql.Time, mysql.Duration, mysql.Decimal,
-		mysql.Hex, mysql.Bit, mysql.Enum, mysql.Set:
+		mysql.Hex, mysql.Bit, mysql.Enum, mysql.Set, mysql.JSON:
 		return x, nil
 	case []byte:
 		target := make([]byte, len(from.([]byte)))